@@ -0,0 +1,222 @@
+// Package admin implements the operational tasks of the admin commands. Every
+// change is recorded as an audit event within the transaction making it.
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/asdsec/thenut/audit"
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+const (
+	latestVersionTag      = "latest"
+	previousVersionTag    = ""
+	blockedSessionsChange = "blocked_sessions"
+)
+
+var (
+	ErrZeroAmount       = errors.New("amount must not be zero")
+	ErrNegativeBalance  = errors.New("balance must not become negative")
+	ErrEmptyVersion     = errors.New("version must not be empty")
+	ErrVersionPublished = errors.New("version is already the latest")
+)
+
+// Admin runs the operational tasks on behalf of the actor
+type Admin struct {
+	store db.Store
+	actor string
+}
+
+// New creates a new Admin recording its changes with the given actor
+func New(store db.Store, actor string) *Admin {
+	return &Admin{
+		store: store,
+		actor: actor,
+	}
+}
+
+// SetUserDisabled disables or enables the user. The sessions of a disabled
+// user are blocked, so that its refresh tokens cannot be renewed.
+func (admin *Admin) SetUserDisabled(ctx context.Context, username string, disabled bool) (db.User, error) {
+	action := audit.ActionEnableUser
+	if disabled {
+		action = audit.ActionDisableUser
+	}
+
+	var user db.User
+	_, err := admin.store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  admin.actor,
+		Action: action,
+		Target: audit.UserTarget(username),
+		Apply: func(q db.Querier) (interface{}, error) {
+			old, err := q.GetUser(ctx, username)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get user %s: %w", username, err)
+			}
+
+			user, err = q.SetUserDisabled(ctx, db.SetUserDisabledParams{
+				Username: username,
+				Disabled: disabled,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			diff := map[string]interface{}{
+				"disabled": audit.Change{Old: old.Disabled, New: user.Disabled},
+			}
+			if disabled {
+				blocked, err := q.BlockUserSessions(ctx, username)
+				if err != nil {
+					return nil, err
+				}
+				diff[blockedSessionsChange] = blocked
+			}
+			return diff, nil
+		},
+	})
+
+	return user, err
+}
+
+// PublishVersion makes the version the latest app version
+func (admin *Admin) PublishVersion(ctx context.Context, version string) (db.AppVersion, error) {
+	if version == "" {
+		return db.AppVersion{}, ErrEmptyVersion
+	}
+
+	var published db.AppVersion
+	_, err := admin.store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  admin.actor,
+		Action: audit.ActionPublishVersion,
+		Target: audit.VersionTarget,
+		Apply: func(q db.Querier) (interface{}, error) {
+			var oldVersion interface{}
+
+			latest, err := q.GetAppVersion(ctx, latestVersionTag)
+			switch {
+			case err == nil:
+				if latest.Version == version {
+					return nil, ErrVersionPublished
+				}
+				oldVersion = latest.Version
+
+				_, err = q.UpdateAppVersion(ctx, db.UpdateAppVersionParams{
+					ID:      latest.ID,
+					Tag:     previousVersionTag,
+					Version: latest.Version,
+				})
+				if err != nil {
+					return nil, err
+				}
+			case !errors.Is(err, sql.ErrNoRows):
+				return nil, err
+			}
+
+			published, err = q.CreateAppVersion(ctx, db.CreateAppVersionParams{
+				Tag:     latestVersionTag,
+				Version: version,
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]interface{}{
+				"version": audit.Change{Old: oldVersion, New: published.Version},
+			}, nil
+		},
+	})
+
+	return published, err
+}
+
+// RevokeSessions blocks the sessions of the user and returns how many are blocked
+func (admin *Admin) RevokeSessions(ctx context.Context, username string) (int64, error) {
+	var blocked int64
+	_, err := admin.store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  admin.actor,
+		Action: audit.ActionRevokeSessions,
+		Target: audit.UserTarget(username),
+		Apply: func(q db.Querier) (interface{}, error) {
+			_, err := q.GetUser(ctx, username)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get user %s: %w", username, err)
+			}
+
+			blocked, err = q.BlockUserSessions(ctx, username)
+			if err != nil {
+				return nil, err
+			}
+
+			return map[string]interface{}{
+				blockedSessionsChange: blocked,
+			}, nil
+		},
+	})
+
+	return blocked, err
+}
+
+// AdjustMerchantBalance adds the amount to the balance of the merchant, a
+// negative amount is subtracted. The balance cannot become negative.
+func (admin *Admin) AdjustMerchantBalance(ctx context.Context, merchantID int64, amount int64) (db.Merchant, error) {
+	if amount == 0 {
+		return db.Merchant{}, ErrZeroAmount
+	}
+
+	var merchant db.Merchant
+	_, err := admin.store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  admin.actor,
+		Action: audit.ActionAdjustBalance,
+		Target: audit.MerchantTarget(merchantID),
+		Apply: func(q db.Querier) (interface{}, error) {
+			var err error
+			merchant, err = q.AddMerchantBalance(ctx, db.AddMerchantBalanceParams{
+				ID:     merchantID,
+				Amount: amount,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("cannot update merchant %d: %w", merchantID, err)
+			}
+			if merchant.Balance < 0 {
+				return nil, ErrNegativeBalance
+			}
+
+			return map[string]interface{}{
+				"balance": audit.Change{Old: merchant.Balance - amount, New: merchant.Balance},
+			}, nil
+		},
+	})
+
+	return merchant, err
+}
+
+// RetryJob puts the dead job back into the queue, with its attempts reset
+func (admin *Admin) RetryJob(ctx context.Context, jobID int64) (db.Job, error) {
+	var job db.Job
+	_, err := admin.store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  admin.actor,
+		Action: audit.ActionRetryJob,
+		Target: audit.JobTarget(jobID),
+		Apply: func(q db.Querier) (interface{}, error) {
+			var err error
+			job, err = q.RequeueDeadJob(ctx, jobID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("job %d is not dead: %w", jobID, err)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("cannot retry job %d: %w", jobID, err)
+			}
+
+			return map[string]interface{}{
+				"status": audit.Change{Old: "dead", New: job.Status},
+			}, nil
+		},
+	})
+
+	return job, err
+}
@@ -0,0 +1,406 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/asdsec/thenut/audit"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+const testActor = "cli:tester"
+
+// expectAuditTx runs the change of the audit transaction against the mock
+// store and checks the recorded event
+func expectAuditTx(t *testing.T, store *mock_db.MockStore, action, target string, checkDiff func(diff map[string]interface{})) {
+	store.EXPECT().
+		AuditTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.AuditTxParams) (db.AuditEvent, error) {
+			require.Equal(t, testActor, arg.Actor)
+			require.Equal(t, action, arg.Action)
+			require.Equal(t, target, arg.Target)
+
+			diff, err := arg.Apply(store)
+			if err != nil {
+				return db.AuditEvent{}, err
+			}
+
+			data, err := json.Marshal(diff)
+			require.NoError(t, err)
+
+			var decoded map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			checkDiff(decoded)
+
+			return db.AuditEvent{Actor: arg.Actor, Action: arg.Action, Target: arg.Target, Diff: data}, nil
+		})
+}
+
+func TestSetUserDisabled(t *testing.T) {
+	user := db.User{Username: utils.RandomOwner()}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	expectAuditTx(t, store, audit.ActionDisableUser, audit.UserTarget(user.Username), func(diff map[string]interface{}) {
+		require.Equal(t, map[string]interface{}{"old": false, "new": true}, diff["disabled"])
+		require.Equal(t, float64(2), diff["blocked_sessions"])
+	})
+
+	disabledUser := user
+	disabledUser.Disabled = true
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		SetUserDisabled(gomock.Any(), gomock.Eq(db.SetUserDisabledParams{Username: user.Username, Disabled: true})).
+		Times(1).
+		Return(disabledUser, nil)
+	store.EXPECT().
+		BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(int64(2), nil)
+
+	result, err := New(store, testActor).SetUserDisabled(context.Background(), user.Username, true)
+	require.NoError(t, err)
+	require.True(t, result.Disabled)
+}
+
+func TestEnableUser(t *testing.T) {
+	user := db.User{Username: utils.RandomOwner(), Disabled: true}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	expectAuditTx(t, store, audit.ActionEnableUser, audit.UserTarget(user.Username), func(diff map[string]interface{}) {
+		require.Equal(t, map[string]interface{}{"old": true, "new": false}, diff["disabled"])
+		require.NotContains(t, diff, "blocked_sessions")
+	})
+
+	enabledUser := user
+	enabledUser.Disabled = false
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		SetUserDisabled(gomock.Any(), gomock.Eq(db.SetUserDisabledParams{Username: user.Username, Disabled: false})).
+		Times(1).
+		Return(enabledUser, nil)
+	store.EXPECT().
+		BlockUserSessions(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	result, err := New(store, testActor).SetUserDisabled(context.Background(), user.Username, false)
+	require.NoError(t, err)
+	require.False(t, result.Disabled)
+}
+
+func TestSetUserDisabledNotFound(t *testing.T) {
+	username := utils.RandomOwner()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	expectAuditTx(t, store, audit.ActionDisableUser, audit.UserTarget(username), nil)
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+	store.EXPECT().
+		SetUserDisabled(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	_, err := New(store, testActor).SetUserDisabled(context.Background(), username, true)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestPublishVersion(t *testing.T) {
+	latest := db.AppVersion{ID: 1, Tag: latestVersionTag, Version: "1.0.0"}
+
+	testCases := []struct {
+		name       string
+		version    string
+		buildStubs func(store *mock_db.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:    "Ok",
+			version: "1.1.0",
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionPublishVersion, audit.VersionTarget, func(diff map[string]interface{}) {
+					require.Equal(t, map[string]interface{}{"old": "1.0.0", "new": "1.1.0"}, diff["version"])
+				})
+
+				store.EXPECT().
+					GetAppVersion(gomock.Any(), gomock.Eq(latestVersionTag)).
+					Times(1).
+					Return(latest, nil)
+				store.EXPECT().
+					UpdateAppVersion(gomock.Any(), gomock.Eq(db.UpdateAppVersionParams{ID: latest.ID, Tag: previousVersionTag, Version: latest.Version})).
+					Times(1)
+				store.EXPECT().
+					CreateAppVersion(gomock.Any(), gomock.Eq(db.CreateAppVersionParams{Tag: latestVersionTag, Version: "1.1.0"})).
+					Times(1).
+					Return(db.AppVersion{ID: 2, Tag: latestVersionTag, Version: "1.1.0"}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:    "FirstVersion",
+			version: "1.0.0",
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionPublishVersion, audit.VersionTarget, func(diff map[string]interface{}) {
+					require.Equal(t, map[string]interface{}{"old": nil, "new": "1.0.0"}, diff["version"])
+				})
+
+				store.EXPECT().
+					GetAppVersion(gomock.Any(), gomock.Eq(latestVersionTag)).
+					Times(1).
+					Return(db.AppVersion{}, sql.ErrNoRows)
+				store.EXPECT().
+					UpdateAppVersion(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateAppVersion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(latest, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:    "AlreadyPublished",
+			version: latest.Version,
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionPublishVersion, audit.VersionTarget, nil)
+
+				store.EXPECT().
+					GetAppVersion(gomock.Any(), gomock.Eq(latestVersionTag)).
+					Times(1).
+					Return(latest, nil)
+				store.EXPECT().
+					CreateAppVersion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrVersionPublished)
+			},
+		},
+		{
+			name:    "EmptyVersion",
+			version: "",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrEmptyVersion)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			_, err := New(store, testActor).PublishVersion(context.Background(), tc.version)
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestRevokeSessions(t *testing.T) {
+	user := db.User{Username: utils.RandomOwner()}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	expectAuditTx(t, store, audit.ActionRevokeSessions, audit.UserTarget(user.Username), func(diff map[string]interface{}) {
+		require.Equal(t, float64(3), diff["blocked_sessions"])
+	})
+
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	store.EXPECT().
+		BlockUserSessions(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(int64(3), nil)
+
+	blocked, err := New(store, testActor).RevokeSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), blocked)
+}
+
+func TestAdjustMerchantBalance(t *testing.T) {
+	merchant := db.Merchant{ID: utils.RandomInt(1, 1000), Balance: 100}
+
+	testCases := []struct {
+		name       string
+		amount     int64
+		buildStubs func(store *mock_db.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:   "Credit",
+			amount: 50,
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionAdjustBalance, audit.MerchantTarget(merchant.ID), func(diff map[string]interface{}) {
+					require.Equal(t, map[string]interface{}{"old": float64(100), "new": float64(150)}, diff["balance"])
+				})
+
+				updated := merchant
+				updated.Balance = 150
+				store.EXPECT().
+					AddMerchantBalance(gomock.Any(), gomock.Eq(db.AddMerchantBalanceParams{ID: merchant.ID, Amount: 50})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "NegativeBalance",
+			amount: -150,
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionAdjustBalance, audit.MerchantTarget(merchant.ID), nil)
+
+				updated := merchant
+				updated.Balance = -50
+				store.EXPECT().
+					AddMerchantBalance(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrNegativeBalance)
+			},
+		},
+		{
+			name:   "MerchantNotFound",
+			amount: 50,
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionAdjustBalance, audit.MerchantTarget(merchant.ID), nil)
+
+				store.EXPECT().
+					AddMerchantBalance(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Merchant{}, sql.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+		{
+			name:   "ZeroAmount",
+			amount: 0,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					AuditTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrZeroAmount)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			_, err := New(store, testActor).AdjustMerchantBalance(context.Background(), merchant.ID, tc.amount)
+			tc.checkError(t, err)
+		})
+	}
+}
+
+func TestRetryJob(t *testing.T) {
+	jobID := utils.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mock_db.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionRetryJob, audit.JobTarget(jobID), func(diff map[string]interface{}) {
+					require.Equal(t, map[string]interface{}{"old": "dead", "new": "pending"}, diff["status"])
+				})
+
+				store.EXPECT().
+					RequeueDeadJob(gomock.Any(), gomock.Eq(jobID)).
+					Times(1).
+					Return(db.Job{ID: jobID, Status: "pending"}, nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "NotDead",
+			buildStubs: func(store *mock_db.MockStore) {
+				expectAuditTx(t, store, audit.ActionRetryJob, audit.JobTarget(jobID), nil)
+
+				store.EXPECT().
+					RequeueDeadJob(gomock.Any(), gomock.Eq(jobID)).
+					Times(1).
+					Return(db.Job{}, sql.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, sql.ErrNoRows)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			_, err := New(store, testActor).RetryJob(context.Background(), jobID)
+			tc.checkError(t, err)
+		})
+	}
+}
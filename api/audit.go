@@ -0,0 +1,137 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/asdsec/thenut/audit"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/gin-gonic/gin"
+)
+
+// recordAuditEvent records the action of the request. A failure is only logged
+// since the audited action is already done.
+func (server *Server) recordAuditEvent(ctx *gin.Context, actor, action, target string, diff interface{}) {
+	err := server.auditor.Record(ctx, audit.Event{
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+		RequestID: ctx.GetString(requestIDKey),
+		Diff:      diff,
+	})
+	if err != nil {
+		ctx.Error(fmt.Errorf("cannot record audit event %s: %w", action, err))
+	}
+}
+
+type auditEventResponse struct {
+	ID        int64           `json:"id"`
+	Actor     string          `json:"actor"`
+	Action    string          `json:"action"`
+	Target    string          `json:"target"`
+	IP        string          `json:"ip"`
+	UserAgent string          `json:"user_agent"`
+	RequestID string          `json:"request_id"`
+	Diff      json.RawMessage `json:"diff"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newAuditEventsResponse(events []db.AuditEvent) []auditEventResponse {
+	rsp := make([]auditEventResponse, len(events))
+	for i, event := range events {
+		rsp[i] = auditEventResponse{
+			ID:        event.ID,
+			Actor:     event.Actor,
+			Action:    event.Action,
+			Target:    event.Target,
+			IP:        event.Ip,
+			UserAgent: event.UserAgent,
+			RequestID: event.RequestID,
+			Diff:      event.Diff,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	return rsp
+}
+
+// auditEventFilter narrows the listed audit events, from and to are RFC 3339 times
+type auditEventFilter struct {
+	Actor  string    `form:"actor"`
+	Action string    `form:"action"`
+	Target string    `form:"target"`
+	From   time.Time `form:"from"`
+	To     time.Time `form:"to"`
+}
+
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
+		return
+	}
+
+	var filter auditEventFilter
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	actor := sql.NullString{String: filter.Actor, Valid: filter.Actor != ""}
+	action := sql.NullString{String: filter.Action, Valid: filter.Action != ""}
+	target := sql.NullString{String: filter.Target, Valid: filter.Target != ""}
+	from := sql.NullTime{Time: filter.From, Valid: !filter.From.IsZero()}
+	to := sql.NullTime{Time: filter.To, Valid: !filter.To.IsZero()}
+
+	if query.isOffset() {
+		arg := db.ListAuditEventsParams{
+			Actor:       actor,
+			Action:      action,
+			Target:      target,
+			FromTime:    from,
+			ToTime:      to,
+			LimitCount:  query.PageSize,
+			OffsetCount: query.offset(),
+		}
+
+		events, err := server.store.ListAuditEvents(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newAuditEventsResponse(events))
+		return
+	}
+
+	createdAt, id := cursor.keyset()
+	arg := db.ListAuditEventsByCursorParams{
+		Actor:           actor,
+		Action:          action,
+		Target:          target,
+		FromTime:        from,
+		ToTime:          to,
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
+	}
+
+	events, err := server.store.ListAuditEventsByCursor(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(events) > int(query.PageSize) {
+		events = events[:query.PageSize]
+		last := events[len(events)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	rsp.Data = newAuditEventsResponse(events)
+
+	ctx.JSON(http.StatusOK, rsp)
+}
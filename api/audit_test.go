@@ -0,0 +1,303 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/audit"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/token"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListAuditEventsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	moderator, _ := randomUser(t)
+	moderator.Role = utils.ModeratorRole
+	admin, _ := randomUser(t)
+	admin.Role = utils.AdminRole
+
+	n := 6
+	events := make([]db.AuditEvent, n)
+	for i := range events {
+		events[i] = randomAuditEvent(user.Username)
+	}
+
+	from := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Ok",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {fmt.Sprint(n)},
+				"actor":     {user.Username},
+				"action":    {audit.ActionLogin},
+				"from":      {from.Format(time.RFC3339)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+
+				arg := db.ListAuditEventsParams{
+					Actor:       sql.NullString{String: user.Username, Valid: true},
+					Action:      sql.NullString{String: audit.ActionLogin, Valid: true},
+					FromTime:    sql.NullTime{Time: from, Valid: true},
+					LimitCount:  int32(n),
+					OffsetCount: 0,
+				}
+
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var actual []auditEventResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+				require.NoError(t, err)
+				require.Equal(t, newAuditEventsResponse(events), actual)
+			},
+		},
+		{
+			name: "Cursor",
+			query: url.Values{
+				"page_size": {fmt.Sprint(n - 1)},
+				"target":    {audit.UserTarget(user.Username)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+
+				arg := db.ListAuditEventsByCursorParams{
+					Target:     sql.NullString{String: audit.UserTarget(user.Username), Valid: true},
+					LimitCount: int32(n),
+				}
+
+				store.EXPECT().
+					ListAuditEventsByCursor(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var page struct {
+					Data       []auditEventResponse `json:"data"`
+					NextCursor string               `json:"next_cursor"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &page)
+				require.NoError(t, err)
+				require.Equal(t, newAuditEventsResponse(events[:n-1]), page.Data)
+
+				last := events[n-2]
+				require.Equal(t, encodeCursor(last.CreatedAt, last.ID), page.NextCursor)
+			},
+		},
+		{
+			name: "NotAdmin",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {fmt.Sprint(n)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTime",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {fmt.Sprint(n)},
+				"from":      {"yesterday"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			query: url.Values{
+				"page_id":   {"1"},
+				"page_size": {fmt.Sprint(n)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+					Times(1).
+					Return(admin, nil)
+
+				store.EXPECT().
+					ListAuditEvents(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditEvent{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, newTestTokenMaker(t))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/audit/events?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLoginAuditEvents(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name     string
+		password string
+		action   string
+		status   int
+	}{
+		{
+			name:     "Ok",
+			password: password,
+			action:   audit.ActionLogin,
+			status:   http.StatusOK,
+		},
+		{
+			name:     "WrongPassword",
+			password: utils.RandomString(8),
+			action:   audit.ActionLoginFailed,
+			status:   http.StatusUnauthorized,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			store.EXPECT().
+				CreateSession(gomock.Any(), gomock.Any()).
+				AnyTimes()
+
+			server := newTestServer(t, store, newTestTokenMaker(t))
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"username": user.Username,
+				"password": tc.password,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("User-Agent", "thenut-test")
+			request.Header.Set(requestIDHeader, "request-1")
+			request.RemoteAddr = "127.0.0.1:41000"
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+
+			events := server.auditor.(*testAuditor).events
+			require.Len(t, events, 1)
+			require.Equal(t, user.Username, events[0].Actor)
+			require.Equal(t, tc.action, events[0].Action)
+			require.Equal(t, audit.UserTarget(user.Username), events[0].Target)
+			require.Equal(t, "thenut-test", events[0].UserAgent)
+			require.Equal(t, "request-1", events[0].RequestID)
+			require.Equal(t, "127.0.0.1", events[0].IP)
+		})
+	}
+}
+
+func randomAuditEvent(actor string) db.AuditEvent {
+	return db.AuditEvent{
+		ID:        utils.RandomInt(1, 1000),
+		Actor:     actor,
+		Action:    audit.ActionLogin,
+		Target:    audit.UserTarget(actor),
+		Ip:        "127.0.0.1",
+		UserAgent: "thenut-test",
+		RequestID: utils.RandomString(12),
+		Diff:      json.RawMessage(`{"session_id":"1"}`),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+}
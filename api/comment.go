@@ -6,6 +6,8 @@
 	"time"
 
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
 	"github.com/gin-gonic/gin"
 )
 
@@ -37,7 +39,7 @@ type createCommentRequest struct {
 func (server *Server) createPostComment(ctx *gin.Context) {
 	var req createCommentRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -52,18 +54,25 @@ func (server *Server) createPostComment(ctx *gin.Context) {
 		},
 	}
 
-	comment, err := server.store.CreateComment(ctx, arg)
+	comment, err := server.store.CreateCommentTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
+	server.metrics.IncEvent(metrics.EventCommentCreated)
+	server.notifyPostOwner(ctx, req.PostID, notification.TypePostCommented, authPayload.Username, map[string]interface{}{
+		"comment_id": comment.ID,
+	})
 	ctx.JSON(http.StatusOK, newCommentResponse(comment))
 }
 
-type listPostCommentsRequestUri struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+func newCommentsResponse(comments []db.Comment) []commentResponse {
+	rsp := make([]commentResponse, len(comments))
+	for i := range comments {
+		rsp[i] = newCommentResponse(comments[i])
+	}
+	return rsp
 }
 
 type listPostCommentsRequest struct {
@@ -72,40 +81,58 @@ type listPostCommentsRequest struct {
 
 func (server *Server) listPostComments(ctx *gin.Context) {
 	var req listPostCommentsRequest
-	var query listPostCommentsRequestUri
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	if err := ctx.ShouldBindQuery(&query); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
 		return
 	}
 
-	arg := db.ListPostCommentsParams{
-		PostID: sql.NullInt64{
-			Int64: req.PostID,
-			Valid: true,
-		},
-		Limit:  query.PageSize,
-		Offset: (query.PageID - 1) * query.PageSize,
+	postID := sql.NullInt64{
+		Int64: req.PostID,
+		Valid: true,
 	}
 
-	comments, err := server.store.ListPostComments(ctx, arg)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+	if query.isOffset() {
+		arg := db.ListPostCommentsParams{
+			PostID: postID,
+			Limit:  query.PageSize,
+			Offset: query.offset(),
+		}
+
+		comments, err := server.store.ListPostComments(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusOK, newCommentsResponse(comments))
 		return
 	}
 
-	rsp := make([]commentResponse, len(comments))
-	for i := range comments {
-		rsp[i] = newCommentResponse(comments[i])
+	createdAt, id := cursor.keyset()
+	arg := db.ListPostCommentsByCursorParams{
+		PostID:          postID,
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
+	}
+
+	comments, err := server.store.ListPostCommentsByCursor(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(comments) > int(query.PageSize) {
+		comments = comments[:query.PageSize]
+		last := comments[len(comments)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
 	}
+	rsp.Data = newCommentsResponse(comments)
 
 	ctx.JSON(http.StatusOK, rsp)
 }
@@ -55,9 +55,17 @@ func TestCreatePostCommentAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateComment(gomock.Any(), gomock.Eq(arg)).
+					CreateCommentTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(comment, nil)
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -75,7 +83,7 @@ func TestCreatePostCommentAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore) {
 				store.EXPECT().
-					CreateComment(gomock.Any(), gomock.Any()).
+					CreateCommentTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -93,7 +101,7 @@ func TestCreatePostCommentAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore) {
 				store.EXPECT().
-					CreateComment(gomock.Any(), gomock.Any()).
+					CreateCommentTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -121,7 +129,7 @@ func TestCreatePostCommentAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateComment(gomock.Any(), gomock.Eq(arg)).
+					CreateCommentTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(db.Comment{}, sql.ErrConnDone)
 			},
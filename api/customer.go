@@ -1,13 +1,12 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
+	"github.com/asdsec/thenut/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
 type createCustomerRequest struct {
@@ -17,28 +16,19 @@ type createCustomerRequest struct {
 func (server *Server) createCustomer(ctx *gin.Context) {
 	var req createCustomerRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := server.getAuthPayload(ctx)
 	if req.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
 	customer, err := server.store.CreateCustomer(ctx, req.Owner)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
@@ -52,75 +42,80 @@ type getCustomerRequest struct {
 func (server *Server) getCustomer(ctx *gin.Context) {
 	var req getCustomerRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	customer, err := server.store.GetCustomer(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if customer.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
+		return
+	}
+	if checkNotModified(ctx, customer.Version) {
 		return
 	}
 
+	setETag(ctx, customer.Version)
 	ctx.JSON(http.StatusOK, customer)
 }
 
 type updateCustomerRequest struct {
-	ID int64 `json:"id" binding:"required,min=1"`
-	// temp variable
-	// todo: handle real image
-	ImageUrl string `json:"image_url" binding:"required"`
+	ID int64 `form:"id" binding:"required,min=1"`
 }
 
 func (server *Server) updateCustomer(ctx *gin.Context) {
 	var req updateCustomerRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if err := ctx.ShouldBind(&req); err != nil {
+		writeBodyError(ctx, err)
+		return
+	}
+
+	upload, ok := readImageUpload(ctx, server, true)
+	if !ok {
 		return
 	}
 
 	customer, err := server.store.GetCustomer(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if customer.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
+		return
+	}
+	version, ok := checkIfMatch(ctx, customer.Version)
+	if !ok {
+		return
+	}
+
+	stored, ok := storeImageUpload(ctx, server, storage.CustomerFolder(customer.ID), upload)
+	if !ok {
 		return
 	}
 
 	arg := db.UpdateCustomerParams{
-		ID:       req.ID,
-		ImageUrl: req.ImageUrl,
+		ID:            req.ID,
+		ImageUrl:      stored.url,
+		ImageVariants: emptyImageVariants,
+		Version:       version,
 	}
 
 	customer, err = server.store.UpdateCustomer(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		deleteImageUpload(ctx, server, stored)
+		writeConditionalStoreError(ctx, err, version)
 		return
 	}
+	queueThumbnails(ctx, server, stored, media.ThumbnailArgs{Owner: media.OwnerCustomer, ID: customer.ID})
 
+	setETag(ctx, customer.Version)
 	ctx.JSON(http.StatusOK, customer)
 }
 
@@ -131,33 +126,24 @@ type deleteCustomerRequest struct {
 func (server *Server) deleteCustomer(ctx *gin.Context) {
 	var req deleteCustomerRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	customer, err := server.store.GetCustomer(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if customer.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
 	err = server.store.DeleteCustomer(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
@@ -13,6 +13,7 @@
 
 	mock_db "github.com/asdsec/thenut/db/mock"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
 	"github.com/asdsec/thenut/token"
 	"github.com/asdsec/thenut/utils"
 	"github.com/gin-gonic/gin"
@@ -220,6 +221,30 @@ func TestDeleteCustomerAPI(t *testing.T) {
 	}
 }
 
+type eqUpdateCustomerParamsMatcher struct {
+	id       int64
+	imageUrl eqImageUrlMatcher
+}
+
+func (e eqUpdateCustomerParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateCustomerParams)
+	if !ok {
+		return false
+	}
+
+	return arg.ID == e.id &&
+		e.imageUrl.Matches(arg.ImageUrl) &&
+		isPendingVariants(arg.ImageVariants)
+}
+
+func (e eqUpdateCustomerParamsMatcher) String() string {
+	return fmt.Sprintf("matches id %d and %v", e.id, e.imageUrl)
+}
+
+func EqUpdateCustomerParams(id int64, folder string) gomock.Matcher {
+	return eqUpdateCustomerParamsMatcher{id, isStoredImageUrl(folder)}
+}
+
 func TestUpdateCustomerAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	customer := randomCustomer(user.Username)
@@ -229,10 +254,13 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		ImageUrl:  utils.RandomImageUrl(),
 		CreatedAt: customer.CreatedAt,
 	}
+	image := randomImage(t, 128, 128)
+	folder := fmt.Sprintf("customers/%d", customer.ID)
 
 	testCases := []struct {
 		name          string
 		body          gin.H
+		image         []byte
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
 		buildStubs    func(store *mock_db.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
@@ -240,9 +268,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "Ok",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -252,15 +280,12 @@ func TestUpdateCustomerAPI(t *testing.T) {
 					Times(1).
 					Return(customer, nil)
 
-				arg := db.UpdateCustomerParams{
-					ID:       customer.ID,
-					ImageUrl: expected.ImageUrl,
-				}
-
 				store.EXPECT().
-					UpdateCustomer(gomock.Any(), gomock.Eq(arg)).
+					UpdateCustomer(gomock.Any(), EqUpdateCustomerParams(customer.ID, folder)).
 					Times(1).
 					Return(expected, nil)
+
+				expectThumbnailJob(store, folder, media.ThumbnailArgs{Owner: media.OwnerCustomer, ID: customer.ID})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -270,9 +295,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "InvalidCustomerID",
 			body: gin.H{
-				"id":        "invld", // invalid id
-				"image_url": expected.ImageUrl,
+				"id": "invld", // invalid id
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -292,9 +317,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "UnauthenticatedUser",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "invalid_username", time.Minute)
 			},
@@ -315,9 +340,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "NoAuthentication",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				// No authentication
 			},
@@ -337,9 +362,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "NotFoundGetCustomer",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -360,9 +385,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "InternalServerErrorGetCustomer",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -383,9 +408,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "NotFoundUpdateCustomer",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -395,13 +420,8 @@ func TestUpdateCustomerAPI(t *testing.T) {
 					Times(1).
 					Return(customer, nil)
 
-				arg := db.UpdateCustomerParams{
-					ID:       customer.ID,
-					ImageUrl: expected.ImageUrl,
-				}
-
 				store.EXPECT().
-					UpdateCustomer(gomock.Any(), gomock.Eq(arg)).
+					UpdateCustomer(gomock.Any(), EqUpdateCustomerParams(customer.ID, folder)).
 					Times(1).
 					Return(db.Customer{}, sql.ErrNoRows)
 			},
@@ -412,9 +432,9 @@ func TestUpdateCustomerAPI(t *testing.T) {
 		{
 			name: "InternalServerErrorUpdateCustomer",
 			body: gin.H{
-				"id":        customer.ID,
-				"image_url": expected.ImageUrl,
+				"id": customer.ID,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -424,13 +444,8 @@ func TestUpdateCustomerAPI(t *testing.T) {
 					Times(1).
 					Return(customer, nil)
 
-				arg := db.UpdateCustomerParams{
-					ID:       customer.ID,
-					ImageUrl: expected.ImageUrl,
-				}
-
 				store.EXPECT().
-					UpdateCustomer(gomock.Any(), gomock.Eq(arg)).
+					UpdateCustomer(gomock.Any(), EqUpdateCustomerParams(customer.ID, folder)).
 					Times(1).
 					Return(db.Customer{}, sql.ErrConnDone)
 			},
@@ -438,6 +453,93 @@ func TestUpdateCustomerAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "MissingImage",
+			body: gin.H{
+				"id": customer.ID,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedImageType",
+			body: gin.H{
+				"id": customer.ID,
+			},
+			image: []byte("GIF89a not really an image"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+			},
+		},
+		{
+			name: "ImageTooSmall",
+			body: gin.H{
+				"id": customer.ID,
+			},
+			image: randomImage(t, minImageDimension-1, minImageDimension),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ImageTooLarge",
+			body: gin.H{
+				"id": customer.ID,
+			},
+			image: make([]byte, testConfig.MaxUploadSize+1),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -454,12 +556,8 @@ func TestUpdateCustomerAPI(t *testing.T) {
 			server := newTestServer(t, store, tokenMaker)
 			recorder := httptest.NewRecorder()
 
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
 			url := "/accounts"
-			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
-			require.NoError(t, err)
+			request := newMultipartRequest(t, http.MethodPatch, url, tc.body, tc.image)
 
 			tc.setupAuth(t, request, tokenMaker)
 			server.router.ServeHTTP(recorder, request)
@@ -768,10 +866,11 @@ func TestCreateCustomerAPI(t *testing.T) {
 
 func randomCustomer(owner string) db.Customer {
 	return db.Customer{
-		ID:        utils.RandomInt(1, 1000),
-		Owner:     owner,
-		ImageUrl:  utils.RandomImageUrl(),
-		CreatedAt: utils.RandomBirthDate(),
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         owner,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: emptyImageVariants,
+		CreatedAt:     utils.RandomBirthDate(),
 	}
 }
 
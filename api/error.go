@@ -0,0 +1,187 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/asdsec/thenut/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+)
+
+// Error codes are part of the API, clients match them instead of the messages
+const (
+	codeInvalidRequest       = "invalid_request"
+	codeValidationFailed     = "validation_failed"
+	codeUnauthorized         = "unauthorized"
+	codeForbidden            = "forbidden"
+	codeNotFound             = "not_found"
+	codeAlreadyExists        = "already_exists"
+	codeInvalidReference     = "invalid_reference"
+	codeConstraintViolation  = "constraint_violation"
+	codeConflict             = "conflict"
+	codePreconditionFailed   = "precondition_failed"
+	codeUnprocessable        = "unprocessable"
+	codePayloadTooLarge      = "payload_too_large"
+	codeUnsupportedMediaType = "unsupported_media_type"
+	codeInternal             = "internal_error"
+)
+
+var (
+	errAccountNotOwned    = i18n.NewError("error.account_not_owned")
+	errUsernameNotOwned   = i18n.NewError("error.username_not_owned")
+	errUserDisabled       = i18n.NewError("error.user_disabled")
+	errPreconditionFailed = i18n.NewError("error.precondition_failed")
+)
+
+// apiError is the body of every error response
+type apiError struct {
+	Status  int          `json:"-"`
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Details []fieldError `json:"details,omitempty"`
+}
+
+func (e apiError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// fieldError describes why a single request field failed the validation
+type fieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// statusCodes is the default error code of the statuses
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            codeInvalidRequest,
+	http.StatusUnauthorized:          codeUnauthorized,
+	http.StatusForbidden:             codeForbidden,
+	http.StatusNotFound:              codeNotFound,
+	http.StatusConflict:              codeConflict,
+	http.StatusPreconditionFailed:    codePreconditionFailed,
+	http.StatusRequestEntityTooLarge: codePayloadTooLarge,
+	http.StatusUnsupportedMediaType:  codeUnsupportedMediaType,
+	http.StatusUnprocessableEntity:   codeUnprocessable,
+	http.StatusInternalServerError:   codeInternal,
+}
+
+// newAPIError converts the error into an apiError with the messages in the
+// locale. Validation, database and postgres errors are mapped here so that
+// handlers only pick the status of their own errors, and the messages of
+// internal errors never reach clients.
+func newAPIError(locale i18n.Locale, status int, err error) apiError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return apiError{
+			Status:  http.StatusBadRequest,
+			Code:    codeValidationFailed,
+			Message: locale.T("error.request_validation_failed"),
+			Details: newFieldErrors(locale, validationErrs),
+		}
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return apiError{
+			Status:  http.StatusNotFound,
+			Code:    codeNotFound,
+			Message: locale.T("error.resource_not_found"),
+		}
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return apiError{
+				Status:  http.StatusForbidden,
+				Code:    codeAlreadyExists,
+				Message: locale.T("error.resource_already_exists"),
+			}
+		case "foreign_key_violation":
+			return apiError{
+				Status:  http.StatusForbidden,
+				Code:    codeInvalidReference,
+				Message: locale.T("error.invalid_reference"),
+			}
+		case "check_violation":
+			return apiError{
+				Status:  http.StatusBadRequest,
+				Code:    codeConstraintViolation,
+				Message: locale.T("error.constraint_violation"),
+			}
+		}
+		status = http.StatusInternalServerError
+	}
+
+	code, ok := statusCodes[status]
+	if !ok {
+		code = codeInternal
+	}
+
+	message := err.Error()
+	var localizedErr *i18n.Error
+	if errors.As(err, &localizedErr) {
+		message = localizedErr.Message(locale)
+	}
+	if status >= http.StatusInternalServerError {
+		message = locale.T("error.internal")
+	}
+
+	return apiError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+// writeError aborts the request with the error response of err in the language
+// of the Accept-Language header. The status is used unless the error is one of
+// the centrally mapped ones.
+func writeError(ctx *gin.Context, status int, err error) {
+	locale := i18n.Match(ctx.GetHeader("Accept-Language"))
+	apiErr := newAPIError(locale, status, err)
+	ctx.Set(errorCodeKey, apiErr.Code)
+	if apiErr.Status >= http.StatusInternalServerError {
+		// keep the original error for the logs since the response hides it
+		ctx.Error(err)
+	}
+	ctx.AbortWithStatusJSON(apiErr.Status, apiErr)
+}
+
+// writeStoreError writes the error response of a failed store call
+func writeStoreError(ctx *gin.Context, err error) {
+	writeError(ctx, http.StatusInternalServerError, err)
+}
+
+func newFieldErrors(locale i18n.Locale, validationErrs validator.ValidationErrors) []fieldError {
+	details := make([]fieldError, len(validationErrs))
+	for i, fe := range validationErrs {
+		details[i] = fieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: locale.TranslateField(fe),
+		}
+	}
+	return details
+}
+
+// fieldName reports the request name of the struct field in validation errors
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
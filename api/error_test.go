@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asdsec/thenut/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAPIError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		status  int
+		err     error
+		checkFn func(t *testing.T, apiErr apiError)
+	}{
+		{
+			name:   "HandlerError",
+			status: http.StatusUnauthorized,
+			err:    errors.New("account does not belong to the authenticated user"),
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusUnauthorized, apiErr.Status)
+				require.Equal(t, codeUnauthorized, apiErr.Code)
+				require.Equal(t, "account does not belong to the authenticated user", apiErr.Message)
+			},
+		},
+		{
+			name:   "LocalizedError",
+			status: http.StatusUnauthorized,
+			err:    errAccountNotOwned,
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusUnauthorized, apiErr.Status)
+				require.Equal(t, codeUnauthorized, apiErr.Code)
+				require.Equal(t, errAccountNotOwned.Error(), apiErr.Message)
+			},
+		},
+		{
+			name:   "NotFound",
+			status: http.StatusInternalServerError,
+			err:    sql.ErrNoRows,
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusNotFound, apiErr.Status)
+				require.Equal(t, codeNotFound, apiErr.Code)
+			},
+		},
+		{
+			name:   "UniqueViolation",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23505", Message: "duplicate key value violates unique constraint \"users_email_key\""},
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusForbidden, apiErr.Status)
+				require.Equal(t, codeAlreadyExists, apiErr.Code)
+				require.NotContains(t, apiErr.Message, "users_email_key")
+			},
+		},
+		{
+			name:   "ForeignKeyViolation",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23503"},
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusForbidden, apiErr.Status)
+				require.Equal(t, codeInvalidReference, apiErr.Code)
+			},
+		},
+		{
+			name:   "CheckViolation",
+			status: http.StatusInternalServerError,
+			err:    &pq.Error{Code: "23514"},
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusBadRequest, apiErr.Status)
+				require.Equal(t, codeConstraintViolation, apiErr.Code)
+			},
+		},
+		{
+			name:   "OtherPostgresError",
+			status: http.StatusBadRequest,
+			err:    &pq.Error{Code: "42P01", Message: "relation \"users\" does not exist"},
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusInternalServerError, apiErr.Status)
+				require.Equal(t, codeInternal, apiErr.Code)
+				require.NotContains(t, apiErr.Message, "relation")
+			},
+		},
+		{
+			name:   "InternalError",
+			status: http.StatusInternalServerError,
+			err:    sql.ErrConnDone,
+			checkFn: func(t *testing.T, apiErr apiError) {
+				require.Equal(t, http.StatusInternalServerError, apiErr.Status)
+				require.Equal(t, codeInternal, apiErr.Code)
+				require.Equal(t, "internal server error", apiErr.Message)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.checkFn(t, newAPIError(i18n.Default(), tc.status, tc.err))
+		})
+	}
+}
+
+func TestLocalizedAPIError(t *testing.T) {
+	apiErr := newAPIError(i18n.Match("tr-TR,tr;q=0.9,en;q=0.8"), http.StatusUnauthorized, errAccountNotOwned)
+	require.Equal(t, codeUnauthorized, apiErr.Code)
+	require.Equal(t, "hesap kimliği doğrulanan kullanıcıya ait değil", apiErr.Message)
+
+	apiErr = newAPIError(i18n.Match("tr"), http.StatusInternalServerError, sql.ErrConnDone)
+	require.Equal(t, codeInternal, apiErr.Code)
+	require.Equal(t, "sunucu hatası", apiErr.Message)
+}
+
+func TestValidationErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name            string
+		acceptLanguage  string
+		usernameMessage string
+		passwordMessage string
+	}{
+		{
+			name:            "English",
+			acceptLanguage:  "en-US,en;q=0.9",
+			usernameMessage: "username can only contain alphanumeric characters",
+			passwordMessage: "password must be at least 6 characters in length",
+		},
+		{
+			name:            "Turkish",
+			acceptLanguage:  "tr-TR",
+			usernameMessage: "username yalnızca alfanümerik karakterler içerebilir",
+			passwordMessage: "password en az 6 karakter uzunluğunda olmalıdır",
+		},
+		{
+			name:            "UnsupportedLanguage",
+			acceptLanguage:  "de-DE",
+			usernameMessage: "username can only contain alphanumeric characters",
+			passwordMessage: "password must be at least 6 characters in length",
+		},
+	}
+
+	server := newTestServer(t, nil, newTestTokenMaker(t))
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"username": "bad user",
+				"password": "123",
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(data))
+			require.NoError(t, err)
+			request.Header.Set("Accept-Language", tc.acceptLanguage)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+			var rsp apiError
+			err = json.Unmarshal(recorder.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+			require.Equal(t, codeValidationFailed, rsp.Code)
+			require.Len(t, rsp.Details, 2)
+
+			require.Equal(t, "username", rsp.Details[0].Field)
+			require.Equal(t, "alphanum", rsp.Details[0].Rule)
+			require.Equal(t, tc.usernameMessage, rsp.Details[0].Message)
+
+			require.Equal(t, "password", rsp.Details[1].Field)
+			require.Equal(t, "min", rsp.Details[1].Rule)
+			require.Equal(t, tc.passwordMessage, rsp.Details[1].Message)
+		})
+	}
+}
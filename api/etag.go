@@ -0,0 +1,90 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	etagHeader        = "ETag"
+	ifMatchHeader     = "If-Match"
+	ifNoneMatchHeader = "If-None-Match"
+)
+
+// etag is the entity tag of a row version. Each resource has its own
+// versions, so the tag only has to tell the versions of one resource apart.
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag sets the ETag header of the response to the row version
+func setETag(ctx *gin.Context, version int64) {
+	ctx.Header(etagHeader, etag(version))
+}
+
+// etagListMatches reports whether the If-Match or If-None-Match header value
+// lists the tag. The strong comparison of If-Match never matches a weak tag,
+// while the weak comparison of If-None-Match ignores the W/ prefix.
+func etagListMatches(header, tag string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNotModified writes 304 and returns true if the If-None-Match header of
+// the conditional GET lists the current version
+func checkNotModified(ctx *gin.Context, version int64) bool {
+	header := ctx.GetHeader(ifNoneMatchHeader)
+	if header == "" || !etagListMatches(header, etag(version), true) {
+		return false
+	}
+
+	setETag(ctx, version)
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch writes 412 and returns false if the If-Match header does not
+// list the current version of the row. The returned version is the condition
+// of the update, so that a change between the read and the update fails too.
+// It is null when the request is not conditional.
+func checkIfMatch(ctx *gin.Context, version int64) (sql.NullInt64, bool) {
+	header := ctx.GetHeader(ifMatchHeader)
+	if header == "" {
+		return sql.NullInt64{}, true
+	}
+	if !etagListMatches(header, etag(version), false) {
+		writeError(ctx, http.StatusPreconditionFailed, errPreconditionFailed)
+		return sql.NullInt64{}, false
+	}
+	return sql.NullInt64{Int64: version, Valid: true}, true
+}
+
+// writeConditionalStoreError writes the error of a conditional update. The
+// row is found before the update, so a conditional update without a row means
+// a concurrent change.
+func writeConditionalStoreError(ctx *gin.Context, err error, version sql.NullInt64) {
+	if version.Valid && errors.Is(err, sql.ErrNoRows) {
+		writeError(ctx, http.StatusPreconditionFailed, errPreconditionFailed)
+		return
+	}
+	writeStoreError(ctx, err)
+}
@@ -0,0 +1,327 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestETagListMatches(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		weak     bool
+		expected bool
+	}{
+		{name: "Same", header: `"3"`, expected: true},
+		{name: "Other", header: `"2"`, expected: false},
+		{name: "List", header: `"1", "3"`, expected: true},
+		{name: "Any", header: "*", expected: true},
+		{name: "WeakStrongComparison", header: `W/"3"`, expected: false},
+		{name: "WeakWeakComparison", header: `W/"3"`, weak: true, expected: true},
+		{name: "Unquoted", header: "3", expected: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, etagListMatches(tc.header, etag(3), tc.weak))
+		})
+	}
+}
+
+func TestConditionalGetMerchantAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+	merchant.Version = 3
+
+	testCases := []struct {
+		name          string
+		ifNoneMatch   string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Unconditional",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get(etagHeader))
+				requireBodyMatchMerchant(t, recorder.Body, merchant)
+			},
+		},
+		{
+			name:        "NotModified",
+			ifNoneMatch: `"3"`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotModified, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get(etagHeader))
+				require.Empty(t, recorder.Body.Bytes())
+			},
+		},
+		{
+			name:        "NotModifiedWeak",
+			ifNoneMatch: `W/"3"`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotModified, recorder.Code)
+			},
+		},
+		{
+			name:        "Modified",
+			ifNoneMatch: `"2"`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"3"`, recorder.Header().Get(etagHeader))
+				requireBodyMatchMerchant(t, recorder.Body, merchant)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			store.EXPECT().
+				GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+				Times(1).
+				Return(merchant, nil)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/merchants/%d", merchant.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+			if tc.ifNoneMatch != "" {
+				request.Header.Set(ifNoneMatchHeader, tc.ifNoneMatch)
+			}
+
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConditionalUpdateMerchantAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+	merchant.Version = 3
+	updated := merchant
+	updated.Title = utils.RandomString(8)
+	updated.Version = 4
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Unconditional",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateMerchant(gomock.Any(), EqUpdateMerchantVersion(sql.NullInt64{})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"4"`, recorder.Header().Get(etagHeader))
+			},
+		},
+		{
+			name:    "Ok",
+			ifMatch: `"3"`,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateMerchant(gomock.Any(), EqUpdateMerchantVersion(sql.NullInt64{Int64: 3, Valid: true})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, `"4"`, recorder.Header().Get(etagHeader))
+				requireBodyMatchMerchant(t, recorder.Body, updated)
+			},
+		},
+		{
+			name:    "Any",
+			ifMatch: "*",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateMerchant(gomock.Any(), EqUpdateMerchantVersion(sql.NullInt64{Int64: 3, Valid: true})).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:    "StaleVersion",
+			ifMatch: `"2"`,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateMerchant(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, codePreconditionFailed)
+			},
+		},
+		{
+			name:    "ConcurrentUpdate",
+			ifMatch: `"3"`,
+			buildStubs: func(store *mock_db.MockStore) {
+				// the merchant is updated after it is read
+				store.EXPECT().
+					UpdateMerchant(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Merchant{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			store.EXPECT().
+				GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+				Times(1).
+				Return(merchant, nil)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"id": merchant.ID, "title": updated.Title})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPatch, "/accounts/merchants", bytes.NewReader(data))
+			require.NoError(t, err)
+			if tc.ifMatch != "" {
+				request.Header.Set(ifMatchHeader, tc.ifMatch)
+			}
+
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+type eqUpdateMerchantVersionMatcher struct {
+	version sql.NullInt64
+}
+
+func (e eqUpdateMerchantVersionMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateMerchantParams)
+	return ok && arg.Version == e.version
+}
+
+func (e eqUpdateMerchantVersionMatcher) String() string {
+	return fmt.Sprintf("has version %v", e.version)
+}
+
+func EqUpdateMerchantVersion(version sql.NullInt64) gomock.Matcher {
+	return eqUpdateMerchantVersionMatcher{version}
+}
+
+// TestConditionalUpdateUserMemStore updates a user from two clients against
+// the in-memory store, so that the second update based on the same version
+// fails instead of overwriting the first one
+func TestConditionalUpdateUserMemStore(t *testing.T) {
+	store := memstore.NewStore()
+	tokenMaker := newTestTokenMaker(t)
+	server := newTestServer(t, store, tokenMaker)
+
+	user, _ := randomUser(t)
+	_, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: user.HashedPassword,
+		FullName:       user.FullName,
+		Email:          user.Email,
+		PhoneNumber:    user.PhoneNumber,
+		Gender:         user.Gender,
+		BirthDate:      user.BirthDate,
+	})
+	require.NoError(t, err)
+
+	do := func(method, url string, body gin.H, header, value string) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			reader = bytes.NewReader(data)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+
+		request, err := http.NewRequest(method, url, reader)
+		require.NoError(t, err)
+		if header != "" {
+			request.Header.Set(header, value)
+		}
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	got := do(http.MethodGet, "/users/"+user.Username, nil, "", "")
+	require.Equal(t, http.StatusOK, got.Code)
+	tag := got.Header().Get(etagHeader)
+	require.NotEmpty(t, tag)
+
+	cached := do(http.MethodGet, "/users/"+user.Username, nil, ifNoneMatchHeader, tag)
+	require.Equal(t, http.StatusNotModified, cached.Code)
+
+	update := gin.H{
+		"username":   user.Username,
+		"full_name":  utils.RandomOwner(),
+		"birth_date": user.BirthDate,
+	}
+	first := do(http.MethodPatch, "/users", update, ifMatchHeader, tag)
+	require.Equal(t, http.StatusOK, first.Code)
+	require.NotEqual(t, tag, first.Header().Get(etagHeader))
+
+	// the second client still has the first version
+	update["full_name"] = utils.RandomOwner()
+	second := do(http.MethodPatch, "/users", update, ifMatchHeader, tag)
+	require.Equal(t, http.StatusPreconditionFailed, second.Code)
+
+	refreshed := do(http.MethodGet, "/users/"+user.Username, nil, ifNoneMatchHeader, tag)
+	require.Equal(t, http.StatusOK, refreshed.Code)
+	require.Equal(t, first.Header().Get(etagHeader), refreshed.Header().Get(etagHeader))
+	require.JSONEq(t, first.Body.String(), refreshed.Body.String())
+}
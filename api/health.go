@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/asdsec/thenut/db/migration"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	checkOK      = "ok"
+	checkFailed  = "failed"
+	statusOK     = "ok"
+	statusFailed = "unavailable"
+)
+
+type healthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// getHealth reports that the process is alive, it does not check the dependencies
+func (server *Server) getHealth(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, healthResponse{Status: statusOK})
+}
+
+// getReadiness reports whether the server can serve requests, which needs a
+// reachable database migrated to the version of the binary
+func (server *Server) getReadiness(ctx *gin.Context) {
+	rsp := healthResponse{
+		Status: statusOK,
+		Checks: map[string]string{
+			"database":   checkOK,
+			"migrations": checkOK,
+		},
+	}
+
+	// the failures are only logged, the probes do not need the details
+	fail := func(check string, err error) {
+		ctx.Error(err)
+		rsp.Status = statusFailed
+		rsp.Checks[check] = checkFailed
+	}
+
+	if err := server.store.Ping(ctx); err != nil {
+		fail("database", err)
+		rsp.Checks["migrations"] = checkFailed
+	} else if err := server.checkSchemaVersion(ctx); err != nil {
+		fail("migrations", err)
+	}
+
+	status := http.StatusOK
+	if rsp.Status != statusOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, rsp)
+}
+
+func (server *Server) checkSchemaVersion(ctx *gin.Context) error {
+	expected, err := migration.LatestVersion()
+	if err != nil {
+		return err
+	}
+
+	version, err := server.store.GetSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version.Dirty {
+		return fmt.Errorf("schema version %d is dirty", version.Version)
+	}
+	if version.Version != int64(expected) {
+		return fmt.Errorf("schema version is %d, expected %d", version.Version, expected)
+	}
+	return nil
+}
+
+// getBuildInfo reports the build of the running binary
+func (server *Server) getBuildInfo(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, utils.GetBuildInfo())
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asdsec/thenut/db/migration"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().Ping(gomock.Any()).Times(0)
+
+	server := newTestServer(t, store, newTestTokenMaker(t))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestReadinessAPI(t *testing.T) {
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Ready",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchHealth(t, recorder, statusOK, checkOK, checkOK)
+			},
+		},
+		{
+			name: "DatabaseUnreachable",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireBodyMatchHealth(t, recorder, statusFailed, checkFailed, checkFailed)
+			},
+		},
+		{
+			name: "OldSchemaVersion",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest) - 1}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireBodyMatchHealth(t, recorder, statusFailed, checkOK, checkFailed)
+			},
+		},
+		{
+			name: "DirtySchema",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{Version: int64(latest), Dirty: true}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireBodyMatchHealth(t, recorder, statusFailed, checkOK, checkFailed)
+			},
+		},
+		{
+			name: "NoMigrationTable",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					Ping(gomock.Any()).
+					Times(1).
+					Return(nil)
+				store.EXPECT().
+					GetSchemaVersion(gomock.Any()).
+					Times(1).
+					Return(db.SchemaVersion{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				requireBodyMatchHealth(t, recorder, statusFailed, checkOK, checkFailed)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, newTestTokenMaker(t))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestBuildInfoAPI(t *testing.T) {
+	server := newTestServer(t, nil, newTestTokenMaker(t))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/version/build", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var info utils.BuildInfo
+	err = json.Unmarshal(recorder.Body.Bytes(), &info)
+	require.NoError(t, err)
+	require.Equal(t, utils.GetBuildInfo(), info)
+	require.NotEmpty(t, info.GoVersion)
+}
+
+func requireBodyMatchHealth(t *testing.T, recorder *httptest.ResponseRecorder, status, database, migrations string) {
+	var rsp healthResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+
+	require.Equal(t, status, rsp.Status)
+	require.Equal(t, database, rsp.Checks["database"])
+	require.Equal(t, migrations, rsp.Checks["migrations"])
+}
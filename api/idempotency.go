@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/token"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// validIdempotencyKey limits the keys to the UUIDs and similar tokens the
+// clients generate
+var validIdempotencyKey = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,255}$`)
+
+// idempotencyMiddleware makes the retries of a request with an Idempotency-Key
+// header safe. The first response of a key is stored for the ttl and replayed
+// to the identical retries of the same user, so the handler runs once per key.
+// A key reused for another request is rejected. The requests without the
+// header are not affected.
+func idempotencyMiddleware(store db.Store, ttl time.Duration, maxBodySize int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if !validIdempotencyKey.MatchString(key) {
+			err := i18n.NewError("error.idempotency_key_invalid")
+			writeError(ctx, http.StatusBadRequest, err)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxBodySize+1))
+		if err != nil {
+			writeError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		if int64(len(body)) > maxBodySize {
+			err := i18n.NewError("error.request_too_large", strconv.FormatInt(maxBodySize, 10))
+			writeError(ctx, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		// the handler reads the body again
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		username := ctx.MustGet(authorizationPayloadKey).(*token.TokenPayload).Username
+		requestHash := hashRequest(ctx.Request, body)
+
+		_, err = store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			Username:      username,
+			Key:           key,
+			RequestMethod: ctx.Request.Method,
+			RequestPath:   ctx.Request.URL.Path,
+			RequestHash:   requestHash,
+			ExpiresAt:     time.Now().Add(ttl),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// the key is taken by an earlier request
+			replayResponse(ctx, store, username, key, requestHash)
+			return
+		}
+		if err != nil {
+			writeStoreError(ctx, err)
+			return
+		}
+
+		// the response is stored even if the client is gone, so that its
+		// retry gets the response it missed
+		storeCtx := context.WithoutCancel(ctx.Request.Context())
+		release := func() {
+			err := store.DeleteIdempotencyKey(storeCtx, db.DeleteIdempotencyKeyParams{Username: username, Key: key})
+			if err != nil {
+				requestLogger(ctx).Error("cannot release idempotency key", "err", err)
+			}
+		}
+
+		defer func() {
+			if r := recover(); r != nil {
+				release()
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		ctx.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			// the failures are not replayed, the retry runs the handler again
+			release()
+			return
+		}
+
+		_, err = store.CompleteIdempotencyKey(storeCtx, db.CompleteIdempotencyKeyParams{
+			Username:       username,
+			Key:            key,
+			ResponseStatus: sql.NullInt32{Int32: int32(status), Valid: true},
+			ResponseBody:   recorder.body.Bytes(),
+		})
+		if err != nil {
+			requestLogger(ctx).Error("cannot store idempotent response", "err", err)
+			release()
+		}
+	}
+}
+
+// replayResponse writes the stored response of the key if the request is the
+// same as the one the key is first used for
+func replayResponse(ctx *gin.Context, store db.Store, username, key, requestHash string) {
+	record, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      key,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// the key is released or expired since, the retry can take it
+		err = i18n.NewError("error.idempotency_key_in_progress")
+		writeError(ctx, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	if record.RequestHash != requestHash {
+		err = i18n.NewError("error.idempotency_key_reused")
+		writeError(ctx, http.StatusUnprocessableEntity, err)
+		return
+	}
+	if !record.ResponseStatus.Valid {
+		err = i18n.NewError("error.idempotency_key_in_progress")
+		writeError(ctx, http.StatusConflict, err)
+		return
+	}
+
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.Data(int(record.ResponseStatus.Int32), gin.MIMEJSON+"; charset=utf-8", record.ResponseBody)
+	ctx.Abort()
+}
+
+// hashRequest fingerprints the method, the path and the body of the request.
+// The multipart boundary is left out since the clients pick a new one when
+// they rebuild the same form for a retry.
+func hashRequest(request *http.Request, body []byte) string {
+	if mediaType, params, err := mime.ParseMediaType(request.Header.Get("Content-Type")); err == nil &&
+		mediaType == gin.MIMEMultipartPOSTForm && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder keeps a copy of the response body written through it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,357 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	user, _ := randomUser(t)
+	customer := randomCustomer(user.Username)
+	key := utils.RandomString(16)
+
+	body, err := json.Marshal(gin.H{"owner": user.Username})
+	require.NoError(t, err)
+
+	newRequest := func(t *testing.T, key string) *http.Request {
+		request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			request.Header.Set(idempotencyKeyHeader, key)
+		}
+		return request
+	}
+
+	requestHash := hashRequest(newRequest(t, key), body)
+	storedBody, err := json.Marshal(customer)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		key           string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "NoKey",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(customer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+			},
+		},
+		{
+			name: "Ok",
+			key:  key,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+						return db.IdempotencyKey{
+							Username:      arg.Username,
+							Key:           arg.Key,
+							RequestMethod: arg.RequestMethod,
+							RequestPath:   arg.RequestPath,
+							RequestHash:   arg.RequestHash,
+							ExpiresAt:     arg.ExpiresAt,
+						}, nil
+					})
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(customer, nil)
+				store.EXPECT().
+					CompleteIdempotencyKey(gomock.Any(), gomock.Eq(db.CompleteIdempotencyKeyParams{
+						Username:       user.Username,
+						Key:            key,
+						ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+						ResponseBody:   storedBody,
+					})).
+					Times(1).
+					Return(db.IdempotencyKey{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, customer)
+			},
+		},
+		{
+			name: "InvalidKey",
+			key:  "invalid key",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Replayed",
+			key:  key,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Eq(db.GetIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:       user.Username,
+						Key:            key,
+						RequestHash:    requestHash,
+						ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+						ResponseBody:   storedBody,
+					}, nil)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+				requireBodyMatchAccount(t, recorder.Body, customer)
+			},
+		},
+		{
+			name: "InProgress",
+			key:  key,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{Username: user.Username, Key: key, RequestHash: requestHash}, nil)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, codeConflict)
+			},
+		},
+		{
+			name: "KeyReused",
+			key:  key,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().
+					GetIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{
+						Username:       user.Username,
+						Key:            key,
+						RequestHash:    "other",
+						ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+						ResponseBody:   storedBody,
+					}, nil)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyErrorCode(t, recorder.Body, codeUnprocessable)
+			},
+		},
+		{
+			name: "HandlerFailed",
+			key:  key,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, nil)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Customer{}, sql.ErrConnDone)
+				store.EXPECT().
+					CompleteIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(0)
+				// the key is released for the retry
+				store.EXPECT().
+					DeleteIdempotencyKey(gomock.Any(), gomock.Eq(db.DeleteIdempotencyKeyParams{Username: user.Username, Key: key})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			key:  key,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.IdempotencyKey{}, sql.ErrConnDone)
+				store.EXPECT().
+					CreateCustomer(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request := newRequest(t, tc.key)
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareBodyTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateIdempotencyKey(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	tokenMaker := newTestTokenMaker(t)
+	server := newTestServer(t, store, tokenMaker)
+	recorder := httptest.NewRecorder()
+
+	body := strings.Repeat("a", int(testConfig.MaxUploadSize+uploadBodyOverhead)+1)
+	request, err := http.NewRequest(http.MethodPost, "/posts/comments", strings.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set(idempotencyKeyHeader, utils.RandomString(16))
+	addAuthorization(t, request, tokenMaker, authorizationTypeBearer, utils.RandomOwner(), time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func TestHashRequestIgnoresBoundary(t *testing.T) {
+	newRequest := func(boundary string) (*http.Request, []byte) {
+		body := []byte(fmt.Sprintf("--%s\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\ntitle\r\n--%s--\r\n", boundary, boundary))
+		request, err := http.NewRequest(http.MethodPost, "/posts", bytes.NewReader(body))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+		return request, body
+	}
+
+	first, firstBody := newRequest(utils.RandomString(12))
+	second, secondBody := newRequest(utils.RandomString(12))
+	require.Equal(t, hashRequest(first, firstBody), hashRequest(second, secondBody))
+
+	third, _ := newRequest(utils.RandomString(12))
+	require.NotEqual(t, hashRequest(first, firstBody), hashRequest(third, []byte("other")))
+}
+
+// TestIdempotencyMemStore retries a create request against the in-memory
+// store, so that the key really keeps the handler from running twice
+func TestIdempotencyMemStore(t *testing.T) {
+	store := memstore.NewStore()
+	tokenMaker := newTestTokenMaker(t)
+	server := newTestServer(t, store, tokenMaker)
+
+	user, _ := randomUser(t)
+	_, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: user.HashedPassword,
+		FullName:       user.FullName,
+		Email:          user.Email,
+		PhoneNumber:    user.PhoneNumber,
+		Gender:         user.Gender,
+		BirthDate:      user.BirthDate,
+	})
+	require.NoError(t, err)
+
+	key := utils.RandomString(16)
+	do := func(owner string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(gin.H{"owner": owner})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(data))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set(idempotencyKeyHeader, key)
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	first := do(user.Username)
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Empty(t, first.Header().Get(idempotentReplayedHeader))
+
+	retry := do(user.Username)
+	require.Equal(t, http.StatusCreated, retry.Code)
+	require.Equal(t, "true", retry.Header().Get(idempotentReplayedHeader))
+	require.JSONEq(t, first.Body.String(), retry.Body.String())
+
+	customers, err := store.ListCustomersByOwner(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, customers, 1)
+
+	// the key cannot be used for another request
+	reused := do(utils.RandomOwner())
+	require.Equal(t, http.StatusUnprocessableEntity, reused.Code)
+}
+
+func requireBodyErrorCode(t *testing.T, body *bytes.Buffer, code string) {
+	var apiErr apiError
+	require.NoError(t, json.Unmarshal(body.Bytes(), &apiErr))
+	require.Equal(t, code, apiErr.Code)
+}
@@ -1,11 +1,18 @@
 package api
 
 import (
+	"context"
+	"io"
+	"log/slog"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/asdsec/thenut/audit"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/storage"
 	"github.com/asdsec/thenut/token"
 	"github.com/asdsec/thenut/utils"
 	"github.com/gin-gonic/gin"
@@ -13,19 +20,50 @@
 )
 
 var testConfig = utils.Config{
-	TokenSymmetricKey:    utils.RandomString(32),
-	AccessTokenDuration:  time.Minute,
-	RefreshTokenDuration: time.Hour,
-	ServerAddress:        "http://localhost:8080",
+	TokenSymmetricKey:          utils.RandomString(32),
+	AccessTokenDuration:        time.Minute,
+	RefreshTokenDuration:       time.Hour,
+	ServerAddress:              "http://localhost:8080",
+	StoragePublicURL:           "/media",
+	MaxUploadSize:              1 << 20,
+	AccountDeletionGracePeriod: 30 * 24 * time.Hour,
+	IdempotencyKeyTTL:          24 * time.Hour,
 }
 
 func newTestServer(t *testing.T, store db.Store, tokenMaker token.TokenMaker) *Server {
-	server, err := NewServer(testConfig, store, tokenMaker)
+	blobStorage, err := storage.NewLocalStorage(t.TempDir(), testConfig.StoragePublicURL)
 	require.NoError(t, err)
 
+	server, err := NewServer(testConfig, store, tokenMaker, blobStorage, metrics.New())
+	require.NoError(t, err)
+
+	// keep the audit events and the notifications away from the mock store
+	server.auditor = &testAuditor{}
+	server.notifier = &testNotifier{}
+
 	return server
 }
 
+// testAuditor keeps the recorded audit events in memory
+type testAuditor struct {
+	events []audit.Event
+}
+
+func (auditor *testAuditor) Record(ctx context.Context, event audit.Event) error {
+	auditor.events = append(auditor.events, event)
+	return nil
+}
+
+// testNotifier keeps the notified events in memory
+type testNotifier struct {
+	events []notification.Event
+}
+
+func (notifier *testNotifier) Notify(ctx context.Context, event notification.Event) error {
+	notifier.events = append(notifier.events, event)
+	return nil
+}
+
 func newTestTokenMaker(t *testing.T) token.TokenMaker {
 	tokenMaker, err := token.NewPasetoMaker(testConfig.TokenSymmetricKey)
 	require.NoError(t, err)
@@ -35,5 +73,6 @@ func newTestTokenMaker(t *testing.T) token.TokenMaker {
 
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
 	os.Exit(m.Run())
 }
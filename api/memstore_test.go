@@ -0,0 +1,112 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/asdsec/thenut/db/memstore"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemStoreEndToEnd runs a user journey against the in-memory store, so the
+// handlers meet the real constraints of the store instead of mock expectations
+func TestMemStoreEndToEnd(t *testing.T) {
+	server := newTestServer(t, memstore.NewStore(), newTestTokenMaker(t))
+
+	do := func(method, url string, body gin.H, accessToken string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(method, url, bytes.NewReader(data))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		if accessToken != "" {
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+		}
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	decode := func(recorder *httptest.ResponseRecorder, v interface{}) {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), v))
+	}
+
+	user, password := randomUser(t)
+	register := gin.H{
+		"username":     user.Username,
+		"password":     password,
+		"email":        user.Email,
+		"full_name":    user.FullName,
+		"phone_number": user.PhoneNumber,
+		"gender":       user.Gender,
+		"birth_date":   user.BirthDate,
+	}
+	recorder := do(http.MethodPost, "/auth/register", register, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the username is taken already
+	recorder = do(http.MethodPost, "/auth/register", register, "")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = do(http.MethodPost, "/auth/login", gin.H{
+		"username": user.Username,
+		"password": password,
+	}, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var auth authResponse
+	decode(recorder, &auth)
+	require.NotEmpty(t, auth.AccessToken)
+
+	recorder = do(http.MethodPost, "/accounts/merchants", gin.H{
+		"owner":      user.Username,
+		"profession": utils.RandomString(8),
+		"title":      utils.RandomString(8),
+		"about":      utils.RandomString(20),
+	}, auth.AccessToken)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var merchant struct {
+		ID int64 `json:"id"`
+	}
+	decode(recorder, &merchant)
+
+	titles := []string{"first", "second"}
+	for _, title := range titles {
+		recorder = do(http.MethodPost, "/posts", gin.H{
+			"merchant_id": merchant.ID,
+			"title":       title,
+		}, auth.AccessToken)
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+
+	recorder = do(http.MethodGet, "/posts?page_id=1&page_size=5", nil, auth.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var posts []postResponse
+	decode(recorder, &posts)
+	require.Len(t, posts, len(titles))
+	// the newest post comes first
+	require.Equal(t, titles[1], posts[0].Title)
+
+	recorder = do(http.MethodPost, "/posts/comments", gin.H{
+		"post_id": posts[0].ID,
+		"comment": utils.RandomString(20),
+	}, auth.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the post does not exist, so the foreign key is violated
+	recorder = do(http.MethodPost, "/posts/comments", gin.H{
+		"post_id": posts[0].ID + 100,
+		"comment": utils.RandomString(20),
+	}, auth.AccessToken)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
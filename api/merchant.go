@@ -2,12 +2,13 @@
 
 import (
 	"database/sql"
-	"errors"
 	"net/http"
 
+	"github.com/asdsec/thenut/audit"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
+	"github.com/asdsec/thenut/storage"
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 )
 
 type createMerchantRequest struct {
@@ -20,14 +21,13 @@ type createMerchantRequest struct {
 func (server *Server) createMerchant(ctx *gin.Context) {
 	var req createMerchantRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := server.getAuthPayload(ctx)
 	if req.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
@@ -41,15 +41,7 @@ func (server *Server) createMerchant(ctx *gin.Context) {
 
 	merchant, err := server.store.CreateMerchant(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
@@ -63,36 +55,31 @@ type deleteMerchantRequest struct {
 func (server *Server) deleteMerchant(ctx *gin.Context) {
 	var req deleteMerchantRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	merchant, err := server.store.GetMerchant(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if merchant.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
 	err = server.store.DeleteMerchant(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
+	server.recordAuditEvent(ctx, authPayload.Username, audit.ActionDeleteMerchant, audit.MerchantTarget(merchant.ID), map[string]interface{}{
+		"merchant": audit.Change{Old: merchant, New: nil},
+	})
+
 	ctx.JSON(http.StatusOK, nil)
 }
 
@@ -103,67 +90,81 @@ type getMerchantRequest struct {
 func (server *Server) getMerchant(ctx *gin.Context) {
 	var req getMerchantRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	merchant, err := server.store.GetMerchant(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if merchant.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
+		return
+	}
+	if checkNotModified(ctx, merchant.Version) {
 		return
 	}
 
+	setETag(ctx, merchant.Version)
 	ctx.JSON(http.StatusOK, merchant)
 }
 
-type listMerchantsRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-}
-
 func (server *Server) listMerchants(ctx *gin.Context) {
-	var req listMerchantsRequest
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
 		return
 	}
 
 	authPayload := server.getAuthPayload(ctx)
-	arg := db.ListMerchantsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
-	}
+	if query.isOffset() {
+		arg := db.ListMerchantsParams{
+			Owner:  authPayload.Username,
+			Limit:  query.PageSize,
+			Offset: query.offset(),
+		}
 
-	merchants, err := server.store.ListMerchants(ctx, arg)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		merchants, err := server.store.ListMerchants(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusOK, merchants)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, merchants)
+	createdAt, id := cursor.keyset()
+	arg := db.ListMerchantsByCursorParams{
+		Owner:           authPayload.Username,
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
+	}
+
+	merchants, err := server.store.ListMerchantsByCursor(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(merchants) > int(query.PageSize) {
+		merchants = merchants[:query.PageSize]
+		last := merchants[len(merchants)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	rsp.Data = merchants
+
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type updateMerchantRequest struct {
 	Profession string `json:"profession"`
 	Title      string `json:"title"`
 	About      string `json:"about"`
-	ImageUrl   string `json:"image_url"`
 	Rating     int32  `json:"rating"`
 	ID         int64  `json:"id" binding:"required,min=1"`
 }
@@ -171,23 +172,22 @@ type updateMerchantRequest struct {
 func (server *Server) updateMerchant(ctx *gin.Context) {
 	var req updateMerchantRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	merchant, err := server.store.GetMerchant(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if merchant.Owner != authPayload.Username {
-		err := errors.New("username does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errUsernameNotOwned)
+		return
+	}
+	version, ok := checkIfMatch(ctx, merchant.Version)
+	if !ok {
 		return
 	}
 
@@ -205,27 +205,75 @@ func (server *Server) updateMerchant(ctx *gin.Context) {
 			String: req.Title,
 			Valid:  len(req.Title) > 0,
 		},
-		ImageUrl: sql.NullString{
-			String: req.Title,
-			Valid:  len(req.Title) > 0,
-		},
 		// fixme: rating valid
 		Rating: sql.NullFloat64{
 			Float64: float64(req.Rating),
 			Valid:   req.Rating != 0,
 		},
+		Version: version,
 	}
 
 	merchant, err = server.store.UpdateMerchant(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+		writeConditionalStoreError(ctx, err, version)
+		return
+	}
+
+	setETag(ctx, merchant.Version)
+	ctx.JSON(http.StatusOK, merchant)
+}
+
+type updateMerchantImageRequest struct {
+	ID int64 `form:"id" binding:"required,min=1"`
+}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+func (server *Server) updateMerchantImage(ctx *gin.Context) {
+	var req updateMerchantImageRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		writeBodyError(ctx, err)
+		return
+	}
+
+	upload, ok := readImageUpload(ctx, server, true)
+	if !ok {
+		return
+	}
+
+	merchant, err := server.store.GetMerchant(ctx, req.ID)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+	authPayload := server.getAuthPayload(ctx)
+	if merchant.Owner != authPayload.Username {
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
+		return
+	}
+	version, ok := checkIfMatch(ctx, merchant.Version)
+	if !ok {
+		return
+	}
+
+	stored, ok := storeImageUpload(ctx, server, storage.MerchantFolder(merchant.ID), upload)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateMerchantImageParams{
+		ID:            merchant.ID,
+		ImageUrl:      stored.url,
+		ImageVariants: emptyImageVariants,
+		Version:       version,
+	}
+
+	merchant, err = server.store.UpdateMerchantImage(ctx, arg)
+	if err != nil {
+		deleteImageUpload(ctx, server, stored)
+		writeConditionalStoreError(ctx, err, version)
 		return
 	}
+	queueThumbnails(ctx, server, stored, media.ThumbnailArgs{Owner: media.OwnerMerchant, ID: merchant.ID})
 
+	setETag(ctx, merchant.Version)
 	ctx.JSON(http.StatusOK, merchant)
 }
@@ -25,15 +25,16 @@ func TestUpdateMerchantAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	merchant := randomMerchant(user.Username)
 	expected := db.Merchant{
-		ID:         merchant.ID,
-		Owner:      merchant.Owner,
-		Balance:    merchant.Balance,
-		Profession: "updated_profession",
-		Title:      "updated_title",
-		About:      "updated_about",
-		ImageUrl:   "updated_image_url",
-		Rating:     float64(utils.RandomInt(1, 5)),
-		CreatedAt:  merchant.CreatedAt,
+		ID:            merchant.ID,
+		Owner:         merchant.Owner,
+		Balance:       merchant.Balance,
+		Profession:    "updated_profession",
+		Title:         "updated_title",
+		About:         "updated_about",
+		ImageUrl:      merchant.ImageUrl,
+		ImageVariants: merchant.ImageVariants,
+		Rating:        float64(utils.RandomInt(1, 5)),
+		CreatedAt:     merchant.CreatedAt,
 	}
 
 	testCases := []struct {
@@ -49,7 +50,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         merchant.ID,
 			},
@@ -71,10 +71,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 						String: expected.Title,
 						Valid:  len(expected.Title) > 0,
 					},
-					ImageUrl: sql.NullString{
-						String: expected.Title,
-						Valid:  len(expected.Title) > 0,
-					},
 					Rating: sql.NullFloat64{
 						Float64: expected.Rating,
 						Valid:   expected.Rating != 0,
@@ -102,7 +98,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         -1, // invalid merchant id
 			},
@@ -128,7 +123,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         expected.ID,
 			},
@@ -155,7 +149,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         expected.ID,
 			},
@@ -181,7 +174,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         expected.ID,
 			},
@@ -208,7 +200,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         expected.ID,
 			},
@@ -235,7 +226,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         expected.ID,
 			},
@@ -263,7 +253,6 @@ func TestUpdateMerchantAPI(t *testing.T) {
 				"profession": expected.Profession,
 				"title":      expected.Title,
 				"about":      expected.About,
-				"image_url":  expected.ImageUrl,
 				"rating":     expected.Rating,
 				"id":         expected.ID,
 			},
@@ -1033,14 +1022,15 @@ func TestCreateMerchantAPI(t *testing.T) {
 
 func randomMerchant(owner string) db.Merchant {
 	return db.Merchant{
-		ID:         utils.RandomInt(1, 1000),
-		Owner:      owner,
-		Balance:    utils.RandomMoney(),
-		Profession: utils.RandomString(12),
-		Title:      utils.RandomString(6),
-		About:      utils.RandomString(16),
-		ImageUrl:   utils.RandomImageUrl(),
-		Rating:     float64(utils.RandomInt(1, 5)),
+		ID:            utils.RandomInt(1, 1000),
+		Owner:         owner,
+		Balance:       utils.RandomMoney(),
+		Profession:    utils.RandomString(12),
+		Title:         utils.RandomString(6),
+		About:         utils.RandomString(16),
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: emptyImageVariants,
+		Rating:        float64(utils.RandomInt(1, 5)),
 	}
 }
 
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(2).
+		Return(user, nil)
+	store.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1)
+
+	server := newTestServer(t, store, newTestTokenMaker(t))
+
+	for _, password := range []string{password, utils.RandomString(8)} {
+		data, err := json.Marshal(gin.H{
+			"username": user.Username,
+			"password": password,
+		})
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(data))
+		require.NoError(t, err)
+		server.router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	request, err := http.NewRequest(http.MethodGet, "/posts/unknown/route", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	recorder := httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `thenut_http_request_duration_seconds_count{method="POST",route="/auth/login",status="200"} 1`)
+	require.Contains(t, body, `thenut_http_request_duration_seconds_count{method="POST",route="/auth/login",status="401"} 1`)
+	require.Contains(t, body, `thenut_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, body, `thenut_events_total{event="login"} 1`)
+	require.Contains(t, body, `thenut_events_total{event="login_failed"} 1`)
+	require.NotContains(t, body, user.Username)
+}
@@ -1,48 +1,153 @@
 package api
 
 import (
+	"database/sql"
 	"errors"
-	"fmt"
+	"log/slog"
 	"net/http"
+	"regexp"
 	"strings"
+	"time"
 
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/metrics"
 	"github.com/asdsec/thenut/token"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	requestIDHeader         = "X-Request-ID"
+	requestIDKey            = "request_id"
+	errorCodeKey            = "error_code"
+	unmatchedRoute          = "unmatched"
 )
 
+// validRequestID limits the propagated request IDs, so that clients cannot
+// put arbitrary content into the logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestIDMiddleware propagates the X-Request-ID of the request or assigns a
+// new one, and puts a logger carrying it into the request context
+func requestIDMiddleware(baseLogger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(requestIDHeader, requestID)
+
+		requestLogger := baseLogger.With(slog.String(requestIDKey, requestID))
+		ctx.Request = ctx.Request.WithContext(logger.WithContext(ctx.Request.Context(), requestLogger))
+		ctx.Next()
+	}
+}
+
+// loggerMiddleware writes a structured log entry for every request. The query
+// string is left out since it may carry secrets.
+func loggerMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", ctx.FullPath()),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+		}
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			attrs = append(attrs, slog.String("username", payload.(*token.TokenPayload).Username))
+		}
+		if code := ctx.GetString(errorCodeKey); code != "" {
+			attrs = append(attrs, slog.String(errorCodeKey, code))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.Any("errors", ctx.Errors.Errors()))
+		}
+		if spanContext := trace.SpanContextFromContext(ctx.Request.Context()); spanContext.IsValid() {
+			attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		requestLogger(ctx).LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// metricsMiddleware records the duration of every request by its route
+// template. The requests matching no route share the same label.
+func metricsMiddleware(metrics *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		metrics.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
+
+// traceFilter leaves the probes and the metrics scrapes out of the traces
+func traceFilter(request *http.Request) bool {
+	switch request.URL.Path {
+	case "/healthz", "/readyz", "/metrics":
+		return false
+	}
+	return true
+}
+
+// requestLogger returns the logger of the request, which carries its request ID
+func requestLogger(ctx *gin.Context) *slog.Logger {
+	return logger.FromContext(ctx.Request.Context())
+}
+
 func authMiddleware(tokenMaker token.TokenMaker) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			err := i18n.NewError("error.authorization_header_missing")
+			writeError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			err := i18n.NewError("error.authorization_header_invalid")
+			writeError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			err := i18n.NewError("error.authorization_type_unsupported", authorizationType)
+			writeError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			writeError(ctx, http.StatusUnauthorized, err)
 			return
 		}
 
@@ -51,6 +156,35 @@ func authMiddleware(tokenMaker token.TokenMaker) gin.HandlerFunc {
 	}
 }
 
+// roleMiddleware only lets authenticated users with one of the given roles through
+func roleMiddleware(store db.Store, roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.TokenPayload)
+
+		user, err := store.GetUser(ctx, authPayload.Username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = i18n.NewError("error.authenticated_user_missing")
+				writeError(ctx, http.StatusUnauthorized, err)
+				return
+			}
+			writeStoreError(ctx, err)
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				ctx.Next()
+				return
+			}
+		}
+
+		// the user is authenticated, but the role does not permit the resource
+		err = i18n.NewError("error.role_not_allowed", user.Role)
+		writeError(ctx, http.StatusForbidden, err)
+	}
+}
+
 func (*Server) getAuthPayload(ctx *gin.Context) *token.TokenPayload {
 	return ctx.MustGet(authorizationPayloadKey).(*token.TokenPayload)
 }
@@ -1,12 +1,17 @@
 package api
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/asdsec/thenut/logger"
 	"github.com/asdsec/thenut/token"
 	mock_token "github.com/asdsec/thenut/token/mock"
 	"github.com/gin-gonic/gin"
@@ -151,3 +156,164 @@ func TestAuthMiddleware(t *testing.T) {
 	}
 
 }
+
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		requestID     string
+		checkResponse func(t *testing.T, requestID string)
+	}{
+		{
+			name:      "Propagated",
+			requestID: "request-1",
+			checkResponse: func(t *testing.T, requestID string) {
+				require.Equal(t, "request-1", requestID)
+			},
+		},
+		{
+			name:      "Generated",
+			requestID: "",
+			checkResponse: func(t *testing.T, requestID string) {
+				require.Len(t, requestID, 36)
+			},
+		},
+		{
+			name:      "Invalid",
+			requestID: "bad request\nid",
+			checkResponse: func(t *testing.T, requestID string) {
+				require.Len(t, requestID, 36)
+				require.NotContains(t, requestID, "bad")
+			},
+		},
+		{
+			name:      "TooLong",
+			requestID: strings.Repeat("a", 129),
+			checkResponse: func(t *testing.T, requestID string) {
+				require.Len(t, requestID, 36)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := gin.New()
+			router.Use(requestIDMiddleware(logger.New(&buf, "info")))
+			router.GET("/test", func(ctx *gin.Context) {
+				require.Equal(t, ctx.GetString(requestIDKey), ctx.Writer.Header().Get(requestIDHeader))
+				requestLogger(ctx).Info("handled")
+				ctx.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/test", nil)
+			require.NoError(t, err)
+			request.Header.Set(requestIDHeader, tc.requestID)
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			requestID := recorder.Header().Get(requestIDHeader)
+			tc.checkResponse(t, requestID)
+
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			require.Equal(t, requestID, entry[requestIDKey])
+		})
+	}
+}
+
+func TestLoggerMiddleware(t *testing.T) {
+	tokenMaker := newTestTokenMaker(t)
+	username := "logged_user"
+
+	testCases := []struct {
+		name      string
+		status    int
+		err       error
+		level     string
+		authorize bool
+		check     func(t *testing.T, entry map[string]interface{})
+	}{
+		{
+			name:      "OK",
+			status:    http.StatusOK,
+			level:     "INFO",
+			authorize: true,
+			check: func(t *testing.T, entry map[string]interface{}) {
+				require.Equal(t, username, entry["username"])
+				require.NotContains(t, entry, errorCodeKey)
+			},
+		},
+		{
+			name:   "NotFound",
+			status: http.StatusNotFound,
+			err:    errors.New("missing"),
+			level:  "WARN",
+			check: func(t *testing.T, entry map[string]interface{}) {
+				require.NotContains(t, entry, "username")
+				require.Equal(t, codeNotFound, entry[errorCodeKey])
+			},
+		},
+		{
+			name:      "InternalError",
+			status:    http.StatusInternalServerError,
+			err:       errors.New("connection refused"),
+			level:     "ERROR",
+			authorize: true,
+			check: func(t *testing.T, entry map[string]interface{}) {
+				require.Equal(t, username, entry["username"])
+				require.Equal(t, codeInternal, entry[errorCodeKey])
+				require.Equal(t, []interface{}{"connection refused"}, entry["errors"])
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := gin.New()
+			router.Use(requestIDMiddleware(logger.New(&buf, "info")), loggerMiddleware())
+
+			handler := func(ctx *gin.Context) {
+				if tc.err != nil {
+					writeError(ctx, tc.status, tc.err)
+					return
+				}
+				ctx.Status(tc.status)
+			}
+			if tc.authorize {
+				router.GET("/test/:id", authMiddleware(tokenMaker), handler)
+			} else {
+				router.GET("/test/:id", handler)
+			}
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/test/1?token=secret-token", nil)
+			require.NoError(t, err)
+			if tc.authorize {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, time.Minute)
+			}
+
+			router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+			require.NotContains(t, buf.String(), "secret-token")
+
+			var entry map[string]interface{}
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			require.Equal(t, "request", entry["msg"])
+			require.Equal(t, tc.level, entry["level"])
+			require.Equal(t, http.MethodGet, entry["method"])
+			require.Equal(t, "/test/:id", entry["route"])
+			require.Equal(t, "/test/1", entry["path"])
+			require.Equal(t, float64(tc.status), entry["status"])
+			require.Equal(t, recorder.Header().Get(requestIDHeader), entry[requestIDKey])
+			require.Contains(t, entry, "latency")
+			tc.check(t, entry)
+		})
+	}
+}
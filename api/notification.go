@@ -0,0 +1,298 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/notification"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// notify delivers the notification of the event. A failure is only logged
+// since the notified action is already done.
+func (server *Server) notify(ctx *gin.Context, event notification.Event) {
+	err := server.notifier.Notify(ctx, event)
+	if err != nil {
+		ctx.Error(fmt.Errorf("cannot notify %s: %w", event.Type, err))
+	}
+}
+
+// notifyPostOwner notifies the owner of the merchant of the post
+func (server *Server) notifyPostOwner(ctx *gin.Context, postID int64, eventType, actor string, data interface{}) {
+	post, err := server.store.GetPost(ctx, postID)
+	if err != nil {
+		ctx.Error(fmt.Errorf("cannot notify %s: %w", eventType, err))
+		return
+	}
+	merchant, err := server.store.GetMerchant(ctx, post.MerchantID)
+	if err != nil {
+		ctx.Error(fmt.Errorf("cannot notify %s: %w", eventType, err))
+		return
+	}
+
+	server.notify(ctx, notification.Event{
+		Recipient: merchant.Owner,
+		Type:      eventType,
+		Actor:     actor,
+		Target:    notification.PostTarget(post.ID),
+		Data:      data,
+	})
+}
+
+type notificationResponse struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	Actor     string          `json:"actor"`
+	Target    string          `json:"target"`
+	Data      json.RawMessage `json:"data"`
+	ReadAt    *time.Time      `json:"read_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func newNotificationResponse(n db.Notification) notificationResponse {
+	rsp := notificationResponse{
+		ID:        n.ID,
+		Type:      n.EventType,
+		Actor:     n.Actor,
+		Target:    n.Target,
+		Data:      n.Data,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.ReadAt.Valid {
+		rsp.ReadAt = &n.ReadAt.Time
+	}
+	return rsp
+}
+
+func newNotificationsResponse(notifications []db.Notification) []notificationResponse {
+	rsp := make([]notificationResponse, len(notifications))
+	for i := range notifications {
+		rsp[i] = newNotificationResponse(notifications[i])
+	}
+	return rsp
+}
+
+type listNotificationsRequest struct {
+	Unread bool `form:"unread"`
+}
+
+func (server *Server) listNotifications(ctx *gin.Context) {
+	var req listNotificationsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
+		return
+	}
+
+	authPayload := server.getAuthPayload(ctx)
+
+	if query.isOffset() {
+		arg := db.ListNotificationsParams{
+			Recipient:   authPayload.Username,
+			UnreadOnly:  req.Unread,
+			LimitCount:  query.PageSize,
+			OffsetCount: query.offset(),
+		}
+
+		notifications, err := server.store.ListNotifications(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newNotificationsResponse(notifications))
+		return
+	}
+
+	createdAt, id := cursor.keyset()
+	arg := db.ListNotificationsByCursorParams{
+		Recipient:       authPayload.Username,
+		UnreadOnly:      req.Unread,
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
+	}
+
+	notifications, err := server.store.ListNotificationsByCursor(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(notifications) > int(query.PageSize) {
+		notifications = notifications[:query.PageSize]
+		last := notifications[len(notifications)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	rsp.Data = newNotificationsResponse(notifications)
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type unreadCountResponse struct {
+	Unread int64 `json:"unread"`
+}
+
+func (server *Server) getUnreadNotificationCount(ctx *gin.Context) {
+	authPayload := server.getAuthPayload(ctx)
+
+	count, err := server.store.CountUnreadNotifications(ctx, authPayload.Username)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, unreadCountResponse{Unread: count})
+}
+
+type markNotificationReadRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) markNotificationRead(ctx *gin.Context) {
+	var req markNotificationReadRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	// the notifications of the others are not found, like the missing ones
+	authPayload := server.getAuthPayload(ctx)
+	notification, err := server.store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{
+		ID:        req.ID,
+		Recipient: authPayload.Username,
+	})
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newNotificationResponse(notification))
+}
+
+type markAllNotificationsReadResponse struct {
+	Marked int64 `json:"marked"`
+}
+
+func (server *Server) markAllNotificationsRead(ctx *gin.Context) {
+	authPayload := server.getAuthPayload(ctx)
+
+	marked, err := server.store.MarkAllNotificationsRead(ctx, authPayload.Username)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, markAllNotificationsReadResponse{Marked: marked})
+}
+
+type deviceTokenSessionRequest struct {
+	SessionID string `uri:"id" binding:"required,uuid"`
+}
+
+type registerDeviceTokenRequest struct {
+	Platform string `json:"platform" binding:"required,oneof=fcm apns"`
+	Token    string `json:"token" binding:"required,max=4096"`
+}
+
+type deviceTokenResponse struct {
+	SessionID uuid.UUID `json:"session_id"`
+	Platform  string    `json:"platform"`
+	Locale    string    `json:"locale"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newDeviceTokenResponse(token db.DeviceToken) deviceTokenResponse {
+	return deviceTokenResponse{
+		SessionID: token.SessionID,
+		Platform:  token.Platform,
+		Locale:    token.Locale,
+		UpdatedAt: token.UpdatedAt,
+	}
+}
+
+// registerDeviceToken sets the push token of the device of the session. The
+// pushes are sent in the language of the Accept-Language header.
+func (server *Server) registerDeviceToken(ctx *gin.Context) {
+	var uri deviceTokenSessionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	var req registerDeviceTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	session, ok := getOwnedSession(ctx, server, uri.SessionID)
+	if !ok {
+		return
+	}
+	if session.IsBlocked {
+		err := i18n.NewError("error.session_blocked")
+		writeError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	token, err := server.store.UpsertDeviceToken(ctx, db.UpsertDeviceTokenParams{
+		SessionID: session.ID,
+		Username:  session.Username,
+		Platform:  req.Platform,
+		Token:     req.Token,
+		Locale:    i18n.Match(ctx.GetHeader("Accept-Language")).Name(),
+	})
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newDeviceTokenResponse(token))
+}
+
+func (server *Server) deleteDeviceToken(ctx *gin.Context) {
+	var uri deviceTokenSessionRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	session, ok := getOwnedSession(ctx, server, uri.SessionID)
+	if !ok {
+		return
+	}
+
+	err := server.store.DeleteDeviceToken(ctx, session.ID)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, nil)
+}
+
+// getOwnedSession returns the session if it belongs to the authenticated user
+func getOwnedSession(ctx *gin.Context, server *Server, id string) (db.Session, bool) {
+	session, err := server.store.GetSession(ctx, uuid.MustParse(id))
+	if err != nil {
+		writeStoreError(ctx, err)
+		return session, false
+	}
+
+	authPayload := server.getAuthPayload(ctx)
+	if session.Username != authPayload.Username {
+		err := i18n.NewError("error.session_user_mismatch")
+		writeError(ctx, http.StatusUnauthorized, err)
+		return session, false
+	}
+	return session, true
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/notification"
+	"github.com/gin-gonic/gin"
+)
+
+type quietHours struct {
+	Start string `json:"start" binding:"required"`
+	End   string `json:"end" binding:"required"`
+}
+
+type notificationPreference struct {
+	EventType string `json:"event_type" binding:"required,oneof=post_commented post_liked consultancy_booked merchant_reviewed marketing"`
+	Channel   string `json:"channel" binding:"required,oneof=in_app push email"`
+	Enabled   *bool  `json:"enabled" binding:"required"`
+}
+
+type notificationPreferencesResponse struct {
+	TimeZone   string      `json:"time_zone"`
+	QuietHours *quietHours `json:"quiet_hours"`
+	// Preferences has every type and channel, the ones the user has not set
+	// with their defaults
+	Preferences []notificationPreference `json:"preferences"`
+}
+
+func newNotificationPreferencesResponse(settings db.NotificationSetting, preferences []db.NotificationPreference) notificationPreferencesResponse {
+	rsp := notificationPreferencesResponse{
+		TimeZone:    settings.TimeZone,
+		Preferences: make([]notificationPreference, 0, len(notification.Types)*len(notification.Channels)),
+	}
+	if settings.QuietHoursStart.Valid && settings.QuietHoursEnd.Valid {
+		rsp.QuietHours = &quietHours{
+			Start: notification.FormatMinute(int(settings.QuietHoursStart.Int32)),
+			End:   notification.FormatMinute(int(settings.QuietHoursEnd.Int32)),
+		}
+	}
+
+	prefs := notification.NewPreferences(settings, preferences)
+	for _, eventType := range notification.Types {
+		for _, channel := range notification.Channels {
+			enabled := prefs.Enabled(eventType, channel)
+			rsp.Preferences = append(rsp.Preferences, notificationPreference{
+				EventType: eventType,
+				Channel:   channel,
+				Enabled:   &enabled,
+			})
+		}
+	}
+	return rsp
+}
+
+func (server *Server) getNotificationPreferences(ctx *gin.Context) {
+	authPayload := server.getAuthPayload(ctx)
+
+	// the users who have not set anything get the defaults
+	settings, err := server.store.GetNotificationSettings(ctx, authPayload.Username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		writeStoreError(ctx, err)
+		return
+	}
+	if settings.TimeZone == "" {
+		settings.TimeZone = time.UTC.String()
+	}
+
+	preferences, err := server.store.ListNotificationPreferences(ctx, authPayload.Username)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newNotificationPreferencesResponse(settings, preferences))
+}
+
+type updateNotificationPreferencesRequest struct {
+	TimeZone string `json:"time_zone" binding:"required"`
+	// QuietHours turns the quiet hours off when it is null
+	QuietHours  *quietHours              `json:"quiet_hours"`
+	Preferences []notificationPreference `json:"preferences" binding:"dive"`
+}
+
+// updateNotificationPreferences replaces the time zone and the quiet hours of
+// the user, and sets the given preferences. The preferences that are not given
+// are kept.
+func (server *Server) updateNotificationPreferences(ctx *gin.Context) {
+	var req updateNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := time.LoadLocation(req.TimeZone); err != nil {
+		err := i18n.NewError("error.time_zone_unknown", req.TimeZone)
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := server.getAuthPayload(ctx)
+	arg := db.UpdateNotificationPreferencesTxParams{
+		Settings: db.UpsertNotificationSettingsParams{
+			Username: authPayload.Username,
+			TimeZone: req.TimeZone,
+		},
+		Preferences: make([]db.UpsertNotificationPreferenceParams, len(req.Preferences)),
+	}
+
+	if req.QuietHours != nil {
+		start, startErr := notification.ParseMinute(req.QuietHours.Start)
+		end, endErr := notification.ParseMinute(req.QuietHours.End)
+		if startErr != nil || endErr != nil || start == end {
+			err := i18n.NewError("error.quiet_hours_invalid")
+			writeError(ctx, http.StatusBadRequest, err)
+			return
+		}
+		arg.Settings.QuietHoursStart = sql.NullInt32{Int32: int32(start), Valid: true}
+		arg.Settings.QuietHoursEnd = sql.NullInt32{Int32: int32(end), Valid: true}
+	}
+
+	for i, preference := range req.Preferences {
+		arg.Preferences[i] = db.UpsertNotificationPreferenceParams{
+			EventType: preference.EventType,
+			Channel:   preference.Channel,
+			Enabled:   *preference.Enabled,
+		}
+	}
+
+	result, err := server.store.UpdateNotificationPreferencesTx(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newNotificationPreferencesResponse(result.Settings, result.Preferences))
+}
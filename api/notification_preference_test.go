@@ -0,0 +1,280 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/notification"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateNotificationPreferencesAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	settings := db.NotificationSetting{
+		Username:        user.Username,
+		TimeZone:        "Europe/Istanbul",
+		QuietHoursStart: sql.NullInt32{Int32: 22 * 60, Valid: true},
+		QuietHoursEnd:   sql.NullInt32{Int32: 7*60 + 30, Valid: true},
+	}
+	preference := db.NotificationPreference{
+		Username:  user.Username,
+		EventType: notification.TypePostLiked,
+		Channel:   notification.ChannelPush,
+		Enabled:   false,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"time_zone":   settings.TimeZone,
+				"quiet_hours": gin.H{"start": "22:00", "end": "07:30"},
+				"preferences": []gin.H{
+					{"event_type": preference.EventType, "channel": preference.Channel, "enabled": false},
+				},
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateNotificationPreferencesTx(gomock.Any(), gomock.Eq(db.UpdateNotificationPreferencesTxParams{
+						Settings: db.UpsertNotificationSettingsParams{
+							Username:        user.Username,
+							TimeZone:        settings.TimeZone,
+							QuietHoursStart: settings.QuietHoursStart,
+							QuietHoursEnd:   settings.QuietHoursEnd,
+						},
+						Preferences: []db.UpsertNotificationPreferenceParams{
+							{EventType: preference.EventType, Channel: preference.Channel, Enabled: false},
+						},
+					})).
+					Times(1).
+					Return(db.UpdateNotificationPreferencesTxResult{
+						Settings:    settings,
+						Preferences: []db.NotificationPreference{preference},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp notificationPreferencesResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, settings.TimeZone, rsp.TimeZone)
+				require.Equal(t, &quietHours{Start: "22:00", End: "07:30"}, rsp.QuietHours)
+				require.Len(t, rsp.Preferences, len(notification.Types)*len(notification.Channels))
+				for _, got := range rsp.Preferences {
+					expected := got.EventType != notification.TypeMarketing &&
+						(got.EventType != preference.EventType || got.Channel != preference.Channel)
+					require.Equal(t, expected, *got.Enabled, "%s %s", got.EventType, got.Channel)
+				}
+			},
+		},
+		{
+			name: "NoQuietHours",
+			body: gin.H{"time_zone": "UTC"},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateNotificationPreferencesTx(gomock.Any(), gomock.Eq(db.UpdateNotificationPreferencesTxParams{
+						Settings:    db.UpsertNotificationSettingsParams{Username: user.Username, TimeZone: "UTC"},
+						Preferences: []db.UpsertNotificationPreferenceParams{},
+					})).
+					Times(1).
+					Return(db.UpdateNotificationPreferencesTxResult{
+						Settings: db.NotificationSetting{Username: user.Username, TimeZone: "UTC"},
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp notificationPreferencesResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Nil(t, rsp.QuietHours)
+			},
+		},
+		{
+			name: "UnknownTimeZone",
+			body: gin.H{"time_zone": "Mars/Olympus"},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().UpdateNotificationPreferencesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidQuietHours",
+			body: gin.H{"time_zone": "UTC", "quiet_hours": gin.H{"start": "22:00", "end": "25:00"}},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().UpdateNotificationPreferencesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "EmptyQuietHours",
+			body: gin.H{"time_zone": "UTC", "quiet_hours": gin.H{"start": "22:00", "end": "22:00"}},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().UpdateNotificationPreferencesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UnsupportedChannel",
+			body: gin.H{
+				"time_zone":   "UTC",
+				"preferences": []gin.H{{"event_type": notification.TypePostLiked, "channel": "sms", "enabled": true}},
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().UpdateNotificationPreferencesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingEnabled",
+			body: gin.H{
+				"time_zone":   "UTC",
+				"preferences": []gin.H{{"event_type": notification.TypePostLiked, "channel": notification.ChannelPush}},
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().UpdateNotificationPreferencesTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"time_zone": "UTC"},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateNotificationPreferencesTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdateNotificationPreferencesTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/users/notification-preferences", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+// TestNotificationPreferencesMemStore reads the defaults of a new user against
+// the in-memory store, then changes them and reads them back
+func TestNotificationPreferencesMemStore(t *testing.T) {
+	store := memstore.NewStore()
+	tokenMaker := newTestTokenMaker(t)
+	server := newTestServer(t, store, tokenMaker)
+
+	user, _ := randomUser(t)
+	_, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: user.HashedPassword,
+		FullName:       user.FullName,
+		Email:          user.Email,
+		PhoneNumber:    user.PhoneNumber,
+		Gender:         user.Gender,
+		BirthDate:      user.BirthDate,
+	})
+	require.NoError(t, err)
+
+	do := func(method string, body gin.H) notificationPreferencesResponse {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(method, "/users/notification-preferences", bytes.NewReader(data))
+		require.NoError(t, err)
+		addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var rsp notificationPreferencesResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+		return rsp
+	}
+
+	enabled := func(rsp notificationPreferencesResponse, eventType, channel string) bool {
+		for _, preference := range rsp.Preferences {
+			if preference.EventType == eventType && preference.Channel == channel {
+				return *preference.Enabled
+			}
+		}
+		t.Fatalf("missing preference %s %s", eventType, channel)
+		return false
+	}
+
+	defaults := do(http.MethodGet, nil)
+	require.Equal(t, "UTC", defaults.TimeZone)
+	require.Nil(t, defaults.QuietHours)
+	require.True(t, enabled(defaults, notification.TypePostCommented, notification.ChannelPush))
+	require.False(t, enabled(defaults, notification.TypeMarketing, notification.ChannelEmail))
+
+	do(http.MethodPut, gin.H{
+		"time_zone":   "America/New_York",
+		"quiet_hours": gin.H{"start": "23:00", "end": "06:00"},
+		"preferences": []gin.H{
+			{"event_type": notification.TypePostCommented, "channel": notification.ChannelPush, "enabled": false},
+			{"event_type": notification.TypeMarketing, "channel": notification.ChannelEmail, "enabled": true},
+		},
+	})
+
+	// the preferences that are not given again are kept
+	do(http.MethodPut, gin.H{
+		"time_zone":   "America/New_York",
+		"preferences": []gin.H{{"event_type": notification.TypePostLiked, "channel": notification.ChannelInApp, "enabled": false}},
+	})
+
+	got := do(http.MethodGet, nil)
+	require.Equal(t, "America/New_York", got.TimeZone)
+	require.Nil(t, got.QuietHours)
+	require.False(t, enabled(got, notification.TypePostCommented, notification.ChannelPush))
+	require.True(t, enabled(got, notification.TypeMarketing, notification.ChannelEmail))
+	require.False(t, enabled(got, notification.TypePostLiked, notification.ChannelInApp))
+	require.True(t, enabled(got, notification.TypePostCommented, notification.ChannelInApp))
+}
@@ -0,0 +1,343 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/token"
+	"github.com/asdsec/thenut/utils"
+	"github.com/asdsec/thenut/worker"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// testPushSender keeps the sent pushes in memory
+type testPushSender struct {
+	pushes []notification.Push
+}
+
+func (sender *testPushSender) Send(ctx context.Context, push notification.Push) error {
+	sender.pushes = append(sender.pushes, push)
+	return nil
+}
+
+// TestNotificationsMemStore comments on a post against the in-memory store and
+// follows the notification from the push job to the inbox of the post owner
+func TestNotificationsMemStore(t *testing.T) {
+	store := memstore.NewStore()
+	server := newTestServer(t, store, newTestTokenMaker(t))
+	server.notifier = notification.NewDispatcher(store)
+
+	sender := &testPushSender{}
+	pool := worker.NewPool(store, 1, time.Second, time.Minute)
+	worker.Handle(pool, notification.PushJob, notification.NewPusher(store, sender).Push)
+	runJobs := func() {
+		for {
+			ran, err := pool.RunNext(context.Background())
+			require.NoError(t, err)
+			if !ran {
+				return
+			}
+		}
+	}
+
+	do := func(method, url string, body gin.H, accessToken string, header ...string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		request, err := http.NewRequest(method, url, bytes.NewReader(data))
+		require.NoError(t, err)
+		request.Header.Set("Content-Type", "application/json")
+		if accessToken != "" {
+			request.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+		}
+		for i := 0; i+1 < len(header); i += 2 {
+			request.Header.Set(header[i], header[i+1])
+		}
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	decode := func(recorder *httptest.ResponseRecorder, v interface{}) {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), v))
+	}
+
+	register := func() authResponse {
+		user, password := randomUser(t)
+		recorder := do(http.MethodPost, "/auth/register", gin.H{
+			"username":     user.Username,
+			"password":     password,
+			"email":        user.Email,
+			"full_name":    user.FullName,
+			"phone_number": user.PhoneNumber,
+			"gender":       user.Gender,
+			"birth_date":   user.BirthDate,
+		}, "")
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var auth authResponse
+		decode(recorder, &auth)
+		require.NotEqual(t, uuid.Nil, auth.SessionID)
+		return auth
+	}
+	owner := register()
+	commenter := register()
+
+	recorder := do(http.MethodPost, "/accounts/merchants", gin.H{
+		"owner":      owner.Username,
+		"profession": utils.RandomString(8),
+		"title":      utils.RandomString(8),
+		"about":      utils.RandomString(20),
+	}, owner.AccessToken)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	var merchant struct {
+		ID int64 `json:"id"`
+	}
+	decode(recorder, &merchant)
+
+	recorder = do(http.MethodPost, "/posts", gin.H{"merchant_id": merchant.ID, "title": "first"}, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var post postResponse
+	decode(recorder, &post)
+
+	tokenURL := fmt.Sprintf("/sessions/%s/device-token", owner.SessionID)
+	recorder = do(http.MethodPut, tokenURL, gin.H{"platform": "apns", "token": "apns-token"}, owner.AccessToken, "Accept-Language", "tr-TR")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var deviceToken deviceTokenResponse
+	decode(recorder, &deviceToken)
+	require.Equal(t, "tr", deviceToken.Locale)
+
+	// the session of another user is not registered
+	recorder = do(http.MethodPut, tokenURL, gin.H{"platform": "fcm", "token": "fcm-token"}, commenter.AccessToken)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = do(http.MethodPost, "/posts/comments", gin.H{"post_id": post.ID, "comment": "nice"}, commenter.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	// the owners are not notified about their own comments
+	recorder = do(http.MethodPost, "/posts/comments", gin.H{"post_id": post.ID, "comment": "thanks"}, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the pushes are sent by the workers, not by the handlers
+	require.Empty(t, sender.pushes)
+	runJobs()
+	require.Len(t, sender.pushes, 1)
+	require.Equal(t, "apns-token", sender.pushes[0].Token)
+	require.Equal(t, commenter.Username+" gönderinize yorum yaptı", sender.pushes[0].Body)
+
+	recorder = do(http.MethodGet, "/notifications/unread-count", nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"unread": 1}`, recorder.Body.String())
+
+	recorder = do(http.MethodGet, "/notifications?unread=true&page_size=5", nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var page struct {
+		Data       []notificationResponse `json:"data"`
+		NextCursor string                 `json:"next_cursor"`
+	}
+	decode(recorder, &page)
+	require.Len(t, page.Data, 1)
+	require.Empty(t, page.NextCursor)
+	got := page.Data[0]
+	require.Equal(t, notification.TypePostCommented, got.Type)
+	require.Equal(t, commenter.Username, got.Actor)
+	require.Equal(t, notification.PostTarget(post.ID), got.Target)
+	require.Nil(t, got.ReadAt)
+
+	// the notifications of the others are not found
+	readURL := fmt.Sprintf("/notifications/%d/read", got.ID)
+	recorder = do(http.MethodPost, readURL, nil, commenter.AccessToken)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = do(http.MethodPost, readURL, nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var read notificationResponse
+	decode(recorder, &read)
+	require.NotNil(t, read.ReadAt)
+
+	recorder = do(http.MethodGet, "/notifications?page_id=1&page_size=5&unread=true", nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `[]`, recorder.Body.String())
+
+	recorder = do(http.MethodPost, "/notifications/read", nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"marked": 0}`, recorder.Body.String())
+
+	recorder = do(http.MethodDelete, tokenURL, nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = do(http.MethodPost, "/posts/comments", gin.H{"post_id": post.ID, "comment": "again"}, commenter.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	// the notification is only in the inbox without a device
+	runJobs()
+	require.Len(t, sender.pushes, 1)
+
+	recorder = do(http.MethodGet, "/notifications/unread-count", nil, owner.AccessToken)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"unread": 1}`, recorder.Body.String())
+}
+
+func TestRegisterDeviceTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	session := db.Session{
+		ID:        uuid.New(),
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	deviceToken := db.DeviceToken{
+		SessionID: session.ID,
+		Username:  user.Username,
+		Platform:  "fcm",
+		Token:     utils.RandomString(64),
+		Locale:    "en",
+	}
+
+	testCases := []struct {
+		name          string
+		sessionID     string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			sessionID: session.ID.String(),
+			body:      gin.H{"platform": deviceToken.Platform, "token": deviceToken.Token},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().
+					UpsertDeviceToken(gomock.Any(), gomock.Eq(db.UpsertDeviceTokenParams{
+						SessionID: session.ID,
+						Username:  user.Username,
+						Platform:  deviceToken.Platform,
+						Token:     deviceToken.Token,
+						Locale:    "en",
+					})).
+					Times(1).
+					Return(deviceToken, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp deviceTokenResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+				require.Equal(t, session.ID, rsp.SessionID)
+				// the token itself is never returned
+				require.NotContains(t, recorder.Body.String(), deviceToken.Token)
+			},
+		},
+		{
+			name:      "UnsupportedPlatform",
+			sessionID: session.ID.String(),
+			body:      gin.H{"platform": "sms", "token": deviceToken.Token},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "InvalidSessionID",
+			sessionID: "session",
+			body:      gin.H{"platform": deviceToken.Platform, "token": deviceToken.Token},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "BlockedSession",
+			sessionID: session.ID.String(),
+			body:      gin.H{"platform": deviceToken.Platform, "token": deviceToken.Token},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				blocked := session
+				blocked.IsBlocked = true
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(blocked, nil)
+				store.EXPECT().UpsertDeviceToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:      "SessionNotOwned",
+			sessionID: session.ID.String(),
+			body:      gin.H{"platform": deviceToken.Platform, "token": deviceToken.Token},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, utils.RandomOwner(), time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(session.ID)).
+					Times(1).
+					Return(session, nil)
+				store.EXPECT().UpsertDeviceToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/sessions/%s/device-token", tc.sessionID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// routeParam matches the gin path parameters
+var routeParam = regexp.MustCompile(`[:*](\w+)`)
+
+func TestOpenAPIDocument(t *testing.T) {
+	loader := openapi3.NewLoader()
+	document, err := loader.LoadFromData(openAPISpec)
+	require.NoError(t, err)
+	require.NoError(t, document.Validate(context.Background()))
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mock_db.NewMockStore(ctrl), newTestTokenMaker(t))
+
+	document, err := openapi3.NewLoader().LoadFromData(openAPISpec)
+	require.NoError(t, err)
+
+	routes := map[string]bool{}
+	for _, route := range server.router.Routes() {
+		path := routeParam.ReplaceAllString(route.Path, "{$1}")
+		routes[route.Method+" "+path] = true
+
+		item := document.Paths.Find(path)
+		require.NotNil(t, item, "route %s %s is missing from the spec", route.Method, route.Path)
+		require.NotNil(t, item.GetOperation(route.Method), "route %s %s is missing from the spec", route.Method, route.Path)
+	}
+
+	for path, item := range document.Paths {
+		for method := range item.Operations() {
+			require.True(t, routes[method+" "+path], "operation %s %s is not routed", method, path)
+		}
+	}
+}
+
+func TestOpenAPIAPI(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Document",
+			url:  openAPIPath,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "application/json")
+
+				var document map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+				require.Equal(t, "3.0.3", document["openapi"])
+				require.Contains(t, document["paths"], "/auth/login")
+			},
+		},
+		{
+			name: "SwaggerUI",
+			url:  "/docs/",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+				require.Contains(t, recorder.Body.String(), "swagger-ui")
+			},
+		},
+		{
+			name: "SwaggerUIInitializer",
+			url:  "/docs/swagger-initializer.js",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), `url: "`+openAPIPath+`"`)
+			},
+		},
+		{
+			name: "SwaggerUIAsset",
+			url:  "/docs/swagger-ui.css",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/css"))
+			},
+		},
+		{
+			name: "SwaggerUINotFound",
+			url:  "/docs/missing.js",
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := newTestServer(t, mock_db.NewMockStore(ctrl), newTestTokenMaker(t))
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
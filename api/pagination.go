@@ -0,0 +1,108 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/asdsec/thenut/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// maxOffsetPageSize keeps the page size limit of the offset pagination, which
+// gets slower with every page, while cursor pages can be larger
+const maxOffsetPageSize = 10
+
+var errInvalidCursor = i18n.NewError("error.invalid_cursor")
+
+// pageQuery selects a page of a list endpoint. The offset pagination is used
+// when page_id is given, otherwise the list is paginated by the cursor.
+type pageQuery struct {
+	PageID   int32  `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=50"`
+	Cursor   string `form:"cursor"`
+}
+
+func (query pageQuery) isOffset() bool {
+	return query.PageID > 0
+}
+
+func (query pageQuery) offset() int32 {
+	return (query.PageID - 1) * query.PageSize
+}
+
+// pageCursor points to the last row of a page, which is ordered by (created_at, id)
+type pageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+func encodeCursor(createdAt time.Time, id int64) string {
+	data, _ := json.Marshal(pageCursor{CreatedAt: createdAt, ID: id})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string) (pageCursor, error) {
+	var c pageCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return c, errInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return c, errInvalidCursor
+	}
+	return c, nil
+}
+
+// keyset returns the cursor as query parameters, both are null for the first page
+func (c pageCursor) keyset() (sql.NullTime, sql.NullInt64) {
+	if c.ID == 0 {
+		return sql.NullTime{}, sql.NullInt64{}
+	}
+	return sql.NullTime{Time: c.CreatedAt, Valid: true}, sql.NullInt64{Int64: c.ID, Valid: true}
+}
+
+// bindPageQuery binds the pagination query and decodes its cursor
+func bindPageQuery(ctx *gin.Context) (pageQuery, pageCursor, bool) {
+	var query pageQuery
+	var cursor pageCursor
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return query, cursor, false
+	}
+
+	if query.isOffset() {
+		if query.Cursor != "" {
+			err := i18n.NewError("error.page_and_cursor")
+			writeError(ctx, http.StatusBadRequest, err)
+			return query, cursor, false
+		}
+		if query.PageSize > maxOffsetPageSize {
+			err := i18n.NewError("error.offset_page_size", strconv.Itoa(maxOffsetPageSize))
+			writeError(ctx, http.StatusBadRequest, err)
+			return query, cursor, false
+		}
+		return query, cursor, true
+	}
+
+	if query.Cursor != "" {
+		var err error
+		cursor, err = decodeCursor(query.Cursor)
+		if err != nil {
+			writeError(ctx, http.StatusBadRequest, err)
+			return query, cursor, false
+		}
+	}
+	return query, cursor, true
+}
+
+// pageResponse is the envelope of the cursor paginated lists,
+// next_cursor is left out on the last page
+type pageResponse struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
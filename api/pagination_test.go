@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	createdAt := time.Now().UTC()
+	id := utils.RandomInt(1, 1000)
+
+	cursor := encodeCursor(createdAt, id)
+	require.NotEmpty(t, cursor)
+
+	decoded, err := decodeCursor(cursor)
+	require.NoError(t, err)
+	require.True(t, createdAt.Equal(decoded.CreatedAt))
+	require.Equal(t, id, decoded.ID)
+
+	cursorCreatedAt, cursorID := decoded.keyset()
+	require.True(t, cursorCreatedAt.Valid)
+	require.True(t, createdAt.Equal(cursorCreatedAt.Time))
+	require.True(t, cursorID.Valid)
+	require.Equal(t, id, cursorID.Int64)
+}
+
+func TestFirstPageCursor(t *testing.T) {
+	cursorCreatedAt, cursorID := pageCursor{}.keyset()
+	require.False(t, cursorCreatedAt.Valid)
+	require.False(t, cursorID.Valid)
+}
+
+func TestInvalidCursor(t *testing.T) {
+	for _, cursor := range []string{
+		"not a cursor",
+		"bm90IGpzb24", // "not json"
+		"eyJpZCI6MH0", // {"id":0}
+		encodeCursor(time.Now(), -1),
+	} {
+		_, err := decodeCursor(cursor)
+		require.ErrorIs(t, err, errInvalidCursor, cursor)
+	}
+}
@@ -2,66 +2,71 @@
 
 import (
 	"database/sql"
-	"errors"
 	"net/http"
 	"time"
 
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/media"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/storage"
 	"github.com/gin-gonic/gin"
 )
 
 type createPostRequest struct {
-	MerchantID int64  `json:"merchant_id" binding:"required,min=1"`
-	Title      string `json:"title"`
-	// implement real image
-	ImageUrl string `json:"image_url"`
+	MerchantID int64  `json:"merchant_id" form:"merchant_id" binding:"required,min=1"`
+	Title      string `json:"title" form:"title"`
 }
 
 type postResponse struct {
-	ID         int64     `json:"id"`
-	MerchantID int64     `json:"merchant_id"`
-	Title      string    `json:"title,omitempty"`
-	ImageUrl   string    `json:"image_url,omitempty"`
-	Likes      int32     `json:"likes"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID            int64             `json:"id"`
+	MerchantID    int64             `json:"merchant_id"`
+	Title         string            `json:"title,omitempty"`
+	ImageUrl      string            `json:"image_url,omitempty"`
+	ImageVariants map[string]string `json:"image_variants,omitempty"`
+	Likes         int32             `json:"likes"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
 }
 
 func newPostResponse(post db.Post) postResponse {
 	return postResponse{
-		ID:         post.ID,
-		MerchantID: post.MerchantID,
-		Title:      post.Title.String,
-		ImageUrl:   post.ImageUrl.String,
-		Likes:      post.Likes,
-		CreatedAt:  post.CreatedAt,
+		ID:            post.ID,
+		MerchantID:    post.MerchantID,
+		Title:         post.Title.String,
+		ImageUrl:      post.ImageUrl.String,
+		ImageVariants: newImageVariantsResponse(post.ImageVariants),
+		Likes:         post.Likes,
+		CreatedAt:     post.CreatedAt,
+		UpdatedAt:     post.UpdatedAt,
 	}
 }
 
 func (server *Server) createPost(ctx *gin.Context) {
 	var req createPostRequest
-	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if err := bindJSONOrMultipart(ctx, &req); err != nil {
+		writeBodyError(ctx, err)
+		return
+	}
+
+	upload, ok := readImageUpload(ctx, server, false)
+	if !ok {
 		return
 	}
-	if req.ImageUrl == "" && req.Title == "" {
-		err := errors.New("both title and image_url is null")
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if upload == nil && req.Title == "" {
+		err := i18n.NewError("error.post_empty")
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	merchant, err := server.store.GetMerchant(ctx, req.MerchantID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if merchant.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
@@ -71,21 +76,143 @@ func (server *Server) createPost(ctx *gin.Context) {
 			String: req.Title,
 			Valid:  req.Title != "",
 		},
-		ImageUrl: sql.NullString{
-			String: req.ImageUrl,
-			Valid:  req.ImageUrl != "",
-		},
+		ImageVariants: emptyImageVariants,
 	}
 
-	post, err := server.store.CreatePost(ctx, arg)
+	var stored *storedImage
+	if upload != nil {
+		stored, ok = storeImageUpload(ctx, server, storage.PostFolder(merchant.ID), upload)
+		if !ok {
+			return
+		}
+		arg.ImageUrl = sql.NullString{
+			String: stored.url,
+			Valid:  true,
+		}
+		arg.ImageVariants = emptyImageVariants
+	}
+
+	post, err := server.store.CreatePostTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		if stored != nil {
+			deleteImageUpload(ctx, server, stored)
+		}
+		writeStoreError(ctx, err)
 		return
 	}
+	if stored != nil {
+		queueThumbnails(ctx, server, stored, media.ThumbnailArgs{Owner: media.OwnerPost, ID: post.ID})
+	}
 
+	server.metrics.IncEvent(metrics.EventPostCreated)
 	ctx.JSON(http.StatusOK, newPostResponse(post))
 }
 
+type updatePostRequestUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type updatePostRequest struct {
+	Title      string `json:"title" form:"title"`
+	ClearTitle bool   `json:"clear_title" form:"clear_title"`
+	ClearImage bool   `json:"clear_image" form:"clear_image"`
+}
+
+func (server *Server) updatePost(ctx *gin.Context) {
+	var uri updatePostRequestUri
+	var req updatePostRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if err := bindJSONOrMultipart(ctx, &req); err != nil {
+		writeBodyError(ctx, err)
+		return
+	}
+
+	upload, ok := readImageUpload(ctx, server, false)
+	if !ok {
+		return
+	}
+	if req.ClearTitle && req.Title != "" {
+		err := i18n.NewError("error.title_set_and_cleared")
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.ClearImage && upload != nil {
+		err := i18n.NewError("error.image_set_and_cleared")
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if req.Title == "" && upload == nil && !req.ClearTitle && !req.ClearImage {
+		err := i18n.NewError("error.nothing_to_update")
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := server.store.GetPost(ctx, uri.ID)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+	merchant, err := server.store.GetMerchant(ctx, post.MerchantID)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+	authPayload := server.getAuthPayload(ctx)
+	if merchant.Owner != authPayload.Username {
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
+		return
+	}
+
+	// the transaction applies the changes to the locked post, the one read here
+	// may be outdated by then and only tells early if the post is left empty
+	arg := db.UpdatePostTxParams{
+		ID:         post.ID,
+		Editor:     authPayload.Username,
+		ClearTitle: req.ClearTitle,
+		ClearImage: req.ClearImage,
+	}
+	if req.Title != "" {
+		arg.Title = sql.NullString{String: req.Title, Valid: true}
+	}
+	updated := arg.Apply(post)
+	if !updated.Title.Valid && !updated.ImageUrl.Valid && upload == nil {
+		err := i18n.NewError("error.post_empty")
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	var stored *storedImage
+	if upload != nil {
+		stored, ok = storeImageUpload(ctx, server, storage.PostFolder(merchant.ID), upload)
+		if !ok {
+			return
+		}
+		arg.ImageUrl = sql.NullString{
+			String: stored.url,
+			Valid:  true,
+		}
+		arg.ImageVariants = emptyImageVariants
+	}
+
+	// the previous image stays in the storage since the revision refers to it
+	result, err := server.store.UpdatePostTx(ctx, arg)
+	if err != nil {
+		if stored != nil {
+			deleteImageUpload(ctx, server, stored)
+		}
+		writeStoreError(ctx, err)
+		return
+	}
+	if stored != nil {
+		queueThumbnails(ctx, server, stored, media.ThumbnailArgs{Owner: media.OwnerPost, ID: result.Post.ID})
+	}
+
+	ctx.JSON(http.StatusOK, newPostResponse(result.Post))
+}
+
 type deletePostRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
@@ -93,51 +220,41 @@ type deletePostRequest struct {
 func (server *Server) deletePost(ctx *gin.Context) {
 	var req deletePostRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	post, err := server.store.GetPost(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	merchant, err := server.store.GetMerchant(ctx, post.MerchantID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	authPayload := server.getAuthPayload(ctx)
 	if merchant.Owner != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
 		return
 	}
 
 	err = server.store.DeletePost(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, nil)
 }
 
-type listPostsRequestQuery struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+func newPostsResponse(posts []db.Post) []postResponse {
+	rsp := make([]postResponse, len(posts))
+	for i := range posts {
+		rsp[i] = newPostResponse(posts[i])
+	}
+	return rsp
 }
 
 type listMerchantPostsRequest struct {
@@ -146,68 +263,186 @@ type listMerchantPostsRequest struct {
 
 func (server *Server) listMerchantPosts(ctx *gin.Context) {
 	var req listMerchantPostsRequest
-	var query listPostsRequestQuery
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
-	if err := ctx.ShouldBindQuery(&query); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
 		return
 	}
 
-	arg := db.ListMerchantPostsParams{
-		MerchantID: req.MerchantID,
-		Limit:      query.PageSize,
-		Offset:     (query.PageID - 1) * query.PageSize,
-	}
+	if query.isOffset() {
+		arg := db.ListMerchantPostsParams{
+			MerchantID: req.MerchantID,
+			Limit:      query.PageSize,
+			Offset:     query.offset(),
+		}
 
-	posts, err := server.store.ListMerchantPosts(ctx, arg)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		posts, err := server.store.ListMerchantPosts(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusOK, newPostsResponse(posts))
 		return
 	}
 
-	rsp := make([]postResponse, len(posts))
-	for i := range posts {
-		rsp[i] = newPostResponse(posts[i])
+	createdAt, id := cursor.keyset()
+	arg := db.ListMerchantPostsByCursorParams{
+		MerchantID:      req.MerchantID,
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
 	}
 
+	posts, err := server.store.ListMerchantPostsByCursor(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(posts) > int(query.PageSize) {
+		posts = posts[:query.PageSize]
+		last := posts[len(posts)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	rsp.Data = newPostsResponse(posts)
+
 	ctx.JSON(http.StatusOK, rsp)
 }
 
 func (server *Server) listPosts(ctx *gin.Context) {
-	var query listPostsRequestQuery
-	if err := ctx.ShouldBindQuery(&query); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
+		return
+	}
+
+	if query.isOffset() {
+		arg := db.ListPostsParams{
+			Limit:  query.PageSize,
+			Offset: query.offset(),
+		}
+
+		posts, err := server.store.ListPosts(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newPostsResponse(posts))
 		return
 	}
 
-	arg := db.ListPostsParams{
-		Limit:  query.PageSize,
-		Offset: (query.PageID - 1) * query.PageSize,
+	createdAt, id := cursor.keyset()
+	arg := db.ListPostsByCursorParams{
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
 	}
 
-	posts, err := server.store.ListPosts(ctx, arg)
+	posts, err := server.store.ListPostsByCursor(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(posts) > int(query.PageSize) {
+		posts = posts[:query.PageSize]
+		last := posts[len(posts)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
+	}
+	rsp.Data = newPostsResponse(posts)
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type postRevisionResponse struct {
+	ID            int64             `json:"id"`
+	PostID        int64             `json:"post_id"`
+	Editor        string            `json:"editor"`
+	Title         string            `json:"title,omitempty"`
+	ImageUrl      string            `json:"image_url,omitempty"`
+	ImageVariants map[string]string `json:"image_variants,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+func newPostRevisionResponse(revision db.PostRevision) postRevisionResponse {
+	return postRevisionResponse{
+		ID:            revision.ID,
+		PostID:        revision.PostID,
+		Editor:        revision.Editor,
+		Title:         revision.Title.String,
+		ImageUrl:      revision.ImageUrl.String,
+		ImageVariants: newImageVariantsResponse(revision.ImageVariants),
+		CreatedAt:     revision.CreatedAt,
+	}
+}
+
+type listPostRevisionsRequestUri struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func newPostRevisionsResponse(revisions []db.PostRevision) []postRevisionResponse {
+	rsp := make([]postRevisionResponse, len(revisions))
+	for i := range revisions {
+		rsp[i] = newPostRevisionResponse(revisions[i])
+	}
+	return rsp
+}
+
+func (server *Server) listPostRevisions(ctx *gin.Context) {
+	var uri listPostRevisionsRequestUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	query, cursor, ok := bindPageQuery(ctx)
+	if !ok {
+		return
+	}
+
+	if query.isOffset() {
+		arg := db.ListPostRevisionsParams{
+			PostID: uri.ID,
+			Limit:  query.PageSize,
+			Offset: query.offset(),
+		}
+
+		revisions, err := server.store.ListPostRevisions(ctx, arg)
+		if err != nil {
+			writeStoreError(ctx, err)
 			return
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusOK, newPostRevisionsResponse(revisions))
 		return
 	}
 
-	rsp := make([]postResponse, len(posts))
-	for i := range posts {
-		rsp[i] = newPostResponse(posts[i])
+	createdAt, id := cursor.keyset()
+	arg := db.ListPostRevisionsByCursorParams{
+		PostID:          uri.ID,
+		CursorCreatedAt: createdAt,
+		CursorID:        id,
+		LimitCount:      query.PageSize + 1,
+	}
+
+	revisions, err := server.store.ListPostRevisionsByCursor(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	var rsp pageResponse
+	if len(revisions) > int(query.PageSize) {
+		revisions = revisions[:query.PageSize]
+		last := revisions[len(revisions)-1]
+		rsp.NextCursor = encodeCursor(last.CreatedAt, last.ID)
 	}
+	rsp.Data = newPostRevisionsResponse(revisions)
 
 	ctx.JSON(http.StatusOK, rsp)
 }
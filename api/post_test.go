@@ -13,6 +13,7 @@
 
 	mock_db "github.com/asdsec/thenut/db/mock"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
 	"github.com/asdsec/thenut/token"
 	"github.com/asdsec/thenut/utils"
 	"github.com/gin-gonic/gin"
@@ -20,15 +21,45 @@
 	"github.com/stretchr/testify/require"
 )
 
+type eqCreatePostParamsMatcher struct {
+	merchantID int64
+	title      sql.NullString
+	imageUrl   eqImageUrlMatcher
+}
+
+func (e eqCreatePostParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreatePostParams)
+	if !ok {
+		return false
+	}
+
+	return arg.MerchantID == e.merchantID &&
+		arg.Title == e.title &&
+		arg.ImageUrl.Valid &&
+		e.imageUrl.Matches(arg.ImageUrl.String) &&
+		isPendingVariants(arg.ImageVariants)
+}
+
+func (e eqCreatePostParamsMatcher) String() string {
+	return fmt.Sprintf("matches merchant id %d, title %v and %v", e.merchantID, e.title, e.imageUrl)
+}
+
+func EqCreatePostParams(merchantID int64, title sql.NullString, folder string) gomock.Matcher {
+	return eqCreatePostParamsMatcher{merchantID, title, isStoredImageUrl(folder)}
+}
+
 func TestCreatePostAPI(t *testing.T) {
 	user, _ := randomUser(t)
 	merchant := randomMerchant(user.Username)
 	post := randomPost(merchant.ID)
 	expected := newPostResponse(post)
+	image := randomImage(t, 128, 128)
+	folder := fmt.Sprintf("posts/%d", merchant.ID)
 
 	testCases := []struct {
 		name          string
 		body          gin.H
+		image         []byte
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
 		buildStubs    func(store *mock_db.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
@@ -38,8 +69,8 @@ func TestCreatePostAPI(t *testing.T) {
 			body: gin.H{
 				"merchant_id": merchant.ID,
 				"title":       post.Title.String,
-				"image_url":   post.ImageUrl.String,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -49,16 +80,12 @@ func TestCreatePostAPI(t *testing.T) {
 					Times(1).
 					Return(merchant, nil)
 
-				arg := db.CreatePostParams{
-					MerchantID: post.MerchantID,
-					Title:      post.Title,
-					ImageUrl:   post.ImageUrl,
-				}
-
 				store.EXPECT().
-					CreatePost(gomock.Any(), gomock.Eq(arg)).
+					CreatePostTx(gomock.Any(), EqCreatePostParams(post.MerchantID, post.Title, folder)).
 					Times(1).
 					Return(post, nil)
+
+				expectThumbnailJob(store, folder, media.ThumbnailArgs{Owner: media.OwnerPost, ID: post.ID})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -70,8 +97,8 @@ func TestCreatePostAPI(t *testing.T) {
 			body: gin.H{
 				"merchant_id": -1, // invalid merchant id
 				"title":       post.Title.String,
-				"image_url":   post.ImageUrl.String,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			},
@@ -81,7 +108,7 @@ func TestCreatePostAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreatePost(gomock.Any(), gomock.Any()).
+					CreatePostTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -102,7 +129,7 @@ func TestCreatePostAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreatePost(gomock.Any(), gomock.Any()).
+					CreatePostTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -114,8 +141,8 @@ func TestCreatePostAPI(t *testing.T) {
 			body: gin.H{
 				"merchant_id": merchant.ID,
 				"title":       post.Title.String,
-				"image_url":   post.ImageUrl.String,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "invalid_username", time.Minute)
 			},
@@ -126,7 +153,7 @@ func TestCreatePostAPI(t *testing.T) {
 					Return(merchant, nil)
 
 				store.EXPECT().
-					CreatePost(gomock.Any(), gomock.Any()).
+					CreatePostTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -138,8 +165,8 @@ func TestCreatePostAPI(t *testing.T) {
 			body: gin.H{
 				"merchant_id": merchant.ID,
 				"title":       post.Title.String,
-				"image_url":   post.ImageUrl.String,
 			},
+			image: image,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				// No authorization
 			},
@@ -149,7 +176,7 @@ func TestCreatePostAPI(t *testing.T) {
 					Times(0)
 
 				store.EXPECT().
-					CreatePost(gomock.Any(), gomock.Any()).
+					CreatePostTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -161,7 +188,31 @@ func TestCreatePostAPI(t *testing.T) {
 			body: gin.H{
 				"merchant_id": merchant.ID,
 				"title":       post.Title.String,
-				"image_url":   post.ImageUrl.String,
+			},
+			image: image,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				store.EXPECT().
+					CreatePostTx(gomock.Any(), EqCreatePostParams(post.MerchantID, post.Title, folder)).
+					Times(1).
+					Return(db.Post{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "OkJSONWithoutImage",
+			body: gin.H{
+				"merchant_id": merchant.ID,
+				"title":       post.Title.String,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
 				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
@@ -173,18 +224,42 @@ func TestCreatePostAPI(t *testing.T) {
 					Return(merchant, nil)
 
 				arg := db.CreatePostParams{
-					MerchantID: post.MerchantID,
-					Title:      post.Title,
-					ImageUrl:   post.ImageUrl,
+					MerchantID:    post.MerchantID,
+					Title:         post.Title,
+					ImageVariants: emptyImageVariants,
 				}
 
 				store.EXPECT().
-					CreatePost(gomock.Any(), gomock.Eq(arg)).
+					CreatePostTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(db.Post{}, sql.ErrConnDone)
+					Return(post, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostResponse(t, recorder.Body, expected)
+			},
+		},
+		{
+			name: "InvalidImage",
+			body: gin.H{
+				"merchant_id": merchant.ID,
+				"title":       post.Title.String,
+			},
+			image: []byte("not an image"),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
 			},
 		},
 	}
@@ -203,12 +278,17 @@ func TestCreatePostAPI(t *testing.T) {
 			server := newTestServer(t, store, tokenMaker)
 			recorder := httptest.NewRecorder()
 
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
 			url := "/posts"
-			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
-			require.NoError(t, err)
+			var request *http.Request
+			if tc.image != nil {
+				request = newMultipartRequest(t, http.MethodPost, url, tc.body, tc.image)
+			} else {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+
+				request, err = http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+				require.NoError(t, err)
+			}
 
 			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
@@ -720,38 +800,917 @@ type Query struct {
 	}
 }
 
-func randomPost(merchantID int64) db.Post {
-	return db.Post{
-		ID:         utils.RandomInt(1, 1000),
-		MerchantID: merchantID,
-		Title: sql.NullString{
-			String: utils.RandomString(6),
-			Valid:  true,
+func TestListPostsByCursorAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+
+	n := 5
+	posts := make([]db.Post, n+1)
+	for i := range posts {
+		posts[i] = randomPost(merchant.ID)
+		posts[i].CreatedAt = time.Now().Add(-time.Duration(i) * time.Minute).UTC()
+	}
+	last := posts[n-1]
+	nextCursor := encodeCursor(last.CreatedAt, last.ID)
+
+	testCases := []struct {
+		name          string
+		query         gin.H
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "FirstPage",
+			query: gin.H{
+				"page_size": n,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				arg := db.ListPostsByCursorParams{
+					LimitCount: int32(n + 1),
+				}
+
+				store.EXPECT().
+					ListPostsByCursor(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostsPage(t, recorder.Body, newPostsResponse(posts[:n]), nextCursor)
+			},
 		},
-		ImageUrl: sql.NullString{
-			String: utils.RandomString(6),
-			Valid:  true,
+		{
+			name: "LastPage",
+			query: gin.H{
+				"page_size": n,
+				"cursor":    nextCursor,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				arg := db.ListPostsByCursorParams{
+					CursorCreatedAt: sql.NullTime{Time: last.CreatedAt, Valid: true},
+					CursorID:        sql.NullInt64{Int64: last.ID, Valid: true},
+					LimitCount:      int32(n + 1),
+				}
+
+				store.EXPECT().
+					ListPostsByCursor(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts[n:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostsPage(t, recorder.Body, newPostsResponse(posts[n:]), "")
+			},
 		},
-		Likes: int32(utils.RandomInt(1, 100)),
+		{
+			name: "LargePageSize",
+			query: gin.H{
+				"page_size": 50,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				arg := db.ListPostsByCursorParams{
+					LimitCount: 51,
+				}
+
+				store.EXPECT().
+					ListPostsByCursor(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(posts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostsPage(t, recorder.Body, newPostsResponse(posts), "")
+			},
+		},
+		{
+			name: "InvalidCursor",
+			query: gin.H{
+				"page_size": n,
+				"cursor":    "invalid",
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListPostsByCursor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PageIDWithCursor",
+			query: gin.H{
+				"page_id":   1,
+				"page_size": n,
+				"cursor":    nextCursor,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					ListPostsByCursor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OffsetPageSizeTooLarge",
+			query: gin.H{
+				"page_id":   1,
+				"page_size": 50,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListPosts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			query: gin.H{
+				"page_size": n,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListPostsByCursor(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Post{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/posts", nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			for key, value := range tc.query {
+				q.Add(key, fmt.Sprintf("%v", value))
+			}
+			request.URL.RawQuery = q.Encode()
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
 	}
 }
 
-func requireBodyMatchPostsResponse(t *testing.T, body *bytes.Buffer, expected []postResponse) {
-	data, err := io.ReadAll(body)
-	require.NoError(t, err)
+type eqUpdatePostTxParamsMatcher struct {
+	id       int64
+	editor   string
+	title    sql.NullString
+	imageUrl eqImageUrlMatcher
+}
 
-	var actual []postResponse
-	err = json.Unmarshal(data, &actual)
-	require.NoError(t, err)
-	require.Equal(t, expected, actual)
+func (e eqUpdatePostTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdatePostTxParams)
+	if !ok {
+		return false
+	}
+
+	return arg.ID == e.id &&
+		arg.Editor == e.editor &&
+		arg.Title == e.title &&
+		arg.ImageUrl.Valid &&
+		e.imageUrl.Matches(arg.ImageUrl.String) &&
+		isPendingVariants(arg.ImageVariants)
 }
 
-func requireBodyMatchPostResponse(t *testing.T, body *bytes.Buffer, expected postResponse) {
-	data, err := io.ReadAll(body)
-	require.NoError(t, err)
+func (e eqUpdatePostTxParamsMatcher) String() string {
+	return fmt.Sprintf("matches post id %d, editor %s, title %v and %v", e.id, e.editor, e.title, e.imageUrl)
+}
 
-	var actual postResponse
-	err = json.Unmarshal(data, &actual)
-	require.NoError(t, err)
-	require.Equal(t, expected, actual)
+func EqUpdatePostTxParams(id int64, editor string, title sql.NullString, folder string) gomock.Matcher {
+	return eqUpdatePostTxParamsMatcher{id, editor, title, isStoredImageUrl(folder)}
+}
+
+func TestUpdatePostAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+	post := randomPost(merchant.ID)
+	updated := post
+	updated.Title = sql.NullString{
+		String: utils.RandomString(6),
+		Valid:  true,
+	}
+	expected := newPostResponse(updated)
+	image := randomImage(t, 128, 128)
+	folder := fmt.Sprintf("posts/%d", merchant.ID)
+
+	testCases := []struct {
+		name          string
+		postID        int64
+		body          gin.H
+		image         []byte
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OkTitle",
+			postID: post.ID,
+			body: gin.H{
+				"title": updated.Title.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				// only the change is sent, the image is kept by the transaction
+				arg := db.UpdatePostTxParams{
+					ID:     post.ID,
+					Editor: user.Username,
+					Title:  updated.Title,
+				}
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.UpdatePostTxResult{Post: updated}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostResponse(t, recorder.Body, expected)
+			},
+		},
+		{
+			name:   "OkImageAndClearTitle",
+			postID: post.ID,
+			body: gin.H{
+				"clear_title": true,
+			},
+			image: image,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), EqUpdatePostTxParams(post.ID, user.Username, sql.NullString{}, folder)).
+					Times(1).
+					Return(db.UpdatePostTxResult{Post: updated}, nil)
+
+				expectThumbnailJob(store, folder, media.ThumbnailArgs{Owner: media.OwnerPost, ID: post.ID})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchPostResponse(t, recorder.Body, expected)
+			},
+		},
+		{
+			name:   "ClearTitleAndImage",
+			postID: post.ID,
+			body: gin.H{
+				"clear_title": true,
+				"clear_image": true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "TitleSetAndCleared",
+			postID: post.ID,
+			body: gin.H{
+				"title":       updated.Title.String,
+				"clear_title": true,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NothingToUpdate",
+			postID: post.ID,
+			body:   gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidID",
+			postID: -1,
+			body: gin.H{
+				"title": updated.Title.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			postID: post.ID,
+			body: gin.H{
+				"title": updated.Title.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(db.Post{}, sql.ErrNoRows)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "UnauthorizedUser",
+			postID: post.ID,
+			body: gin.H{
+				"title": updated.Title.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "invalid_owner", time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			postID: post.ID,
+			body: gin.H{
+				"title": updated.Title.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				// no authorization
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalServerError",
+			postID: post.ID,
+			body: gin.H{
+				"title": updated.Title.String,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetPost(gomock.Any(), gomock.Eq(post.ID)).
+					Times(1).
+					Return(post, nil)
+
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				store.EXPECT().
+					UpdatePostTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.UpdatePostTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/posts/%d", tc.postID)
+			var request *http.Request
+			if tc.image != nil {
+				request = newMultipartRequest(t, http.MethodPatch, url, tc.body, tc.image)
+			} else {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+
+				request, err = http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+				require.NoError(t, err)
+			}
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPostRevisionsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	moderator, _ := randomUser(t)
+	moderator.Role = utils.ModeratorRole
+	post := randomPost(utils.RandomInt(1, 1000))
+
+	n := 6
+	revisions := make([]db.PostRevision, n)
+	for i := range revisions {
+		revisions[i] = randomPostRevision(post.ID, user.Username)
+		revisions[i].CreatedAt = time.Now().Add(-time.Duration(i) * time.Minute).UTC()
+	}
+	last := revisions[n-2]
+	nextCursor := encodeCursor(last.CreatedAt, last.ID)
+
+	testCases := []struct {
+		name          string
+		postID        int64
+		query         gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Ok",
+			postID: post.ID,
+			query: gin.H{
+				"page_id":   1,
+				"page_size": n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				arg := db.ListPostRevisionsParams{
+					PostID: post.ID,
+					Limit:  int32(n),
+					Offset: 0,
+				}
+
+				store.EXPECT().
+					ListPostRevisions(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(revisions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var actual []postRevisionResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &actual)
+				require.NoError(t, err)
+				require.Len(t, actual, n)
+				for i := range revisions {
+					require.Equal(t, newPostRevisionResponse(revisions[i]), actual[i])
+				}
+			},
+		},
+		{
+			name:   "FirstPage",
+			postID: post.ID,
+			query: gin.H{
+				"page_size": n - 1,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				arg := db.ListPostRevisionsByCursorParams{
+					PostID:     post.ID,
+					LimitCount: int32(n),
+				}
+
+				store.EXPECT().
+					ListPostRevisionsByCursor(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(revisions, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchRevisionsPage(t, recorder.Body, newPostRevisionsResponse(revisions[:n-1]), nextCursor)
+			},
+		},
+		{
+			name:   "LastPage",
+			postID: post.ID,
+			query: gin.H{
+				"page_size": n - 1,
+				"cursor":    nextCursor,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				arg := db.ListPostRevisionsByCursorParams{
+					PostID:          post.ID,
+					CursorCreatedAt: sql.NullTime{Time: last.CreatedAt, Valid: true},
+					CursorID:        sql.NullInt64{Int64: last.ID, Valid: true},
+					LimitCount:      int32(n),
+				}
+
+				store.EXPECT().
+					ListPostRevisionsByCursor(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(revisions[n-1:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchRevisionsPage(t, recorder.Body, newPostRevisionsResponse(revisions[n-1:]), "")
+			},
+		},
+		{
+			name:   "InvalidCursor",
+			postID: post.ID,
+			query: gin.H{
+				"page_size": n,
+				"cursor":    "invalid",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				store.EXPECT().
+					ListPostRevisionsByCursor(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotModerator",
+			postID: post.ID,
+			query: gin.H{
+				"page_id":   1,
+				"page_size": n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					ListPostRevisions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+
+				var apiErr apiError
+				err := json.Unmarshal(recorder.Body.Bytes(), &apiErr)
+				require.NoError(t, err)
+				require.Equal(t, codeForbidden, apiErr.Code)
+			},
+		},
+		{
+			name:   "UserMissing",
+			postID: post.ID,
+			query: gin.H{
+				"page_id":   1,
+				"page_size": n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				// the decorators of the store wrap its errors
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, fmt.Errorf("get user: %w", sql.ErrNoRows))
+
+				store.EXPECT().
+					ListPostRevisions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			postID: post.ID,
+			query: gin.H{
+				"page_id":   1,
+				"page_size": n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				// no authorization
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					ListPostRevisions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidPageSize",
+			postID: post.ID,
+			query: gin.H{
+				"page_id":   1,
+				"page_size": 100,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				store.EXPECT().
+					ListPostRevisions(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalServerError",
+			postID: post.ID,
+			query: gin.H{
+				"page_id":   1,
+				"page_size": n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, moderator.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(moderator.Username)).
+					Times(1).
+					Return(moderator, nil)
+
+				store.EXPECT().
+					ListPostRevisions(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.PostRevision{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/posts/%d/revisions", tc.postID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			q := request.URL.Query()
+			for key, value := range tc.query {
+				q.Add(key, fmt.Sprintf("%v", value))
+			}
+			request.URL.RawQuery = q.Encode()
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func randomPostRevision(postID int64, editor string) db.PostRevision {
+	return db.PostRevision{
+		ID:     utils.RandomInt(1, 1000),
+		PostID: postID,
+		Editor: editor,
+		Title: sql.NullString{
+			String: utils.RandomString(6),
+			Valid:  true,
+		},
+	}
+}
+
+func randomPost(merchantID int64) db.Post {
+	return db.Post{
+		ID:         utils.RandomInt(1, 1000),
+		MerchantID: merchantID,
+		Title: sql.NullString{
+			String: utils.RandomString(6),
+			Valid:  true,
+		},
+		ImageUrl: sql.NullString{
+			String: utils.RandomString(6),
+			Valid:  true,
+		},
+		Likes: int32(utils.RandomInt(1, 100)),
+	}
+}
+
+func requireBodyMatchPostsResponse(t *testing.T, body *bytes.Buffer, expected []postResponse) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var actual []postResponse
+	err = json.Unmarshal(data, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func requireBodyMatchPostResponse(t *testing.T, body *bytes.Buffer, expected postResponse) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var actual postResponse
+	err = json.Unmarshal(data, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+func requireBodyMatchPostsPage(t *testing.T, body *bytes.Buffer, expected []postResponse, nextCursor string) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var actual struct {
+		Data       []postResponse `json:"data"`
+		NextCursor string         `json:"next_cursor"`
+	}
+	err = json.Unmarshal(data, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual.Data)
+	require.Equal(t, nextCursor, actual.NextCursor)
+}
+
+func requireBodyMatchRevisionsPage(t *testing.T, body *bytes.Buffer, expected []postRevisionResponse, nextCursor string) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var actual struct {
+		Data       []postRevisionResponse `json:"data"`
+		NextCursor string                 `json:"next_cursor"`
+	}
+	err = json.Unmarshal(data, &actual)
+	require.NoError(t, err)
+	require.Equal(t, expected, actual.Data)
+	require.Equal(t, nextCursor, actual.NextCursor)
 }
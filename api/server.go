@@ -1,51 +1,136 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+
+	"github.com/asdsec/thenut/audit"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/storage"
 	"github.com/asdsec/thenut/token"
+	"github.com/asdsec/thenut/tracing"
 	"github.com/asdsec/thenut/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
 type Server struct {
-	config     utils.Config
-	store      db.Store
-	tokenMaker token.TokenMaker
-	router     *gin.Engine
+	config      utils.Config
+	store       db.Store
+	tokenMaker  token.TokenMaker
+	blobStorage storage.BlobStorage
+	router      *gin.Engine
+	httpServer  *http.Server
+	auditor     audit.Auditor
+	notifier    notification.Notifier
+	logger      *slog.Logger
+	metrics     *metrics.Metrics
+	openAPI     []byte
 }
 
 // NewServer creates a new HTTP server and routing
-func NewServer(config utils.Config, store db.Store, tokenMaker token.TokenMaker) (*Server, error) {
+func NewServer(config utils.Config, store db.Store, tokenMaker token.TokenMaker, blobStorage storage.BlobStorage, metrics *metrics.Metrics) (*Server, error) {
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:      config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		blobStorage: blobStorage,
+		auditor:     audit.NewStoreAuditor(store),
+		notifier:    notification.NewDispatcher(store),
+		logger:      slog.Default(),
+		metrics:     metrics,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(fieldName)
 		v.RegisterValidation("gender", validGender)
+		err := i18n.RegisterValidatorTranslations(v)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	openAPI, err := loadOpenAPIDocument()
+	if err != nil {
+		return nil, err
 	}
+	server.openAPI = openAPI
 
 	server.setupRouter()
+	server.httpServer = &http.Server{
+		Handler:      server.router,
+		ReadTimeout:  config.HTTPReadTimeout,
+		WriteTimeout: config.HTTPWriteTimeout,
+		IdleTimeout:  config.HTTPIdleTimeout,
+	}
 	return server, nil
 }
 
-// Start runs the HTTP server on a specific address
+// Start runs the HTTP server on a specific address. It blocks until the server
+// fails or is shut down, and returns nil after a shutdown.
 func (server *Server) Start(addr string) error {
-	return server.router.Run(addr)
+	server.httpServer.Addr = addr
+
+	err := server.httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting new connections and waits for the in-flight
+// requests to finish until the context is done
+func (server *Server) Shutdown(ctx context.Context) error {
+	return server.httpServer.Shutdown(ctx)
 }
 
 func (server *Server) setupRouter() {
-	router := gin.Default()
+	router := gin.New()
+	// let the store calls see the span in the request context
+	router.ContextWithFallback = true
+	router.Use(
+		otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(traceFilter)),
+		requestIDMiddleware(server.logger),
+		loggerMiddleware(),
+		metricsMiddleware(server.metrics),
+		gin.Recovery(),
+	)
 
 	router.POST("/auth/register", server.registerUser)
 	router.POST("/auth/login", server.loginUser)
 	router.POST("/tokens/renew", server.renewAccessToken)
 	router.GET("/versions", server.getVersion)
 
+	router.GET("/healthz", server.getHealth)
+	router.GET("/readyz", server.getReadiness)
+	router.GET("/version/build", server.getBuildInfo)
+	router.GET("/metrics", gin.WrapH(server.metrics.Handler()))
+	router.GET(openAPIPath, server.getOpenAPI)
+	router.GET("/docs/*filepath", server.getSwaggerUI)
+
+	if server.config.StorageBackend == "local" {
+		if publicURL, err := url.Parse(server.config.StoragePublicURL); err == nil && publicURL.Path != "" {
+			router.Static(publicURL.Path, server.config.StorageLocalDir)
+		}
+	}
+
 	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	// the create requests are retried with an Idempotency-Key to run once
+	idempotent := idempotencyMiddleware(
+		server.store,
+		server.config.IdempotencyKeyTTL,
+		server.config.MaxUploadSize+uploadBodyOverhead,
+	)
+	// the upload routes are limited before their multipart form is parsed
+	upload := uploadBodyLimitMiddleware(server.config.MaxUploadSize + uploadBodyOverhead)
 
 	authRoutes.GET("/users/:username", server.getUser)
 	// todo: add more auth method for updating email, like email verification
@@ -53,31 +138,56 @@ func (server *Server) setupRouter() {
 	// todo: add more auth method for updating password, like email verification
 	authRoutes.POST("/users/password", server.updatePassword)
 	authRoutes.PATCH("/users", server.updateUser)
+	authRoutes.PATCH("/users/image", upload, server.updateUserImage)
+	authRoutes.GET("/users/export", server.exportUser)
+	authRoutes.POST("/users/deletion", server.scheduleUserDeletion)
+	authRoutes.DELETE("/users/deletion", server.cancelUserDeletion)
+	authRoutes.GET("/users/notification-preferences", server.getNotificationPreferences)
+	authRoutes.PUT("/users/notification-preferences", server.updateNotificationPreferences)
 
 	authRoutes.GET("/accounts/:id", server.getCustomer)
-	authRoutes.POST("/accounts", server.createCustomer)
-	authRoutes.PATCH("/accounts", server.updateCustomer)
+	authRoutes.POST("/accounts", idempotent, server.createCustomer)
+	authRoutes.PATCH("/accounts", upload, server.updateCustomer)
 	authRoutes.DELETE("/accounts/:id", server.deleteCustomer)
 
 	authRoutes.GET("/accounts/merchants", server.listMerchants)
 	authRoutes.GET("/accounts/merchants/:id", server.getMerchant)
-	authRoutes.POST("/accounts/merchants", server.createMerchant)
+	authRoutes.POST("/accounts/merchants", idempotent, server.createMerchant)
 	authRoutes.PATCH("/accounts/merchants", server.updateMerchant)
+	authRoutes.PATCH("/accounts/merchants/image", upload, server.updateMerchantImage)
 	authRoutes.DELETE("/accounts/merchants/:id", server.deleteMerchant)
 
 	authRoutes.GET("/merchants/posts", server.listMerchantPosts)
 	authRoutes.GET("/posts", server.listPosts)
-	authRoutes.POST("/posts", server.createPost)
+	authRoutes.POST("/posts", idempotent, upload, server.createPost)
+	authRoutes.PATCH("/posts/:id", upload, server.updatePost)
 	authRoutes.DELETE("/posts/:id", server.deletePost)
 	authRoutes.GET("/posts/comments", server.listPostComments)
-	authRoutes.POST("/posts/comments", server.createPostComment)
+	authRoutes.POST("/posts/comments", idempotent, server.createPostComment)
+
+	authRoutes.GET("/notifications", server.listNotifications)
+	authRoutes.GET("/notifications/unread-count", server.getUnreadNotificationCount)
+	authRoutes.POST("/notifications/:id/read", server.markNotificationRead)
+	authRoutes.POST("/notifications/read", server.markAllNotificationsRead)
+	authRoutes.PUT("/sessions/:id/device-token", server.registerDeviceToken)
+	authRoutes.DELETE("/sessions/:id/device-token", server.deleteDeviceToken)
+
+	moderatorRoutes := router.Group("/").Use(
+		authMiddleware(server.tokenMaker),
+		roleMiddleware(server.store, utils.ModeratorRole, utils.AdminRole),
+	)
+
+	moderatorRoutes.GET("/posts/:id/revisions", server.listPostRevisions)
+
+	adminRoutes := router.Group("/").Use(
+		authMiddleware(server.tokenMaker),
+		roleMiddleware(server.store, utils.AdminRole),
+	)
+
+	adminRoutes.GET("/audit/events", server.listAuditEvents)
 
 	// fixme: update versions after admin
 	router.POST("/versions", server.createAppVersion)
 
 	server.router = router
 }
-
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
-}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	"github.com/asdsec/thenut/gapi"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServerShutdown(t *testing.T) {
+	server := newTestServer(t, nil, newTestTokenMaker(t))
+
+	startErr := make(chan error, 1)
+	go func() {
+		startErr <- server.Start("127.0.0.1:0")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, server.Shutdown(ctx))
+
+	select {
+	case err := <-startErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after shutdown")
+	}
+}
+
+func TestValidationFieldNamesWithGRPCServer(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	tokenMaker := newTestTokenMaker(t)
+
+	// main builds the gRPC server after the HTTP server, it must not change the
+	// field names of the HTTP validation errors
+	server := newTestServer(t, store, tokenMaker)
+	_, err := gapi.NewServer(testConfig, store, tokenMaker, metrics.New())
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		url   string
+		field string
+	}{
+		{
+			name:  "Query",
+			url:   "/posts?page_size=1",
+			field: "page_size",
+		},
+		{
+			name:  "URI",
+			url:   "/users/abc",
+			field: "username",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, utils.RandomOwner(), time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+			var apiErr apiError
+			err = json.Unmarshal(recorder.Body.Bytes(), &apiErr)
+			require.NoError(t, err)
+			require.NotEmpty(t, apiErr.Details)
+			require.Equal(t, tc.field, apiErr.Details[0].Field)
+		})
+	}
+}
@@ -1,11 +1,12 @@
 package api
 
 import (
-	"database/sql"
-	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/asdsec/thenut/audit"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/metrics"
 	"github.com/gin-gonic/gin"
 )
 
@@ -23,48 +24,43 @@ type tokenResponse struct {
 func (server *Server) renewAccessToken(ctx *gin.Context) {
 	var req renewAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	refreshTokenPayload, err := server.tokenMaker.VerifyToken(req.RefreshToken)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
 	session, err := server.store.GetSession(ctx, refreshTokenPayload.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
 	if session.IsBlocked {
-		err := fmt.Errorf("blocked session")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		err := i18n.NewError("error.session_blocked")
+		writeError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
 	if session.Username != refreshTokenPayload.Username {
-		err := fmt.Errorf("improper session user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		err := i18n.NewError("error.session_user_mismatch")
+		writeError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
 	if session.RefreshToken != req.RefreshToken {
-		err := fmt.Errorf("mismatched session token")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		err := i18n.NewError("error.session_token_mismatch")
+		writeError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
 	if time.Now().After(session.ExpiresAt) {
-		err := fmt.Errorf("expired session")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		err := i18n.NewError("error.session_expired")
+		writeError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
@@ -73,10 +69,15 @@ func (server *Server) renewAccessToken(ctx *gin.Context) {
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
+	server.recordAuditEvent(ctx, session.Username, audit.ActionRenewSession, audit.UserTarget(session.Username), map[string]interface{}{
+		"session_id": session.ID,
+	})
+	server.metrics.IncEvent(metrics.EventTokenRenewal)
+
 	rsp := tokenResponse{
 		ExpiresIn:    int(server.config.AccessTokenDuration.Seconds()),
 		RefreshToken: session.RefreshToken,
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	"github.com/asdsec/thenut/tracing"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	user, password := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_db.NewMockStore(ctrl)
+	mockStore.EXPECT().
+		GetUser(gomock.Any(), gomock.Eq(user.Username)).
+		Times(1).
+		Return(user, nil)
+	mockStore.EXPECT().
+		CreateSession(gomock.Any(), gomock.Any()).
+		Times(1)
+
+	server := newTestServer(t, tracing.NewTracedStore(mockStore), newTestTokenMaker(t))
+
+	data, err := json.Marshal(gin.H{
+		"username": user.Username,
+		"password": password,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	request, err = http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+	server.router.ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	root := spans[2]
+	require.Equal(t, "/auth/login", root.Name())
+	require.Equal(t, trace.SpanKindServer, root.SpanKind())
+
+	require.Equal(t, "GetUser", spans[0].Name())
+	require.Equal(t, "CreateSession", spans[1].Name())
+	for _, span := range spans[:2] {
+		require.Equal(t, root.SpanContext().TraceID(), span.SpanContext().TraceID())
+		require.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
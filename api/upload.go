@@ -0,0 +1,215 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/media"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	imageFormKey      = "image"
+	minImageDimension = 64
+	maxImageDimension = 4096
+	// uploadBodyOverhead is the room for the form fields and the multipart
+	// headers next to an upload
+	uploadBodyOverhead = 1 << 20
+)
+
+// supportedImageTypes are the sniffed content types accepted for uploads
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/webp": true,
+}
+
+type imageUpload struct {
+	content     []byte
+	contentType string
+	width       int
+	height      int
+}
+
+// storedImage is a processed upload written to the blob storage
+type storedImage struct {
+	key string
+	url string
+}
+
+// emptyImageVariants is stored for rows without an image, and for the images
+// whose thumbnails are not generated yet
+var emptyImageVariants = json.RawMessage("{}")
+
+// uploadBodyLimitMiddleware caps the request body before the multipart form is
+// parsed, which would otherwise spool the whole body to memory and disk before
+// the size of the image is checked
+func uploadBodyLimitMiddleware(maxBodySize int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxBodySize)
+		ctx.Next()
+	}
+}
+
+// writeBodyError responds to a request whose body could not be read or bound,
+// a body over the limit of uploadBodyLimitMiddleware is too large
+func writeBodyError(ctx *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		err := i18n.NewError("error.request_too_large", strconv.FormatInt(maxBytesErr.Limit, 10))
+		writeError(ctx, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	writeError(ctx, http.StatusBadRequest, err)
+}
+
+// readImageUpload reads and validates the image file of a multipart request.
+// A missing file, or a request that is not multipart at all, is not an error
+// unless the image is required.
+func readImageUpload(ctx *gin.Context, server *Server, required bool) (*imageUpload, bool) {
+	fileHeader, err := ctx.FormFile(imageFormKey)
+	if err != nil {
+		missing := errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart)
+		if missing && !required {
+			return nil, true
+		}
+		writeBodyError(ctx, err)
+		return nil, false
+	}
+
+	if fileHeader.Size > server.config.MaxUploadSize {
+		err := i18n.NewError("error.image_too_large", strconv.FormatInt(server.config.MaxUploadSize, 10))
+		writeError(ctx, http.StatusRequestEntityTooLarge, err)
+		return nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, server.config.MaxUploadSize+1))
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+	if int64(len(content)) > server.config.MaxUploadSize {
+		err := i18n.NewError("error.image_too_large", strconv.FormatInt(server.config.MaxUploadSize, 10))
+		writeError(ctx, http.StatusRequestEntityTooLarge, err)
+		return nil, false
+	}
+
+	contentType := http.DetectContentType(content)
+	if !supportedImageTypes[contentType] {
+		err := i18n.NewError("error.image_type_unsupported", contentType)
+		writeError(ctx, http.StatusUnsupportedMediaType, err)
+		return nil, false
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+	if cfg.Width < minImageDimension || cfg.Height < minImageDimension ||
+		cfg.Width > maxImageDimension || cfg.Height > maxImageDimension {
+		err := i18n.NewError("error.image_dimensions",
+			strconv.Itoa(minImageDimension), strconv.Itoa(minImageDimension),
+			strconv.Itoa(maxImageDimension), strconv.Itoa(maxImageDimension))
+		writeError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	upload := &imageUpload{
+		content:     content,
+		contentType: contentType,
+		width:       cfg.Width,
+		height:      cfg.Height,
+	}
+	return upload, true
+}
+
+// bindJSONOrMultipart binds a multipart form when the request carries one and
+// falls back to JSON, so that clients without images keep sending JSON bodies.
+func bindJSONOrMultipart(ctx *gin.Context, obj any) error {
+	if ctx.ContentType() == binding.MIMEMultipartPOSTForm {
+		return ctx.ShouldBindWith(obj, binding.FormMultipart)
+	}
+	return ctx.ShouldBindJSON(obj)
+}
+
+// storeImageUpload normalizes the upload and writes it under a unique name in
+// the given folder. Its thumbnails are generated later by the ThumbnailJob,
+// see queueThumbnails.
+func storeImageUpload(ctx *gin.Context, server *Server, folder string, upload *imageUpload) (*storedImage, bool) {
+	processed, err := media.Process(upload.content, nil)
+	if err != nil {
+		if errors.Is(err, media.ErrUnsupportedFormat) {
+			writeError(ctx, http.StatusUnsupportedMediaType, err)
+			return nil, false
+		}
+		writeError(ctx, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	name := fmt.Sprintf("%s/%s", folder, uuid.New())
+	stored := &storedImage{}
+
+	stored.url, err = putImage(ctx, server, stored, name, processed.Original)
+	if err != nil {
+		writeError(ctx, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return stored, true
+}
+
+func putImage(ctx *gin.Context, server *Server, stored *storedImage, name string, img media.Image) (string, error) {
+	key := name + img.Extension
+	url, err := server.blobStorage.Put(ctx, key, img.ContentType, bytes.NewReader(img.Content), int64(len(img.Content)))
+	if err != nil {
+		return "", err
+	}
+	stored.key = key
+	return url, nil
+}
+
+// queueThumbnails queues the ThumbnailJob of an image stored on the row. The
+// image is shown without thumbnails until the job is done, so a failure to
+// queue it does not fail the request.
+func queueThumbnails(ctx *gin.Context, server *Server, stored *storedImage, args media.ThumbnailArgs) {
+	args.Key = stored.key
+	args.URL = stored.url
+	_, err := media.ThumbnailJob.Enqueue(ctx, server.store, args)
+	if err != nil {
+		ctx.Error(fmt.Errorf("cannot queue thumbnails of %s: %w", args.Key, err))
+	}
+}
+
+// deleteImageUpload removes a stored image whose database update failed
+func deleteImageUpload(ctx *gin.Context, server *Server, stored *storedImage) {
+	_ = server.blobStorage.Delete(ctx, stored.key)
+}
+
+// newImageVariantsResponse decodes the stored thumbnail urls of a row
+func newImageVariantsResponse(variants json.RawMessage) map[string]string {
+	var urls map[string]string
+	if err := json.Unmarshal(variants, &urls); err != nil || len(urls) == 0 {
+		return nil
+	}
+	return urls
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
+	"github.com/asdsec/thenut/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomImage(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 0x80, A: 0xff})
+		}
+	}
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	require.NoError(t, err)
+
+	return buf.Bytes()
+}
+
+func newMultipartRequest(t *testing.T, method, url string, fields gin.H, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	for key, value := range fields {
+		err := writer.WriteField(key, fmt.Sprint(value))
+		require.NoError(t, err)
+	}
+
+	if content != nil {
+		part, err := writer.CreateFormFile(imageFormKey, "image.png")
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+
+	err := writer.Close()
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(method, url, &body)
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return request
+}
+
+type eqImageUrlMatcher struct {
+	prefix string
+}
+
+func (e eqImageUrlMatcher) Matches(x interface{}) bool {
+	url, ok := x.(string)
+	return ok && strings.HasPrefix(url, e.prefix) && len(url) > len(e.prefix)
+}
+
+func (e eqImageUrlMatcher) String() string {
+	return fmt.Sprintf("is an image url stored under %s", e.prefix)
+}
+
+// isPendingVariants checks that no thumbnail is stored before the ThumbnailJob
+func isPendingVariants(raw json.RawMessage) bool {
+	return string(raw) == string(emptyImageVariants)
+}
+
+type eqThumbnailJobMatcher struct {
+	row      media.ThumbnailArgs
+	folder   string
+	imageUrl eqImageUrlMatcher
+}
+
+func (e eqThumbnailJobMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.EnqueueJobParams)
+	if !ok || arg.Kind != media.ThumbnailJob.Name {
+		return false
+	}
+
+	var args media.ThumbnailArgs
+	if err := json.Unmarshal(arg.Payload, &args); err != nil {
+		return false
+	}
+	if !e.imageUrl.Matches(args.URL) || !strings.HasPrefix(args.Key, e.folder+"/") ||
+		!strings.HasSuffix(args.URL, "/"+args.Key) {
+		return false
+	}
+
+	args.Key, args.URL = "", ""
+	return args == e.row
+}
+
+func (e eqThumbnailJobMatcher) String() string {
+	return fmt.Sprintf("queues the thumbnails of %v, %s", e.row, e.imageUrl)
+}
+
+// expectThumbnailJob expects the ThumbnailJob of an image stored in the folder
+// for the given row
+func expectThumbnailJob(store *mock_db.MockStore, folder string, row media.ThumbnailArgs) {
+	store.EXPECT().
+		EnqueueJob(gomock.Any(), eqThumbnailJobMatcher{row, folder, isStoredImageUrl(folder)}).
+		Times(1).
+		Return(db.Job{ID: 1, Kind: media.ThumbnailJob.Name}, nil)
+}
+
+// isStoredImageUrl matches the URLs generated by storeImageUpload for a folder
+func isStoredImageUrl(folder string) eqImageUrlMatcher {
+	return eqImageUrlMatcher{testConfig.StoragePublicURL + "/" + folder + "/"}
+}
+
+func TestUploadBodyTooLarge(t *testing.T) {
+	content := make([]byte, testConfig.MaxUploadSize+uploadBodyOverhead+1)
+
+	testCases := []struct {
+		name   string
+		method string
+		url    string
+		fields gin.H
+	}{
+		{
+			name:   "UpdateUserImage",
+			method: http.MethodPatch,
+			url:    "/users/image",
+		},
+		{
+			name:   "UpdateCustomer",
+			method: http.MethodPatch,
+			url:    "/accounts",
+			fields: gin.H{"id": 1},
+		},
+		{
+			name:   "UpdateMerchantImage",
+			method: http.MethodPatch,
+			url:    "/accounts/merchants/image",
+			fields: gin.H{"id": 1},
+		},
+		{
+			name:   "CreatePost",
+			method: http.MethodPost,
+			url:    "/posts",
+			fields: gin.H{"merchant_id": 1, "title": "title"},
+		},
+		{
+			name:   "UpdatePost",
+			method: http.MethodPatch,
+			url:    "/posts/1",
+			fields: gin.H{"title": "title"},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// the body is rejected before any store call
+			store := mock_db.NewMockStore(ctrl)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request := newMultipartRequest(t, tc.method, tc.url, tc.fields, content)
+			addAuthorization(t, request, tokenMaker, authorizationTypeBearer, utils.RandomOwner(), time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+
+			// the body limit stops the request, not the image size check
+			var apiErr apiError
+			err := json.Unmarshal(recorder.Body.Bytes(), &apiErr)
+			require.NoError(t, err)
+			require.Contains(t, apiErr.Message, strconv.FormatInt(testConfig.MaxUploadSize+uploadBodyOverhead, 10))
+		})
+	}
+}
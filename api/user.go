@@ -2,14 +2,18 @@
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/asdsec/thenut/audit"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/storage"
 	"github.com/asdsec/thenut/utils"
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
+	"github.com/google/uuid"
 )
 
 type authResponse struct {
@@ -19,6 +23,8 @@ type authResponse struct {
 	Username     string `json:"username"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int    `json:"expires_in"`
+	// SessionID registers the push token of the device of the session
+	SessionID uuid.UUID `json:"session_id"`
 }
 
 type registerUserRequest struct {
@@ -34,13 +40,13 @@ type registerUserRequest struct {
 func (server *Server) registerUser(ctx *gin.Context) {
 	var req registerUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -54,17 +60,9 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		BirthDate:      req.BirthDate,
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
+	user, err := server.store.CreateUserTx(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
@@ -73,7 +71,7 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -82,7 +80,7 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -96,7 +94,7 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		ExpiresAt:    refreshTokenPayload.ExpiredAt,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
@@ -107,7 +105,9 @@ func (server *Server) registerUser(ctx *gin.Context) {
 		Username:     user.Username,
 		RefreshToken: refreshToken,
 		ExpiresIn:    int(server.config.AccessTokenDuration.Seconds()),
+		SessionID:    refreshTokenPayload.ID,
 	}
+	server.metrics.IncEvent(metrics.EventRegistration)
 	ctx.JSON(http.StatusOK, rsp)
 }
 
@@ -119,7 +119,7 @@ type loginUserRequest struct {
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -130,7 +130,20 @@ func (server *Server) loginUser(ctx *gin.Context) {
 
 	err := utils.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		server.recordAuditEvent(ctx, user.Username, audit.ActionLoginFailed, audit.UserTarget(user.Username), map[string]interface{}{
+			"reason": "wrong_password",
+		})
+		server.metrics.IncEvent(metrics.EventLoginFailed)
+		writeError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if user.Disabled {
+		server.recordAuditEvent(ctx, user.Username, audit.ActionLoginFailed, audit.UserTarget(user.Username), map[string]interface{}{
+			"reason": "disabled",
+		})
+		server.metrics.IncEvent(metrics.EventLoginFailed)
+		writeError(ctx, http.StatusForbidden, errUserDisabled)
 		return
 	}
 
@@ -139,7 +152,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		server.config.AccessTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -148,7 +161,7 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		server.config.RefreshTokenDuration,
 	)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -162,10 +175,15 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		ExpiresAt:    refreshTokenPayload.ExpiredAt,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
+	server.recordAuditEvent(ctx, user.Username, audit.ActionLogin, audit.UserTarget(user.Username), map[string]interface{}{
+		"session_id": refreshTokenPayload.ID,
+	})
+	server.metrics.IncEvent(metrics.EventLogin)
+
 	rsp := authResponse{
 		AccessToken:  accessToken,
 		Email:        user.Email,
@@ -173,25 +191,29 @@ func (server *Server) loginUser(ctx *gin.Context) {
 		Username:     user.Username,
 		RefreshToken: refreshToken,
 		ExpiresIn:    int(server.config.AccessTokenDuration.Seconds()),
+		SessionID:    refreshTokenPayload.ID,
 	}
 	ctx.JSON(http.StatusOK, rsp)
 }
 
 type userResponse struct {
-	Username          string    `json:"username"`
-	FullName          string    `json:"full_name"`
-	Email             string    `json:"email"`
-	PhoneNumber       string    `json:"phone_number"`
-	ImageUrl          string    `json:"image_url"`
-	Gender            string    `json:"gender"`
-	Disabled          bool      `json:"disabled"`
-	BirthDate         time.Time `json:"birth_date"`
-	PasswordChangedAt time.Time `json:"password_changed_at"`
-	CreatedAt         time.Time `json:"created_at"`
+	Username          string            `json:"username"`
+	FullName          string            `json:"full_name"`
+	Email             string            `json:"email"`
+	PhoneNumber       string            `json:"phone_number"`
+	ImageUrl          string            `json:"image_url"`
+	ImageVariants     map[string]string `json:"image_variants,omitempty"`
+	Gender            string            `json:"gender"`
+	Disabled          bool              `json:"disabled"`
+	BirthDate         time.Time         `json:"birth_date"`
+	PasswordChangedAt time.Time         `json:"password_changed_at"`
+	CreatedAt         time.Time         `json:"created_at"`
+	// DeletionScheduledAt is set while an account deletion is pending
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
 }
 
 func newUserResponse(user db.User) userResponse {
-	return userResponse{
+	rsp := userResponse{
 		Username:          user.Username,
 		Email:             user.Email,
 		FullName:          user.FullName,
@@ -199,10 +221,15 @@ func newUserResponse(user db.User) userResponse {
 		Gender:            user.Gender,
 		BirthDate:         user.BirthDate,
 		ImageUrl:          user.ImageUrl,
+		ImageVariants:     newImageVariantsResponse(user.ImageVariants),
 		Disabled:          user.Disabled,
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
+	if user.DeletionScheduledAt.Valid {
+		rsp.DeletionScheduledAt = &user.DeletionScheduledAt.Time
+	}
+	return rsp
 }
 
 type getUserRequest struct {
@@ -212,7 +239,7 @@ type getUserRequest struct {
 func (server *Server) getUser(ctx *gin.Context) {
 	var req getUserRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -223,11 +250,14 @@ func (server *Server) getUser(ctx *gin.Context) {
 
 	authPayload := server.getAuthPayload(ctx)
 	if user.Username != authPayload.Username {
-		err := errors.New("account does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errAccountNotOwned)
+		return
+	}
+	if checkNotModified(ctx, user.Version) {
 		return
 	}
 
+	setETag(ctx, user.Version)
 	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
@@ -239,14 +269,18 @@ type updateEmailRequest struct {
 func (server *Server) updateEmail(ctx *gin.Context) {
 	var req updateEmailRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := server.getAuthPayload(ctx)
 	if req.Username != authPayload.Username {
-		err := errors.New("username does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errUsernameNotOwned)
+		return
+	}
+
+	oldUser, ok := getUserFromStore(ctx, server, req.Username)
+	if !ok {
 		return
 	}
 
@@ -257,15 +291,14 @@ func (server *Server) updateEmail(ctx *gin.Context) {
 
 	user, err := server.store.UpdateEmail(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
+	server.recordAuditEvent(ctx, user.Username, audit.ActionChangeEmail, audit.UserTarget(user.Username), map[string]interface{}{
+		"email": audit.Change{Old: oldUser.Email, New: user.Email},
+	})
+
 	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
@@ -278,14 +311,13 @@ type updatePasswordRequest struct {
 func (server *Server) updatePassword(ctx *gin.Context) {
 	var req updatePasswordRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := server.getAuthPayload(ctx)
 	if req.Username != authPayload.Username {
-		err := errors.New("username does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errUsernameNotOwned)
 		return
 	}
 
@@ -296,13 +328,13 @@ func (server *Server) updatePassword(ctx *gin.Context) {
 
 	err := utils.CheckPassword(req.OldPassword, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, err)
 		return
 	}
 
 	hashedPassword, err := utils.HashPassword(req.NewPassword)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -312,17 +344,18 @@ func (server *Server) updatePassword(ctx *gin.Context) {
 		PasswordChangedAt: time.Now(),
 	}
 
+	oldPasswordChangedAt := user.PasswordChangedAt
 	user, err = server.store.UpdatePassword(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
+	// the diff never contains the password hashes
+	server.recordAuditEvent(ctx, user.Username, audit.ActionChangePassword, audit.UserTarget(user.Username), map[string]interface{}{
+		"password_changed_at": audit.Change{Old: oldPasswordChangedAt, New: user.PasswordChangedAt},
+	})
+
 	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
@@ -331,21 +364,23 @@ type updateUserRequest struct {
 	PhoneNumber string    `json:"phone_number"`
 	Gender      string    `json:"gender"`
 	BirthDate   time.Time `json:"birth_date"`
-	ImageUrl    string    `json:"image_url"`
 	Username    string    `json:"username" binding:"required,min=6"`
 }
 
 func (server *Server) updateUser(ctx *gin.Context) {
 	var req updateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
 	authPayload := server.getAuthPayload(ctx)
 	if req.Username != authPayload.Username {
-		err := errors.New("username does not belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		writeError(ctx, http.StatusUnauthorized, errUsernameNotOwned)
+		return
+	}
+	version, ok := checkUserIfMatch(ctx, server, req.Username)
+	if !ok {
 		return
 	}
 
@@ -363,40 +398,228 @@ func (server *Server) updateUser(ctx *gin.Context) {
 			String: req.Gender,
 			Valid:  len(req.Gender) > 0,
 		},
-		ImageUrl: sql.NullString{
-			String: req.ImageUrl,
-			Valid:  len(req.ImageUrl) > 0,
-		},
 		BirthDate: sql.NullTime{
 			Time:  req.BirthDate,
 			Valid: true,
 		},
+		Version: version,
 	}
 
 	user, err := server.store.UpdateUser(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
+		writeConditionalStoreError(ctx, err, version)
+		return
+	}
+
+	setETag(ctx, user.Version)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (server *Server) updateUserImage(ctx *gin.Context) {
+	upload, ok := readImageUpload(ctx, server, true)
+	if !ok {
+		return
+	}
+
+	authPayload := server.getAuthPayload(ctx)
+	version, ok := checkUserIfMatch(ctx, server, authPayload.Username)
+	if !ok {
+		return
+	}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	stored, ok := storeImageUpload(ctx, server, storage.UserFolder(authPayload.Username), upload)
+	if !ok {
+		return
+	}
+
+	arg := db.UpdateUserImageParams{
+		Username:      authPayload.Username,
+		ImageUrl:      stored.url,
+		ImageVariants: emptyImageVariants,
+		Version:       version,
+	}
+
+	user, err := server.store.UpdateUserImage(ctx, arg)
+	if err != nil {
+		deleteImageUpload(ctx, server, stored)
+		writeConditionalStoreError(ctx, err, version)
 		return
 	}
+	queueThumbnails(ctx, server, stored, media.ThumbnailArgs{Owner: media.OwnerUser, Username: user.Username})
 
+	setETag(ctx, user.Version)
 	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
+// checkUserIfMatch checks the If-Match header against the current version of
+// the user. The user is read only for the conditional updates, the others
+// update it directly.
+func checkUserIfMatch(ctx *gin.Context, server *Server, username string) (sql.NullInt64, bool) {
+	if ctx.GetHeader(ifMatchHeader) == "" {
+		return sql.NullInt64{}, true
+	}
+
+	user, ok := getUserFromStore(ctx, server, username)
+	if !ok {
+		return sql.NullInt64{}, false
+	}
+	return checkIfMatch(ctx, user.Version)
+}
+
 func getUserFromStore(ctx *gin.Context, server *Server, username string) (db.User, bool) {
 	user, err := server.store.GetUser(ctx, username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return user, false
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return user, false
 	}
 	return user, true
 }
+
+type scheduleUserDeletionRequest struct {
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// scheduleUserDeletion marks the account of the authenticated user to be
+// purged once the grace period passes, until then the deletion can be canceled
+func (server *Server) scheduleUserDeletion(ctx *gin.Context) {
+	var req scheduleUserDeletionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		writeError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	authPayload := server.getAuthPayload(ctx)
+	user, ok := getUserFromStore(ctx, server, authPayload.Username)
+	if !ok {
+		return
+	}
+
+	err := utils.CheckPassword(req.Password, user.HashedPassword)
+	if err != nil {
+		writeError(ctx, http.StatusUnauthorized, err)
+		return
+	}
+
+	if user.DeletionScheduledAt.Valid {
+		ctx.JSON(http.StatusOK, newUserResponse(user))
+		return
+	}
+
+	arg := db.ScheduleUserDeletionParams{
+		Username: user.Username,
+		DeletionScheduledAt: sql.NullTime{
+			Time:  time.Now().Add(server.config.AccountDeletionGracePeriod),
+			Valid: true,
+		},
+	}
+
+	user, err = server.store.ScheduleUserDeletion(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+func (server *Server) cancelUserDeletion(ctx *gin.Context) {
+	authPayload := server.getAuthPayload(ctx)
+	arg := db.ScheduleUserDeletionParams{
+		Username: authPayload.Username,
+	}
+
+	user, err := server.store.ScheduleUserDeletion(ctx, arg)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type sessionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+type exportUserResponse struct {
+	User          userResponse                    `json:"user"`
+	Customers     []db.Customer                   `json:"customers"`
+	Merchants     []db.Merchant                   `json:"merchants"`
+	Posts         []postResponse                  `json:"posts"`
+	Comments      []commentResponse               `json:"comments"`
+	Consultancies []db.Consultancy                `json:"consultancies"`
+	Sessions      []sessionResponse               `json:"sessions"`
+	Notifications []notificationResponse          `json:"notifications"`
+	DeviceTokens  []deviceTokenResponse           `json:"device_tokens"`
+	Preferences   notificationPreferencesResponse `json:"notification_preferences"`
+	AuditEvents   []auditEventResponse            `json:"audit_events"`
+	ExportedAt    time.Time                       `json:"exported_at"`
+}
+
+func newExportUserResponse(data db.ExportUserTxResult) exportUserResponse {
+	rsp := exportUserResponse{
+		User:          newUserResponse(data.User),
+		Customers:     data.Customers,
+		Merchants:     data.Merchants,
+		Posts:         make([]postResponse, len(data.Posts)),
+		Comments:      make([]commentResponse, len(data.Comments)),
+		Consultancies: data.Consultancies,
+		Sessions:      make([]sessionResponse, len(data.Sessions)),
+		Notifications: newNotificationsResponse(data.Notifications),
+		DeviceTokens:  make([]deviceTokenResponse, len(data.DeviceTokens)),
+		AuditEvents:   newAuditEventsResponse(data.AuditEvents),
+		ExportedAt:    time.Now(),
+	}
+	for i := range data.Posts {
+		rsp.Posts[i] = newPostResponse(data.Posts[i])
+	}
+	for i := range data.Comments {
+		rsp.Comments[i] = newCommentResponse(data.Comments[i])
+	}
+	for i := range data.Sessions {
+		rsp.Sessions[i] = newSessionResponse(data.Sessions[i])
+	}
+	for i := range data.DeviceTokens {
+		rsp.DeviceTokens[i] = newDeviceTokenResponse(data.DeviceTokens[i])
+	}
+
+	// the users who have not set anything get the defaults
+	settings := db.NotificationSetting{TimeZone: time.UTC.String()}
+	if data.NotificationSettings != nil {
+		settings = *data.NotificationSettings
+	}
+	rsp.Preferences = newNotificationPreferencesResponse(settings, data.NotificationPreferences)
+	return rsp
+}
+
+// exportUser sends everything stored about the authenticated user as a JSON
+// file, secrets like the password hash and refresh tokens are left out
+func (server *Server) exportUser(ctx *gin.Context) {
+	authPayload := server.getAuthPayload(ctx)
+
+	data, err := server.store.ExportUserTx(ctx, authPayload.Username)
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	filename := fmt.Sprintf("thenut-%s.json", authPayload.Username)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.JSON(http.StatusOK, newExportUserResponse(data))
+}
@@ -16,11 +16,13 @@
 
 	mock_db "github.com/asdsec/thenut/db/mock"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/media"
 	"github.com/asdsec/thenut/token"
 	mock_token "github.com/asdsec/thenut/token/mock"
 	"github.com/asdsec/thenut/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
@@ -67,6 +69,33 @@ func TestLoginUserAPI(t *testing.T) {
 				requireBodyMatchAuthResponse(t, recorder.Body, rsp)
 			},
 		},
+		{
+			name: "DisabledUser",
+			body: gin.H{
+				"username": user.Username,
+				"password": password,
+			},
+			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
+				disabledUser := user
+				disabledUser.Disabled = true
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(disabledUser, nil)
+
+				tokenMaker.EXPECT().
+					CreateToken(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "InvalidUsername",
 			body: gin.H{
@@ -333,7 +362,7 @@ func TestRegisterUserAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateUser(gomock.Any(), EqRegisterUserParams(arg, password)).
+					CreateUserTx(gomock.Any(), EqRegisterUserParams(arg, password)).
 					Times(1).
 					Return(user, nil)
 
@@ -370,7 +399,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 
 				tokenMaker.EXPECT().
@@ -402,7 +431,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(0)
 
 				tokenMaker.EXPECT().
@@ -434,7 +463,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, &pq.Error{Code: "23505"})
 
@@ -467,7 +496,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.User{}, sql.ErrConnDone)
 
@@ -500,7 +529,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(user, nil)
 
@@ -534,7 +563,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(user, nil)
 
@@ -569,7 +598,7 @@ func TestRegisterUserAPI(t *testing.T) {
 			},
 			buildStubs: func(store *mock_db.MockStore, tokenMaker *mock_token.MockTokenMaker) {
 				store.EXPECT().
-					CreateUser(gomock.Any(), gomock.Any()).
+					CreateUserTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(user, nil)
 
@@ -778,6 +807,11 @@ func TestUpdateEmailAPI(t *testing.T) {
 					Email:    user.Email,
 				}
 
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
 				store.EXPECT().
 					UpdateEmail(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -821,6 +855,11 @@ func TestUpdateEmailAPI(t *testing.T) {
 					Email:    user.Email,
 				}
 
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
 				store.EXPECT().
 					UpdateEmail(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -845,6 +884,11 @@ func TestUpdateEmailAPI(t *testing.T) {
 					Email:    user.Email,
 				}
 
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
 				store.EXPECT().
 					UpdateEmail(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
@@ -952,7 +996,6 @@ func TestUpdateUserAPI(t *testing.T) {
 	phoneNumber := "updated_phone_number"
 	gender := "updated_gender"
 	birthDate := time.Now()
-	imageUrl := "updated_image_url"
 
 	testCases := []struct {
 		name          string
@@ -968,7 +1011,6 @@ func TestUpdateUserAPI(t *testing.T) {
 				"phone_number": phoneNumber,
 				"gender":       gender,
 				"birth_date":   birthDate,
-				"image_url":    imageUrl,
 				"username":     user.Username,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
@@ -979,7 +1021,7 @@ func TestUpdateUserAPI(t *testing.T) {
 					FullName:          fullName,
 					PhoneNumber:       phoneNumber,
 					Gender:            gender,
-					ImageUrl:          imageUrl,
+					ImageUrl:          user.ImageUrl,
 					BirthDate:         birthDate,
 					Username:          user.Username,
 					HashedPassword:    user.HashedPassword,
@@ -1006,10 +1048,6 @@ func TestUpdateUserAPI(t *testing.T) {
 						Time:  expectedUser.BirthDate,
 						Valid: true,
 					},
-					ImageUrl: sql.NullString{
-						String: expectedUser.ImageUrl,
-						Valid:  true,
-					},
 					Username: expectedUser.Username,
 				}
 
@@ -1076,7 +1114,6 @@ func TestUpdateUserAPI(t *testing.T) {
 				"phone_number": phoneNumber,
 				"gender":       gender,
 				"birth_date":   birthDate,
-				"image_url":    imageUrl,
 				"username":     "invld", // invalid username
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
@@ -1114,7 +1151,6 @@ func TestUpdateUserAPI(t *testing.T) {
 				"phone_number": phoneNumber,
 				"gender":       gender,
 				"birth_date":   birthDate,
-				"image_url":    imageUrl,
 				"username":     user.Username,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
@@ -1135,7 +1171,6 @@ func TestUpdateUserAPI(t *testing.T) {
 				"phone_number": phoneNumber,
 				"gender":       gender,
 				"birth_date":   birthDate,
-				"image_url":    imageUrl,
 				"username":     user.Username,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
@@ -1156,7 +1191,6 @@ func TestUpdateUserAPI(t *testing.T) {
 				"phone_number": phoneNumber,
 				"gender":       gender,
 				"birth_date":   birthDate,
-				"image_url":    imageUrl,
 				"username":     user.Username,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
@@ -1167,7 +1201,7 @@ func TestUpdateUserAPI(t *testing.T) {
 					FullName:          fullName,
 					PhoneNumber:       phoneNumber,
 					Gender:            gender,
-					ImageUrl:          imageUrl,
+					ImageUrl:          user.ImageUrl,
 					BirthDate:         birthDate,
 					Username:          user.Username,
 					HashedPassword:    user.HashedPassword,
@@ -1194,10 +1228,6 @@ func TestUpdateUserAPI(t *testing.T) {
 						Time:  expectedUser.BirthDate,
 						Valid: true,
 					},
-					ImageUrl: sql.NullString{
-						String: expectedUser.ImageUrl,
-						Valid:  true,
-					},
 					Username: expectedUser.Username,
 				}
 
@@ -1218,7 +1248,6 @@ func TestUpdateUserAPI(t *testing.T) {
 				"phone_number": phoneNumber,
 				"gender":       gender,
 				"birth_date":   birthDate,
-				"image_url":    imageUrl,
 				"username":     user.Username,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
@@ -1229,7 +1258,7 @@ func TestUpdateUserAPI(t *testing.T) {
 					FullName:          fullName,
 					PhoneNumber:       phoneNumber,
 					Gender:            gender,
-					ImageUrl:          imageUrl,
+					ImageUrl:          user.ImageUrl,
 					BirthDate:         birthDate,
 					Username:          user.Username,
 					HashedPassword:    user.HashedPassword,
@@ -1256,10 +1285,6 @@ func TestUpdateUserAPI(t *testing.T) {
 						Time:  expectedUser.BirthDate,
 						Valid: true,
 					},
-					ImageUrl: sql.NullString{
-						String: expectedUser.ImageUrl,
-						Valid:  true,
-					},
 					Username: expectedUser.Username,
 				}
 
@@ -1740,6 +1765,7 @@ func randomUser(t *testing.T) (user db.User, password string) {
 		PhoneNumber:    utils.RandomPhoneNumber(),
 		ImageUrl:       utils.RandomImageUrl(),
 		Gender:         utils.RandomGender(),
+		Role:           utils.UserRole,
 		Disabled:       false,
 		BirthDate:      utils.RandomBirthDate(),
 	}
@@ -1792,3 +1818,592 @@ func requireBodyMatchAuthResponse(t *testing.T, body *bytes.Buffer, expectedAuth
 	require.Equal(t, expectedAuthResponse.RefreshToken, gotAuthResponse.RefreshToken)
 	require.Equal(t, expectedAuthResponse.Username, gotAuthResponse.Username)
 }
+
+type eqUpdateUserImageParamsMatcher struct {
+	username string
+	imageUrl eqImageUrlMatcher
+}
+
+func (e eqUpdateUserImageParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.UpdateUserImageParams)
+	if !ok {
+		return false
+	}
+
+	return arg.Username == e.username &&
+		e.imageUrl.Matches(arg.ImageUrl) &&
+		isPendingVariants(arg.ImageVariants)
+}
+
+func (e eqUpdateUserImageParamsMatcher) String() string {
+	return fmt.Sprintf("matches username %s and %v", e.username, e.imageUrl)
+}
+
+func EqUpdateUserImageParams(username string) gomock.Matcher {
+	return eqUpdateUserImageParamsMatcher{username, isStoredImageUrl("users/" + username)}
+}
+
+func TestUpdateUserImageAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	image := randomImage(t, 128, 128)
+
+	testCases := []struct {
+		name          string
+		image         []byte
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Ok",
+			image: image,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUserImage(gomock.Any(), EqUpdateUserImageParams(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				expectThumbnailJob(store, "users/"+user.Username, media.ThumbnailArgs{Owner: media.OwnerUser, Username: user.Username})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name:  "MissingImage",
+			image: nil,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUserImage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "ImageDimensionsTooLarge",
+			image: randomImage(t, maxImageDimension+1, 64),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUserImage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NoAuthorization",
+			image: image,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				// No authorization
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUserImage(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalServerError",
+			image: image,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUserImage(gomock.Any(), EqUpdateUserImageParams(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			url := "/users/image"
+			request := newMultipartRequest(t, http.MethodPatch, url, gin.H{}, tc.image)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+type eqScheduleUserDeletionParamsMatcher struct {
+	username    string
+	scheduledAt time.Time
+}
+
+func (e eqScheduleUserDeletionParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.ScheduleUserDeletionParams)
+	if !ok {
+		return false
+	}
+
+	if arg.Username != e.username || !arg.DeletionScheduledAt.Valid {
+		return false
+	}
+	diff := arg.DeletionScheduledAt.Time.Sub(e.scheduledAt)
+	return diff > -time.Minute && diff < time.Minute
+}
+
+func (e eqScheduleUserDeletionParamsMatcher) String() string {
+	return fmt.Sprintf("matches username %s and deletion at %v", e.username, e.scheduledAt)
+}
+
+func EqScheduleUserDeletionParams(username string, scheduledAt time.Time) gomock.Matcher {
+	return eqScheduleUserDeletionParamsMatcher{username, scheduledAt}
+}
+
+func TestScheduleUserDeletionAPI(t *testing.T) {
+	user, password := randomUser(t)
+	scheduled := user
+	scheduled.DeletionScheduledAt = sql.NullTime{
+		Time:  time.Now().Add(testConfig.AccountDeletionGracePeriod),
+		Valid: true,
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Ok",
+			body: gin.H{
+				"password": password,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), EqScheduleUserDeletionParams(user.Username, scheduled.DeletionScheduledAt.Time)).
+					Times(1).
+					Return(scheduled, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp userResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.Username)
+				require.NotNil(t, rsp.DeletionScheduledAt)
+				require.WithinDuration(t, scheduled.DeletionScheduledAt.Time, *rsp.DeletionScheduledAt, time.Second)
+			},
+		},
+		{
+			name: "AlreadyScheduled",
+			body: gin.H{
+				"password": password,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(scheduled, nil)
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "WrongPassword",
+			body: gin.H{
+				"password": "wrong_password",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "MissingPassword",
+			body: gin.H{},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			body: gin.H{
+				"password": password,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				// no authorization
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			body: gin.H{
+				"password": password,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/deletion", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCancelUserDeletionAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Ok",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				arg := db.ScheduleUserDeletionParams{
+					Username: user.Username,
+				}
+
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "deletion_scheduled_at")
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				// no authorization
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ScheduleUserDeletion(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, "/users/deletion", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestExportUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+	data := db.ExportUserTxResult{
+		User:      user,
+		Merchants: []db.Merchant{merchant},
+		Posts:     []db.Post{randomPost(merchant.ID)},
+		Sessions: []db.Session{
+			{
+				ID:           uuid.New(),
+				Username:     user.Username,
+				RefreshToken: utils.RandomString(32),
+				UserAgent:    utils.RandomString(12),
+				ClientIp:     "127.0.0.1",
+				ExpiresAt:    time.Now().Add(time.Hour),
+			},
+		},
+		Notifications: []db.Notification{
+			{ID: 1, Recipient: user.Username, EventType: "post_commented", Actor: utils.RandomOwner(), Target: "post:1", Data: json.RawMessage(`{}`)},
+		},
+		DeviceTokens: []db.DeviceToken{
+			{SessionID: uuid.New(), Username: user.Username, Platform: "fcm", Token: utils.RandomString(64), Locale: "en"},
+		},
+		NotificationPreferences: []db.NotificationPreference{
+			{Username: user.Username, EventType: "marketing", Channel: "push", Enabled: true},
+		},
+		AuditEvents: []db.AuditEvent{
+			{ID: 1, Actor: user.Username, Action: "user.update", Target: "user:" + user.Username, Diff: json.RawMessage(`{}`)},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker)
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Ok",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ExportUserTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(data, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment")
+
+				body := recorder.Body.String()
+				require.NotContains(t, body, user.HashedPassword)
+				require.NotContains(t, body, data.Sessions[0].RefreshToken)
+
+				var rsp exportUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.User.Username)
+				require.Equal(t, user.Email, rsp.User.Email)
+				require.Len(t, rsp.Merchants, 1)
+				require.Equal(t, merchant.ID, rsp.Merchants[0].ID)
+				require.Len(t, rsp.Posts, 1)
+				require.Len(t, rsp.Sessions, 1)
+				require.Equal(t, data.Sessions[0].ID, rsp.Sessions[0].ID)
+				require.Len(t, rsp.Notifications, 1)
+				require.Equal(t, data.Notifications[0].ID, rsp.Notifications[0].ID)
+
+				// the push token is left out like the refresh token
+				require.NotContains(t, body, data.DeviceTokens[0].Token)
+				require.Len(t, rsp.DeviceTokens, 1)
+				require.Equal(t, data.DeviceTokens[0].SessionID, rsp.DeviceTokens[0].SessionID)
+
+				// the settings that are not set are the defaults, marketing is
+				// disabled unless the user enabled it
+				require.Equal(t, time.UTC.String(), rsp.Preferences.TimeZone)
+				for _, preference := range rsp.Preferences.Preferences {
+					if preference.EventType == "marketing" {
+						require.Equal(t, preference.Channel == "push", *preference.Enabled)
+					}
+				}
+				require.Len(t, rsp.AuditEvents, 1)
+				require.Equal(t, data.AuditEvents[0].Action, rsp.AuditEvents[0].Action)
+			},
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ExportUserTx(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.ExportUserTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				// no authorization
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ExportUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "InternalServerError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.TokenMaker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ExportUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ExportUserTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/users/export", nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
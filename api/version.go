@@ -1,7 +1,6 @@
 package api
 
 import (
-	"database/sql"
 	"net/http"
 
 	db "github.com/asdsec/thenut/db/sqlc"
@@ -17,7 +16,7 @@ type createAppVersionRequest struct {
 func (server *Server) createAppVersion(ctx *gin.Context) {
 	var req createAppVersionRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		writeError(ctx, http.StatusBadRequest, err)
 		return
 	}
 
@@ -32,11 +31,7 @@ func (server *Server) createAppVersion(ctx *gin.Context) {
 		}
 		_, err = server.store.UpdateAppVersion(ctx, updateArg)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				ctx.JSON(http.StatusNotFound, errorResponse(err))
-				return
-			}
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			writeStoreError(ctx, err)
 			return
 		}
 	}
@@ -47,11 +42,7 @@ func (server *Server) createAppVersion(ctx *gin.Context) {
 	}
 	vrs, err := server.store.CreateAppVersion(ctx, arg)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 
@@ -61,11 +52,7 @@ func (server *Server) createAppVersion(ctx *gin.Context) {
 func (server *Server) getVersion(ctx *gin.Context) {
 	vrs, err := server.store.GetAppVersion(ctx, latest)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		writeStoreError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, vrs.Version)
@@ -0,0 +1,106 @@
+// Package audit records the security-sensitive actions as audit events
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// Actions of the audit events
+const (
+	ActionLogin          = "auth.login"
+	ActionLoginFailed    = "auth.login_failed"
+	ActionRenewSession   = "session.renew"
+	ActionChangePassword = "user.change_password"
+	ActionChangeEmail    = "user.change_email"
+	ActionDisableUser    = "user.disable"
+	ActionEnableUser     = "user.enable"
+	ActionRevokeSessions = "sessions.revoke"
+	ActionDeleteMerchant = "merchant.delete"
+	ActionAdjustBalance  = "merchant.adjust_balance"
+	ActionPublishVersion = "version.publish"
+	ActionRetryJob       = "job.retry"
+)
+
+// VersionTarget is the target of the app version events
+const VersionTarget = "app_versions"
+
+// UserTarget returns the target of the events changing the user
+func UserTarget(username string) string {
+	return "user:" + username
+}
+
+// MerchantTarget returns the target of the events changing the merchant
+func MerchantTarget(id int64) string {
+	return fmt.Sprintf("merchant:%d", id)
+}
+
+// JobTarget returns the target of the events changing the background job
+func JobTarget(id int64) string {
+	return fmt.Sprintf("job:%d", id)
+}
+
+// Change is the old and the new value of a field in the diffs
+type Change struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// Event is a security-sensitive action
+type Event struct {
+	Actor     string
+	Action    string
+	Target    string
+	IP        string
+	UserAgent string
+	RequestID string
+	// Diff is stored as JSON, secrets must never be put into it
+	Diff interface{}
+}
+
+// Auditor records the audit events
+type Auditor interface {
+	Record(ctx context.Context, event Event) error
+}
+
+// StoreAuditor is an Auditor writing the events to the database
+type StoreAuditor struct {
+	store db.Querier
+}
+
+// NewStoreAuditor creates a new StoreAuditor
+func NewStoreAuditor(store db.Querier) Auditor {
+	return &StoreAuditor{
+		store: store,
+	}
+}
+
+// Record writes the event to the audit_events table
+func (auditor *StoreAuditor) Record(ctx context.Context, event Event) error {
+	diff, err := MarshalDiff(event.Diff)
+	if err != nil {
+		return err
+	}
+
+	_, err = auditor.store.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		Actor:     event.Actor,
+		Action:    event.Action,
+		Target:    event.Target,
+		Ip:        event.IP,
+		UserAgent: event.UserAgent,
+		RequestID: event.RequestID,
+		Diff:      diff,
+	})
+	return err
+}
+
+// MarshalDiff returns the diff as JSON, an empty object if there is no diff
+func MarshalDiff(diff interface{}) (json.RawMessage, error) {
+	if diff == nil {
+		return json.RawMessage("{}"), nil
+	}
+	return json.Marshal(diff)
+}
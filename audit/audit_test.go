@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreAuditorRecord(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Eq(db.CreateAuditEventParams{
+			Actor:     "alice",
+			Action:    ActionChangeEmail,
+			Target:    UserTarget("alice"),
+			Ip:        "127.0.0.1",
+			UserAgent: "curl",
+			RequestID: "request-1",
+			Diff:      []byte(`{"email":{"old":"a@thenut.com","new":"b@thenut.com"}}`),
+		})).
+		Times(1)
+
+	err := NewStoreAuditor(store).Record(context.Background(), Event{
+		Actor:     "alice",
+		Action:    ActionChangeEmail,
+		Target:    UserTarget("alice"),
+		IP:        "127.0.0.1",
+		UserAgent: "curl",
+		RequestID: "request-1",
+		Diff: map[string]interface{}{
+			"email": Change{Old: "a@thenut.com", New: "b@thenut.com"},
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestStoreAuditorRecordError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAuditEvent(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.AuditEvent{}, sql.ErrConnDone)
+
+	err := NewStoreAuditor(store).Record(context.Background(), Event{Action: ActionLogin})
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestMarshalDiff(t *testing.T) {
+	diff, err := MarshalDiff(nil)
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(diff))
+
+	diff, err = MarshalDiff(map[string]interface{}{"balance": Change{Old: 1, New: 2}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"balance":{"old":1,"new":2}}`, string(diff))
+}
+
+func TestTargets(t *testing.T) {
+	require.Equal(t, "user:alice", UserTarget("alice"))
+	require.Equal(t, "merchant:42", MerchantTarget(42))
+}
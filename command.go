@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"os/user"
+	"strconv"
+
+	"github.com/asdsec/thenut/admin"
+	"github.com/asdsec/thenut/db/migration"
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+var errMigrateUsage = errors.New("usage: thenut migrate up [n] | down [n] | status | goto <version>")
+
+// runCommand runs the command given in the arguments instead of the server
+func runCommand(conn *sql.DB, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(conn, args[1:])
+	case "admin":
+		return runAdmin(db.NewStore(conn), args[1:])
+	}
+	return fmt.Errorf("unknown command %s", args[0])
+}
+
+func runMigrate(conn *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	migrator, err := migration.NewMigrator(context.Background(), conn)
+	if err != nil {
+		return fmt.Errorf("cannot create migrator: %w", err)
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		if len(args) == 1 {
+			err = migrator.Up()
+			break
+		}
+		var n int
+		n, err = parseSteps(args[1])
+		if err == nil {
+			err = migrator.Steps(n)
+		}
+	case "down":
+		// reverting the whole schema is never the default
+		n := 1
+		if len(args) > 1 {
+			n, err = parseSteps(args[1])
+		}
+		if err == nil {
+			err = migrator.Steps(-n)
+		}
+	case "goto":
+		if len(args) != 2 {
+			return errMigrateUsage
+		}
+		var version uint64
+		version, err = strconv.ParseUint(args[1], 10, 0)
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		err = migrator.Goto(uint(version))
+	case "status":
+	default:
+		return errMigrateUsage
+	}
+	if err != nil {
+		return err
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d, latest: %d, dirty: %t\n", status.Version, status.Latest, status.Dirty)
+	return nil
+}
+
+func parseSteps(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid number of migrations %s", arg)
+	}
+	return n, nil
+}
+
+var errAdminUsage = errors.New(`usage: thenut admin [-actor name] <command>
+  users disable <username>
+  users enable <username>
+  versions publish <version>
+  sessions revoke <username>
+  merchants adjust-balance <merchant-id> <amount>
+  jobs dead
+  jobs retry <job-id>`)
+
+// deadJobsLimit is the number of the dead jobs listed by the admin command
+const deadJobsLimit = 100
+
+func runAdmin(store db.Store, args []string) error {
+	flags := flag.NewFlagSet("admin", flag.ContinueOnError)
+	actor := flags.String("actor", currentUsername(), "name recorded as the actor of the audit events")
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	args = flags.Args()
+	if len(args) < 2 {
+		return errAdminUsage
+	}
+
+	ctx := context.Background()
+	a := admin.New(store, "cli:"+*actor)
+
+	switch args[0] + " " + args[1] {
+	case "jobs dead":
+		if len(args) != 2 {
+			return errAdminUsage
+		}
+		jobs, err := store.ListDeadJobs(ctx, db.ListDeadJobsParams{
+			Limit:  deadJobsLimit,
+			Offset: 0,
+		})
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			fmt.Printf("%d\t%s\t%d attempts\t%s\n", job.ID, job.Kind, job.Attempts, job.LastError.String)
+		}
+	case "jobs retry":
+		if len(args) != 3 {
+			return errAdminUsage
+		}
+		jobID, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid job id %s", args[2])
+		}
+		job, err := a.RetryJob(ctx, jobID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("job %d is queued again\n", job.ID)
+	case "users disable", "users enable":
+		if len(args) != 3 {
+			return errAdminUsage
+		}
+		user, err := a.SetUserDisabled(ctx, args[2], args[1] == "disable")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("user %s disabled: %t\n", user.Username, user.Disabled)
+	case "versions publish":
+		if len(args) != 3 {
+			return errAdminUsage
+		}
+		version, err := a.PublishVersion(ctx, args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version %s is published\n", version.Version)
+	case "sessions revoke":
+		if len(args) != 3 {
+			return errAdminUsage
+		}
+		blocked, err := a.RevokeSessions(ctx, args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d sessions of %s are revoked\n", blocked, args[2])
+	case "merchants adjust-balance":
+		if len(args) != 4 {
+			return errAdminUsage
+		}
+		merchantID, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid merchant id %s", args[2])
+		}
+		amount, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", args[3])
+		}
+		merchant, err := a.AdjustMerchantBalance(ctx, merchantID, amount)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("balance of merchant %d is %d\n", merchant.ID, merchant.Balance)
+	default:
+		return errAdminUsage
+	}
+	return nil
+}
+
+func currentUsername() string {
+	current, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+	return current.Username
+}
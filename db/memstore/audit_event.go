@@ -0,0 +1,76 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) (db.AuditEvent, error) {
+	defer q.lock()()
+
+	diff, err := jsonColumn("audit_events", "diff", arg.Diff)
+	if err != nil {
+		return db.AuditEvent{}, err
+	}
+
+	event := db.AuditEvent{
+		ID:        q.nextID("audit_events"),
+		Actor:     arg.Actor,
+		Action:    arg.Action,
+		Target:    arg.Target,
+		Diff:      diff,
+		CreatedAt: q.now(),
+		Ip:        arg.Ip,
+		UserAgent: arg.UserAgent,
+		RequestID: arg.RequestID,
+	}
+	q.store.tables.auditEvents[event.ID] = event
+	return event, nil
+}
+
+// auditEventMatch matches the events by the optional filters of the audit
+// event queries
+func auditEventMatch(actor, action, target sql.NullString, fromTime, toTime sql.NullTime) func(db.AuditEvent) bool {
+	return func(event db.AuditEvent) bool {
+		return (!actor.Valid || event.Actor == actor.String) &&
+			(!action.Valid || event.Action == action.String) &&
+			(!target.Valid || event.Target == target.String) &&
+			(!fromTime.Valid || !event.CreatedAt.Before(fromTime.Time)) &&
+			(!toTime.Valid || event.CreatedAt.Before(toTime.Time))
+	}
+}
+
+func newestEventFirst(a, b db.AuditEvent) bool {
+	return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+}
+
+func (q *queries) ListAuditEvents(ctx context.Context, arg db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	defer q.lock()()
+
+	match := auditEventMatch(arg.Actor, arg.Action, arg.Target, arg.FromTime, arg.ToTime)
+	events := rows(q.store.tables.auditEvents, match, newestEventFirst)
+	return page(events, arg.LimitCount, arg.OffsetCount)
+}
+
+func (q *queries) ListUserAuditEvents(ctx context.Context, username string) ([]db.AuditEvent, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.auditEvents, func(event db.AuditEvent) bool {
+		return event.Actor == username || event.Target == "user:"+username
+	}, func(a, b db.AuditEvent) bool {
+		return a.ID < b.ID
+	}), nil
+}
+
+func (q *queries) ListAuditEventsByCursor(ctx context.Context, arg db.ListAuditEventsByCursorParams) ([]db.AuditEvent, error) {
+	defer q.lock()()
+
+	match := auditEventMatch(arg.Actor, arg.Action, arg.Target, arg.FromTime, arg.ToTime)
+	events := rows(q.store.tables.auditEvents, func(event db.AuditEvent) bool {
+		return match(event) &&
+			afterCursor(event.CreatedAt, event.ID, arg.CursorCreatedAt, arg.CursorID, true)
+	}, newestEventFirst)
+	return page(events, arg.LimitCount, 0)
+}
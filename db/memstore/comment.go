@@ -0,0 +1,114 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreateComment(ctx context.Context, arg db.CreateCommentParams) (db.Comment, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	switch arg.CommentType {
+	case db.CommentTypePost, db.CommentTypeMerchant:
+	default:
+		return db.Comment{}, invalidEnumValue("comment_type", string(arg.CommentType))
+	}
+	if arg.PostID.Valid {
+		if _, ok := t.posts[arg.PostID.Int64]; !ok {
+			return db.Comment{}, foreignKeyViolation("comments", "post_id", arg.PostID.Int64, "posts")
+		}
+	}
+	if arg.MerchantID.Valid {
+		if _, ok := t.merchants[arg.MerchantID.Int64]; !ok {
+			return db.Comment{}, foreignKeyViolation("comments", "merchant_id", arg.MerchantID.Int64, "merchants")
+		}
+	}
+	if _, ok := t.users[arg.Owner]; !ok {
+		return db.Comment{}, foreignKeyViolation("comments", "owner", arg.Owner, "users")
+	}
+
+	comment := db.Comment{
+		ID:          q.nextID("comments"),
+		CommentType: arg.CommentType,
+		PostID:      arg.PostID,
+		MerchantID:  arg.MerchantID,
+		Owner:       arg.Owner,
+		Comment:     arg.Comment,
+		CreatedAt:   q.now(),
+	}
+	t.comments[comment.ID] = comment
+	return comment, nil
+}
+
+func (q *queries) GetComment(ctx context.Context, id int64) (db.Comment, error) {
+	defer q.lock()()
+
+	comment, ok := q.store.tables.comments[id]
+	if !ok {
+		return db.Comment{}, sql.ErrNoRows
+	}
+	return comment, nil
+}
+
+// postCommentMatch matches the comments of a visible post, like the join of
+// the post comment queries
+func (q *queries) postCommentMatch(postID sql.NullInt64) func(db.Comment) bool {
+	return func(comment db.Comment) bool {
+		if !postID.Valid || !comment.PostID.Valid || comment.PostID.Int64 != postID.Int64 {
+			return false
+		}
+		post, ok := q.store.tables.posts[comment.PostID.Int64]
+		return ok && !post.DeletedAt.Valid
+	}
+}
+
+func commentByID(a, b db.Comment) bool {
+	return a.ID < b.ID
+}
+
+func (q *queries) ListPostComments(ctx context.Context, arg db.ListPostCommentsParams) ([]db.Comment, error) {
+	defer q.lock()()
+
+	comments := rows(q.store.tables.comments, q.postCommentMatch(arg.PostID), commentByID)
+	return page(comments, arg.Limit, arg.Offset)
+}
+
+func (q *queries) ListPostCommentsByCursor(ctx context.Context, arg db.ListPostCommentsByCursorParams) ([]db.Comment, error) {
+	defer q.lock()()
+
+	match := q.postCommentMatch(arg.PostID)
+	comments := rows(q.store.tables.comments, func(comment db.Comment) bool {
+		return match(comment) &&
+			afterCursor(comment.CreatedAt, comment.ID, arg.CursorCreatedAt, arg.CursorID, false)
+	}, func(a, b db.Comment) bool {
+		return oldestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return page(comments, arg.LimitCount, 0)
+}
+
+func (q *queries) ListMerchantComments(ctx context.Context, arg db.ListMerchantCommentsParams) ([]db.Comment, error) {
+	defer q.lock()()
+
+	comments := rows(q.store.tables.comments, func(comment db.Comment) bool {
+		return arg.MerchantID.Valid && comment.MerchantID.Valid && comment.MerchantID.Int64 == arg.MerchantID.Int64
+	}, commentByID)
+	return page(comments, arg.Limit, arg.Offset)
+}
+
+func (q *queries) DeleteComment(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	delete(q.store.tables.comments, id)
+	return nil
+}
+
+func (q *queries) ListCommentsByOwner(ctx context.Context, owner string) ([]db.Comment, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.comments, func(comment db.Comment) bool {
+		return comment.Owner == owner
+	}, commentByID), nil
+}
@@ -0,0 +1,67 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreateConsultancy(ctx context.Context, arg db.CreateConsultancyParams) (db.Consultancy, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.merchants[arg.MerchantID]; !ok {
+		return db.Consultancy{}, foreignKeyViolation("consultancies", "merchant_id", arg.MerchantID, "merchants")
+	}
+	if _, ok := t.customers[arg.CustomerID]; !ok {
+		return db.Consultancy{}, foreignKeyViolation("consultancies", "customer_id", arg.CustomerID, "customers")
+	}
+
+	consultancy := db.Consultancy{
+		ID:         q.nextID("consultancies"),
+		MerchantID: arg.MerchantID,
+		CustomerID: arg.CustomerID,
+		Cost:       arg.Cost,
+		CreatedAt:  q.now(),
+	}
+	t.consultancies[consultancy.ID] = consultancy
+	return consultancy, nil
+}
+
+func (q *queries) GetConsultancy(ctx context.Context, id int64) (db.Consultancy, error) {
+	defer q.lock()()
+
+	consultancy, ok := q.store.tables.consultancies[id]
+	if !ok {
+		return db.Consultancy{}, sql.ErrNoRows
+	}
+	return consultancy, nil
+}
+
+func consultancyByID(a, b db.Consultancy) bool {
+	return a.ID < b.ID
+}
+
+func (q *queries) ListConsultancies(ctx context.Context, arg db.ListConsultanciesParams) ([]db.Consultancy, error) {
+	defer q.lock()()
+
+	consultancies := rows(q.store.tables.consultancies, func(consultancy db.Consultancy) bool {
+		return consultancy.MerchantID == arg.MerchantID || consultancy.CustomerID == arg.CustomerID
+	}, consultancyByID)
+	return page(consultancies, arg.Limit, arg.Offset)
+}
+
+func (q *queries) ListConsultanciesByOwner(ctx context.Context, owner string) ([]db.Consultancy, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	return rows(t.consultancies, func(consultancy db.Consultancy) bool {
+		merchant, ok := t.merchants[consultancy.MerchantID]
+		if ok && merchant.Owner == owner {
+			return true
+		}
+		customer, ok := t.customers[consultancy.CustomerID]
+		return ok && customer.Owner == owner
+	}, consultancyByID), nil
+}
@@ -0,0 +1,124 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreateCustomer(ctx context.Context, owner string) (db.Customer, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.users[owner]; !ok {
+		return db.Customer{}, foreignKeyViolation("customers", "owner", owner, "users")
+	}
+
+	customer := db.Customer{
+		ID:            q.nextID("customers"),
+		Owner:         owner,
+		ImageUrl:      defaultUserImage,
+		CreatedAt:     q.now(),
+		ImageVariants: emptyJSON(),
+		Version:       initialVersion,
+	}
+	t.customers[customer.ID] = customer
+	return customer, nil
+}
+
+func (q *queries) GetCustomer(ctx context.Context, id int64) (db.Customer, error) {
+	defer q.lock()()
+
+	customer, ok := q.store.tables.customers[id]
+	if !ok {
+		return db.Customer{}, sql.ErrNoRows
+	}
+	return customer, nil
+}
+
+func (q *queries) UpdateCustomer(ctx context.Context, arg db.UpdateCustomerParams) (db.Customer, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	customer, ok := t.customers[arg.ID]
+	if !ok || !versionMatches(customer.Version, arg.Version) {
+		return db.Customer{}, sql.ErrNoRows
+	}
+
+	variants, err := jsonColumn("customers", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return db.Customer{}, err
+	}
+	customer.ImageUrl = arg.ImageUrl
+	customer.ImageVariants = variants
+	customer.Version++
+
+	t.customers[customer.ID] = customer
+	return customer, nil
+}
+
+func (q *queries) SetCustomerImageVariants(ctx context.Context, arg db.SetCustomerImageVariantsParams) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	customer, ok := t.customers[arg.ID]
+	if !ok || customer.ImageUrl != arg.ImageUrl {
+		return 0, nil
+	}
+
+	variants, err := jsonColumn("customers", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return 0, err
+	}
+	customer.ImageVariants = variants
+	customer.Version++
+
+	t.customers[customer.ID] = customer
+	return 1, nil
+}
+
+func (q *queries) DeleteCustomer(ctx context.Context, id int64) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.customers[id]; !ok {
+		return nil
+	}
+	for _, consultancy := range t.consultancies {
+		if consultancy.CustomerID == id {
+			return referencedViolation("customers", "id", id, "consultancies", "customer_id")
+		}
+	}
+
+	delete(t.customers, id)
+	return nil
+}
+
+func (q *queries) ListCustomersByOwner(ctx context.Context, owner string) ([]db.Customer, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.customers, func(customer db.Customer) bool {
+		return customer.Owner == owner
+	}, func(a, b db.Customer) bool {
+		return a.ID < b.ID
+	}), nil
+}
+
+func (q *queries) AnonymizeUserCustomers(ctx context.Context, owner string) ([]int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	ids := []int64{}
+	for id, customer := range t.customers {
+		if customer.Owner == owner {
+			customer.Owner = deletedUser
+			customer.ImageUrl = defaultUserImage
+			customer.ImageVariants = emptyJSON()
+			customer.Version++
+			t.customers[id] = customer
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
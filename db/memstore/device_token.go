@@ -0,0 +1,93 @@
+package memstore
+
+import (
+	"context"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// devicePlatforms are the values the device_tokens_platform_check allows
+var devicePlatforms = map[string]bool{"fcm": true, "apns": true}
+
+func (q *queries) UpsertDeviceToken(ctx context.Context, arg db.UpsertDeviceTokenParams) (db.DeviceToken, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if !devicePlatforms[arg.Platform] {
+		return db.DeviceToken{}, checkViolation("device_tokens", "device_tokens_platform_check")
+	}
+	if _, ok := t.sessions[arg.SessionID]; !ok {
+		return db.DeviceToken{}, foreignKeyViolation("device_tokens", "session_id", arg.SessionID, "sessions")
+	}
+	if _, ok := t.users[arg.Username]; !ok {
+		return db.DeviceToken{}, foreignKeyViolation("device_tokens", "username", arg.Username, "users")
+	}
+
+	// the token moves from the session registering it before
+	for sessionID, token := range t.deviceTokens {
+		if token.Token == arg.Token && sessionID != arg.SessionID {
+			delete(t.deviceTokens, sessionID)
+		}
+	}
+
+	now := q.now()
+	token, ok := t.deviceTokens[arg.SessionID]
+	if !ok {
+		token = db.DeviceToken{
+			SessionID: arg.SessionID,
+			Username:  arg.Username,
+			CreatedAt: now,
+		}
+	}
+	token.Platform = arg.Platform
+	token.Token = arg.Token
+	token.Locale = arg.Locale
+	token.UpdatedAt = now
+
+	t.deviceTokens[arg.SessionID] = token
+	return token, nil
+}
+
+func (q *queries) ListUserDeviceTokens(ctx context.Context, username string) ([]db.DeviceToken, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	now := q.now()
+	return rows(t.deviceTokens, func(token db.DeviceToken) bool {
+		session, ok := t.sessions[token.SessionID]
+		return ok && token.Username == username &&
+			!session.IsBlocked && session.ExpiresAt.After(now)
+	}, func(a, b db.DeviceToken) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+func (q *queries) ListAllUserDeviceTokens(ctx context.Context, username string) ([]db.DeviceToken, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.deviceTokens, func(token db.DeviceToken) bool {
+		return token.Username == username
+	}, func(a, b db.DeviceToken) bool {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}), nil
+}
+
+func (q *queries) DeleteDeviceToken(ctx context.Context, sessionID uuid.UUID) error {
+	defer q.lock()()
+
+	delete(q.store.tables.deviceTokens, sessionID)
+	return nil
+}
+
+func (q *queries) DeleteUserDeviceTokens(ctx context.Context, username string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for sessionID, token := range t.deviceTokens {
+		if token.Username == username {
+			delete(t.deviceTokens, sessionID)
+		}
+	}
+	return nil
+}
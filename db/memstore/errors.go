@@ -0,0 +1,125 @@
+package memstore
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// The errors are the ones lib/pq returns for the same violations, so that the
+// callers can map them by their codes
+
+func uniqueViolation(table, constraint, column, value string) error {
+	return &pq.Error{
+		Severity:   "ERROR",
+		Code:       "23505",
+		Message:    fmt.Sprintf("duplicate key value violates unique constraint %q", constraint),
+		Detail:     fmt.Sprintf("Key (%s)=(%s) already exists.", column, value),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+// foreignKeyViolation is returned when a row references a missing row
+func foreignKeyViolation(table, column string, value interface{}, referenced string) error {
+	constraint := fmt.Sprintf("%s_%s_fkey", table, column)
+	return &pq.Error{
+		Severity:   "ERROR",
+		Code:       "23503",
+		Message:    fmt.Sprintf("insert or update on table %q violates foreign key constraint %q", table, constraint),
+		Detail:     fmt.Sprintf("Key (%s)=(%v) is not present in table %q.", column, value, referenced),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+// referencedViolation is returned when a deleted row is still referenced
+func referencedViolation(table, key string, value interface{}, referencing, column string) error {
+	constraint := fmt.Sprintf("%s_%s_fkey", referencing, column)
+	return &pq.Error{
+		Severity:   "ERROR",
+		Code:       "23503",
+		Message:    fmt.Sprintf("update or delete on table %q violates foreign key constraint %q on table %q", table, constraint, referencing),
+		Detail:     fmt.Sprintf("Key (%s)=(%v) is still referenced from table %q.", key, value, referencing),
+		Table:      referencing,
+		Constraint: constraint,
+	}
+}
+
+func checkViolation(table, constraint string) error {
+	return &pq.Error{
+		Severity:   "ERROR",
+		Code:       "23514",
+		Message:    fmt.Sprintf("new row for relation %q violates check constraint %q", table, constraint),
+		Table:      table,
+		Constraint: constraint,
+	}
+}
+
+func notNullViolation(table, column string) error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     "23502",
+		Message:  fmt.Sprintf("null value in column %q of relation %q violates not-null constraint", column, table),
+		Table:    table,
+		Column:   column,
+	}
+}
+
+func stringTooLong(length int) error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     "22001",
+		Message:  fmt.Sprintf("value too long for type character varying(%d)", length),
+	}
+}
+
+func invalidEnumValue(enum, value string) error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     "22P02",
+		Message:  fmt.Sprintf("invalid input value for enum %s: %q", enum, value),
+	}
+}
+
+func invalidJSON() error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     "22P02",
+		Message:  "invalid input syntax for type json",
+	}
+}
+
+func negativeLimit() error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     "2201W",
+		Message:  "LIMIT must not be negative",
+	}
+}
+
+func negativeOffset() error {
+	return &pq.Error{
+		Severity: "ERROR",
+		Code:     "2201X",
+		Message:  "OFFSET must not be negative",
+	}
+}
+
+// jsonColumn validates the value of a jsonb column and copies it, so that the
+// callers cannot change the stored value
+func jsonColumn(table, column string, value json.RawMessage) (json.RawMessage, error) {
+	if value == nil {
+		return nil, notNullViolation(table, column)
+	}
+	if !json.Valid(value) {
+		return nil, invalidJSON()
+	}
+	return append(json.RawMessage(nil), value...), nil
+}
+
+// emptyJSON is the default of the jsonb columns
+func emptyJSON() json.RawMessage {
+	return json.RawMessage("{}")
+}
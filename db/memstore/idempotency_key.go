@@ -0,0 +1,103 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// idempotencyKeyID is the primary key of the idempotency keys
+type idempotencyKeyID struct {
+	username string
+	key      string
+}
+
+func (q *queries) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.users[arg.Username]; !ok {
+		return db.IdempotencyKey{}, foreignKeyViolation("idempotency_keys", "username", arg.Username, "users")
+	}
+
+	// an expired key is taken over, a live one is left as it is
+	id := idempotencyKeyID{username: arg.Username, key: arg.Key}
+	now := q.now()
+	if existing, ok := t.idempotencyKeys[id]; ok && existing.ExpiresAt.After(now) {
+		return db.IdempotencyKey{}, sql.ErrNoRows
+	}
+
+	key := db.IdempotencyKey{
+		Username:      arg.Username,
+		Key:           arg.Key,
+		RequestMethod: arg.RequestMethod,
+		RequestPath:   arg.RequestPath,
+		RequestHash:   arg.RequestHash,
+		CreatedAt:     now,
+		ExpiresAt:     timestamp(arg.ExpiresAt),
+	}
+	t.idempotencyKeys[id] = key
+	return key, nil
+}
+
+func (q *queries) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	defer q.lock()()
+
+	key, ok := q.store.tables.idempotencyKeys[idempotencyKeyID{username: arg.Username, key: arg.Key}]
+	if !ok || !key.ExpiresAt.After(q.now()) {
+		return db.IdempotencyKey{}, sql.ErrNoRows
+	}
+	return key, nil
+}
+
+func (q *queries) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	id := idempotencyKeyID{username: arg.Username, key: arg.Key}
+	key, ok := t.idempotencyKeys[id]
+	if !ok {
+		return db.IdempotencyKey{}, sql.ErrNoRows
+	}
+
+	key.ResponseStatus = arg.ResponseStatus
+	key.ResponseBody = bytes.Clone(arg.ResponseBody)
+	t.idempotencyKeys[id] = key
+	return key, nil
+}
+
+func (q *queries) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) error {
+	defer q.lock()()
+
+	delete(q.store.tables.idempotencyKeys, idempotencyKeyID{username: arg.Username, key: arg.Key})
+	return nil
+}
+
+func (q *queries) DeleteUserIdempotencyKeys(ctx context.Context, username string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id := range t.idempotencyKeys {
+		if id.username == username {
+			delete(t.idempotencyKeys, id)
+		}
+	}
+	return nil
+}
+
+func (q *queries) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	var deleted int64
+	for id, key := range t.idempotencyKeys {
+		if !key.ExpiresAt.After(now) {
+			delete(t.idempotencyKeys, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
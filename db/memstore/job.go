@@ -0,0 +1,163 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) EnqueueJob(ctx context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+	defer q.lock()()
+
+	if arg.MaxAttempts <= 0 {
+		return db.Job{}, checkViolation("jobs", "jobs_max_attempts_check")
+	}
+	payload, err := jsonColumn("jobs", "payload", arg.Payload)
+	if err != nil {
+		return db.Job{}, err
+	}
+
+	job := db.Job{
+		ID:          q.nextID("jobs"),
+		Kind:        arg.Kind,
+		Payload:     payload,
+		Status:      "pending",
+		MaxAttempts: arg.MaxAttempts,
+		RunAt:       timestamp(arg.RunAt),
+		CreatedAt:   q.now(),
+	}
+	q.store.tables.jobs[job.ID] = job
+	return job, nil
+}
+
+// ClaimJobs claims the due jobs and the ones of the gone workers. The store is
+// locked, so the concurrent claims never get the same job, like with SKIP
+// LOCKED.
+func (q *queries) ClaimJobs(ctx context.Context, arg db.ClaimJobsParams) ([]db.Job, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	now := timestamp(arg.Now)
+	staleBefore := timestamp(arg.StaleBefore)
+	due := rows(t.jobs, func(job db.Job) bool {
+		return (job.Status == "pending" && !job.RunAt.After(now)) ||
+			(job.Status == "running" && !job.LockedAt.Time.After(staleBefore) && job.Attempts < job.MaxAttempts)
+	}, func(a, b db.Job) bool {
+		if !a.RunAt.Equal(b.RunAt) {
+			return a.RunAt.Before(b.RunAt)
+		}
+		return a.ID < b.ID
+	})
+	due, err := page(due, arg.LimitCount, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range due {
+		due[i].Status = "running"
+		due[i].Attempts++
+		due[i].LockedAt = sql.NullTime{Time: now, Valid: true}
+		t.jobs[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (q *queries) KillStaleJobs(ctx context.Context, arg db.KillStaleJobsParams) ([]db.Job, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	staleBefore := timestamp(arg.StaleBefore)
+	stale := rows(t.jobs, func(job db.Job) bool {
+		return job.Status == "running" && !job.LockedAt.Time.After(staleBefore) && job.Attempts >= job.MaxAttempts
+	}, func(a, b db.Job) bool {
+		return a.ID < b.ID
+	})
+
+	for i := range stale {
+		stale[i].Status = "dead"
+		stale[i].LockedAt = sql.NullTime{}
+		stale[i].LastError = sql.NullString{String: arg.LastError, Valid: true}
+		t.jobs[stale[i].ID] = stale[i]
+	}
+	return stale, nil
+}
+
+// claimedBy reports whether the job is running with the lock of the claim,
+// the workers whose claims are taken over cannot change the job
+func claimedBy(job db.Job, lockedAt time.Time) bool {
+	return job.Status == "running" && job.LockedAt.Time.Equal(timestamp(lockedAt))
+}
+
+func (q *queries) CompleteJob(ctx context.Context, arg db.CompleteJobParams) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	job, ok := t.jobs[arg.ID]
+	if !ok || !claimedBy(job, arg.LockedAt) {
+		return 0, nil
+	}
+	delete(t.jobs, arg.ID)
+	return 1, nil
+}
+
+func (q *queries) RetryJob(ctx context.Context, arg db.RetryJobParams) (db.Job, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	job, ok := t.jobs[arg.ID]
+	if !ok || !claimedBy(job, arg.LockedAt) {
+		return db.Job{}, sql.ErrNoRows
+	}
+	job.Status = "pending"
+	job.RunAt = timestamp(arg.RunAt)
+	job.LockedAt = sql.NullTime{}
+	job.LastError = arg.LastError
+
+	t.jobs[job.ID] = job
+	return job, nil
+}
+
+func (q *queries) KillJob(ctx context.Context, arg db.KillJobParams) (db.Job, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	job, ok := t.jobs[arg.ID]
+	if !ok || !claimedBy(job, arg.LockedAt) {
+		return db.Job{}, sql.ErrNoRows
+	}
+	job.Status = "dead"
+	job.LockedAt = sql.NullTime{}
+	job.LastError = arg.LastError
+
+	t.jobs[job.ID] = job
+	return job, nil
+}
+
+func (q *queries) ListDeadJobs(ctx context.Context, arg db.ListDeadJobsParams) ([]db.Job, error) {
+	defer q.lock()()
+
+	jobs := rows(q.store.tables.jobs, func(job db.Job) bool {
+		return job.Status == "dead"
+	}, func(a, b db.Job) bool {
+		return a.ID < b.ID
+	})
+	return page(jobs, arg.Limit, arg.Offset)
+}
+
+func (q *queries) RequeueDeadJob(ctx context.Context, id int64) (db.Job, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	job, ok := t.jobs[id]
+	if !ok || job.Status != "dead" {
+		return db.Job{}, sql.ErrNoRows
+	}
+	job.Status = "pending"
+	job.Attempts = 0
+	job.RunAt = q.now()
+
+	t.jobs[job.ID] = job
+	return job, nil
+}
@@ -0,0 +1,210 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+const defaultMerchantImage = "/default/merchant/avatar.jpg"
+
+func (q *queries) CreateMerchant(ctx context.Context, arg db.CreateMerchantParams) (db.Merchant, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.users[arg.Owner]; !ok {
+		return db.Merchant{}, foreignKeyViolation("merchants", "owner", arg.Owner, "users")
+	}
+
+	merchant := db.Merchant{
+		ID:            q.nextID("merchants"),
+		Owner:         arg.Owner,
+		Profession:    arg.Profession,
+		Title:         arg.Title,
+		About:         arg.About,
+		ImageUrl:      defaultMerchantImage,
+		CreatedAt:     q.now(),
+		ImageVariants: emptyJSON(),
+		Version:       initialVersion,
+	}
+	t.merchants[merchant.ID] = merchant
+	return merchant, nil
+}
+
+func (q *queries) GetMerchant(ctx context.Context, id int64) (db.Merchant, error) {
+	defer q.lock()()
+
+	merchant, ok := q.store.tables.merchants[id]
+	if !ok {
+		return db.Merchant{}, sql.ErrNoRows
+	}
+	return merchant, nil
+}
+
+func (q *queries) ListMerchants(ctx context.Context, arg db.ListMerchantsParams) ([]db.Merchant, error) {
+	defer q.lock()()
+
+	merchants := rows(q.store.tables.merchants, func(merchant db.Merchant) bool {
+		return merchant.Owner == arg.Owner
+	}, func(a, b db.Merchant) bool {
+		return a.ID < b.ID
+	})
+	return page(merchants, arg.Limit, arg.Offset)
+}
+
+func (q *queries) ListMerchantsByCursor(ctx context.Context, arg db.ListMerchantsByCursorParams) ([]db.Merchant, error) {
+	defer q.lock()()
+
+	merchants := rows(q.store.tables.merchants, func(merchant db.Merchant) bool {
+		return merchant.Owner == arg.Owner &&
+			afterCursor(merchant.CreatedAt, merchant.ID, arg.CursorCreatedAt, arg.CursorID, false)
+	}, func(a, b db.Merchant) bool {
+		return oldestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return page(merchants, arg.LimitCount, 0)
+}
+
+func (q *queries) ListMerchantsByOwner(ctx context.Context, owner string) ([]db.Merchant, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.merchants, func(merchant db.Merchant) bool {
+		return merchant.Owner == owner
+	}, func(a, b db.Merchant) bool {
+		return a.ID < b.ID
+	}), nil
+}
+
+// updateMerchant applies the update to the merchant and stores it. A merchant
+// whose version does not match is not found, as in postgres.
+func (q *queries) updateMerchant(id int64, version sql.NullInt64, update func(merchant *db.Merchant) error) (db.Merchant, error) {
+	t := q.store.tables
+
+	merchant, ok := t.merchants[id]
+	if !ok || !versionMatches(merchant.Version, version) {
+		return db.Merchant{}, sql.ErrNoRows
+	}
+	if err := update(&merchant); err != nil {
+		return db.Merchant{}, err
+	}
+	merchant.Version++
+
+	t.merchants[id] = merchant
+	return merchant, nil
+}
+
+func (q *queries) UpdateMerchant(ctx context.Context, arg db.UpdateMerchantParams) (db.Merchant, error) {
+	defer q.lock()()
+
+	return q.updateMerchant(arg.ID, arg.Version, func(merchant *db.Merchant) error {
+		if arg.Balance.Valid {
+			merchant.Balance = arg.Balance.Int64
+		}
+		if arg.Profession.Valid {
+			merchant.Profession = arg.Profession.String
+		}
+		if arg.Title.Valid {
+			merchant.Title = arg.Title.String
+		}
+		if arg.About.Valid {
+			merchant.About = arg.About.String
+		}
+		if arg.ImageUrl.Valid {
+			merchant.ImageUrl = arg.ImageUrl.String
+		}
+		if arg.Rating.Valid {
+			merchant.Rating = arg.Rating.Float64
+		}
+		return nil
+	})
+}
+
+func (q *queries) UpdateMerchantImage(ctx context.Context, arg db.UpdateMerchantImageParams) (db.Merchant, error) {
+	defer q.lock()()
+
+	return q.updateMerchant(arg.ID, arg.Version, func(merchant *db.Merchant) error {
+		variants, err := jsonColumn("merchants", "image_variants", arg.ImageVariants)
+		if err != nil {
+			return err
+		}
+		merchant.ImageUrl = arg.ImageUrl
+		merchant.ImageVariants = variants
+		return nil
+	})
+}
+
+func (q *queries) SetMerchantImageVariants(ctx context.Context, arg db.SetMerchantImageVariantsParams) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	merchant, ok := t.merchants[arg.ID]
+	if !ok || merchant.ImageUrl != arg.ImageUrl {
+		return 0, nil
+	}
+
+	variants, err := jsonColumn("merchants", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return 0, err
+	}
+	merchant.ImageVariants = variants
+	merchant.Version++
+
+	t.merchants[merchant.ID] = merchant
+	return 1, nil
+}
+
+func (q *queries) AddMerchantBalance(ctx context.Context, arg db.AddMerchantBalanceParams) (db.Merchant, error) {
+	defer q.lock()()
+
+	return q.updateMerchant(arg.ID, sql.NullInt64{}, func(merchant *db.Merchant) error {
+		merchant.Balance += arg.Amount
+		return nil
+	})
+}
+
+func (q *queries) DeleteMerchant(ctx context.Context, id int64) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.merchants[id]; !ok {
+		return nil
+	}
+	for _, post := range t.posts {
+		if post.MerchantID == id {
+			return referencedViolation("merchants", "id", id, "posts", "merchant_id")
+		}
+	}
+	for _, comment := range t.comments {
+		if comment.MerchantID.Valid && comment.MerchantID.Int64 == id {
+			return referencedViolation("merchants", "id", id, "comments", "merchant_id")
+		}
+	}
+	for _, consultancy := range t.consultancies {
+		if consultancy.MerchantID == id {
+			return referencedViolation("merchants", "id", id, "consultancies", "merchant_id")
+		}
+	}
+
+	delete(t.merchants, id)
+	return nil
+}
+
+func (q *queries) AnonymizeUserMerchants(ctx context.Context, owner string) ([]int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	ids := []int64{}
+	for id, merchant := range t.merchants {
+		if merchant.Owner == owner {
+			merchant.Owner = deletedUser
+			merchant.Title = "Deleted merchant"
+			merchant.About = ""
+			merchant.ImageUrl = defaultMerchantImage
+			merchant.ImageVariants = emptyJSON()
+			merchant.Version++
+			t.merchants[id] = merchant
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,137 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (db.Notification, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.users[arg.Recipient]; !ok {
+		return db.Notification{}, foreignKeyViolation("notifications", "recipient", arg.Recipient, "users")
+	}
+	if _, ok := t.users[arg.Actor]; !ok {
+		return db.Notification{}, foreignKeyViolation("notifications", "actor", arg.Actor, "users")
+	}
+	data, err := jsonColumn("notifications", "data", arg.Data)
+	if err != nil {
+		return db.Notification{}, err
+	}
+
+	notification := db.Notification{
+		ID:        q.nextID("notifications"),
+		Recipient: arg.Recipient,
+		EventType: arg.EventType,
+		Actor:     arg.Actor,
+		Target:    arg.Target,
+		Data:      data,
+		CreatedAt: q.now(),
+	}
+	t.notifications[notification.ID] = notification
+	return notification, nil
+}
+
+// notificationMatch matches the notifications of the inbox queries
+func notificationMatch(recipient string, unreadOnly bool) func(db.Notification) bool {
+	return func(notification db.Notification) bool {
+		return notification.Recipient == recipient &&
+			(!unreadOnly || !notification.ReadAt.Valid)
+	}
+}
+
+func newestNotificationFirst(a, b db.Notification) bool {
+	return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+}
+
+func (q *queries) ListNotifications(ctx context.Context, arg db.ListNotificationsParams) ([]db.Notification, error) {
+	defer q.lock()()
+
+	match := notificationMatch(arg.Recipient, arg.UnreadOnly)
+	notifications := rows(q.store.tables.notifications, match, newestNotificationFirst)
+	return page(notifications, arg.LimitCount, arg.OffsetCount)
+}
+
+func (q *queries) ListUserNotifications(ctx context.Context, recipient string) ([]db.Notification, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.notifications, notificationMatch(recipient, false), func(a, b db.Notification) bool {
+		return a.ID < b.ID
+	}), nil
+}
+
+func (q *queries) ListNotificationsByCursor(ctx context.Context, arg db.ListNotificationsByCursorParams) ([]db.Notification, error) {
+	defer q.lock()()
+
+	match := notificationMatch(arg.Recipient, arg.UnreadOnly)
+	notifications := rows(q.store.tables.notifications, func(notification db.Notification) bool {
+		return match(notification) &&
+			afterCursor(notification.CreatedAt, notification.ID, arg.CursorCreatedAt, arg.CursorID, true)
+	}, newestNotificationFirst)
+	return page(notifications, arg.LimitCount, 0)
+}
+
+func (q *queries) CountUnreadNotifications(ctx context.Context, recipient string) (int64, error) {
+	defer q.lock()()
+
+	unread := rows(q.store.tables.notifications, notificationMatch(recipient, true), newestNotificationFirst)
+	return int64(len(unread)), nil
+}
+
+func (q *queries) MarkNotificationRead(ctx context.Context, arg db.MarkNotificationReadParams) (db.Notification, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	notification, ok := t.notifications[arg.ID]
+	if !ok || notification.Recipient != arg.Recipient {
+		return db.Notification{}, sql.ErrNoRows
+	}
+	if !notification.ReadAt.Valid {
+		notification.ReadAt = sql.NullTime{Time: q.now(), Valid: true}
+		t.notifications[notification.ID] = notification
+	}
+	return notification, nil
+}
+
+func (q *queries) MarkAllNotificationsRead(ctx context.Context, recipient string) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	var marked int64
+	for id, notification := range t.notifications {
+		if notification.Recipient == recipient && !notification.ReadAt.Valid {
+			notification.ReadAt = sql.NullTime{Time: q.now(), Valid: true}
+			t.notifications[id] = notification
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (q *queries) DeleteUserNotifications(ctx context.Context, recipient string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id, notification := range t.notifications {
+		if notification.Recipient == recipient {
+			delete(t.notifications, id)
+		}
+	}
+	return nil
+}
+
+func (q *queries) AnonymizeUserNotifications(ctx context.Context, actor string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id, notification := range t.notifications {
+		if notification.Actor == actor {
+			notification.Actor = deletedUser
+			t.notifications[id] = notification
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// notificationPreferenceID is the primary key of the notification preferences
+type notificationPreferenceID struct {
+	username  string
+	eventType string
+	channel   string
+}
+
+// notificationChannels are the values the
+// notification_preferences_channel_check allows
+var notificationChannels = map[string]bool{"in_app": true, "push": true, "email": true}
+
+// validQuietHours is the notification_settings_quiet_hours_check
+func validQuietHours(start, end sql.NullInt32) bool {
+	if !start.Valid && !end.Valid {
+		return true
+	}
+	return start.Valid && end.Valid &&
+		start.Int32 >= 0 && start.Int32 < 1440 &&
+		end.Int32 >= 0 && end.Int32 < 1440 &&
+		start.Int32 != end.Int32
+}
+
+func (q *queries) GetNotificationSettings(ctx context.Context, username string) (db.NotificationSetting, error) {
+	defer q.lock()()
+
+	settings, ok := q.store.tables.notificationSettings[username]
+	if !ok {
+		return db.NotificationSetting{}, sql.ErrNoRows
+	}
+	return settings, nil
+}
+
+func (q *queries) UpsertNotificationSettings(ctx context.Context, arg db.UpsertNotificationSettingsParams) (db.NotificationSetting, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if !validQuietHours(arg.QuietHoursStart, arg.QuietHoursEnd) {
+		return db.NotificationSetting{}, checkViolation("notification_settings", "notification_settings_quiet_hours_check")
+	}
+	if _, ok := t.users[arg.Username]; !ok {
+		return db.NotificationSetting{}, foreignKeyViolation("notification_settings", "username", arg.Username, "users")
+	}
+
+	settings := db.NotificationSetting{
+		Username:        arg.Username,
+		TimeZone:        arg.TimeZone,
+		QuietHoursStart: arg.QuietHoursStart,
+		QuietHoursEnd:   arg.QuietHoursEnd,
+		UpdatedAt:       q.now(),
+	}
+	t.notificationSettings[arg.Username] = settings
+	return settings, nil
+}
+
+func (q *queries) ListNotificationPreferences(ctx context.Context, username string) ([]db.NotificationPreference, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.notificationPreferences, func(preference db.NotificationPreference) bool {
+		return preference.Username == username
+	}, func(a, b db.NotificationPreference) bool {
+		if a.EventType != b.EventType {
+			return a.EventType < b.EventType
+		}
+		return a.Channel < b.Channel
+	}), nil
+}
+
+func (q *queries) UpsertNotificationPreference(ctx context.Context, arg db.UpsertNotificationPreferenceParams) (db.NotificationPreference, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	if !notificationChannels[arg.Channel] {
+		return db.NotificationPreference{}, checkViolation("notification_preferences", "notification_preferences_channel_check")
+	}
+	if _, ok := t.users[arg.Username]; !ok {
+		return db.NotificationPreference{}, foreignKeyViolation("notification_preferences", "username", arg.Username, "users")
+	}
+
+	preference := db.NotificationPreference{
+		Username:  arg.Username,
+		EventType: arg.EventType,
+		Channel:   arg.Channel,
+		Enabled:   arg.Enabled,
+		UpdatedAt: q.now(),
+	}
+	id := notificationPreferenceID{username: arg.Username, eventType: arg.EventType, channel: arg.Channel}
+	t.notificationPreferences[id] = preference
+	return preference, nil
+}
+
+func (q *queries) DeleteUserNotificationSettings(ctx context.Context, username string) error {
+	defer q.lock()()
+
+	delete(q.store.tables.notificationSettings, username)
+	return nil
+}
+
+func (q *queries) DeleteUserNotificationPreferences(ctx context.Context, username string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id := range t.notificationPreferences {
+		if id.username == username {
+			delete(t.notificationPreferences, id)
+		}
+	}
+	return nil
+}
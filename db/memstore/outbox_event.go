@@ -0,0 +1,47 @@
+package memstore
+
+import (
+	"context"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) AppendOutboxEvent(ctx context.Context, arg db.AppendOutboxEventParams) (db.OutboxEvent, error) {
+	defer q.lock()()
+
+	payload, err := jsonColumn("outbox_events", "payload", arg.Payload)
+	if err != nil {
+		return db.OutboxEvent{}, err
+	}
+
+	event := db.OutboxEvent{
+		ID:        q.nextID("outbox_events"),
+		EventType: arg.EventType,
+		Aggregate: arg.Aggregate,
+		Payload:   payload,
+		CreatedAt: q.now(),
+		XactID:    q.xactID(),
+	}
+	q.store.tables.outboxEvents[event.ID] = event
+	return event, nil
+}
+
+func (q *queries) ListOutboxEventsForUpdate(ctx context.Context, limit int32) ([]db.OutboxEvent, error) {
+	defer q.lock()()
+
+	// the transactions run one at a time, so every appended event is finished
+	events := rows(q.store.tables.outboxEvents, nil, func(a, b db.OutboxEvent) bool {
+		if a.XactID != b.XactID {
+			return a.XactID < b.XactID
+		}
+		return a.ID < b.ID
+	})
+	return page(events, limit, 0)
+}
+
+func (q *queries) DeleteOutboxEvent(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	delete(q.store.tables.outboxEvents, id)
+	return nil
+}
@@ -0,0 +1,194 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// checkPost checks the posts_title_or_image_check constraint
+func checkPost(post db.Post) error {
+	if !post.Title.Valid && !post.ImageUrl.Valid {
+		return checkViolation("posts", "posts_title_or_image_check")
+	}
+	return nil
+}
+
+func (q *queries) CreatePost(ctx context.Context, arg db.CreatePostParams) (db.Post, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	variants, err := jsonColumn("posts", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return db.Post{}, err
+	}
+
+	createdAt := q.now()
+	post := db.Post{
+		MerchantID:    arg.MerchantID,
+		Title:         arg.Title,
+		ImageUrl:      arg.ImageUrl,
+		CreatedAt:     createdAt,
+		ImageVariants: variants,
+		UpdatedAt:     createdAt,
+	}
+	if err := checkPost(post); err != nil {
+		return db.Post{}, err
+	}
+	if _, ok := t.merchants[arg.MerchantID]; !ok {
+		return db.Post{}, foreignKeyViolation("posts", "merchant_id", arg.MerchantID, "merchants")
+	}
+
+	post.ID = q.nextID("posts")
+	t.posts[post.ID] = post
+	return post, nil
+}
+
+// visiblePost returns the post unless it is missing or soft deleted
+func (q *queries) visiblePost(id int64) (db.Post, error) {
+	post, ok := q.store.tables.posts[id]
+	if !ok || post.DeletedAt.Valid {
+		return db.Post{}, sql.ErrNoRows
+	}
+	return post, nil
+}
+
+func (q *queries) GetPost(ctx context.Context, id int64) (db.Post, error) {
+	defer q.lock()()
+
+	return q.visiblePost(id)
+}
+
+// GetPostForUpdate is the same as GetPost, the transactions hold the whole
+// store already
+func (q *queries) GetPostForUpdate(ctx context.Context, id int64) (db.Post, error) {
+	defer q.lock()()
+
+	return q.visiblePost(id)
+}
+
+// newestPostFirst orders the posts by created_at descending
+func newestPostFirst(a, b db.Post) bool {
+	return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+}
+
+func (q *queries) ListMerchantPosts(ctx context.Context, arg db.ListMerchantPostsParams) ([]db.Post, error) {
+	defer q.lock()()
+
+	posts := rows(q.store.tables.posts, func(post db.Post) bool {
+		return post.MerchantID == arg.MerchantID && !post.DeletedAt.Valid
+	}, newestPostFirst)
+	return page(posts, arg.Limit, arg.Offset)
+}
+
+func (q *queries) ListPosts(ctx context.Context, arg db.ListPostsParams) ([]db.Post, error) {
+	defer q.lock()()
+
+	posts := rows(q.store.tables.posts, func(post db.Post) bool {
+		return !post.DeletedAt.Valid
+	}, newestPostFirst)
+	return page(posts, arg.Limit, arg.Offset)
+}
+
+func (q *queries) ListPostsByCursor(ctx context.Context, arg db.ListPostsByCursorParams) ([]db.Post, error) {
+	defer q.lock()()
+
+	posts := rows(q.store.tables.posts, func(post db.Post) bool {
+		return !post.DeletedAt.Valid &&
+			afterCursor(post.CreatedAt, post.ID, arg.CursorCreatedAt, arg.CursorID, true)
+	}, newestPostFirst)
+	return page(posts, arg.LimitCount, 0)
+}
+
+func (q *queries) ListMerchantPostsByCursor(ctx context.Context, arg db.ListMerchantPostsByCursorParams) ([]db.Post, error) {
+	defer q.lock()()
+
+	posts := rows(q.store.tables.posts, func(post db.Post) bool {
+		return post.MerchantID == arg.MerchantID && !post.DeletedAt.Valid &&
+			afterCursor(post.CreatedAt, post.ID, arg.CursorCreatedAt, arg.CursorID, true)
+	}, newestPostFirst)
+	return page(posts, arg.LimitCount, 0)
+}
+
+func (q *queries) ListPostsByOwner(ctx context.Context, owner string) ([]db.Post, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	return rows(t.posts, func(post db.Post) bool {
+		merchant, ok := t.merchants[post.MerchantID]
+		return ok && merchant.Owner == owner
+	}, func(a, b db.Post) bool {
+		return a.ID < b.ID
+	}), nil
+}
+
+func (q *queries) UpdatePost(ctx context.Context, arg db.UpdatePostParams) (db.Post, error) {
+	defer q.lock()()
+
+	post, err := q.visiblePost(arg.ID)
+	if err != nil {
+		return db.Post{}, err
+	}
+
+	variants, err := jsonColumn("posts", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return db.Post{}, err
+	}
+	post.Title = arg.Title
+	post.ImageUrl = arg.ImageUrl
+	post.ImageVariants = variants
+	post.UpdatedAt = q.now()
+	if err := checkPost(post); err != nil {
+		return db.Post{}, err
+	}
+
+	q.store.tables.posts[post.ID] = post
+	return post, nil
+}
+
+func (q *queries) SetPostImageVariants(ctx context.Context, arg db.SetPostImageVariantsParams) (int64, error) {
+	defer q.lock()()
+
+	post, err := q.visiblePost(arg.ID)
+	if err != nil || !post.ImageUrl.Valid || post.ImageUrl.String != arg.ImageUrl {
+		return 0, nil
+	}
+
+	variants, err := jsonColumn("posts", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return 0, err
+	}
+	post.ImageVariants = variants
+
+	q.store.tables.posts[post.ID] = post
+	return 1, nil
+}
+
+func (q *queries) DeletePost(ctx context.Context, id int64) error {
+	defer q.lock()()
+
+	post, err := q.visiblePost(id)
+	if err != nil {
+		return nil
+	}
+
+	post.DeletedAt = sql.NullTime{Time: q.now(), Valid: true}
+	q.store.tables.posts[id] = post
+	return nil
+}
+
+func (q *queries) DeleteOwnerPosts(ctx context.Context, owner string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id, post := range t.posts {
+		merchant, ok := t.merchants[post.MerchantID]
+		if post.DeletedAt.Valid || !ok || merchant.Owner != owner {
+			continue
+		}
+		post.DeletedAt = sql.NullTime{Time: q.now(), Valid: true}
+		t.posts[id] = post
+	}
+	return nil
+}
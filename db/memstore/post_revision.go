@@ -0,0 +1,58 @@
+package memstore
+
+import (
+	"context"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreatePostRevision(ctx context.Context, arg db.CreatePostRevisionParams) (db.PostRevision, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	variants, err := jsonColumn("post_revisions", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return db.PostRevision{}, err
+	}
+	if _, ok := t.posts[arg.PostID]; !ok {
+		return db.PostRevision{}, foreignKeyViolation("post_revisions", "post_id", arg.PostID, "posts")
+	}
+	if _, ok := t.users[arg.Editor]; !ok {
+		return db.PostRevision{}, foreignKeyViolation("post_revisions", "editor", arg.Editor, "users")
+	}
+
+	revision := db.PostRevision{
+		ID:            q.nextID("post_revisions"),
+		PostID:        arg.PostID,
+		Editor:        arg.Editor,
+		Title:         arg.Title,
+		ImageUrl:      arg.ImageUrl,
+		ImageVariants: variants,
+		CreatedAt:     q.now(),
+	}
+	t.postRevisions[revision.ID] = revision
+	return revision, nil
+}
+
+func (q *queries) ListPostRevisions(ctx context.Context, arg db.ListPostRevisionsParams) ([]db.PostRevision, error) {
+	defer q.lock()()
+
+	revisions := rows(q.store.tables.postRevisions, func(revision db.PostRevision) bool {
+		return revision.PostID == arg.PostID
+	}, func(a, b db.PostRevision) bool {
+		return a.ID > b.ID
+	})
+	return page(revisions, arg.Limit, arg.Offset)
+}
+
+func (q *queries) ListPostRevisionsByCursor(ctx context.Context, arg db.ListPostRevisionsByCursorParams) ([]db.PostRevision, error) {
+	defer q.lock()()
+
+	revisions := rows(q.store.tables.postRevisions, func(revision db.PostRevision) bool {
+		return revision.PostID == arg.PostID &&
+			afterCursor(revision.CreatedAt, revision.ID, arg.CursorCreatedAt, arg.CursorID, true)
+	}, func(a, b db.PostRevision) bool {
+		return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	})
+	return page(revisions, arg.LimitCount, 0)
+}
@@ -0,0 +1,85 @@
+package memstore
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// rows returns the rows of the table matching the filter, sorted by less
+func rows[K comparable, V any](table map[K]V, match func(V) bool, less func(a, b V) bool) []V {
+	result := []V{}
+	for _, row := range table {
+		if match == nil || match(row) {
+			result = append(result, row)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return less(result[i], result[j])
+	})
+	return result
+}
+
+// page applies LIMIT and OFFSET to the sorted rows
+func page[V any](rows []V, limit, offset int32) ([]V, error) {
+	if limit < 0 {
+		return nil, negativeLimit()
+	}
+	if offset < 0 {
+		return nil, negativeOffset()
+	}
+
+	if int(offset) >= len(rows) {
+		return rows[:0], nil
+	}
+	rows = rows[offset:]
+	if int(limit) < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// afterCursor reports whether the row comes after the cursor of a keyset
+// page, comparing (created_at, id) with the cursor as postgres does. The rows
+// are ordered newest first when desc is set.
+func afterCursor(createdAt time.Time, id int64, cursorCreatedAt sql.NullTime, cursorID sql.NullInt64, desc bool) bool {
+	if !cursorCreatedAt.Valid {
+		return true
+	}
+	if !createdAt.Equal(cursorCreatedAt.Time) {
+		return createdAt.Before(cursorCreatedAt.Time) == desc
+	}
+	if !cursorID.Valid {
+		// comparing with null is never true
+		return false
+	}
+	if desc {
+		return id < cursorID.Int64
+	}
+	return id > cursorID.Int64
+}
+
+// initialVersion is the default of the version columns
+const initialVersion = 1
+
+// versionMatches reports whether the version of the row passes the optional
+// version condition of an update
+func versionMatches(version int64, expected sql.NullInt64) bool {
+	return !expected.Valid || version == expected.Int64
+}
+
+// newestFirst orders the rows by (created_at, id) descending
+func newestFirst(aCreatedAt time.Time, aID int64, bCreatedAt time.Time, bID int64) bool {
+	if !aCreatedAt.Equal(bCreatedAt) {
+		return aCreatedAt.After(bCreatedAt)
+	}
+	return aID > bID
+}
+
+// oldestFirst orders the rows by (created_at, id) ascending
+func oldestFirst(aCreatedAt time.Time, aID int64, bCreatedAt time.Time, bID int64) bool {
+	if !aCreatedAt.Equal(bCreatedAt) {
+		return aCreatedAt.Before(bCreatedAt)
+	}
+	return aID < bID
+}
@@ -0,0 +1,89 @@
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/google/uuid"
+)
+
+func (q *queries) CreateSession(ctx context.Context, arg db.CreateSessionParams) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.sessions[arg.ID]; ok {
+		return uniqueViolation("sessions", "sessions_pkey", "id", arg.ID.String())
+	}
+	if _, ok := t.users[arg.Username]; !ok {
+		return foreignKeyViolation("sessions", "username", arg.Username, "users")
+	}
+
+	t.sessions[arg.ID] = db.Session{
+		ID:           arg.ID,
+		Username:     arg.Username,
+		RefreshToken: arg.RefreshToken,
+		UserAgent:    arg.UserAgent,
+		ClientIp:     arg.ClientIp,
+		IsBlocked:    arg.IsBlocked,
+		ExpiresAt:    timestamp(arg.ExpiresAt),
+		CreatedAt:    q.now(),
+	}
+	return nil
+}
+
+func (q *queries) GetSession(ctx context.Context, id uuid.UUID) (db.Session, error) {
+	defer q.lock()()
+
+	session, ok := q.store.tables.sessions[id]
+	if !ok {
+		return db.Session{}, sql.ErrNoRows
+	}
+	return session, nil
+}
+
+func (q *queries) ListUserSessions(ctx context.Context, username string) ([]db.Session, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.sessions, func(session db.Session) bool {
+		return session.Username == username
+	}, func(a, b db.Session) bool {
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return bytes.Compare(a.ID[:], b.ID[:]) < 0
+	}), nil
+}
+
+func (q *queries) DeleteUserSessions(ctx context.Context, username string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for _, token := range t.deviceTokens {
+		if session, ok := t.sessions[token.SessionID]; ok && session.Username == username {
+			return referencedViolation("sessions", "id", token.SessionID, "device_tokens", "session_id")
+		}
+	}
+	for id, session := range t.sessions {
+		if session.Username == username {
+			delete(t.sessions, id)
+		}
+	}
+	return nil
+}
+
+func (q *queries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	var blocked int64
+	for id, session := range t.sessions {
+		if session.Username == username && !session.IsBlocked {
+			session.IsBlocked = true
+			t.sessions[id] = session
+			blocked++
+		}
+	}
+	return blocked, nil
+}
@@ -0,0 +1,223 @@
+// Package memstore keeps the data of the store in memory. It follows the
+// constraints of the database schema, so the handlers see the same errors,
+// orders and pages as they do with the SQL store.
+package memstore
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/asdsec/thenut/db/migration"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// deletedUser is the placeholder user the records of the purged users are
+// reassigned to, it is created by the migrations in the SQL store
+const deletedUser = "deleted_user"
+
+// Store is a db.Store keeping the tables in memory. It is safe for concurrent
+// use, the transactions run one at a time and are rolled back on errors.
+type Store struct {
+	*queries
+	mu     sync.Mutex
+	tables *tables
+	// sequences are never rolled back, as in postgres
+	sequences map[string]int64
+}
+
+// NewStore creates an empty Store with the rows the migrations insert
+func NewStore() db.Store {
+	store := &Store{
+		tables:    newTables(),
+		sequences: make(map[string]int64),
+	}
+	store.queries = &queries{store: store}
+
+	store.tables.users[deletedUser] = db.User{
+		Username:      deletedUser,
+		FullName:      "Deleted User",
+		Email:         "deleted_user@thenut.invalid",
+		PhoneNumber:   deletedUser,
+		ImageUrl:      defaultUserImage,
+		Gender:        defaultGender,
+		Disabled:      true,
+		CreatedAt:     now(),
+		ImageVariants: emptyJSON(),
+		Role:          defaultRole,
+		Version:       initialVersion,
+	}
+	return store
+}
+
+// tables holds the rows of every table by their primary keys
+type tables struct {
+	users         map[string]db.User
+	customers     map[int64]db.Customer
+	merchants     map[int64]db.Merchant
+	posts         map[int64]db.Post
+	postRevisions map[int64]db.PostRevision
+	comments      map[int64]db.Comment
+	consultancies map[int64]db.Consultancy
+	sessions      map[uuid.UUID]db.Session
+	appVersions   map[int64]db.AppVersion
+	auditEvents   map[int64]db.AuditEvent
+	// idempotencyKeys are keyed by the username and the key
+	idempotencyKeys map[idempotencyKeyID]db.IdempotencyKey
+	notifications   map[int64]db.Notification
+	// deviceTokens are keyed by the session
+	deviceTokens         map[uuid.UUID]db.DeviceToken
+	notificationSettings map[string]db.NotificationSetting
+	// notificationPreferences are keyed by the username, the event type and
+	// the channel
+	notificationPreferences map[notificationPreferenceID]db.NotificationPreference
+	jobs                    map[int64]db.Job
+	outboxEvents            map[int64]db.OutboxEvent
+}
+
+func newTables() *tables {
+	return &tables{
+		users:                   make(map[string]db.User),
+		customers:               make(map[int64]db.Customer),
+		merchants:               make(map[int64]db.Merchant),
+		posts:                   make(map[int64]db.Post),
+		postRevisions:           make(map[int64]db.PostRevision),
+		comments:                make(map[int64]db.Comment),
+		consultancies:           make(map[int64]db.Consultancy),
+		sessions:                make(map[uuid.UUID]db.Session),
+		appVersions:             make(map[int64]db.AppVersion),
+		auditEvents:             make(map[int64]db.AuditEvent),
+		idempotencyKeys:         make(map[idempotencyKeyID]db.IdempotencyKey),
+		notifications:           make(map[int64]db.Notification),
+		deviceTokens:            make(map[uuid.UUID]db.DeviceToken),
+		notificationSettings:    make(map[string]db.NotificationSetting),
+		notificationPreferences: make(map[notificationPreferenceID]db.NotificationPreference),
+		jobs:                    make(map[int64]db.Job),
+		outboxEvents:            make(map[int64]db.OutboxEvent),
+	}
+}
+
+// clone copies the tables, the rows are values so the copy is independent
+func (t *tables) clone() *tables {
+	return &tables{
+		users:                   cloneMap(t.users),
+		customers:               cloneMap(t.customers),
+		merchants:               cloneMap(t.merchants),
+		posts:                   cloneMap(t.posts),
+		postRevisions:           cloneMap(t.postRevisions),
+		comments:                cloneMap(t.comments),
+		consultancies:           cloneMap(t.consultancies),
+		sessions:                cloneMap(t.sessions),
+		appVersions:             cloneMap(t.appVersions),
+		auditEvents:             cloneMap(t.auditEvents),
+		idempotencyKeys:         cloneMap(t.idempotencyKeys),
+		notifications:           cloneMap(t.notifications),
+		deviceTokens:            cloneMap(t.deviceTokens),
+		notificationSettings:    cloneMap(t.notificationSettings),
+		notificationPreferences: cloneMap(t.notificationPreferences),
+		jobs:                    cloneMap(t.jobs),
+		outboxEvents:            cloneMap(t.outboxEvents),
+	}
+}
+
+func cloneMap[K comparable, V any](m map[K]V) map[K]V {
+	cloned := make(map[K]V, len(m))
+	for key, value := range m {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// queries runs the queries on the tables of the store. Outside a transaction
+// every query takes the lock of the store, inside one the transaction holds it.
+type queries struct {
+	store *Store
+	inTx  bool
+	// txTime is the start of the transaction, which is what now() returns in postgres
+	txTime time.Time
+	// txID identifies the transaction like pg_current_xact_id() does
+	txID int64
+}
+
+// lock locks the store unless the queries run in a transaction and returns the
+// function releasing it
+func (q *queries) lock() func() {
+	if q.inTx {
+		return func() {}
+	}
+	q.store.mu.Lock()
+	return q.store.mu.Unlock
+}
+
+// now returns the time the rows are created or updated at
+func (q *queries) now() time.Time {
+	if q.inTx {
+		return q.txTime
+	}
+	return now()
+}
+
+// xactID returns the id of the transaction, a query outside of one runs in a
+// transaction of its own
+func (q *queries) xactID() int64 {
+	if q.inTx {
+		return q.txID
+	}
+	return q.nextID("transactions")
+}
+
+// nextID returns the next value of the sequence of the table
+func (q *queries) nextID(table string) int64 {
+	q.store.sequences[table]++
+	return q.store.sequences[table]
+}
+
+// execTx runs the function in a transaction. The store stays locked until the
+// transaction ends, and the tables are restored if the function fails.
+func (store *Store) execTx(ctx context.Context, fn func(*queries) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	snapshot := store.tables.clone()
+	q := &queries{
+		store:  store,
+		inTx:   true,
+		txTime: now(),
+	}
+	q.txID = q.nextID("transactions")
+	err := fn(q)
+	if err != nil {
+		store.tables = snapshot
+	}
+	return err
+}
+
+// Ping always succeeds since there is no connection
+func (store *Store) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// GetSchemaVersion reports the latest migration, the tables always have the
+// newest schema
+func (store *Store) GetSchemaVersion(ctx context.Context) (db.SchemaVersion, error) {
+	latest, err := migration.LatestVersion()
+	if err != nil {
+		return db.SchemaVersion{}, err
+	}
+	return db.SchemaVersion{Version: int64(latest)}, nil
+}
+
+// now returns the current time with the precision of postgres timestamps
+func now() time.Time {
+	return timestamp(time.Now())
+}
+
+// timestamp rounds the time to microseconds as postgres stores it
+func timestamp(t time.Time) time.Time {
+	return t.Round(time.Microsecond)
+}
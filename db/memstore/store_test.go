@@ -0,0 +1,225 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/db/storetest"
+	"github.com/asdsec/thenut/utils"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomUser(t *testing.T, store db.Store) db.User {
+	user, err := store.CreateUser(context.Background(), db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(32),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		Gender:         utils.RandomGender(),
+		BirthDate:      utils.RandomBirthDate(),
+	})
+	require.NoError(t, err)
+	return user
+}
+
+func createRandomMerchant(t *testing.T, store db.Store, owner string) db.Merchant {
+	merchant, err := store.CreateMerchant(context.Background(), db.CreateMerchantParams{
+		Owner:      owner,
+		Profession: utils.RandomString(8),
+		Title:      utils.RandomString(8),
+		About:      utils.RandomString(20),
+	})
+	require.NoError(t, err)
+	return merchant
+}
+
+func requirePQCode(t *testing.T, err error, code string) {
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr)
+	require.Equal(t, pq.ErrorCode(code), pqErr.Code)
+}
+
+func TestStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store {
+		return NewStore()
+	})
+}
+
+func TestCreateUserConstraints(t *testing.T) {
+	store := NewStore()
+	user := createRandomUser(t, store)
+
+	require.Equal(t, defaultUserImage, user.ImageUrl)
+	require.Equal(t, defaultRole, user.Role)
+	require.JSONEq(t, "{}", string(user.ImageVariants))
+
+	arg := db.CreateUserParams{
+		Username:    utils.RandomOwner(),
+		Email:       user.Email,
+		PhoneNumber: utils.RandomPhoneNumber(),
+		Gender:      utils.RandomGender(),
+	}
+	_, err := store.CreateUser(context.Background(), arg)
+	requirePQCode(t, err, "23505")
+
+	arg.Email = utils.RandomEmail()
+	arg.Gender = "mf"
+	_, err = store.CreateUser(context.Background(), arg)
+	requirePQCode(t, err, "22001")
+
+	_, err = store.GetUser(context.Background(), arg.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestForeignKeys(t *testing.T) {
+	store := NewStore()
+
+	_, err := store.CreateMerchant(context.Background(), db.CreateMerchantParams{Owner: utils.RandomOwner()})
+	requirePQCode(t, err, "23503")
+
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+
+	// the merchant is referenced, so neither can be deleted
+	err = store.DeleteUser(context.Background(), user.Username)
+	requirePQCode(t, err, "23503")
+
+	_, err = store.CreatePost(context.Background(), db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		Title:         sql.NullString{String: utils.RandomString(8), Valid: true},
+		ImageVariants: json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+
+	err = store.DeleteMerchant(context.Background(), merchant.ID)
+	requirePQCode(t, err, "23503")
+}
+
+func TestPostConstraints(t *testing.T) {
+	store := NewStore()
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	_, err := store.CreatePost(context.Background(), db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		ImageVariants: json.RawMessage("{}"),
+	})
+	requirePQCode(t, err, "23514")
+
+	_, err = store.CreatePost(context.Background(), db.CreatePostParams{
+		MerchantID: merchant.ID,
+		Title:      sql.NullString{String: utils.RandomString(8), Valid: true},
+	})
+	requirePQCode(t, err, "23502")
+}
+
+func TestListPostsPagination(t *testing.T) {
+	store := NewStore()
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	n := 5
+	posts := make([]db.Post, n)
+	for i := range posts {
+		post, err := store.CreatePost(context.Background(), db.CreatePostParams{
+			MerchantID:    merchant.ID,
+			Title:         sql.NullString{String: utils.RandomString(8), Valid: true},
+			ImageVariants: json.RawMessage("{}"),
+		})
+		require.NoError(t, err)
+		posts[i] = post
+	}
+
+	err := store.DeletePost(context.Background(), posts[n-1].ID)
+	require.NoError(t, err)
+
+	// newest first, without the deleted post
+	listed, err := store.ListPosts(context.Background(), db.ListPostsParams{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	require.Equal(t, posts[n-3].ID, listed[0].ID)
+	require.Equal(t, posts[n-4].ID, listed[1].ID)
+
+	listed, err = store.ListPosts(context.Background(), db.ListPostsParams{Limit: 5, Offset: 10})
+	require.NoError(t, err)
+	require.NotNil(t, listed)
+	require.Empty(t, listed)
+
+	_, err = store.ListPosts(context.Background(), db.ListPostsParams{Limit: -1})
+	requirePQCode(t, err, "2201W")
+}
+
+func TestTxRollback(t *testing.T) {
+	store := NewStore()
+	user := createRandomUser(t, store)
+
+	errApply := errors.New("apply failed")
+	_, err := store.AuditTx(context.Background(), db.AuditTxParams{
+		Actor:  user.Username,
+		Action: "user.disable",
+		Target: "user:" + user.Username,
+		Apply: func(q db.Querier) (interface{}, error) {
+			_, err := q.SetUserDisabled(context.Background(), db.SetUserDisabledParams{
+				Username: user.Username,
+				Disabled: true,
+			})
+			require.NoError(t, err)
+			return nil, errApply
+		},
+	})
+	require.ErrorIs(t, err, errApply)
+
+	user, err = store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, user.Disabled)
+
+	events, err := store.ListAuditEvents(context.Background(), db.ListAuditEventsParams{LimitCount: 10})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestDeleteUserTx(t *testing.T) {
+	store := NewStore()
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+
+	result, err := store.DeleteUserTx(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []int64{merchant.ID}, result.MerchantIDs)
+
+	_, err = store.GetUser(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	merchant, err = store.GetMerchant(context.Background(), merchant.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, merchant.Owner)
+}
+
+func TestConcurrentAddMerchantBalance(t *testing.T) {
+	store := NewStore()
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	n := 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := store.AddMerchantBalance(context.Background(), db.AddMerchantBalanceParams{
+				ID:     merchant.ID,
+				Amount: 10,
+			})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	merchant, err := store.GetMerchant(context.Background(), merchant.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(n*10), merchant.Balance)
+}
@@ -0,0 +1,308 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// The transactions run the same queries as the ones of the SQL store
+
+func (store *Store) UpdatePostTx(ctx context.Context, arg db.UpdatePostTxParams) (db.UpdatePostTxResult, error) {
+	var result db.UpdatePostTxResult
+
+	err := store.execTx(ctx, func(q *queries) error {
+		post, err := q.GetPostForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Revision, err = q.CreatePostRevision(ctx, db.CreatePostRevisionParams{
+			PostID:        post.ID,
+			Editor:        arg.Editor,
+			Title:         post.Title,
+			ImageUrl:      post.ImageUrl,
+			ImageVariants: post.ImageVariants,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Post, err = q.UpdatePost(ctx, arg.Apply(post))
+		if err != nil {
+			return err
+		}
+
+		_, err = db.AppendEvent(ctx, q, db.PostUpdated(result.Post, result.Revision))
+		return err
+	})
+
+	return result, err
+}
+
+func (store *Store) DeleteUserTx(ctx context.Context, username string) (db.DeleteUserTxResult, error) {
+	var result db.DeleteUserTxResult
+
+	err := store.execTx(ctx, func(q *queries) error {
+		if err := q.DeleteUserDeviceTokens(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserSessions(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserIdempotencyKeys(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserNotifications(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserNotificationPreferences(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserNotificationSettings(ctx, username); err != nil {
+			return err
+		}
+		if err := q.AnonymizeUserNotifications(ctx, username); err != nil {
+			return err
+		}
+		if err := q.AnonymizeUserComments(ctx, username); err != nil {
+			return err
+		}
+		if err := q.AnonymizeUserPostRevisions(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteOwnerPosts(ctx, username); err != nil {
+			return err
+		}
+		var err error
+		result.CustomerIDs, err = q.AnonymizeUserCustomers(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.MerchantIDs, err = q.AnonymizeUserMerchants(ctx, username)
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteUser(ctx, username); err != nil {
+			return err
+		}
+
+		_, err = db.AppendEvent(ctx, q, db.UserDeleted(username))
+		return err
+	})
+
+	return result, err
+}
+
+func (store *Store) UpdateNotificationPreferencesTx(ctx context.Context, arg db.UpdateNotificationPreferencesTxParams) (db.UpdateNotificationPreferencesTxResult, error) {
+	var result db.UpdateNotificationPreferencesTxResult
+
+	err := store.execTx(ctx, func(q *queries) error {
+		var err error
+		result.Settings, err = q.UpsertNotificationSettings(ctx, arg.Settings)
+		if err != nil {
+			return err
+		}
+
+		for _, preference := range arg.Preferences {
+			preference.Username = arg.Settings.Username
+			if _, err := q.UpsertNotificationPreference(ctx, preference); err != nil {
+				return err
+			}
+		}
+
+		result.Preferences, err = q.ListNotificationPreferences(ctx, arg.Settings.Username)
+		return err
+	})
+
+	return result, err
+}
+
+func (store *Store) ExportUserTx(ctx context.Context, username string) (db.ExportUserTxResult, error) {
+	var result db.ExportUserTxResult
+
+	err := store.execTx(ctx, func(q *queries) error {
+		var err error
+
+		result.User, err = q.GetUser(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Customers, err = q.ListCustomersByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Merchants, err = q.ListMerchantsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Posts, err = q.ListPostsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Comments, err = q.ListCommentsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Consultancies, err = q.ListConsultanciesByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Sessions, err = q.ListUserSessions(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Notifications, err = q.ListUserNotifications(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.DeviceTokens, err = q.ListAllUserDeviceTokens(ctx, username)
+		if err != nil {
+			return err
+		}
+		settings, err := q.GetNotificationSettings(ctx, username)
+		if err == nil {
+			result.NotificationSettings = &settings
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		result.NotificationPreferences, err = q.ListNotificationPreferences(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.AuditEvents, err = q.ListUserAuditEvents(ctx, username)
+		return err
+	})
+
+	return result, err
+}
+
+func (store *Store) AuditTx(ctx context.Context, arg db.AuditTxParams) (db.AuditEvent, error) {
+	var event db.AuditEvent
+
+	err := store.execTx(ctx, func(q *queries) error {
+		diff, err := arg.Apply(q)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(diff)
+		if err != nil {
+			return err
+		}
+
+		event, err = q.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+			Actor:  arg.Actor,
+			Action: arg.Action,
+			Target: arg.Target,
+			Diff:   data,
+		})
+		return err
+	})
+
+	return event, err
+}
+
+func (store *Store) CreateUserTx(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	var user db.User
+
+	err := store.execTx(ctx, func(q *queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.AppendEvent(ctx, q, db.UserRegistered(user))
+		return err
+	})
+
+	return user, err
+}
+
+func (store *Store) CreatePostTx(ctx context.Context, arg db.CreatePostParams) (db.Post, error) {
+	var post db.Post
+
+	err := store.execTx(ctx, func(q *queries) error {
+		var err error
+		post, err = q.CreatePost(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.AppendEvent(ctx, q, db.PostCreated(post))
+		return err
+	})
+
+	return post, err
+}
+
+func (store *Store) CreateCommentTx(ctx context.Context, arg db.CreateCommentParams) (db.Comment, error) {
+	var comment db.Comment
+
+	err := store.execTx(ctx, func(q *queries) error {
+		var err error
+		comment, err = q.CreateComment(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.AppendEvent(ctx, q, db.CommentCreated(comment))
+		return err
+	})
+
+	return comment, err
+}
+
+func (store *Store) CreateConsultancyTx(ctx context.Context, arg db.CreateConsultancyParams) (db.Consultancy, error) {
+	var consultancy db.Consultancy
+
+	err := store.execTx(ctx, func(q *queries) error {
+		var err error
+		consultancy, err = q.CreateConsultancy(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.AppendEvent(ctx, q, db.ConsultancyBooked(consultancy))
+		return err
+	})
+
+	return consultancy, err
+}
+
+// RelayOutboxTx delivers the events while the store is locked, the deliveries
+// must not use the store
+func (store *Store) RelayOutboxTx(ctx context.Context, arg db.RelayOutboxTxParams) (int, error) {
+	var delivered int
+	var deliverErr error
+
+	err := store.execTx(ctx, func(q *queries) error {
+		events, err := q.ListOutboxEventsForUpdate(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			deliverErr = arg.Deliver(event)
+			if deliverErr != nil {
+				break
+			}
+
+			err = q.DeleteOutboxEvent(ctx, event.ID)
+			if err != nil {
+				return err
+			}
+			delivered++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return delivered, deliverErr
+}
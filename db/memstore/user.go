@@ -0,0 +1,305 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+const (
+	defaultUserImage = "/default/user/avatar.jpg"
+	defaultGender    = "m"
+	defaultRole      = "user"
+	genderLength     = 1
+)
+
+func (q *queries) CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	user := db.User{
+		Username:       arg.Username,
+		HashedPassword: arg.HashedPassword,
+		FullName:       arg.FullName,
+		Email:          arg.Email,
+		PhoneNumber:    arg.PhoneNumber,
+		ImageUrl:       defaultUserImage,
+		Gender:         arg.Gender,
+		BirthDate:      timestamp(arg.BirthDate),
+		CreatedAt:      q.now(),
+		ImageVariants:  emptyJSON(),
+		Role:           defaultRole,
+		Version:        initialVersion,
+	}
+	if _, ok := t.users[user.Username]; ok {
+		return db.User{}, uniqueViolation("users", "users_pkey", "username", user.Username)
+	}
+	if err := q.checkUser(user); err != nil {
+		return db.User{}, err
+	}
+
+	t.users[user.Username] = user
+	return user, nil
+}
+
+// checkUser checks the constraints of the user row other than the primary key
+func (q *queries) checkUser(user db.User) error {
+	if len(user.Gender) > genderLength {
+		return stringTooLong(genderLength)
+	}
+
+	for _, other := range q.store.tables.users {
+		if other.Username == user.Username {
+			continue
+		}
+		if other.Email == user.Email {
+			return uniqueViolation("users", "users_email_key", "email", user.Email)
+		}
+		if other.PhoneNumber == user.PhoneNumber {
+			return uniqueViolation("users", "users_phone_number_key", "phone_number", user.PhoneNumber)
+		}
+	}
+	return nil
+}
+
+func (q *queries) GetUser(ctx context.Context, username string) (db.User, error) {
+	defer q.lock()()
+
+	user, ok := q.store.tables.users[username]
+	if !ok {
+		return db.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+// updateUser applies the update to the user and stores it if the constraints
+// hold. A user whose version does not match is not found, as in postgres.
+func (q *queries) updateUser(username string, version sql.NullInt64, update func(user *db.User) error) (db.User, error) {
+	t := q.store.tables
+
+	user, ok := t.users[username]
+	if !ok || !versionMatches(user.Version, version) {
+		return db.User{}, sql.ErrNoRows
+	}
+	if err := update(&user); err != nil {
+		return db.User{}, err
+	}
+	if err := q.checkUser(user); err != nil {
+		return db.User{}, err
+	}
+	user.Version++
+
+	t.users[username] = user
+	return user, nil
+}
+
+func (q *queries) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error) {
+	defer q.lock()()
+
+	return q.updateUser(arg.Username, arg.Version, func(user *db.User) error {
+		if arg.FullName.Valid {
+			user.FullName = arg.FullName.String
+		}
+		if arg.PhoneNumber.Valid {
+			user.PhoneNumber = arg.PhoneNumber.String
+		}
+		if arg.Gender.Valid {
+			user.Gender = arg.Gender.String
+		}
+		if arg.BirthDate.Valid {
+			user.BirthDate = timestamp(arg.BirthDate.Time)
+		}
+		if arg.ImageUrl.Valid {
+			user.ImageUrl = arg.ImageUrl.String
+		}
+		return nil
+	})
+}
+
+func (q *queries) UpdateUserImage(ctx context.Context, arg db.UpdateUserImageParams) (db.User, error) {
+	defer q.lock()()
+
+	return q.updateUser(arg.Username, arg.Version, func(user *db.User) error {
+		variants, err := jsonColumn("users", "image_variants", arg.ImageVariants)
+		if err != nil {
+			return err
+		}
+		user.ImageUrl = arg.ImageUrl
+		user.ImageVariants = variants
+		return nil
+	})
+}
+
+func (q *queries) SetUserImageVariants(ctx context.Context, arg db.SetUserImageVariantsParams) (int64, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	user, ok := t.users[arg.Username]
+	if !ok || user.ImageUrl != arg.ImageUrl {
+		return 0, nil
+	}
+
+	variants, err := jsonColumn("users", "image_variants", arg.ImageVariants)
+	if err != nil {
+		return 0, err
+	}
+	user.ImageVariants = variants
+	user.Version++
+
+	t.users[user.Username] = user
+	return 1, nil
+}
+
+func (q *queries) UpdatePassword(ctx context.Context, arg db.UpdatePasswordParams) (db.User, error) {
+	defer q.lock()()
+
+	return q.updateUser(arg.Username, sql.NullInt64{}, func(user *db.User) error {
+		user.HashedPassword = arg.HashedPassword
+		user.PasswordChangedAt = timestamp(arg.PasswordChangedAt)
+		return nil
+	})
+}
+
+func (q *queries) UpdateEmail(ctx context.Context, arg db.UpdateEmailParams) (db.User, error) {
+	defer q.lock()()
+
+	return q.updateUser(arg.Username, sql.NullInt64{}, func(user *db.User) error {
+		user.Email = arg.Email
+		return nil
+	})
+}
+
+func (q *queries) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (db.User, error) {
+	defer q.lock()()
+
+	return q.updateUser(arg.Username, sql.NullInt64{}, func(user *db.User) error {
+		user.DeletionScheduledAt = arg.DeletionScheduledAt
+		if arg.DeletionScheduledAt.Valid {
+			user.DeletionScheduledAt.Time = timestamp(arg.DeletionScheduledAt.Time)
+		}
+		return nil
+	})
+}
+
+func (q *queries) SetUserDisabled(ctx context.Context, arg db.SetUserDisabledParams) (db.User, error) {
+	defer q.lock()()
+
+	return q.updateUser(arg.Username, sql.NullInt64{}, func(user *db.User) error {
+		user.Disabled = arg.Disabled
+		return nil
+	})
+}
+
+func (q *queries) DeleteUser(ctx context.Context, username string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	if _, ok := t.users[username]; !ok {
+		return nil
+	}
+
+	for _, comment := range t.comments {
+		if comment.Owner == username {
+			return referencedViolation("users", "username", username, "comments", "owner")
+		}
+	}
+	for _, customer := range t.customers {
+		if customer.Owner == username {
+			return referencedViolation("users", "username", username, "customers", "owner")
+		}
+	}
+	for _, merchant := range t.merchants {
+		if merchant.Owner == username {
+			return referencedViolation("users", "username", username, "merchants", "owner")
+		}
+	}
+	for _, session := range t.sessions {
+		if session.Username == username {
+			return referencedViolation("users", "username", username, "sessions", "username")
+		}
+	}
+	for _, revision := range t.postRevisions {
+		if revision.Editor == username {
+			return referencedViolation("users", "username", username, "post_revisions", "editor")
+		}
+	}
+	for id := range t.idempotencyKeys {
+		if id.username == username {
+			return referencedViolation("users", "username", username, "idempotency_keys", "username")
+		}
+	}
+	for _, notification := range t.notifications {
+		if notification.Recipient == username {
+			return referencedViolation("users", "username", username, "notifications", "recipient")
+		}
+		if notification.Actor == username {
+			return referencedViolation("users", "username", username, "notifications", "actor")
+		}
+	}
+	for _, token := range t.deviceTokens {
+		if token.Username == username {
+			return referencedViolation("users", "username", username, "device_tokens", "username")
+		}
+	}
+	if _, ok := t.notificationSettings[username]; ok {
+		return referencedViolation("users", "username", username, "notification_settings", "username")
+	}
+	for id := range t.notificationPreferences {
+		if id.username == username {
+			return referencedViolation("users", "username", username, "notification_preferences", "username")
+		}
+	}
+
+	delete(t.users, username)
+	return nil
+}
+
+func (q *queries) ListUsersDueForDeletion(ctx context.Context, arg db.ListUsersDueForDeletionParams) ([]string, error) {
+	defer q.lock()()
+
+	users := rows(q.store.tables.users, func(user db.User) bool {
+		return user.DeletionScheduledAt.Valid && !user.DeletionScheduledAt.Time.After(arg.Now)
+	}, func(a, b db.User) bool {
+		if !a.DeletionScheduledAt.Time.Equal(b.DeletionScheduledAt.Time) {
+			return a.DeletionScheduledAt.Time.Before(b.DeletionScheduledAt.Time)
+		}
+		return a.Username < b.Username
+	})
+	users, err := page(users, arg.LimitCount, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	usernames := []string{}
+	for _, user := range users {
+		usernames = append(usernames, user.Username)
+	}
+	return usernames, nil
+}
+
+func (q *queries) AnonymizeUserComments(ctx context.Context, owner string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id, comment := range t.comments {
+		if comment.Owner == owner {
+			comment.Owner = deletedUser
+			t.comments[id] = comment
+		}
+	}
+	return nil
+}
+
+func (q *queries) AnonymizeUserPostRevisions(ctx context.Context, editor string) error {
+	defer q.lock()()
+	t := q.store.tables
+
+	for id, revision := range t.postRevisions {
+		if revision.Editor == editor {
+			revision.Editor = deletedUser
+			t.postRevisions[id] = revision
+		}
+	}
+	return nil
+}
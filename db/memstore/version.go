@@ -0,0 +1,59 @@
+package memstore
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+func (q *queries) CreateAppVersion(ctx context.Context, arg db.CreateAppVersionParams) (db.AppVersion, error) {
+	defer q.lock()()
+
+	version := db.AppVersion{
+		ID:        q.nextID("app_versions"),
+		Tag:       arg.Tag,
+		Version:   arg.Version,
+		CreatedAt: q.now(),
+	}
+	q.store.tables.appVersions[version.ID] = version
+	return version, nil
+}
+
+// GetAppVersion returns the first version with the tag, the tags are not unique
+func (q *queries) GetAppVersion(ctx context.Context, tag string) (db.AppVersion, error) {
+	defer q.lock()()
+
+	versions := rows(q.store.tables.appVersions, func(version db.AppVersion) bool {
+		return version.Tag == tag
+	}, func(a, b db.AppVersion) bool {
+		return a.ID < b.ID
+	})
+	if len(versions) == 0 {
+		return db.AppVersion{}, sql.ErrNoRows
+	}
+	return versions[0], nil
+}
+
+func (q *queries) ListAppVersions(ctx context.Context) ([]db.AppVersion, error) {
+	defer q.lock()()
+
+	return rows(q.store.tables.appVersions, nil, func(a, b db.AppVersion) bool {
+		return newestFirst(a.CreatedAt, a.ID, b.CreatedAt, b.ID)
+	}), nil
+}
+
+func (q *queries) UpdateAppVersion(ctx context.Context, arg db.UpdateAppVersionParams) (db.AppVersion, error) {
+	defer q.lock()()
+	t := q.store.tables
+
+	version, ok := t.appVersions[arg.ID]
+	if !ok {
+		return db.AppVersion{}, sql.ErrNoRows
+	}
+	version.Tag = arg.Tag
+	version.Version = arg.Version
+
+	t.appVersions[version.ID] = version
+	return version, nil
+}
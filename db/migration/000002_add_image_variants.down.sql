@@ -0,0 +1,7 @@
+ALTER TABLE "posts" DROP COLUMN IF EXISTS "image_variants";
+
+ALTER TABLE "merchants" DROP COLUMN IF EXISTS "image_variants";
+
+ALTER TABLE "customers" DROP COLUMN IF EXISTS "image_variants";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "image_variants";
@@ -0,0 +1,15 @@
+ALTER TABLE "users" ADD COLUMN "image_variants" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "customers" ADD COLUMN "image_variants" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "merchants" ADD COLUMN "image_variants" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "posts" ADD COLUMN "image_variants" jsonb NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN "users"."image_variants" IS 'thumbnail urls of image_url keyed by variant name';
+
+COMMENT ON COLUMN "customers"."image_variants" IS 'thumbnail urls of image_url keyed by variant name';
+
+COMMENT ON COLUMN "merchants"."image_variants" IS 'thumbnail urls of image_url keyed by variant name';
+
+COMMENT ON COLUMN "posts"."image_variants" IS 'thumbnail urls of image_url keyed by variant name';
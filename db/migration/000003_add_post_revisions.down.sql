@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "post_revisions";
+
+ALTER TABLE "posts" DROP CONSTRAINT IF EXISTS "posts_title_or_image_check";
+
+ALTER TABLE "posts" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "posts" DROP COLUMN IF EXISTS "updated_at";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "role";
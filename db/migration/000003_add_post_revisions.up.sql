@@ -0,0 +1,31 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'user';
+
+ALTER TABLE "posts" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "posts" ADD COLUMN "deleted_at" timestamptz;
+
+ALTER TABLE "posts" ADD CONSTRAINT "posts_title_or_image_check" CHECK ("title" IS NOT NULL OR "image_url" IS NOT NULL);
+
+CREATE TABLE "post_revisions" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "post_id" bigint NOT NULL,
+  "editor" varchar NOT NULL,
+  "title" varchar,
+  "image_url" varchar,
+  "image_variants" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "post_revisions" ("post_id");
+
+COMMENT ON COLUMN "users"."role" IS 'one of user, moderator or admin';
+
+COMMENT ON COLUMN "posts"."deleted_at" IS 'posts are soft deleted, null while the post is visible';
+
+COMMENT ON COLUMN "post_revisions"."title" IS 'title of the post before the edit';
+
+COMMENT ON COLUMN "post_revisions"."image_url" IS 'image_url of the post before the edit';
+
+ALTER TABLE "post_revisions" ADD FOREIGN KEY ("post_id") REFERENCES "posts" ("id");
+
+ALTER TABLE "post_revisions" ADD FOREIGN KEY ("editor") REFERENCES "users" ("username");
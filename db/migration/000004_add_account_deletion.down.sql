@@ -0,0 +1,12 @@
+-- this migration cannot be fully reversed once an account is purged: the
+-- placeholder owns the records reassigned to it then, and is kept along with
+-- them instead of violating their foreign keys
+DELETE FROM "users"
+WHERE "username" = 'deleted_user'
+  AND NOT EXISTS (SELECT 1 FROM "comments" WHERE "owner" = 'deleted_user')
+  AND NOT EXISTS (SELECT 1 FROM "customers" WHERE "owner" = 'deleted_user')
+  AND NOT EXISTS (SELECT 1 FROM "merchants" WHERE "owner" = 'deleted_user')
+  AND NOT EXISTS (SELECT 1 FROM "sessions" WHERE "username" = 'deleted_user')
+  AND NOT EXISTS (SELECT 1 FROM "post_revisions" WHERE "editor" = 'deleted_user');
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "deletion_scheduled_at";
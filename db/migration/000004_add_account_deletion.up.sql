@@ -0,0 +1,24 @@
+ALTER TABLE "users" ADD COLUMN "deletion_scheduled_at" timestamptz;
+
+COMMENT ON COLUMN "users"."deletion_scheduled_at" IS 'the account is purged once this time passes, null if no deletion is requested';
+
+CREATE INDEX ON "users" ("deletion_scheduled_at");
+
+-- records of deleted users are reassigned to this user, it cannot be
+-- registered since usernames must be alphanumeric. The down migration keeps it
+-- once records are reassigned to it.
+INSERT INTO "users" (
+  "username",
+  "hashed_password",
+  "full_name",
+  "email",
+  "phone_number",
+  "disabled"
+) VALUES (
+  'deleted_user',
+  '',
+  'Deleted User',
+  'deleted_user@thenut.invalid',
+  'deleted_user',
+  true
+) ON CONFLICT ("username") DO NOTHING;
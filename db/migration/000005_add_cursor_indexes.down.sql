@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "merchants_owner_created_at_id_idx";
+
+DROP INDEX IF EXISTS "comments_post_id_created_at_id_idx";
+
+DROP INDEX IF EXISTS "posts_merchant_id_created_at_id_idx";
+
+DROP INDEX IF EXISTS "posts_created_at_id_idx";
@@ -0,0 +1,7 @@
+CREATE INDEX ON "posts" ("created_at", "id");
+
+CREATE INDEX ON "posts" ("merchant_id", "created_at", "id");
+
+CREATE INDEX ON "comments" ("post_id", "created_at", "id");
+
+CREATE INDEX ON "merchants" ("owner", "created_at", "id");
@@ -0,0 +1,18 @@
+CREATE TABLE "audit_events" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "target" varchar NOT NULL,
+  "diff" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_events" ("actor");
+
+CREATE INDEX ON "audit_events" ("target");
+
+CREATE INDEX ON "audit_events" ("created_at");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username of the user, or cli:<name> for the admin commands';
+
+COMMENT ON COLUMN "audit_events"."target" IS 'the changed resource, like user:<username> or merchant:<id>';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "audit_events_action_idx";
+
+ALTER TABLE "audit_events" DROP COLUMN IF EXISTS "request_id";
+
+ALTER TABLE "audit_events" DROP COLUMN IF EXISTS "user_agent";
+
+ALTER TABLE "audit_events" DROP COLUMN IF EXISTS "ip";
@@ -0,0 +1,9 @@
+ALTER TABLE "audit_events" ADD COLUMN "ip" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "audit_events" ADD COLUMN "user_agent" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "audit_events" ADD COLUMN "request_id" varchar NOT NULL DEFAULT '';
+
+CREATE INDEX ON "audit_events" ("action");
+
+COMMENT ON COLUMN "audit_events"."ip" IS 'client IP of the request, empty for the admin commands';
@@ -0,0 +1,20 @@
+CREATE TABLE "idempotency_keys" (
+  "username" varchar NOT NULL,
+  "key" varchar NOT NULL,
+  "request_method" varchar NOT NULL,
+  "request_path" varchar NOT NULL,
+  "request_hash" varchar NOT NULL,
+  "response_status" int,
+  "response_body" bytea,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expires_at" timestamptz NOT NULL,
+  PRIMARY KEY ("username", "key")
+);
+
+CREATE INDEX ON "idempotency_keys" ("expires_at");
+
+ALTER TABLE "idempotency_keys" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'sha256 of the method, the path and the body of the first request';
+
+COMMENT ON COLUMN "idempotency_keys"."response_status" IS 'null while the first request is in progress';
@@ -0,0 +1,5 @@
+ALTER TABLE "customers" DROP COLUMN IF EXISTS "version";
+
+ALTER TABLE "merchants" DROP COLUMN IF EXISTS "version";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "version";
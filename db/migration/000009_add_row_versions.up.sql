@@ -0,0 +1,11 @@
+ALTER TABLE "users" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "merchants" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+ALTER TABLE "customers" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN "users"."version" IS 'incremented on every update, the ETag of the user';
+
+COMMENT ON COLUMN "merchants"."version" IS 'incremented on every update, the ETag of the merchant';
+
+COMMENT ON COLUMN "customers"."version" IS 'incremented on every update, the ETag of the customer';
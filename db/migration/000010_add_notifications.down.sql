@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "device_tokens";
+
+DROP TABLE IF EXISTS "notifications";
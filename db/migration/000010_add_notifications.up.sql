@@ -0,0 +1,51 @@
+CREATE TABLE "notifications" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "recipient" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "actor" varchar NOT NULL,
+  "target" varchar NOT NULL,
+  "data" jsonb NOT NULL DEFAULT '{}',
+  "read_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "notifications" ("recipient", "created_at", "id");
+
+CREATE INDEX ON "notifications" ("recipient") WHERE "read_at" IS NULL;
+
+CREATE TABLE "device_tokens" (
+  "session_id" uuid PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "platform" varchar NOT NULL,
+  "token" varchar NOT NULL,
+  "locale" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "device_tokens_platform_check" CHECK ("platform" IN ('fcm', 'apns'))
+);
+
+CREATE INDEX ON "device_tokens" ("username");
+
+CREATE INDEX ON "device_tokens" ("token");
+
+ALTER TABLE "notifications" ADD FOREIGN KEY ("recipient") REFERENCES "users" ("username");
+
+ALTER TABLE "notifications" ADD FOREIGN KEY ("actor") REFERENCES "users" ("username");
+
+ALTER TABLE "device_tokens" ADD FOREIGN KEY ("session_id") REFERENCES "sessions" ("id");
+
+ALTER TABLE "device_tokens" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "notifications"."event_type" IS 'one of post_commented, post_liked or consultancy_booked';
+
+COMMENT ON COLUMN "notifications"."actor" IS 'the user whose action is notified';
+
+COMMENT ON COLUMN "notifications"."target" IS 'the resource of the event, like post:42';
+
+COMMENT ON COLUMN "notifications"."read_at" IS 'null while the notification is unread';
+
+COMMENT ON COLUMN "device_tokens"."token" IS 'a token moves to the session registering it last';
+
+COMMENT ON COLUMN "device_tokens"."platform" IS 'fcm for android, apns for ios';
+
+COMMENT ON COLUMN "device_tokens"."locale" IS 'the locale of the push texts, from the Accept-Language of the registration';
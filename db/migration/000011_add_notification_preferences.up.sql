@@ -0,0 +1,35 @@
+CREATE TABLE "notification_settings" (
+  "username" varchar PRIMARY KEY,
+  "time_zone" varchar NOT NULL DEFAULT 'UTC',
+  "quiet_hours_start" int,
+  "quiet_hours_end" int,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "notification_settings_quiet_hours_check" CHECK (
+    ("quiet_hours_start" IS NULL AND "quiet_hours_end" IS NULL) OR
+    ("quiet_hours_start" BETWEEN 0 AND 1439 AND
+     "quiet_hours_end" BETWEEN 0 AND 1439 AND
+     "quiet_hours_start" <> "quiet_hours_end")
+  )
+);
+
+CREATE TABLE "notification_preferences" (
+  "username" varchar NOT NULL,
+  "event_type" varchar NOT NULL,
+  "channel" varchar NOT NULL,
+  "enabled" boolean NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "event_type", "channel"),
+  CONSTRAINT "notification_preferences_channel_check" CHECK ("channel" IN ('in_app', 'push', 'email'))
+);
+
+ALTER TABLE "notification_settings" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "notification_preferences" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+COMMENT ON COLUMN "notification_settings"."time_zone" IS 'the IANA time zone the quiet hours are in';
+
+COMMENT ON COLUMN "notification_settings"."quiet_hours_start" IS 'minutes after midnight, the quiet hours wrap around midnight when they end earlier';
+
+COMMENT ON COLUMN "notification_preferences"."event_type" IS 'one of the notification types, or marketing';
+
+COMMENT ON COLUMN "notification_preferences"."enabled" IS 'the users without a row get the default of the event type';
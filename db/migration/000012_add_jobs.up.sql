@@ -0,0 +1,22 @@
+CREATE TABLE "jobs" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "kind" varchar NOT NULL,
+  "payload" jsonb NOT NULL DEFAULT '{}',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" int NOT NULL DEFAULT 0,
+  "max_attempts" int NOT NULL,
+  "run_at" timestamptz NOT NULL DEFAULT (now()),
+  "locked_at" timestamptz,
+  "last_error" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "jobs_status_check" CHECK ("status" IN ('pending', 'running', 'dead')),
+  CONSTRAINT "jobs_max_attempts_check" CHECK ("max_attempts" > 0)
+);
+
+CREATE INDEX ON "jobs" ("run_at", "id") WHERE "status" = 'pending';
+
+CREATE INDEX ON "jobs" ("locked_at") WHERE "status" = 'running';
+
+COMMENT ON COLUMN "jobs"."status" IS 'the finished jobs are deleted, the dead ones failed every attempt and wait for a manual retry';
+
+COMMENT ON COLUMN "jobs"."locked_at" IS 'a running job locked before the lease is taken over, since its worker is gone';
@@ -0,0 +1,13 @@
+CREATE TABLE "outbox_events" (
+  "id" BIGSERIAL PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "aggregate" varchar NOT NULL,
+  "payload" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "outbox_events"."id" IS 'the events are appended under a lock, so they commit in the order of their ids. They are deleted once the relay delivers them.';
+
+COMMENT ON COLUMN "outbox_events"."event_type" IS 'the domain event, like user.registered';
+
+COMMENT ON COLUMN "outbox_events"."aggregate" IS 'the resource of the event, like user:alice';
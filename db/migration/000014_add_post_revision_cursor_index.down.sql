@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "post_revisions_post_id_created_at_id_idx";
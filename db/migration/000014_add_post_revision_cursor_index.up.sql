@@ -0,0 +1 @@
+CREATE INDEX ON "post_revisions" ("post_id", "created_at", "id");
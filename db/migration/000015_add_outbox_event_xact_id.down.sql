@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "outbox_events_xact_id_id_idx";
+
+ALTER TABLE "outbox_events" DROP COLUMN IF EXISTS "xact_id";
+
+COMMENT ON COLUMN "outbox_events"."id" IS 'the events are appended under a lock, so they commit in the order of their ids. They are deleted once the relay delivers them.';
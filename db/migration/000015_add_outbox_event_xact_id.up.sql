@@ -0,0 +1,7 @@
+ALTER TABLE "outbox_events" ADD COLUMN "xact_id" bigint NOT NULL DEFAULT (pg_current_xact_id()::text::bigint);
+
+CREATE INDEX ON "outbox_events" ("xact_id", "id");
+
+COMMENT ON COLUMN "outbox_events"."id" IS 'the events are deleted once the relay delivers them';
+
+COMMENT ON COLUMN "outbox_events"."xact_id" IS 'the transaction appending the event. The relay delivers the events of the finished transactions only, in the order of (xact_id, id), so an event committed late is never passed over.';
@@ -0,0 +1,45 @@
+// Package migration embeds the SQL migrations of the database schema
+package migration
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+// FS holds the migration files, named like 000001_init_schema.up.sql
+//
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the version of the newest migration, which is the
+// version the database is expected to be at
+func LatestVersion() (uint, error) {
+	names, err := fs.Glob(FS, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, name := range names {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("invalid migration file name %s", name)
+		}
+
+		version, err := strconv.ParseUint(prefix, 10, 0)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s: %w", name, err)
+		}
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+
+	if latest == 0 {
+		return 0, fmt.Errorf("no migration found")
+	}
+	return latest, nil
+}
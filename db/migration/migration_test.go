@@ -0,0 +1,29 @@
+package migration
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	names, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+
+	version, err := LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, uint(len(names)), version)
+}
+
+func TestDownMigrations(t *testing.T) {
+	names, err := fs.Glob(FS, "*.up.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, names)
+
+	for _, name := range names {
+		_, err := fs.Stat(FS, strings.TrimSuffix(name, ".up.sql")+".down.sql")
+		require.NoError(t, err, "%s has no down migration", name)
+	}
+}
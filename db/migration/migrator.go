@@ -0,0 +1,135 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// lockID is the key of the advisory lock held while migrating on start, so
+// that only one of the replicas applies the migrations at a time
+const lockID int64 = 7_317_456_219_004_113
+
+// Status is the migration state of a database
+type Status struct {
+	Version uint
+	Latest  uint
+	Dirty   bool
+}
+
+// Migrator applies the embedded migrations to a postgres database
+type Migrator struct {
+	conn    *sql.DB
+	migrate *migrate.Migrate
+}
+
+// NewMigrator creates a new Migrator. It keeps a connection of the pool until
+// it is closed. The driver gets the connection rather than the pool, since the
+// driver closes whatever it is given.
+func NewMigrator(ctx context.Context, conn *sql.DB) (*Migrator, error) {
+	source, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	driverConn, err := conn.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithConnection(ctx, driverConn, &postgres.Config{})
+	if err != nil {
+		driverConn.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	return &Migrator{
+		conn:    conn,
+		migrate: m,
+	}, nil
+}
+
+// Up applies all the pending migrations
+func (migrator *Migrator) Up() error {
+	return ignoreNoChange(migrator.migrate.Up())
+}
+
+// Steps applies the next n migrations, or reverts the last -n migrations if n is negative
+func (migrator *Migrator) Steps(n int) error {
+	return ignoreNoChange(migrator.migrate.Steps(n))
+}
+
+// Goto migrates up or down to the given version
+func (migrator *Migrator) Goto(version uint) error {
+	return ignoreNoChange(migrator.migrate.Migrate(version))
+}
+
+// Status returns the current and the latest versions of the database
+func (migrator *Migrator) Status() (Status, error) {
+	latest, err := LatestVersion()
+	if err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := migrator.migrate.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+
+	return Status{
+		Version: version,
+		Latest:  latest,
+		Dirty:   dirty,
+	}, nil
+}
+
+// UpLocked applies all the pending migrations while holding an advisory lock.
+// Replicas starting at the same time wait for each other instead of racing.
+func (migrator *Migrator) UpLocked(ctx context.Context) (err error) {
+	// the advisory lock belongs to the session, so the same connection is
+	// needed to unlock it
+	lockConn, err := migrator.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer lockConn.Close()
+
+	_, err = lockConn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, unlockErr := lockConn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	return migrator.Up()
+}
+
+// Close releases the connection of the Migrator, the pool stays open
+func (migrator *Migrator) Close() error {
+	sourceErr, databaseErr := migrator.migrate.Close()
+	if sourceErr != nil {
+		return sourceErr
+	}
+	return databaseErr
+}
+
+func ignoreNoChange(err error) error {
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/asdsec/thenut/utils"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigratorCloseKeepsPool(t *testing.T) {
+	config, err := utils.LoadConfig("../..")
+	require.NoError(t, err)
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	migrator, err := NewMigrator(context.Background(), conn)
+	require.NoError(t, err)
+
+	_, err = migrator.Status()
+	require.NoError(t, err)
+	require.NoError(t, migrator.Close())
+
+	// the store keeps using the pool after the migrations on start
+	var one int
+	err = conn.QueryRowContext(context.Background(), "SELECT 1").Scan(&one)
+	require.NoError(t, err)
+	require.Equal(t, 1, one)
+}
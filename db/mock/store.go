@@ -7,6 +7,7 @@
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	db "github.com/asdsec/thenut/db/sqlc"
 	gomock "github.com/golang/mock/gomock"
@@ -51,6 +52,183 @@ func (mr *MockStoreMockRecorder) AddMerchantBalance(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMerchantBalance", reflect.TypeOf((*MockStore)(nil).AddMerchantBalance), arg0, arg1)
 }
 
+// AnonymizeUserComments mocks base method.
+func (m *MockStore) AnonymizeUserComments(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUserComments", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnonymizeUserComments indicates an expected call of AnonymizeUserComments.
+func (mr *MockStoreMockRecorder) AnonymizeUserComments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUserComments", reflect.TypeOf((*MockStore)(nil).AnonymizeUserComments), arg0, arg1)
+}
+
+// AnonymizeUserCustomers mocks base method.
+func (m *MockStore) AnonymizeUserCustomers(arg0 context.Context, arg1 string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUserCustomers", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUserCustomers indicates an expected call of AnonymizeUserCustomers.
+func (mr *MockStoreMockRecorder) AnonymizeUserCustomers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUserCustomers", reflect.TypeOf((*MockStore)(nil).AnonymizeUserCustomers), arg0, arg1)
+}
+
+// AnonymizeUserMerchants mocks base method.
+func (m *MockStore) AnonymizeUserMerchants(arg0 context.Context, arg1 string) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUserMerchants", arg0, arg1)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnonymizeUserMerchants indicates an expected call of AnonymizeUserMerchants.
+func (mr *MockStoreMockRecorder) AnonymizeUserMerchants(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUserMerchants", reflect.TypeOf((*MockStore)(nil).AnonymizeUserMerchants), arg0, arg1)
+}
+
+// AnonymizeUserNotifications mocks base method.
+func (m *MockStore) AnonymizeUserNotifications(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUserNotifications", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnonymizeUserNotifications indicates an expected call of AnonymizeUserNotifications.
+func (mr *MockStoreMockRecorder) AnonymizeUserNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUserNotifications", reflect.TypeOf((*MockStore)(nil).AnonymizeUserNotifications), arg0, arg1)
+}
+
+// AnonymizeUserPostRevisions mocks base method.
+func (m *MockStore) AnonymizeUserPostRevisions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUserPostRevisions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnonymizeUserPostRevisions indicates an expected call of AnonymizeUserPostRevisions.
+func (mr *MockStoreMockRecorder) AnonymizeUserPostRevisions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUserPostRevisions", reflect.TypeOf((*MockStore)(nil).AnonymizeUserPostRevisions), arg0, arg1)
+}
+
+// AppendOutboxEvent mocks base method.
+func (m *MockStore) AppendOutboxEvent(arg0 context.Context, arg1 db.AppendOutboxEventParams) (db.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AppendOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AppendOutboxEvent indicates an expected call of AppendOutboxEvent.
+func (mr *MockStoreMockRecorder) AppendOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AppendOutboxEvent", reflect.TypeOf((*MockStore)(nil).AppendOutboxEvent), arg0, arg1)
+}
+
+// AuditTx mocks base method.
+func (m *MockStore) AuditTx(arg0 context.Context, arg1 db.AuditTxParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditTx", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditTx indicates an expected call of AuditTx.
+func (mr *MockStoreMockRecorder) AuditTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditTx", reflect.TypeOf((*MockStore)(nil).AuditTx), arg0, arg1)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockStore) BlockUserSessions(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockStoreMockRecorder) BlockUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockStore)(nil).BlockUserSessions), arg0, arg1)
+}
+
+// ClaimJobs mocks base method.
+func (m *MockStore) ClaimJobs(arg0 context.Context, arg1 db.ClaimJobsParams) ([]db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimJobs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimJobs indicates an expected call of ClaimJobs.
+func (mr *MockStoreMockRecorder) ClaimJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimJobs", reflect.TypeOf((*MockStore)(nil).ClaimJobs), arg0, arg1)
+}
+
+// CompleteIdempotencyKey mocks base method.
+func (m *MockStore) CompleteIdempotencyKey(arg0 context.Context, arg1 db.CompleteIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteIdempotencyKey indicates an expected call of CompleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) CompleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CompleteIdempotencyKey), arg0, arg1)
+}
+
+// CompleteJob mocks base method.
+func (m *MockStore) CompleteJob(arg0 context.Context, arg1 db.CompleteJobParams) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteJob", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteJob indicates an expected call of CompleteJob.
+func (mr *MockStoreMockRecorder) CompleteJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteJob", reflect.TypeOf((*MockStore)(nil).CompleteJob), arg0, arg1)
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockStore) CountUnreadNotifications(arg0 context.Context, arg1 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockStoreMockRecorder) CountUnreadNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockStore)(nil).CountUnreadNotifications), arg0, arg1)
+}
+
 // CreateAppVersion mocks base method.
 func (m *MockStore) CreateAppVersion(arg0 context.Context, arg1 db.CreateAppVersionParams) (db.AppVersion, error) {
 	m.ctrl.T.Helper()
@@ -66,6 +244,21 @@ func (mr *MockStoreMockRecorder) CreateAppVersion(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAppVersion", reflect.TypeOf((*MockStore)(nil).CreateAppVersion), arg0, arg1)
 }
 
+// CreateAuditEvent mocks base method.
+func (m *MockStore) CreateAuditEvent(arg0 context.Context, arg1 db.CreateAuditEventParams) (db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuditEvent indicates an expected call of CreateAuditEvent.
+func (mr *MockStoreMockRecorder) CreateAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuditEvent", reflect.TypeOf((*MockStore)(nil).CreateAuditEvent), arg0, arg1)
+}
+
 // CreateComment mocks base method.
 func (m *MockStore) CreateComment(arg0 context.Context, arg1 db.CreateCommentParams) (db.Comment, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +274,21 @@ func (mr *MockStoreMockRecorder) CreateComment(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockStore)(nil).CreateComment), arg0, arg1)
 }
 
+// CreateCommentTx mocks base method.
+func (m *MockStore) CreateCommentTx(arg0 context.Context, arg1 db.CreateCommentParams) (db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCommentTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCommentTx indicates an expected call of CreateCommentTx.
+func (mr *MockStoreMockRecorder) CreateCommentTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCommentTx", reflect.TypeOf((*MockStore)(nil).CreateCommentTx), arg0, arg1)
+}
+
 // CreateConsultancy mocks base method.
 func (m *MockStore) CreateConsultancy(arg0 context.Context, arg1 db.CreateConsultancyParams) (db.Consultancy, error) {
 	m.ctrl.T.Helper()
@@ -96,6 +304,21 @@ func (mr *MockStoreMockRecorder) CreateConsultancy(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConsultancy", reflect.TypeOf((*MockStore)(nil).CreateConsultancy), arg0, arg1)
 }
 
+// CreateConsultancyTx mocks base method.
+func (m *MockStore) CreateConsultancyTx(arg0 context.Context, arg1 db.CreateConsultancyParams) (db.Consultancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateConsultancyTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Consultancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateConsultancyTx indicates an expected call of CreateConsultancyTx.
+func (mr *MockStoreMockRecorder) CreateConsultancyTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConsultancyTx", reflect.TypeOf((*MockStore)(nil).CreateConsultancyTx), arg0, arg1)
+}
+
 // CreateCustomer mocks base method.
 func (m *MockStore) CreateCustomer(arg0 context.Context, arg1 string) (db.Customer, error) {
 	m.ctrl.T.Helper()
@@ -111,6 +334,21 @@ func (mr *MockStoreMockRecorder) CreateCustomer(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCustomer", reflect.TypeOf((*MockStore)(nil).CreateCustomer), arg0, arg1)
 }
 
+// CreateIdempotencyKey mocks base method.
+func (m *MockStore) CreateIdempotencyKey(arg0 context.Context, arg1 db.CreateIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockStoreMockRecorder) CreateIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockStore)(nil).CreateIdempotencyKey), arg0, arg1)
+}
+
 // CreateMerchant mocks base method.
 func (m *MockStore) CreateMerchant(arg0 context.Context, arg1 db.CreateMerchantParams) (db.Merchant, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +364,21 @@ func (mr *MockStoreMockRecorder) CreateMerchant(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMerchant", reflect.TypeOf((*MockStore)(nil).CreateMerchant), arg0, arg1)
 }
 
+// CreateNotification mocks base method.
+func (m *MockStore) CreateNotification(arg0 context.Context, arg1 db.CreateNotificationParams) (db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", arg0, arg1)
+	ret0, _ := ret[0].(db.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification.
+func (mr *MockStoreMockRecorder) CreateNotification(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockStore)(nil).CreateNotification), arg0, arg1)
+}
+
 // CreatePost mocks base method.
 func (m *MockStore) CreatePost(arg0 context.Context, arg1 db.CreatePostParams) (db.Post, error) {
 	m.ctrl.T.Helper()
@@ -138,331 +391,1205 @@ func (m *MockStore) CreatePost(arg0 context.Context, arg1 db.CreatePostParams) (
 // CreatePost indicates an expected call of CreatePost.
 func (mr *MockStoreMockRecorder) CreatePost(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockStore)(nil).CreatePost), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockStore)(nil).CreatePost), arg0, arg1)
+}
+
+// CreatePostRevision mocks base method.
+func (m *MockStore) CreatePostRevision(arg0 context.Context, arg1 db.CreatePostRevisionParams) (db.PostRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePostRevision", arg0, arg1)
+	ret0, _ := ret[0].(db.PostRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePostRevision indicates an expected call of CreatePostRevision.
+func (mr *MockStoreMockRecorder) CreatePostRevision(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePostRevision", reflect.TypeOf((*MockStore)(nil).CreatePostRevision), arg0, arg1)
+}
+
+// CreatePostTx mocks base method.
+func (m *MockStore) CreatePostTx(arg0 context.Context, arg1 db.CreatePostParams) (db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePostTx", arg0, arg1)
+	ret0, _ := ret[0].(db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePostTx indicates an expected call of CreatePostTx.
+func (mr *MockStoreMockRecorder) CreatePostTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePostTx", reflect.TypeOf((*MockStore)(nil).CreatePostTx), arg0, arg1)
+}
+
+// CreateSession mocks base method.
+func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSession indicates an expected call of CreateSession.
+func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+}
+
+// CreateUser mocks base method.
+func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+}
+
+// CreateUserTx mocks base method.
+func (m *MockStore) CreateUserTx(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserTx indicates an expected call of CreateUserTx.
+func (mr *MockStoreMockRecorder) CreateUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserTx", reflect.TypeOf((*MockStore)(nil).CreateUserTx), arg0, arg1)
+}
+
+// DeleteComment mocks base method.
+func (m *MockStore) DeleteComment(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteComment", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteComment indicates an expected call of DeleteComment.
+func (mr *MockStoreMockRecorder) DeleteComment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockStore)(nil).DeleteComment), arg0, arg1)
+}
+
+// DeleteCustomer mocks base method.
+func (m *MockStore) DeleteCustomer(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCustomer", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCustomer indicates an expected call of DeleteCustomer.
+func (mr *MockStoreMockRecorder) DeleteCustomer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomer", reflect.TypeOf((*MockStore)(nil).DeleteCustomer), arg0, arg1)
+}
+
+// DeleteDeviceToken mocks base method.
+func (m *MockStore) DeleteDeviceToken(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeviceToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeviceToken indicates an expected call of DeleteDeviceToken.
+func (mr *MockStoreMockRecorder) DeleteDeviceToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeviceToken", reflect.TypeOf((*MockStore)(nil).DeleteDeviceToken), arg0, arg1)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteExpiredIdempotencyKeys(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteExpiredIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteExpiredIdempotencyKeys), arg0, arg1)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockStore) DeleteIdempotencyKey(arg0 context.Context, arg1 db.DeleteIdempotencyKeyParams) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockStoreMockRecorder) DeleteIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockStore)(nil).DeleteIdempotencyKey), arg0, arg1)
+}
+
+// DeleteMerchant mocks base method.
+func (m *MockStore) DeleteMerchant(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMerchant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMerchant indicates an expected call of DeleteMerchant.
+func (mr *MockStoreMockRecorder) DeleteMerchant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMerchant", reflect.TypeOf((*MockStore)(nil).DeleteMerchant), arg0, arg1)
+}
+
+// DeleteOutboxEvent mocks base method.
+func (m *MockStore) DeleteOutboxEvent(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutboxEvent indicates an expected call of DeleteOutboxEvent.
+func (mr *MockStoreMockRecorder) DeleteOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutboxEvent", reflect.TypeOf((*MockStore)(nil).DeleteOutboxEvent), arg0, arg1)
+}
+
+// DeleteOwnerPosts mocks base method.
+func (m *MockStore) DeleteOwnerPosts(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOwnerPosts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOwnerPosts indicates an expected call of DeleteOwnerPosts.
+func (mr *MockStoreMockRecorder) DeleteOwnerPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOwnerPosts", reflect.TypeOf((*MockStore)(nil).DeleteOwnerPosts), arg0, arg1)
+}
+
+// DeletePost mocks base method.
+func (m *MockStore) DeletePost(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePost", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePost indicates an expected call of DeletePost.
+func (mr *MockStoreMockRecorder) DeletePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockStore)(nil).DeletePost), arg0, arg1)
+}
+
+// DeleteUser mocks base method.
+func (m *MockStore) DeleteUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+}
+
+// DeleteUserDeviceTokens mocks base method.
+func (m *MockStore) DeleteUserDeviceTokens(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserDeviceTokens", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserDeviceTokens indicates an expected call of DeleteUserDeviceTokens.
+func (mr *MockStoreMockRecorder) DeleteUserDeviceTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserDeviceTokens", reflect.TypeOf((*MockStore)(nil).DeleteUserDeviceTokens), arg0, arg1)
+}
+
+// DeleteUserIdempotencyKeys mocks base method.
+func (m *MockStore) DeleteUserIdempotencyKeys(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserIdempotencyKeys", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserIdempotencyKeys indicates an expected call of DeleteUserIdempotencyKeys.
+func (mr *MockStoreMockRecorder) DeleteUserIdempotencyKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserIdempotencyKeys", reflect.TypeOf((*MockStore)(nil).DeleteUserIdempotencyKeys), arg0, arg1)
+}
+
+// DeleteUserNotificationPreferences mocks base method.
+func (m *MockStore) DeleteUserNotificationPreferences(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserNotificationPreferences indicates an expected call of DeleteUserNotificationPreferences.
+func (mr *MockStoreMockRecorder) DeleteUserNotificationPreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserNotificationPreferences", reflect.TypeOf((*MockStore)(nil).DeleteUserNotificationPreferences), arg0, arg1)
+}
+
+// DeleteUserNotificationSettings mocks base method.
+func (m *MockStore) DeleteUserNotificationSettings(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserNotificationSettings", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserNotificationSettings indicates an expected call of DeleteUserNotificationSettings.
+func (mr *MockStoreMockRecorder) DeleteUserNotificationSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserNotificationSettings", reflect.TypeOf((*MockStore)(nil).DeleteUserNotificationSettings), arg0, arg1)
+}
+
+// DeleteUserNotifications mocks base method.
+func (m *MockStore) DeleteUserNotifications(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserNotifications", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserNotifications indicates an expected call of DeleteUserNotifications.
+func (mr *MockStoreMockRecorder) DeleteUserNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserNotifications", reflect.TypeOf((*MockStore)(nil).DeleteUserNotifications), arg0, arg1)
+}
+
+// DeleteUserSessions mocks base method.
+func (m *MockStore) DeleteUserSessions(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserSessions", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserSessions indicates an expected call of DeleteUserSessions.
+func (mr *MockStoreMockRecorder) DeleteUserSessions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserSessions", reflect.TypeOf((*MockStore)(nil).DeleteUserSessions), arg0, arg1)
+}
+
+// DeleteUserTx mocks base method.
+func (m *MockStore) DeleteUserTx(arg0 context.Context, arg1 string) (db.DeleteUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.DeleteUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUserTx indicates an expected call of DeleteUserTx.
+func (mr *MockStoreMockRecorder) DeleteUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserTx", reflect.TypeOf((*MockStore)(nil).DeleteUserTx), arg0, arg1)
+}
+
+// EnqueueJob mocks base method.
+func (m *MockStore) EnqueueJob(arg0 context.Context, arg1 db.EnqueueJobParams) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnqueueJob", arg0, arg1)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnqueueJob indicates an expected call of EnqueueJob.
+func (mr *MockStoreMockRecorder) EnqueueJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnqueueJob", reflect.TypeOf((*MockStore)(nil).EnqueueJob), arg0, arg1)
+}
+
+// ExportUserTx mocks base method.
+func (m *MockStore) ExportUserTx(arg0 context.Context, arg1 string) (db.ExportUserTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserTx", arg0, arg1)
+	ret0, _ := ret[0].(db.ExportUserTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExportUserTx indicates an expected call of ExportUserTx.
+func (mr *MockStoreMockRecorder) ExportUserTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserTx", reflect.TypeOf((*MockStore)(nil).ExportUserTx), arg0, arg1)
+}
+
+// GetAppVersion mocks base method.
+func (m *MockStore) GetAppVersion(arg0 context.Context, arg1 string) (db.AppVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppVersion", arg0, arg1)
+	ret0, _ := ret[0].(db.AppVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppVersion indicates an expected call of GetAppVersion.
+func (mr *MockStoreMockRecorder) GetAppVersion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersion", reflect.TypeOf((*MockStore)(nil).GetAppVersion), arg0, arg1)
+}
+
+// GetComment mocks base method.
+func (m *MockStore) GetComment(arg0 context.Context, arg1 int64) (db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetComment", arg0, arg1)
+	ret0, _ := ret[0].(db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetComment indicates an expected call of GetComment.
+func (mr *MockStoreMockRecorder) GetComment(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComment", reflect.TypeOf((*MockStore)(nil).GetComment), arg0, arg1)
+}
+
+// GetConsultancy mocks base method.
+func (m *MockStore) GetConsultancy(arg0 context.Context, arg1 int64) (db.Consultancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsultancy", arg0, arg1)
+	ret0, _ := ret[0].(db.Consultancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsultancy indicates an expected call of GetConsultancy.
+func (mr *MockStoreMockRecorder) GetConsultancy(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsultancy", reflect.TypeOf((*MockStore)(nil).GetConsultancy), arg0, arg1)
+}
+
+// GetCustomer mocks base method.
+func (m *MockStore) GetCustomer(arg0 context.Context, arg1 int64) (db.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCustomer", arg0, arg1)
+	ret0, _ := ret[0].(db.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCustomer indicates an expected call of GetCustomer.
+func (mr *MockStoreMockRecorder) GetCustomer(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomer", reflect.TypeOf((*MockStore)(nil).GetCustomer), arg0, arg1)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockStore) GetIdempotencyKey(arg0 context.Context, arg1 db.GetIdempotencyKeyParams) (db.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", arg0, arg1)
+	ret0, _ := ret[0].(db.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockStoreMockRecorder) GetIdempotencyKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockStore)(nil).GetIdempotencyKey), arg0, arg1)
+}
+
+// GetMerchant mocks base method.
+func (m *MockStore) GetMerchant(arg0 context.Context, arg1 int64) (db.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMerchant", arg0, arg1)
+	ret0, _ := ret[0].(db.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMerchant indicates an expected call of GetMerchant.
+func (mr *MockStoreMockRecorder) GetMerchant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchant", reflect.TypeOf((*MockStore)(nil).GetMerchant), arg0, arg1)
+}
+
+// GetNotificationSettings mocks base method.
+func (m *MockStore) GetNotificationSettings(arg0 context.Context, arg1 string) (db.NotificationSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationSettings", arg0, arg1)
+	ret0, _ := ret[0].(db.NotificationSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationSettings indicates an expected call of GetNotificationSettings.
+func (mr *MockStoreMockRecorder) GetNotificationSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationSettings", reflect.TypeOf((*MockStore)(nil).GetNotificationSettings), arg0, arg1)
+}
+
+// GetPost mocks base method.
+func (m *MockStore) GetPost(arg0 context.Context, arg1 int64) (db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPost", arg0, arg1)
+	ret0, _ := ret[0].(db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPost indicates an expected call of GetPost.
+func (mr *MockStoreMockRecorder) GetPost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockStore)(nil).GetPost), arg0, arg1)
+}
+
+// GetPostForUpdate mocks base method.
+func (m *MockStore) GetPostForUpdate(arg0 context.Context, arg1 int64) (db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostForUpdate", arg0, arg1)
+	ret0, _ := ret[0].(db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostForUpdate indicates an expected call of GetPostForUpdate.
+func (mr *MockStoreMockRecorder) GetPostForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostForUpdate", reflect.TypeOf((*MockStore)(nil).GetPostForUpdate), arg0, arg1)
+}
+
+// GetSchemaVersion mocks base method.
+func (m *MockStore) GetSchemaVersion(arg0 context.Context) (db.SchemaVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSchemaVersion", arg0)
+	ret0, _ := ret[0].(db.SchemaVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSchemaVersion indicates an expected call of GetSchemaVersion.
+func (mr *MockStoreMockRecorder) GetSchemaVersion(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchemaVersion", reflect.TypeOf((*MockStore)(nil).GetSchemaVersion), arg0)
+}
+
+// GetSession mocks base method.
+func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
+	ret0, _ := ret[0].(db.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSession indicates an expected call of GetSession.
+func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+}
+
+// GetUser mocks base method.
+func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+}
+
+// KillJob mocks base method.
+func (m *MockStore) KillJob(arg0 context.Context, arg1 db.KillJobParams) (db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KillJob", arg0, arg1)
+	ret0, _ := ret[0].(db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KillJob indicates an expected call of KillJob.
+func (mr *MockStoreMockRecorder) KillJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KillJob", reflect.TypeOf((*MockStore)(nil).KillJob), arg0, arg1)
+}
+
+// KillStaleJobs mocks base method.
+func (m *MockStore) KillStaleJobs(arg0 context.Context, arg1 db.KillStaleJobsParams) ([]db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "KillStaleJobs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// KillStaleJobs indicates an expected call of KillStaleJobs.
+func (mr *MockStoreMockRecorder) KillStaleJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "KillStaleJobs", reflect.TypeOf((*MockStore)(nil).KillStaleJobs), arg0, arg1)
+}
+
+// ListAllUserDeviceTokens mocks base method.
+func (m *MockStore) ListAllUserDeviceTokens(arg0 context.Context, arg1 string) ([]db.DeviceToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllUserDeviceTokens", arg0, arg1)
+	ret0, _ := ret[0].([]db.DeviceToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllUserDeviceTokens indicates an expected call of ListAllUserDeviceTokens.
+func (mr *MockStoreMockRecorder) ListAllUserDeviceTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllUserDeviceTokens", reflect.TypeOf((*MockStore)(nil).ListAllUserDeviceTokens), arg0, arg1)
+}
+
+// ListAppVersions mocks base method.
+func (m *MockStore) ListAppVersions(arg0 context.Context) ([]db.AppVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAppVersions", arg0)
+	ret0, _ := ret[0].([]db.AppVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAppVersions indicates an expected call of ListAppVersions.
+func (mr *MockStoreMockRecorder) ListAppVersions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppVersions", reflect.TypeOf((*MockStore)(nil).ListAppVersions), arg0)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockStore) ListAuditEvents(arg0 context.Context, arg1 db.ListAuditEventsParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockStoreMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockStore)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListAuditEventsByCursor mocks base method.
+func (m *MockStore) ListAuditEventsByCursor(arg0 context.Context, arg1 db.ListAuditEventsByCursorParams) ([]db.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEventsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEventsByCursor indicates an expected call of ListAuditEventsByCursor.
+func (mr *MockStoreMockRecorder) ListAuditEventsByCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEventsByCursor", reflect.TypeOf((*MockStore)(nil).ListAuditEventsByCursor), arg0, arg1)
+}
+
+// ListCommentsByOwner mocks base method.
+func (m *MockStore) ListCommentsByOwner(arg0 context.Context, arg1 string) ([]db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCommentsByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCommentsByOwner indicates an expected call of ListCommentsByOwner.
+func (mr *MockStoreMockRecorder) ListCommentsByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCommentsByOwner", reflect.TypeOf((*MockStore)(nil).ListCommentsByOwner), arg0, arg1)
+}
+
+// ListConsultancies mocks base method.
+func (m *MockStore) ListConsultancies(arg0 context.Context, arg1 db.ListConsultanciesParams) ([]db.Consultancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConsultancies", arg0, arg1)
+	ret0, _ := ret[0].([]db.Consultancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConsultancies indicates an expected call of ListConsultancies.
+func (mr *MockStoreMockRecorder) ListConsultancies(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConsultancies", reflect.TypeOf((*MockStore)(nil).ListConsultancies), arg0, arg1)
+}
+
+// ListConsultanciesByOwner mocks base method.
+func (m *MockStore) ListConsultanciesByOwner(arg0 context.Context, arg1 string) ([]db.Consultancy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListConsultanciesByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]db.Consultancy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListConsultanciesByOwner indicates an expected call of ListConsultanciesByOwner.
+func (mr *MockStoreMockRecorder) ListConsultanciesByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConsultanciesByOwner", reflect.TypeOf((*MockStore)(nil).ListConsultanciesByOwner), arg0, arg1)
+}
+
+// ListCustomersByOwner mocks base method.
+func (m *MockStore) ListCustomersByOwner(arg0 context.Context, arg1 string) ([]db.Customer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCustomersByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]db.Customer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCustomersByOwner indicates an expected call of ListCustomersByOwner.
+func (mr *MockStoreMockRecorder) ListCustomersByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCustomersByOwner", reflect.TypeOf((*MockStore)(nil).ListCustomersByOwner), arg0, arg1)
+}
+
+// ListDeadJobs mocks base method.
+func (m *MockStore) ListDeadJobs(arg0 context.Context, arg1 db.ListDeadJobsParams) ([]db.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeadJobs", arg0, arg1)
+	ret0, _ := ret[0].([]db.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeadJobs indicates an expected call of ListDeadJobs.
+func (mr *MockStoreMockRecorder) ListDeadJobs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeadJobs", reflect.TypeOf((*MockStore)(nil).ListDeadJobs), arg0, arg1)
+}
+
+// ListMerchantComments mocks base method.
+func (m *MockStore) ListMerchantComments(arg0 context.Context, arg1 db.ListMerchantCommentsParams) ([]db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantComments", arg0, arg1)
+	ret0, _ := ret[0].([]db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantComments indicates an expected call of ListMerchantComments.
+func (mr *MockStoreMockRecorder) ListMerchantComments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantComments", reflect.TypeOf((*MockStore)(nil).ListMerchantComments), arg0, arg1)
+}
+
+// ListMerchantPosts mocks base method.
+func (m *MockStore) ListMerchantPosts(arg0 context.Context, arg1 db.ListMerchantPostsParams) ([]db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantPosts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantPosts indicates an expected call of ListMerchantPosts.
+func (mr *MockStoreMockRecorder) ListMerchantPosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantPosts", reflect.TypeOf((*MockStore)(nil).ListMerchantPosts), arg0, arg1)
+}
+
+// ListMerchantPostsByCursor mocks base method.
+func (m *MockStore) ListMerchantPostsByCursor(arg0 context.Context, arg1 db.ListMerchantPostsByCursorParams) ([]db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantPostsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantPostsByCursor indicates an expected call of ListMerchantPostsByCursor.
+func (mr *MockStoreMockRecorder) ListMerchantPostsByCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantPostsByCursor", reflect.TypeOf((*MockStore)(nil).ListMerchantPostsByCursor), arg0, arg1)
+}
+
+// ListMerchants mocks base method.
+func (m *MockStore) ListMerchants(arg0 context.Context, arg1 db.ListMerchantsParams) ([]db.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchants", arg0, arg1)
+	ret0, _ := ret[0].([]db.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchants indicates an expected call of ListMerchants.
+func (mr *MockStoreMockRecorder) ListMerchants(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchants", reflect.TypeOf((*MockStore)(nil).ListMerchants), arg0, arg1)
+}
+
+// ListMerchantsByCursor mocks base method.
+func (m *MockStore) ListMerchantsByCursor(arg0 context.Context, arg1 db.ListMerchantsByCursorParams) ([]db.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantsByCursor indicates an expected call of ListMerchantsByCursor.
+func (mr *MockStoreMockRecorder) ListMerchantsByCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantsByCursor", reflect.TypeOf((*MockStore)(nil).ListMerchantsByCursor), arg0, arg1)
+}
+
+// ListMerchantsByOwner mocks base method.
+func (m *MockStore) ListMerchantsByOwner(arg0 context.Context, arg1 string) ([]db.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMerchantsByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]db.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMerchantsByOwner indicates an expected call of ListMerchantsByOwner.
+func (mr *MockStoreMockRecorder) ListMerchantsByOwner(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantsByOwner", reflect.TypeOf((*MockStore)(nil).ListMerchantsByOwner), arg0, arg1)
+}
+
+// ListNotificationPreferences mocks base method.
+func (m *MockStore) ListNotificationPreferences(arg0 context.Context, arg1 string) ([]db.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotificationPreferences", arg0, arg1)
+	ret0, _ := ret[0].([]db.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotificationPreferences indicates an expected call of ListNotificationPreferences.
+func (mr *MockStoreMockRecorder) ListNotificationPreferences(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotificationPreferences", reflect.TypeOf((*MockStore)(nil).ListNotificationPreferences), arg0, arg1)
+}
+
+// ListNotifications mocks base method.
+func (m *MockStore) ListNotifications(arg0 context.Context, arg1 db.ListNotificationsParams) ([]db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]db.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications.
+func (mr *MockStoreMockRecorder) ListNotifications(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockStore)(nil).ListNotifications), arg0, arg1)
+}
+
+// ListNotificationsByCursor mocks base method.
+func (m *MockStore) ListNotificationsByCursor(arg0 context.Context, arg1 db.ListNotificationsByCursorParams) ([]db.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotificationsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotificationsByCursor indicates an expected call of ListNotificationsByCursor.
+func (mr *MockStoreMockRecorder) ListNotificationsByCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotificationsByCursor", reflect.TypeOf((*MockStore)(nil).ListNotificationsByCursor), arg0, arg1)
+}
+
+// ListOutboxEventsForUpdate mocks base method.
+func (m *MockStore) ListOutboxEventsForUpdate(arg0 context.Context, arg1 int32) ([]db.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOutboxEventsForUpdate", arg0, arg1)
+	ret0, _ := ret[0].([]db.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOutboxEventsForUpdate indicates an expected call of ListOutboxEventsForUpdate.
+func (mr *MockStoreMockRecorder) ListOutboxEventsForUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOutboxEventsForUpdate", reflect.TypeOf((*MockStore)(nil).ListOutboxEventsForUpdate), arg0, arg1)
+}
+
+// ListPostComments mocks base method.
+func (m *MockStore) ListPostComments(arg0 context.Context, arg1 db.ListPostCommentsParams) ([]db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPostComments", arg0, arg1)
+	ret0, _ := ret[0].([]db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPostComments indicates an expected call of ListPostComments.
+func (mr *MockStoreMockRecorder) ListPostComments(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostComments", reflect.TypeOf((*MockStore)(nil).ListPostComments), arg0, arg1)
+}
+
+// ListPostCommentsByCursor mocks base method.
+func (m *MockStore) ListPostCommentsByCursor(arg0 context.Context, arg1 db.ListPostCommentsByCursorParams) ([]db.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPostCommentsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.Comment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPostCommentsByCursor indicates an expected call of ListPostCommentsByCursor.
+func (mr *MockStoreMockRecorder) ListPostCommentsByCursor(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostCommentsByCursor", reflect.TypeOf((*MockStore)(nil).ListPostCommentsByCursor), arg0, arg1)
 }
 
-// CreateSession mocks base method.
-func (m *MockStore) CreateSession(arg0 context.Context, arg1 db.CreateSessionParams) error {
+// ListPostRevisions mocks base method.
+func (m *MockStore) ListPostRevisions(arg0 context.Context, arg1 db.ListPostRevisionsParams) ([]db.PostRevision, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSession", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListPostRevisions", arg0, arg1)
+	ret0, _ := ret[0].([]db.PostRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// CreateSession indicates an expected call of CreateSession.
-func (mr *MockStoreMockRecorder) CreateSession(arg0, arg1 interface{}) *gomock.Call {
+// ListPostRevisions indicates an expected call of ListPostRevisions.
+func (mr *MockStoreMockRecorder) ListPostRevisions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockStore)(nil).CreateSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostRevisions", reflect.TypeOf((*MockStore)(nil).ListPostRevisions), arg0, arg1)
 }
 
-// CreateUser mocks base method.
-func (m *MockStore) CreateUser(arg0 context.Context, arg1 db.CreateUserParams) (db.User, error) {
+// ListPostRevisionsByCursor mocks base method.
+func (m *MockStore) ListPostRevisionsByCursor(arg0 context.Context, arg1 db.ListPostRevisionsByCursorParams) ([]db.PostRevision, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "ListPostRevisionsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.PostRevision)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateUser indicates an expected call of CreateUser.
-func (mr *MockStoreMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.Call {
+// ListPostRevisionsByCursor indicates an expected call of ListPostRevisionsByCursor.
+func (mr *MockStoreMockRecorder) ListPostRevisionsByCursor(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockStore)(nil).CreateUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostRevisionsByCursor", reflect.TypeOf((*MockStore)(nil).ListPostRevisionsByCursor), arg0, arg1)
 }
 
-// DeleteComment mocks base method.
-func (m *MockStore) DeleteComment(arg0 context.Context, arg1 int64) error {
+// ListPosts mocks base method.
+func (m *MockStore) ListPosts(arg0 context.Context, arg1 db.ListPostsParams) ([]db.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteComment", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListPosts", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteComment indicates an expected call of DeleteComment.
-func (mr *MockStoreMockRecorder) DeleteComment(arg0, arg1 interface{}) *gomock.Call {
+// ListPosts indicates an expected call of ListPosts.
+func (mr *MockStoreMockRecorder) ListPosts(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockStore)(nil).DeleteComment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPosts", reflect.TypeOf((*MockStore)(nil).ListPosts), arg0, arg1)
 }
 
-// DeleteCustomer mocks base method.
-func (m *MockStore) DeleteCustomer(arg0 context.Context, arg1 int64) error {
+// ListPostsByCursor mocks base method.
+func (m *MockStore) ListPostsByCursor(arg0 context.Context, arg1 db.ListPostsByCursorParams) ([]db.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteCustomer", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListPostsByCursor", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteCustomer indicates an expected call of DeleteCustomer.
-func (mr *MockStoreMockRecorder) DeleteCustomer(arg0, arg1 interface{}) *gomock.Call {
+// ListPostsByCursor indicates an expected call of ListPostsByCursor.
+func (mr *MockStoreMockRecorder) ListPostsByCursor(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCustomer", reflect.TypeOf((*MockStore)(nil).DeleteCustomer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostsByCursor", reflect.TypeOf((*MockStore)(nil).ListPostsByCursor), arg0, arg1)
 }
 
-// DeleteMerchant mocks base method.
-func (m *MockStore) DeleteMerchant(arg0 context.Context, arg1 int64) error {
+// ListPostsByOwner mocks base method.
+func (m *MockStore) ListPostsByOwner(arg0 context.Context, arg1 string) ([]db.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteMerchant", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListPostsByOwner", arg0, arg1)
+	ret0, _ := ret[0].([]db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteMerchant indicates an expected call of DeleteMerchant.
-func (mr *MockStoreMockRecorder) DeleteMerchant(arg0, arg1 interface{}) *gomock.Call {
+// ListPostsByOwner indicates an expected call of ListPostsByOwner.
+func (mr *MockStoreMockRecorder) ListPostsByOwner(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMerchant", reflect.TypeOf((*MockStore)(nil).DeleteMerchant), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostsByOwner", reflect.TypeOf((*MockStore)(nil).ListPostsByOwner), arg0, arg1)
 }
 
-// DeletePost mocks base method.
-func (m *MockStore) DeletePost(arg0 context.Context, arg1 int64) error {
+// ListUserAuditEvents mocks base method.
+func (m *MockStore) ListUserAuditEvents(arg0 context.Context, arg1 string) ([]db.AuditEvent, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePost", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListUserAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]db.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeletePost indicates an expected call of DeletePost.
-func (mr *MockStoreMockRecorder) DeletePost(arg0, arg1 interface{}) *gomock.Call {
+// ListUserAuditEvents indicates an expected call of ListUserAuditEvents.
+func (mr *MockStoreMockRecorder) ListUserAuditEvents(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockStore)(nil).DeletePost), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserAuditEvents", reflect.TypeOf((*MockStore)(nil).ListUserAuditEvents), arg0, arg1)
 }
 
-// DeleteUser mocks base method.
-func (m *MockStore) DeleteUser(arg0 context.Context, arg1 string) error {
+// ListUserDeviceTokens mocks base method.
+func (m *MockStore) ListUserDeviceTokens(arg0 context.Context, arg1 string) ([]db.DeviceToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteUser", arg0, arg1)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "ListUserDeviceTokens", arg0, arg1)
+	ret0, _ := ret[0].([]db.DeviceToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// DeleteUser indicates an expected call of DeleteUser.
-func (mr *MockStoreMockRecorder) DeleteUser(arg0, arg1 interface{}) *gomock.Call {
+// ListUserDeviceTokens indicates an expected call of ListUserDeviceTokens.
+func (mr *MockStoreMockRecorder) ListUserDeviceTokens(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStore)(nil).DeleteUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserDeviceTokens", reflect.TypeOf((*MockStore)(nil).ListUserDeviceTokens), arg0, arg1)
 }
 
-// GetAppVersion mocks base method.
-func (m *MockStore) GetAppVersion(arg0 context.Context, arg1 string) (db.AppVersion, error) {
+// ListUserNotifications mocks base method.
+func (m *MockStore) ListUserNotifications(arg0 context.Context, arg1 string) ([]db.Notification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAppVersion", arg0, arg1)
-	ret0, _ := ret[0].(db.AppVersion)
+	ret := m.ctrl.Call(m, "ListUserNotifications", arg0, arg1)
+	ret0, _ := ret[0].([]db.Notification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAppVersion indicates an expected call of GetAppVersion.
-func (mr *MockStoreMockRecorder) GetAppVersion(arg0, arg1 interface{}) *gomock.Call {
+// ListUserNotifications indicates an expected call of ListUserNotifications.
+func (mr *MockStoreMockRecorder) ListUserNotifications(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppVersion", reflect.TypeOf((*MockStore)(nil).GetAppVersion), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserNotifications", reflect.TypeOf((*MockStore)(nil).ListUserNotifications), arg0, arg1)
 }
 
-// GetComment mocks base method.
-func (m *MockStore) GetComment(arg0 context.Context, arg1 int64) (db.Comment, error) {
+// ListUserSessions mocks base method.
+func (m *MockStore) ListUserSessions(arg0 context.Context, arg1 string) ([]db.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetComment", arg0, arg1)
-	ret0, _ := ret[0].(db.Comment)
+	ret := m.ctrl.Call(m, "ListUserSessions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetComment indicates an expected call of GetComment.
-func (mr *MockStoreMockRecorder) GetComment(arg0, arg1 interface{}) *gomock.Call {
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockStoreMockRecorder) ListUserSessions(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetComment", reflect.TypeOf((*MockStore)(nil).GetComment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockStore)(nil).ListUserSessions), arg0, arg1)
 }
 
-// GetConsultancy mocks base method.
-func (m *MockStore) GetConsultancy(arg0 context.Context, arg1 int64) (db.Consultancy, error) {
+// ListUsersDueForDeletion mocks base method.
+func (m *MockStore) ListUsersDueForDeletion(arg0 context.Context, arg1 db.ListUsersDueForDeletionParams) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetConsultancy", arg0, arg1)
-	ret0, _ := ret[0].(db.Consultancy)
+	ret := m.ctrl.Call(m, "ListUsersDueForDeletion", arg0, arg1)
+	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetConsultancy indicates an expected call of GetConsultancy.
-func (mr *MockStoreMockRecorder) GetConsultancy(arg0, arg1 interface{}) *gomock.Call {
+// ListUsersDueForDeletion indicates an expected call of ListUsersDueForDeletion.
+func (mr *MockStoreMockRecorder) ListUsersDueForDeletion(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsultancy", reflect.TypeOf((*MockStore)(nil).GetConsultancy), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersDueForDeletion", reflect.TypeOf((*MockStore)(nil).ListUsersDueForDeletion), arg0, arg1)
 }
 
-// GetCustomer mocks base method.
-func (m *MockStore) GetCustomer(arg0 context.Context, arg1 int64) (db.Customer, error) {
+// MarkAllNotificationsRead mocks base method.
+func (m *MockStore) MarkAllNotificationsRead(arg0 context.Context, arg1 string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetCustomer", arg0, arg1)
-	ret0, _ := ret[0].(db.Customer)
+	ret := m.ctrl.Call(m, "MarkAllNotificationsRead", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetCustomer indicates an expected call of GetCustomer.
-func (mr *MockStoreMockRecorder) GetCustomer(arg0, arg1 interface{}) *gomock.Call {
+// MarkAllNotificationsRead indicates an expected call of MarkAllNotificationsRead.
+func (mr *MockStoreMockRecorder) MarkAllNotificationsRead(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCustomer", reflect.TypeOf((*MockStore)(nil).GetCustomer), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllNotificationsRead", reflect.TypeOf((*MockStore)(nil).MarkAllNotificationsRead), arg0, arg1)
 }
 
-// GetMerchant mocks base method.
-func (m *MockStore) GetMerchant(arg0 context.Context, arg1 int64) (db.Merchant, error) {
+// MarkNotificationRead mocks base method.
+func (m *MockStore) MarkNotificationRead(arg0 context.Context, arg1 db.MarkNotificationReadParams) (db.Notification, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMerchant", arg0, arg1)
-	ret0, _ := ret[0].(db.Merchant)
+	ret := m.ctrl.Call(m, "MarkNotificationRead", arg0, arg1)
+	ret0, _ := ret[0].(db.Notification)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetMerchant indicates an expected call of GetMerchant.
-func (mr *MockStoreMockRecorder) GetMerchant(arg0, arg1 interface{}) *gomock.Call {
+// MarkNotificationRead indicates an expected call of MarkNotificationRead.
+func (mr *MockStoreMockRecorder) MarkNotificationRead(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerchant", reflect.TypeOf((*MockStore)(nil).GetMerchant), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationRead", reflect.TypeOf((*MockStore)(nil).MarkNotificationRead), arg0, arg1)
 }
 
-// GetPost mocks base method.
-func (m *MockStore) GetPost(arg0 context.Context, arg1 int64) (db.Post, error) {
+// Ping mocks base method.
+func (m *MockStore) Ping(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPost", arg0, arg1)
-	ret0, _ := ret[0].(db.Post)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Ping", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetPost indicates an expected call of GetPost.
-func (mr *MockStoreMockRecorder) GetPost(arg0, arg1 interface{}) *gomock.Call {
+// Ping indicates an expected call of Ping.
+func (mr *MockStoreMockRecorder) Ping(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockStore)(nil).GetPost), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockStore)(nil).Ping), arg0)
 }
 
-// GetSession mocks base method.
-func (m *MockStore) GetSession(arg0 context.Context, arg1 uuid.UUID) (db.Session, error) {
+// RelayOutboxTx mocks base method.
+func (m *MockStore) RelayOutboxTx(arg0 context.Context, arg1 db.RelayOutboxTxParams) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetSession", arg0, arg1)
-	ret0, _ := ret[0].(db.Session)
+	ret := m.ctrl.Call(m, "RelayOutboxTx", arg0, arg1)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetSession indicates an expected call of GetSession.
-func (mr *MockStoreMockRecorder) GetSession(arg0, arg1 interface{}) *gomock.Call {
+// RelayOutboxTx indicates an expected call of RelayOutboxTx.
+func (mr *MockStoreMockRecorder) RelayOutboxTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockStore)(nil).GetSession), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayOutboxTx", reflect.TypeOf((*MockStore)(nil).RelayOutboxTx), arg0, arg1)
 }
 
-// GetUser mocks base method.
-func (m *MockStore) GetUser(arg0 context.Context, arg1 string) (db.User, error) {
+// RequeueDeadJob mocks base method.
+func (m *MockStore) RequeueDeadJob(arg0 context.Context, arg1 int64) (db.Job, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUser", arg0, arg1)
-	ret0, _ := ret[0].(db.User)
+	ret := m.ctrl.Call(m, "RequeueDeadJob", arg0, arg1)
+	ret0, _ := ret[0].(db.Job)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUser indicates an expected call of GetUser.
-func (mr *MockStoreMockRecorder) GetUser(arg0, arg1 interface{}) *gomock.Call {
+// RequeueDeadJob indicates an expected call of RequeueDeadJob.
+func (mr *MockStoreMockRecorder) RequeueDeadJob(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockStore)(nil).GetUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadJob", reflect.TypeOf((*MockStore)(nil).RequeueDeadJob), arg0, arg1)
 }
 
-// ListAppVersions mocks base method.
-func (m *MockStore) ListAppVersions(arg0 context.Context) ([]db.AppVersion, error) {
+// RetryJob mocks base method.
+func (m *MockStore) RetryJob(arg0 context.Context, arg1 db.RetryJobParams) (db.Job, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListAppVersions", arg0)
-	ret0, _ := ret[0].([]db.AppVersion)
+	ret := m.ctrl.Call(m, "RetryJob", arg0, arg1)
+	ret0, _ := ret[0].(db.Job)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListAppVersions indicates an expected call of ListAppVersions.
-func (mr *MockStoreMockRecorder) ListAppVersions(arg0 interface{}) *gomock.Call {
+// RetryJob indicates an expected call of RetryJob.
+func (mr *MockStoreMockRecorder) RetryJob(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAppVersions", reflect.TypeOf((*MockStore)(nil).ListAppVersions), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryJob", reflect.TypeOf((*MockStore)(nil).RetryJob), arg0, arg1)
 }
 
-// ListConsultancies mocks base method.
-func (m *MockStore) ListConsultancies(arg0 context.Context, arg1 db.ListConsultanciesParams) ([]db.Consultancy, error) {
+// ScheduleUserDeletion mocks base method.
+func (m *MockStore) ScheduleUserDeletion(arg0 context.Context, arg1 db.ScheduleUserDeletionParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListConsultancies", arg0, arg1)
-	ret0, _ := ret[0].([]db.Consultancy)
+	ret := m.ctrl.Call(m, "ScheduleUserDeletion", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListConsultancies indicates an expected call of ListConsultancies.
-func (mr *MockStoreMockRecorder) ListConsultancies(arg0, arg1 interface{}) *gomock.Call {
+// ScheduleUserDeletion indicates an expected call of ScheduleUserDeletion.
+func (mr *MockStoreMockRecorder) ScheduleUserDeletion(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListConsultancies", reflect.TypeOf((*MockStore)(nil).ListConsultancies), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleUserDeletion", reflect.TypeOf((*MockStore)(nil).ScheduleUserDeletion), arg0, arg1)
 }
 
-// ListMerchantComments mocks base method.
-func (m *MockStore) ListMerchantComments(arg0 context.Context, arg1 db.ListMerchantCommentsParams) ([]db.Comment, error) {
+// SetCustomerImageVariants mocks base method.
+func (m *MockStore) SetCustomerImageVariants(arg0 context.Context, arg1 db.SetCustomerImageVariantsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMerchantComments", arg0, arg1)
-	ret0, _ := ret[0].([]db.Comment)
+	ret := m.ctrl.Call(m, "SetCustomerImageVariants", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMerchantComments indicates an expected call of ListMerchantComments.
-func (mr *MockStoreMockRecorder) ListMerchantComments(arg0, arg1 interface{}) *gomock.Call {
+// SetCustomerImageVariants indicates an expected call of SetCustomerImageVariants.
+func (mr *MockStoreMockRecorder) SetCustomerImageVariants(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantComments", reflect.TypeOf((*MockStore)(nil).ListMerchantComments), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetCustomerImageVariants", reflect.TypeOf((*MockStore)(nil).SetCustomerImageVariants), arg0, arg1)
 }
 
-// ListMerchantPosts mocks base method.
-func (m *MockStore) ListMerchantPosts(arg0 context.Context, arg1 db.ListMerchantPostsParams) ([]db.Post, error) {
+// SetMerchantImageVariants mocks base method.
+func (m *MockStore) SetMerchantImageVariants(arg0 context.Context, arg1 db.SetMerchantImageVariantsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMerchantPosts", arg0, arg1)
-	ret0, _ := ret[0].([]db.Post)
+	ret := m.ctrl.Call(m, "SetMerchantImageVariants", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMerchantPosts indicates an expected call of ListMerchantPosts.
-func (mr *MockStoreMockRecorder) ListMerchantPosts(arg0, arg1 interface{}) *gomock.Call {
+// SetMerchantImageVariants indicates an expected call of SetMerchantImageVariants.
+func (mr *MockStoreMockRecorder) SetMerchantImageVariants(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchantPosts", reflect.TypeOf((*MockStore)(nil).ListMerchantPosts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMerchantImageVariants", reflect.TypeOf((*MockStore)(nil).SetMerchantImageVariants), arg0, arg1)
 }
 
-// ListMerchants mocks base method.
-func (m *MockStore) ListMerchants(arg0 context.Context, arg1 db.ListMerchantsParams) ([]db.Merchant, error) {
+// SetPostImageVariants mocks base method.
+func (m *MockStore) SetPostImageVariants(arg0 context.Context, arg1 db.SetPostImageVariantsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMerchants", arg0, arg1)
-	ret0, _ := ret[0].([]db.Merchant)
+	ret := m.ctrl.Call(m, "SetPostImageVariants", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMerchants indicates an expected call of ListMerchants.
-func (mr *MockStoreMockRecorder) ListMerchants(arg0, arg1 interface{}) *gomock.Call {
+// SetPostImageVariants indicates an expected call of SetPostImageVariants.
+func (mr *MockStoreMockRecorder) SetPostImageVariants(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMerchants", reflect.TypeOf((*MockStore)(nil).ListMerchants), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPostImageVariants", reflect.TypeOf((*MockStore)(nil).SetPostImageVariants), arg0, arg1)
 }
 
-// ListPostComments mocks base method.
-func (m *MockStore) ListPostComments(arg0 context.Context, arg1 db.ListPostCommentsParams) ([]db.Comment, error) {
+// SetUserDisabled mocks base method.
+func (m *MockStore) SetUserDisabled(arg0 context.Context, arg1 db.SetUserDisabledParams) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPostComments", arg0, arg1)
-	ret0, _ := ret[0].([]db.Comment)
+	ret := m.ctrl.Call(m, "SetUserDisabled", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListPostComments indicates an expected call of ListPostComments.
-func (mr *MockStoreMockRecorder) ListPostComments(arg0, arg1 interface{}) *gomock.Call {
+// SetUserDisabled indicates an expected call of SetUserDisabled.
+func (mr *MockStoreMockRecorder) SetUserDisabled(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPostComments", reflect.TypeOf((*MockStore)(nil).ListPostComments), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserDisabled", reflect.TypeOf((*MockStore)(nil).SetUserDisabled), arg0, arg1)
 }
 
-// ListPosts mocks base method.
-func (m *MockStore) ListPosts(arg0 context.Context, arg1 db.ListPostsParams) ([]db.Post, error) {
+// SetUserImageVariants mocks base method.
+func (m *MockStore) SetUserImageVariants(arg0 context.Context, arg1 db.SetUserImageVariantsParams) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPosts", arg0, arg1)
-	ret0, _ := ret[0].([]db.Post)
+	ret := m.ctrl.Call(m, "SetUserImageVariants", arg0, arg1)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListPosts indicates an expected call of ListPosts.
-func (mr *MockStoreMockRecorder) ListPosts(arg0, arg1 interface{}) *gomock.Call {
+// SetUserImageVariants indicates an expected call of SetUserImageVariants.
+func (mr *MockStoreMockRecorder) SetUserImageVariants(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPosts", reflect.TypeOf((*MockStore)(nil).ListPosts), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserImageVariants", reflect.TypeOf((*MockStore)(nil).SetUserImageVariants), arg0, arg1)
 }
 
 // UpdateAppVersion mocks base method.
@@ -525,6 +1652,36 @@ func (mr *MockStoreMockRecorder) UpdateMerchant(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchant", reflect.TypeOf((*MockStore)(nil).UpdateMerchant), arg0, arg1)
 }
 
+// UpdateMerchantImage mocks base method.
+func (m *MockStore) UpdateMerchantImage(arg0 context.Context, arg1 db.UpdateMerchantImageParams) (db.Merchant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMerchantImage", arg0, arg1)
+	ret0, _ := ret[0].(db.Merchant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMerchantImage indicates an expected call of UpdateMerchantImage.
+func (mr *MockStoreMockRecorder) UpdateMerchantImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMerchantImage", reflect.TypeOf((*MockStore)(nil).UpdateMerchantImage), arg0, arg1)
+}
+
+// UpdateNotificationPreferencesTx mocks base method.
+func (m *MockStore) UpdateNotificationPreferencesTx(arg0 context.Context, arg1 db.UpdateNotificationPreferencesTxParams) (db.UpdateNotificationPreferencesTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferencesTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdateNotificationPreferencesTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationPreferencesTx indicates an expected call of UpdateNotificationPreferencesTx.
+func (mr *MockStoreMockRecorder) UpdateNotificationPreferencesTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferencesTx", reflect.TypeOf((*MockStore)(nil).UpdateNotificationPreferencesTx), arg0, arg1)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockStore) UpdatePassword(arg0 context.Context, arg1 db.UpdatePasswordParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -540,6 +1697,36 @@ func (mr *MockStoreMockRecorder) UpdatePassword(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockStore)(nil).UpdatePassword), arg0, arg1)
 }
 
+// UpdatePost mocks base method.
+func (m *MockStore) UpdatePost(arg0 context.Context, arg1 db.UpdatePostParams) (db.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", arg0, arg1)
+	ret0, _ := ret[0].(db.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockStoreMockRecorder) UpdatePost(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockStore)(nil).UpdatePost), arg0, arg1)
+}
+
+// UpdatePostTx mocks base method.
+func (m *MockStore) UpdatePostTx(arg0 context.Context, arg1 db.UpdatePostTxParams) (db.UpdatePostTxResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePostTx", arg0, arg1)
+	ret0, _ := ret[0].(db.UpdatePostTxResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePostTx indicates an expected call of UpdatePostTx.
+func (mr *MockStoreMockRecorder) UpdatePostTx(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePostTx", reflect.TypeOf((*MockStore)(nil).UpdatePostTx), arg0, arg1)
+}
+
 // UpdateUser mocks base method.
 func (m *MockStore) UpdateUser(arg0 context.Context, arg1 db.UpdateUserParams) (db.User, error) {
 	m.ctrl.T.Helper()
@@ -554,3 +1741,63 @@ func (mr *MockStoreMockRecorder) UpdateUser(arg0, arg1 interface{}) *gomock.Call
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockStore)(nil).UpdateUser), arg0, arg1)
 }
+
+// UpdateUserImage mocks base method.
+func (m *MockStore) UpdateUserImage(arg0 context.Context, arg1 db.UpdateUserImageParams) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserImage", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateUserImage indicates an expected call of UpdateUserImage.
+func (mr *MockStoreMockRecorder) UpdateUserImage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserImage", reflect.TypeOf((*MockStore)(nil).UpdateUserImage), arg0, arg1)
+}
+
+// UpsertDeviceToken mocks base method.
+func (m *MockStore) UpsertDeviceToken(arg0 context.Context, arg1 db.UpsertDeviceTokenParams) (db.DeviceToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertDeviceToken", arg0, arg1)
+	ret0, _ := ret[0].(db.DeviceToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertDeviceToken indicates an expected call of UpsertDeviceToken.
+func (mr *MockStoreMockRecorder) UpsertDeviceToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertDeviceToken", reflect.TypeOf((*MockStore)(nil).UpsertDeviceToken), arg0, arg1)
+}
+
+// UpsertNotificationPreference mocks base method.
+func (m *MockStore) UpsertNotificationPreference(arg0 context.Context, arg1 db.UpsertNotificationPreferenceParams) (db.NotificationPreference, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertNotificationPreference", arg0, arg1)
+	ret0, _ := ret[0].(db.NotificationPreference)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertNotificationPreference indicates an expected call of UpsertNotificationPreference.
+func (mr *MockStoreMockRecorder) UpsertNotificationPreference(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertNotificationPreference", reflect.TypeOf((*MockStore)(nil).UpsertNotificationPreference), arg0, arg1)
+}
+
+// UpsertNotificationSettings mocks base method.
+func (m *MockStore) UpsertNotificationSettings(arg0 context.Context, arg1 db.UpsertNotificationSettingsParams) (db.NotificationSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertNotificationSettings", arg0, arg1)
+	ret0, _ := ret[0].(db.NotificationSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertNotificationSettings indicates an expected call of UpsertNotificationSettings.
+func (mr *MockStoreMockRecorder) UpsertNotificationSettings(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertNotificationSettings", reflect.TypeOf((*MockStore)(nil).UpsertNotificationSettings), arg0, arg1)
+}
@@ -0,0 +1,43 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+  actor,
+  action,
+  target,
+  ip,
+  user_agent,
+  request_id,
+  diff
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target)::varchar IS NULL OR target = sqlc.narg(target))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListAuditEventsByCursor :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg(actor)::varchar IS NULL OR actor = sqlc.narg(actor))
+  AND (sqlc.narg(action)::varchar IS NULL OR action = sqlc.narg(action))
+  AND (sqlc.narg(target)::varchar IS NULL OR target = sqlc.narg(target))
+  AND (sqlc.narg(from_time)::timestamptz IS NULL OR created_at >= sqlc.narg(from_time))
+  AND (sqlc.narg(to_time)::timestamptz IS NULL OR created_at < sqlc.narg(to_time))
+  AND (
+    sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+    (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListUserAuditEvents :many
+SELECT * FROM audit_events
+WHERE actor = sqlc.arg(username)::varchar
+   OR target = 'user:' || sqlc.arg(username)::varchar
+ORDER BY id;
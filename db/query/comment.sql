@@ -14,9 +14,10 @@ SELECT * FROM comments
 WHERE id = $1 LIMIT 1;
 
 -- name: ListPostComments :many
-SELECT * FROM comments
-WHERE post_id = $1
-ORDER BY id
+SELECT comments.* FROM comments
+JOIN posts ON posts.id = comments.post_id
+WHERE comments.post_id = $1 AND posts.deleted_at IS NULL
+ORDER BY comments.id
 LIMIT $2
 OFFSET $3;
 
@@ -29,4 +30,19 @@ OFFSET $3;
 
 -- name: DeleteComment :exec
 DELETE FROM comments
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListCommentsByOwner :many
+SELECT * FROM comments
+WHERE owner = $1
+ORDER BY id;
+
+-- name: ListPostCommentsByCursor :many
+SELECT comments.* FROM comments
+JOIN posts ON posts.id = comments.post_id
+WHERE comments.post_id = sqlc.arg(post_id) AND posts.deleted_at IS NULL AND (
+  sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+  (comments.created_at, comments.id) > (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+)
+ORDER BY comments.created_at, comments.id
+LIMIT sqlc.arg(limit_count);
@@ -18,4 +18,11 @@ WHERE
     customer_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: ListConsultanciesByOwner :many
+SELECT consultancies.* FROM consultancies
+LEFT JOIN merchants ON merchants.id = consultancies.merchant_id
+LEFT JOIN customers ON customers.id = consultancies.customer_id
+WHERE merchants.owner = $1 OR customers.owner = $1
+ORDER BY consultancies.id;
@@ -11,10 +11,34 @@ WHERE id = $1 LIMIT 1;
 
 -- name: UpdateCustomer :one
 UPDATE customers
-SET image_url = $2
-WHERE id = $1
+SET image_url = sqlc.arg(image_url),
+    image_variants = sqlc.arg(image_variants),
+    version = version + 1
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(version)::bigint IS NULL OR version = sqlc.narg(version))
 RETURNING *;
 
+-- name: SetCustomerImageVariants :execrows
+UPDATE customers
+SET image_variants = sqlc.arg(image_variants),
+    version = version + 1
+WHERE id = sqlc.arg(id)
+  AND image_url = sqlc.arg(image_url);
+
 -- name: DeleteCustomer :exec
 DELETE FROM customers
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListCustomersByOwner :many
+SELECT * FROM customers
+WHERE owner = $1
+ORDER BY id;
+
+-- name: AnonymizeUserCustomers :many
+UPDATE customers
+SET owner = 'deleted_user',
+    image_url = DEFAULT,
+    image_variants = DEFAULT,
+    version = version + 1
+WHERE owner = $1
+RETURNING id;
@@ -0,0 +1,41 @@
+-- name: UpsertDeviceToken :one
+WITH moved AS (
+  DELETE FROM device_tokens
+  WHERE token = sqlc.arg(token) AND session_id <> sqlc.arg(session_id)
+)
+INSERT INTO device_tokens (
+  session_id,
+  username,
+  platform,
+  token,
+  locale
+) VALUES (
+  sqlc.arg(session_id), sqlc.arg(username), sqlc.arg(platform), sqlc.arg(token), sqlc.arg(locale)
+)
+ON CONFLICT (session_id) DO UPDATE
+SET platform = EXCLUDED.platform,
+    token = EXCLUDED.token,
+    locale = EXCLUDED.locale,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListUserDeviceTokens :many
+SELECT device_tokens.* FROM device_tokens
+JOIN sessions ON sessions.id = device_tokens.session_id
+WHERE device_tokens.username = $1
+  AND sessions.is_blocked = false
+  AND sessions.expires_at > now()
+ORDER BY device_tokens.created_at;
+
+-- name: ListAllUserDeviceTokens :many
+SELECT * FROM device_tokens
+WHERE username = $1
+ORDER BY created_at;
+
+-- name: DeleteDeviceToken :exec
+DELETE FROM device_tokens
+WHERE session_id = $1;
+
+-- name: DeleteUserDeviceTokens :exec
+DELETE FROM device_tokens
+WHERE username = $1;
@@ -0,0 +1,44 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  username,
+  key,
+  request_method,
+  request_path,
+  request_hash,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (username, key) DO UPDATE
+SET request_method = EXCLUDED.request_method,
+    request_path = EXCLUDED.request_path,
+    request_hash = EXCLUDED.request_hash,
+    response_status = NULL,
+    response_body = NULL,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= now()
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2 AND expires_at > now()
+LIMIT 1;
+
+-- name: CompleteIdempotencyKey :one
+UPDATE idempotency_keys
+SET response_status = $3, response_body = $4
+WHERE username = $1 AND key = $2
+RETURNING *;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE username = $1 AND key = $2;
+
+-- name: DeleteUserIdempotencyKeys :exec
+DELETE FROM idempotency_keys
+WHERE username = $1;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= sqlc.arg(now)::timestamptz;
@@ -0,0 +1,72 @@
+-- name: EnqueueJob :one
+INSERT INTO jobs (
+  kind,
+  payload,
+  max_attempts,
+  run_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ClaimJobs :many
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = sqlc.arg(now)::timestamptz
+WHERE id IN (
+  SELECT id FROM jobs
+  WHERE (status = 'pending' AND run_at <= sqlc.arg(now)::timestamptz)
+     OR (status = 'running' AND locked_at <= sqlc.arg(stale_before)::timestamptz
+         AND attempts < max_attempts)
+  ORDER BY run_at, id
+  LIMIT sqlc.arg(limit_count)
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: KillStaleJobs :many
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = sqlc.arg(last_error)::varchar
+WHERE status = 'running'
+  AND locked_at <= sqlc.arg(stale_before)::timestamptz
+  AND attempts >= max_attempts
+RETURNING *;
+
+-- name: CompleteJob :execrows
+DELETE FROM jobs
+WHERE id = $1 AND status = 'running' AND locked_at = sqlc.arg(locked_at)::timestamptz;
+
+-- name: RetryJob :one
+UPDATE jobs
+SET status = 'pending',
+    run_at = $2,
+    locked_at = NULL,
+    last_error = $3
+WHERE id = $1 AND status = 'running' AND locked_at = sqlc.arg(locked_at)::timestamptz
+RETURNING *;
+
+-- name: KillJob :one
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = $2
+WHERE id = $1 AND status = 'running' AND locked_at = sqlc.arg(locked_at)::timestamptz
+RETURNING *;
+
+-- name: ListDeadJobs :many
+SELECT * FROM jobs
+WHERE status = 'dead'
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: RequeueDeadJob :one
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    run_at = now()
+WHERE id = $1 AND status = 'dead'
+RETURNING *;
@@ -26,16 +26,59 @@ SET balance = COALESCE(sqlc.narg(balance), balance),
     title = COALESCE(sqlc.narg(title), title),
     about = COALESCE(sqlc.narg(about), about),
     image_url = COALESCE(sqlc.narg(image_url), image_url),
-    rating = COALESCE(sqlc.narg(rating), rating)
+    rating = COALESCE(sqlc.narg(rating), rating),
+    version = version + 1
 WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(version)::bigint IS NULL OR version = sqlc.narg(version))
 RETURNING *;
 
+-- name: UpdateMerchantImage :one
+UPDATE merchants
+SET image_url = sqlc.arg(image_url),
+    image_variants = sqlc.arg(image_variants),
+    version = version + 1
+WHERE id = sqlc.arg(id)
+  AND (sqlc.narg(version)::bigint IS NULL OR version = sqlc.narg(version))
+RETURNING *;
+
+-- name: SetMerchantImageVariants :execrows
+UPDATE merchants
+SET image_variants = sqlc.arg(image_variants),
+    version = version + 1
+WHERE id = sqlc.arg(id)
+  AND image_url = sqlc.arg(image_url);
+
 -- name: AddMerchantBalance :one
 UPDATE merchants
-SET balance = balance + sqlc.arg(amount)
+SET balance = balance + sqlc.arg(amount), version = version + 1
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: DeleteMerchant :exec
 DELETE FROM merchants
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListMerchantsByOwner :many
+SELECT * FROM merchants
+WHERE owner = $1
+ORDER BY id;
+
+-- name: AnonymizeUserMerchants :many
+UPDATE merchants
+SET owner = 'deleted_user',
+    title = 'Deleted merchant',
+    about = '',
+    image_url = DEFAULT,
+    image_variants = DEFAULT,
+    version = version + 1
+WHERE owner = $1
+RETURNING id;
+
+-- name: ListMerchantsByCursor :many
+SELECT * FROM merchants
+WHERE owner = sqlc.arg(owner) AND (
+  sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+  (created_at, id) > (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+)
+ORDER BY created_at, id
+LIMIT sqlc.arg(limit_count);
@@ -0,0 +1,58 @@
+-- name: CreateNotification :one
+INSERT INTO notifications (
+  recipient,
+  event_type,
+  actor,
+  target,
+  data
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListNotifications :many
+SELECT * FROM notifications
+WHERE recipient = sqlc.arg(recipient)
+  AND (NOT sqlc.arg(unread_only)::boolean OR read_at IS NULL)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count)
+OFFSET sqlc.arg(offset_count);
+
+-- name: ListNotificationsByCursor :many
+SELECT * FROM notifications
+WHERE recipient = sqlc.arg(recipient)
+  AND (NOT sqlc.arg(unread_only)::boolean OR read_at IS NULL)
+  AND (
+    sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+    (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+  )
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListUserNotifications :many
+SELECT * FROM notifications
+WHERE recipient = $1
+ORDER BY id;
+
+-- name: CountUnreadNotifications :one
+SELECT count(*) FROM notifications
+WHERE recipient = $1 AND read_at IS NULL;
+
+-- name: MarkNotificationRead :one
+UPDATE notifications
+SET read_at = COALESCE(read_at, now())
+WHERE id = $1 AND recipient = $2
+RETURNING *;
+
+-- name: MarkAllNotificationsRead :execrows
+UPDATE notifications
+SET read_at = now()
+WHERE recipient = $1 AND read_at IS NULL;
+
+-- name: DeleteUserNotifications :exec
+DELETE FROM notifications
+WHERE recipient = $1;
+
+-- name: AnonymizeUserNotifications :exec
+UPDATE notifications
+SET actor = 'deleted_user'
+WHERE actor = $1;
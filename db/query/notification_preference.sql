@@ -0,0 +1,47 @@
+-- name: GetNotificationSettings :one
+SELECT * FROM notification_settings
+WHERE username = $1
+LIMIT 1;
+
+-- name: UpsertNotificationSettings :one
+INSERT INTO notification_settings (
+  username,
+  time_zone,
+  quiet_hours_start,
+  quiet_hours_end
+) VALUES (
+  sqlc.arg(username), sqlc.arg(time_zone), sqlc.narg(quiet_hours_start), sqlc.narg(quiet_hours_end)
+)
+ON CONFLICT (username) DO UPDATE
+SET time_zone = EXCLUDED.time_zone,
+    quiet_hours_start = EXCLUDED.quiet_hours_start,
+    quiet_hours_end = EXCLUDED.quiet_hours_end,
+    updated_at = now()
+RETURNING *;
+
+-- name: ListNotificationPreferences :many
+SELECT * FROM notification_preferences
+WHERE username = $1
+ORDER BY event_type, channel;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO notification_preferences (
+  username,
+  event_type,
+  channel,
+  enabled
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (username, event_type, channel) DO UPDATE
+SET enabled = EXCLUDED.enabled,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteUserNotificationSettings :exec
+DELETE FROM notification_settings
+WHERE username = $1;
+
+-- name: DeleteUserNotificationPreferences :exec
+DELETE FROM notification_preferences
+WHERE username = $1;
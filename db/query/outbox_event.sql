@@ -0,0 +1,20 @@
+-- name: AppendOutboxEvent :one
+INSERT INTO outbox_events (
+  event_type,
+  aggregate,
+  payload
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListOutboxEventsForUpdate :many
+SELECT * FROM outbox_events
+WHERE xact_id < pg_snapshot_xmin(pg_current_snapshot())::text::bigint
+ORDER BY xact_id, id
+LIMIT $1
+FOR UPDATE;
+
+-- name: DeleteOutboxEvent :exec
+DELETE FROM outbox_events
+WHERE id = $1;
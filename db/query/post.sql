@@ -2,28 +2,82 @@
 INSERT INTO posts (
   merchant_id,
   title,
-  image_url
+  image_url,
+  image_variants
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetPost :one
 SELECT * FROM posts
-WHERE id = $1 LIMIT 1;
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1;
+
+-- name: GetPostForUpdate :one
+SELECT * FROM posts
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR NO KEY UPDATE;
 
 -- name: ListMerchantPosts :many
 SELECT * FROM posts
-WHERE merchant_id = $1
+WHERE merchant_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2
 OFFSET $3;
 
 -- name: ListPosts :many
 SELECT * FROM posts
+WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $1
 OFFSET $2;
 
+-- name: UpdatePost :one
+UPDATE posts
+SET title = $2, image_url = $3, image_variants = $4, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: SetPostImageVariants :execrows
+UPDATE posts
+SET image_variants = sqlc.arg(image_variants)
+WHERE id = sqlc.arg(id)
+  AND image_url = sqlc.arg(image_url)::varchar
+  AND deleted_at IS NULL;
+
 -- name: DeletePost :exec
-DELETE FROM posts
-WHERE id = $1;
\ No newline at end of file
+UPDATE posts
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: ListPostsByOwner :many
+SELECT posts.* FROM posts
+JOIN merchants ON merchants.id = posts.merchant_id
+WHERE merchants.owner = $1
+ORDER BY posts.id;
+
+-- name: DeleteOwnerPosts :exec
+UPDATE posts
+SET deleted_at = now()
+WHERE deleted_at IS NULL AND merchant_id IN (
+  SELECT id FROM merchants WHERE owner = $1
+);
+
+-- name: ListPostsByCursor :many
+SELECT * FROM posts
+WHERE deleted_at IS NULL AND (
+  sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+  (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
+
+-- name: ListMerchantPostsByCursor :many
+SELECT * FROM posts
+WHERE merchant_id = sqlc.arg(merchant_id) AND deleted_at IS NULL AND (
+  sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+  (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
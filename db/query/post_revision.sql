@@ -0,0 +1,26 @@
+-- name: CreatePostRevision :one
+INSERT INTO post_revisions (
+  post_id,
+  editor,
+  title,
+  image_url,
+  image_variants
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListPostRevisions :many
+SELECT * FROM post_revisions
+WHERE post_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListPostRevisionsByCursor :many
+SELECT * FROM post_revisions
+WHERE post_id = sqlc.arg(post_id) AND (
+  sqlc.narg(cursor_created_at)::timestamptz IS NULL OR
+  (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::bigint)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(limit_count);
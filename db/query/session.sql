@@ -13,4 +13,18 @@ INSERT INTO sessions (
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: ListUserSessions :many
+SELECT * FROM sessions
+WHERE username = $1
+ORDER BY created_at;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions
+WHERE username = $1;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND is_blocked = false;
@@ -21,22 +21,68 @@ SET full_name = COALESCE(sqlc.narg(full_name), full_name),
     phone_number = COALESCE(sqlc.narg(phone_number), phone_number),
     gender = COALESCE(sqlc.narg(gender), gender), 
     birth_date = COALESCE(sqlc.narg(birth_date), birth_date),
-    image_url = COALESCE(sqlc.narg(image_url), image_url)
+    image_url = COALESCE(sqlc.narg(image_url), image_url),
+    version = version + 1
 WHERE username = sqlc.arg(username)
+  AND (sqlc.narg(version)::bigint IS NULL OR version = sqlc.narg(version))
 RETURNING *;
 
+-- name: UpdateUserImage :one
+UPDATE users
+SET image_url = sqlc.arg(image_url),
+    image_variants = sqlc.arg(image_variants),
+    version = version + 1
+WHERE username = sqlc.arg(username)
+  AND (sqlc.narg(version)::bigint IS NULL OR version = sqlc.narg(version))
+RETURNING *;
+
+-- name: SetUserImageVariants :execrows
+UPDATE users
+SET image_variants = sqlc.arg(image_variants),
+    version = version + 1
+WHERE username = sqlc.arg(username)
+  AND image_url = sqlc.arg(image_url);
+
 -- name: UpdatePassword :one
 UPDATE users
-SET hashed_password = $2, password_changed_at = $3
+SET hashed_password = $2, password_changed_at = $3, version = version + 1
 WHERE username = $1
 RETURNING *;
 
 -- name: UpdateEmail :one
 UPDATE users
-SET email = $2
+SET email = $2, version = version + 1
 WHERE username = $1
 RETURNING *;
 
 -- name: DeleteUser :exec
 DELETE FROM users
-WHERE username = $1;
\ No newline at end of file
+WHERE username = $1;
+
+-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = $2, version = version + 1
+WHERE username = $1
+RETURNING *;
+
+-- name: ListUsersDueForDeletion :many
+SELECT username FROM users
+WHERE deletion_scheduled_at <= sqlc.arg(now)::timestamptz
+ORDER BY deletion_scheduled_at
+LIMIT sqlc.arg(limit_count);
+
+-- name: AnonymizeUserComments :exec
+UPDATE comments
+SET owner = 'deleted_user'
+WHERE owner = $1;
+
+-- name: AnonymizeUserPostRevisions :exec
+UPDATE post_revisions
+SET editor = 'deleted_user'
+WHERE editor = $1;
+
+-- name: SetUserDisabled :one
+UPDATE users
+SET disabled = $2, version = version + 1
+WHERE username = $1
+RETURNING *;
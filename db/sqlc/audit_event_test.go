@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuditEvent(t *testing.T, actor string, action string) AuditEvent {
+	arg := CreateAuditEventParams{
+		Actor:     actor,
+		Action:    action,
+		Target:    "user:" + actor,
+		Ip:        "127.0.0.1",
+		UserAgent: "thenut-test",
+		RequestID: utils.RandomString(12),
+		Diff:      json.RawMessage(`{"session_id": "1"}`),
+	}
+
+	event, err := testQueries.CreateAuditEvent(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, event)
+
+	require.Equal(t, arg.Actor, event.Actor)
+	require.Equal(t, arg.Action, event.Action)
+	require.Equal(t, arg.Target, event.Target)
+	require.Equal(t, arg.Ip, event.Ip)
+	require.Equal(t, arg.UserAgent, event.UserAgent)
+	require.Equal(t, arg.RequestID, event.RequestID)
+	require.JSONEq(t, string(arg.Diff), string(event.Diff))
+	require.NotZero(t, event.ID)
+	require.NotZero(t, event.CreatedAt)
+
+	return event
+}
+
+func TestCreateAuditEvent(t *testing.T) {
+	createRandomAuditEvent(t, utils.RandomOwner(), "auth.login")
+}
+
+func TestListAuditEvents(t *testing.T) {
+	actor := utils.RandomOwner()
+	for i := 0; i < 3; i++ {
+		createRandomAuditEvent(t, actor, "auth.login")
+	}
+	failed := createRandomAuditEvent(t, actor, "auth.login_failed")
+	createRandomAuditEvent(t, utils.RandomOwner(), "auth.login_failed")
+
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:      sql.NullString{String: actor, Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, failed, events[0])
+
+	events, err = testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Actor:      sql.NullString{String: actor, Valid: true},
+		Action:     sql.NullString{String: "auth.login_failed", Valid: true},
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, failed, events[0])
+}
+
+func TestListAuditEventsByCursor(t *testing.T) {
+	actor := utils.RandomOwner()
+	for i := 0; i < 5; i++ {
+		createRandomAuditEvent(t, actor, "auth.login")
+	}
+
+	arg := ListAuditEventsByCursorParams{
+		Actor:      sql.NullString{String: actor, Valid: true},
+		LimitCount: 3,
+	}
+
+	firstPage, err := testQueries.ListAuditEventsByCursor(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 3)
+
+	last := firstPage[len(firstPage)-1]
+	arg.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+	arg.CursorID = sql.NullInt64{Int64: last.ID, Valid: true}
+
+	secondPage, err := testQueries.ListAuditEventsByCursor(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, secondPage, 2)
+	for _, event := range secondPage {
+		require.NotContains(t, firstPage, event)
+	}
+}
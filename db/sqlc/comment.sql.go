@@ -81,6 +81,43 @@ func (q *Queries) GetComment(ctx context.Context, id int64) (Comment, error) {
 	return i, err
 }
 
+const listCommentsByOwner = `-- name: ListCommentsByOwner :many
+SELECT id, comment_type, post_id, merchant_id, owner, comment, created_at FROM comments
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListCommentsByOwner(ctx context.Context, owner string) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, listCommentsByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Comment{}
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.CommentType,
+			&i.PostID,
+			&i.MerchantID,
+			&i.Owner,
+			&i.Comment,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMerchantComments = `-- name: ListMerchantComments :many
 SELECT id, comment_type, post_id, merchant_id, owner, comment, created_at FROM comments
 WHERE merchant_id = $1
@@ -127,9 +164,10 @@ func (q *Queries) ListMerchantComments(ctx context.Context, arg ListMerchantComm
 }
 
 const listPostComments = `-- name: ListPostComments :many
-SELECT id, comment_type, post_id, merchant_id, owner, comment, created_at FROM comments
-WHERE post_id = $1
-ORDER BY id
+SELECT comments.id, comments.comment_type, comments.post_id, comments.merchant_id, comments.owner, comments.comment, comments.created_at FROM comments
+JOIN posts ON posts.id = comments.post_id
+WHERE comments.post_id = $1 AND posts.deleted_at IS NULL
+ORDER BY comments.id
 LIMIT $2
 OFFSET $3
 `
@@ -170,3 +208,57 @@ func (q *Queries) ListPostComments(ctx context.Context, arg ListPostCommentsPara
 	}
 	return items, nil
 }
+
+const listPostCommentsByCursor = `-- name: ListPostCommentsByCursor :many
+SELECT comments.id, comments.comment_type, comments.post_id, comments.merchant_id, comments.owner, comments.comment, comments.created_at FROM comments
+JOIN posts ON posts.id = comments.post_id
+WHERE comments.post_id = $1 AND posts.deleted_at IS NULL AND (
+  $2::timestamptz IS NULL OR
+  (comments.created_at, comments.id) > ($2::timestamptz, $3::bigint)
+)
+ORDER BY comments.created_at, comments.id
+LIMIT $4
+`
+
+type ListPostCommentsByCursorParams struct {
+	PostID          sql.NullInt64 `json:"post_id"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	LimitCount      int32         `json:"limit_count"`
+}
+
+func (q *Queries) ListPostCommentsByCursor(ctx context.Context, arg ListPostCommentsByCursorParams) ([]Comment, error) {
+	rows, err := q.db.QueryContext(ctx, listPostCommentsByCursor,
+		arg.PostID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Comment{}
+	for rows.Next() {
+		var i Comment
+		if err := rows.Scan(
+			&i.ID,
+			&i.CommentType,
+			&i.PostID,
+			&i.MerchantID,
+			&i.Owner,
+			&i.Comment,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
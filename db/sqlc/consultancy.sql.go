@@ -106,3 +106,40 @@ func (q *Queries) ListConsultancies(ctx context.Context, arg ListConsultanciesPa
 	}
 	return items, nil
 }
+
+const listConsultanciesByOwner = `-- name: ListConsultanciesByOwner :many
+SELECT consultancies.id, consultancies.merchant_id, consultancies.customer_id, consultancies.cost, consultancies.created_at FROM consultancies
+LEFT JOIN merchants ON merchants.id = consultancies.merchant_id
+LEFT JOIN customers ON customers.id = consultancies.customer_id
+WHERE merchants.owner = $1 OR customers.owner = $1
+ORDER BY consultancies.id
+`
+
+func (q *Queries) ListConsultanciesByOwner(ctx context.Context, owner string) ([]Consultancy, error) {
+	rows, err := q.db.QueryContext(ctx, listConsultanciesByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Consultancy{}
+	for rows.Next() {
+		var i Consultancy
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.CustomerID,
+			&i.Cost,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,14 +7,49 @@
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 )
 
+const anonymizeUserCustomers = `-- name: AnonymizeUserCustomers :many
+UPDATE customers
+SET owner = 'deleted_user',
+    image_url = DEFAULT,
+    image_variants = DEFAULT,
+    version = version + 1
+WHERE owner = $1
+RETURNING id
+`
+
+func (q *Queries) AnonymizeUserCustomers(ctx context.Context, owner string) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, anonymizeUserCustomers, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createCustomer = `-- name: CreateCustomer :one
 INSERT INTO customers (
   owner
 ) VALUES (
   $1
-) RETURNING id, owner, image_url, created_at
+) RETURNING id, owner, image_url, created_at, image_variants, version
 `
 
 func (q *Queries) CreateCustomer(ctx context.Context, owner string) (Customer, error) {
@@ -25,6 +60,8 @@ func (q *Queries) CreateCustomer(ctx context.Context, owner string) (Customer, e
 		&i.Owner,
 		&i.ImageUrl,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
@@ -40,7 +77,7 @@ func (q *Queries) DeleteCustomer(ctx context.Context, id int64) error {
 }
 
 const getCustomer = `-- name: GetCustomer :one
-SELECT id, owner, image_url, created_at FROM customers
+SELECT id, owner, image_url, created_at, image_variants, version FROM customers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,30 +89,102 @@ func (q *Queries) GetCustomer(ctx context.Context, id int64) (Customer, error) {
 		&i.Owner,
 		&i.ImageUrl,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
 
+const listCustomersByOwner = `-- name: ListCustomersByOwner :many
+SELECT id, owner, image_url, created_at, image_variants, version FROM customers
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListCustomersByOwner(ctx context.Context, owner string) ([]Customer, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomersByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Customer{}
+	for rows.Next() {
+		var i Customer
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.ImageUrl,
+			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setCustomerImageVariants = `-- name: SetCustomerImageVariants :execrows
+UPDATE customers
+SET image_variants = $1,
+    version = version + 1
+WHERE id = $2
+  AND image_url = $3
+`
+
+type SetCustomerImageVariantsParams struct {
+	ImageVariants json.RawMessage `json:"image_variants"`
+	ID            int64           `json:"id"`
+	ImageUrl      string          `json:"image_url"`
+}
+
+func (q *Queries) SetCustomerImageVariants(ctx context.Context, arg SetCustomerImageVariantsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setCustomerImageVariants, arg.ImageVariants, arg.ID, arg.ImageUrl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateCustomer = `-- name: UpdateCustomer :one
 UPDATE customers
-SET image_url = $2
-WHERE id = $1
-RETURNING id, owner, image_url, created_at
+SET image_url = $1,
+    image_variants = $2,
+    version = version + 1
+WHERE id = $3
+  AND ($4::bigint IS NULL OR version = $4)
+RETURNING id, owner, image_url, created_at, image_variants, version
 `
 
 type UpdateCustomerParams struct {
-	ID       int64  `json:"id"`
-	ImageUrl string `json:"image_url"`
+	ImageUrl      string          `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
+	ID            int64           `json:"id"`
+	Version       sql.NullInt64   `json:"version"`
 }
 
 func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error) {
-	row := q.db.QueryRowContext(ctx, updateCustomer, arg.ID, arg.ImageUrl)
+	row := q.db.QueryRowContext(ctx, updateCustomer,
+		arg.ImageUrl,
+		arg.ImageVariants,
+		arg.ID,
+		arg.Version,
+	)
 	var i Customer
 	err := row.Scan(
 		&i.ID,
 		&i.Owner,
 		&i.ImageUrl,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
@@ -66,8 +66,9 @@ func TestGetCustomer(t *testing.T) {
 func TestUpdateCustomer(t *testing.T) {
 	tCustomer := createRandomCustomer(t)
 	arg := UpdateCustomerParams{
-		ID:       tCustomer.ID,
-		ImageUrl: utils.RandomImageUrl(),
+		ID:            tCustomer.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomImageVariants(),
 	}
 
 	customer, err := testQueries.UpdateCustomer(context.Background(), arg)
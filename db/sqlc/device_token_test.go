@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// createLiveSession creates a session whose device tokens are listed, unlike
+// the expired ones of createRandomSession
+func createLiveSession(t *testing.T, user User) uuid.UUID {
+	id := uuid.New()
+	err := testQueries.CreateSession(context.Background(), CreateSessionParams{
+		ID:           id,
+		Username:     user.Username,
+		RefreshToken: utils.RandomString(12),
+		UserAgent:    utils.RandomString(12),
+		ClientIp:     utils.RandomString(12),
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	return id
+}
+
+func TestUpsertDeviceToken(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := UpsertDeviceTokenParams{
+		SessionID: createLiveSession(t, user),
+		Username:  user.Username,
+		Platform:  "fcm",
+		Token:     utils.RandomString(64),
+		Locale:    "en",
+	}
+	token, err := testQueries.UpsertDeviceToken(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Token, token.Token)
+
+	// the token moves to the other session of the device
+	other := createLiveSession(t, user)
+	arg.SessionID = other
+	_, err = testQueries.UpsertDeviceToken(context.Background(), arg)
+	require.NoError(t, err)
+
+	tokens, err := testQueries.ListUserDeviceTokens(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, other, tokens[0].SessionID)
+
+	require.NoError(t, testQueries.DeleteUserDeviceTokens(context.Background(), user.Username))
+}
@@ -0,0 +1,22 @@
+package db
+
+import "context"
+
+// SchemaVersion is the migration state recorded by golang-migrate
+type SchemaVersion struct {
+	Version int64 `json:"version"`
+	Dirty   bool  `json:"dirty"`
+}
+
+// Ping verifies that the database is reachable
+func (store *SQLStore) Ping(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+// GetSchemaVersion returns the version of the last applied migration
+func (store *SQLStore) GetSchemaVersion(ctx context.Context) (SchemaVersion, error) {
+	var version SchemaVersion
+	err := store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").
+		Scan(&version.Version, &version.Dirty)
+	return version, err
+}
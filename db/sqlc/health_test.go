@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asdsec/thenut/db/migration"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPing(t *testing.T) {
+	store := NewStore(testDB)
+	require.NoError(t, store.Ping(context.Background()))
+}
+
+func TestGetSchemaVersion(t *testing.T) {
+	store := NewStore(testDB)
+
+	version, err := store.GetSchemaVersion(context.Background())
+	require.NoError(t, err)
+	require.False(t, version.Dirty)
+
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, int64(latest), version.Version)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, user User) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:      user.Username,
+		Key:           utils.RandomString(16),
+		RequestMethod: http.MethodPost,
+		RequestPath:   "/posts",
+		RequestHash:   utils.RandomString(64),
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.WithinDuration(t, arg.ExpiresAt, key.ExpiresAt, time.Second)
+	require.False(t, key.ResponseStatus.Valid)
+
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t, createRandomUser(t))
+
+	_, err := testQueries.CreateIdempotencyKey(context.Background(), CreateIdempotencyKeyParams{
+		Username:    key.Username,
+		Key:         key.Key,
+		RequestHash: utils.RandomString(64),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCompleteIdempotencyKey(t *testing.T) {
+	key := createRandomIdempotencyKey(t, createRandomUser(t))
+
+	arg := CompleteIdempotencyKeyParams{
+		Username:       key.Username,
+		Key:            key.Key,
+		ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+		ResponseBody:   []byte(`{"id":1}`),
+	}
+	completed, err := testQueries.CompleteIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.ResponseStatus, completed.ResponseStatus)
+	require.Equal(t, arg.ResponseBody, completed.ResponseBody)
+
+	got, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key.Username,
+		Key:      key.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, completed.ResponseBody, got.ResponseBody)
+}
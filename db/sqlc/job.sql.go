@@ -0,0 +1,327 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.16.0
+// source: job.sql
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+const claimJobs = `-- name: ClaimJobs :many
+UPDATE jobs
+SET status = 'running',
+    attempts = attempts + 1,
+    locked_at = $1::timestamptz
+WHERE id IN (
+  SELECT id FROM jobs
+  WHERE (status = 'pending' AND run_at <= $1::timestamptz)
+     OR (status = 'running' AND locked_at <= $2::timestamptz
+         AND attempts < max_attempts)
+  ORDER BY run_at, id
+  LIMIT $3
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at
+`
+
+type ClaimJobsParams struct {
+	Now         time.Time `json:"now"`
+	StaleBefore time.Time `json:"stale_before"`
+	LimitCount  int32     `json:"limit_count"`
+}
+
+func (q *Queries) ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, claimJobs, arg.Now, arg.StaleBefore, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeJob = `-- name: CompleteJob :execrows
+DELETE FROM jobs
+WHERE id = $1 AND status = 'running' AND locked_at = $2::timestamptz
+`
+
+type CompleteJobParams struct {
+	ID       int64     `json:"id"`
+	LockedAt time.Time `json:"locked_at"`
+}
+
+func (q *Queries) CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, completeJob, arg.ID, arg.LockedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const enqueueJob = `-- name: EnqueueJob :one
+INSERT INTO jobs (
+  kind,
+  payload,
+  max_attempts,
+  run_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at
+`
+
+type EnqueueJobParams struct {
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	MaxAttempts int32           `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+}
+
+func (q *Queries) EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, enqueueJob,
+		arg.Kind,
+		arg.Payload,
+		arg.MaxAttempts,
+		arg.RunAt,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const killJob = `-- name: KillJob :one
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = $2
+WHERE id = $1 AND status = 'running' AND locked_at = $3::timestamptz
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at
+`
+
+type KillJobParams struct {
+	ID        int64          `json:"id"`
+	LastError sql.NullString `json:"last_error"`
+	LockedAt  time.Time      `json:"locked_at"`
+}
+
+func (q *Queries) KillJob(ctx context.Context, arg KillJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, killJob, arg.ID, arg.LastError, arg.LockedAt)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const killStaleJobs = `-- name: KillStaleJobs :many
+UPDATE jobs
+SET status = 'dead',
+    locked_at = NULL,
+    last_error = $1::varchar
+WHERE status = 'running'
+  AND locked_at <= $2::timestamptz
+  AND attempts >= max_attempts
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at
+`
+
+type KillStaleJobsParams struct {
+	LastError   string    `json:"last_error"`
+	StaleBefore time.Time `json:"stale_before"`
+}
+
+func (q *Queries) KillStaleJobs(ctx context.Context, arg KillStaleJobsParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, killStaleJobs, arg.LastError, arg.StaleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeadJobs = `-- name: ListDeadJobs :many
+SELECT id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at FROM jobs
+WHERE status = 'dead'
+ORDER BY id
+LIMIT $1
+OFFSET $2
+`
+
+type ListDeadJobsParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+func (q *Queries) ListDeadJobs(ctx context.Context, arg ListDeadJobsParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, listDeadJobs, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Job{}
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.MaxAttempts,
+			&i.RunAt,
+			&i.LockedAt,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const requeueDeadJob = `-- name: RequeueDeadJob :one
+UPDATE jobs
+SET status = 'pending',
+    attempts = 0,
+    run_at = now()
+WHERE id = $1 AND status = 'dead'
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at
+`
+
+func (q *Queries) RequeueDeadJob(ctx context.Context, id int64) (Job, error) {
+	row := q.db.QueryRowContext(ctx, requeueDeadJob, id)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const retryJob = `-- name: RetryJob :one
+UPDATE jobs
+SET status = 'pending',
+    run_at = $2,
+    locked_at = NULL,
+    last_error = $3
+WHERE id = $1 AND status = 'running' AND locked_at = $4::timestamptz
+RETURNING id, kind, payload, status, attempts, max_attempts, run_at, locked_at, last_error, created_at
+`
+
+type RetryJobParams struct {
+	ID        int64          `json:"id"`
+	RunAt     time.Time      `json:"run_at"`
+	LastError sql.NullString `json:"last_error"`
+	LockedAt  time.Time      `json:"locked_at"`
+}
+
+func (q *Queries) RetryJob(ctx context.Context, arg RetryJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, retryJob,
+		arg.ID,
+		arg.RunAt,
+		arg.LastError,
+		arg.LockedAt,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.MaxAttempts,
+		&i.RunAt,
+		&i.LockedAt,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,47 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimJobs(t *testing.T) {
+	now := time.Now()
+	job, err := testQueries.EnqueueJob(context.Background(), EnqueueJobParams{
+		Kind:        "test." + utils.RandomString(6),
+		Payload:     json.RawMessage(`{}`),
+		MaxAttempts: 3,
+		RunAt:       now.Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	jobs, err := testQueries.ClaimJobs(context.Background(), ClaimJobsParams{
+		Now:         now,
+		StaleBefore: now.Add(-time.Hour),
+		LimitCount:  1000,
+	})
+	require.NoError(t, err)
+
+	var claimed Job
+	for _, got := range jobs {
+		require.Equal(t, "running", got.Status)
+		if got.ID == job.ID {
+			claimed = got
+		}
+	}
+	require.Equal(t, job.ID, claimed.ID)
+
+	killed, err := testQueries.KillJob(context.Background(), KillJobParams{
+		ID:        job.ID,
+		LastError: sql.NullString{String: "failed", Valid: true},
+		LockedAt:  claimed.LockedAt.Time,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "dead", killed.Status)
+}
@@ -8,13 +8,14 @@
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 )
 
 const addMerchantBalance = `-- name: AddMerchantBalance :one
 UPDATE merchants
-SET balance = balance + $1
+SET balance = balance + $1, version = version + 1
 WHERE id = $2
-RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at
+RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version
 `
 
 type AddMerchantBalanceParams struct {
@@ -35,10 +36,47 @@ func (q *Queries) AddMerchantBalance(ctx context.Context, arg AddMerchantBalance
 		&i.ImageUrl,
 		&i.Rating,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
 
+const anonymizeUserMerchants = `-- name: AnonymizeUserMerchants :many
+UPDATE merchants
+SET owner = 'deleted_user',
+    title = 'Deleted merchant',
+    about = '',
+    image_url = DEFAULT,
+    image_variants = DEFAULT,
+    version = version + 1
+WHERE owner = $1
+RETURNING id
+`
+
+func (q *Queries) AnonymizeUserMerchants(ctx context.Context, owner string) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, anonymizeUserMerchants, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createMerchant = `-- name: CreateMerchant :one
 INSERT INTO merchants (
   owner,
@@ -47,7 +85,7 @@ func (q *Queries) AddMerchantBalance(ctx context.Context, arg AddMerchantBalance
   about
 ) VALUES (
   $1, $2, $3, $4
-) RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at
+) RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version
 `
 
 type CreateMerchantParams struct {
@@ -75,6 +113,8 @@ func (q *Queries) CreateMerchant(ctx context.Context, arg CreateMerchantParams)
 		&i.ImageUrl,
 		&i.Rating,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
@@ -90,7 +130,7 @@ func (q *Queries) DeleteMerchant(ctx context.Context, id int64) error {
 }
 
 const getMerchant = `-- name: GetMerchant :one
-SELECT id, owner, balance, profession, title, about, image_url, rating, created_at FROM merchants
+SELECT id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version FROM merchants
 WHERE id = $1 LIMIT 1
 `
 
@@ -107,12 +147,14 @@ func (q *Queries) GetMerchant(ctx context.Context, id int64) (Merchant, error) {
 		&i.ImageUrl,
 		&i.Rating,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
 
 const listMerchants = `-- name: ListMerchants :many
-SELECT id, owner, balance, profession, title, about, image_url, rating, created_at FROM merchants
+SELECT id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version FROM merchants
 WHERE owner = $1
 ORDER BY id
 LIMIT $2
@@ -144,6 +186,65 @@ func (q *Queries) ListMerchants(ctx context.Context, arg ListMerchantsParams) ([
 			&i.ImageUrl,
 			&i.Rating,
 			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchantsByCursor = `-- name: ListMerchantsByCursor :many
+SELECT id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version FROM merchants
+WHERE owner = $1 AND (
+  $2::timestamptz IS NULL OR
+  (created_at, id) > ($2::timestamptz, $3::bigint)
+)
+ORDER BY created_at, id
+LIMIT $4
+`
+
+type ListMerchantsByCursorParams struct {
+	Owner           string        `json:"owner"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	LimitCount      int32         `json:"limit_count"`
+}
+
+func (q *Queries) ListMerchantsByCursor(ctx context.Context, arg ListMerchantsByCursorParams) ([]Merchant, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantsByCursor,
+		arg.Owner,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Merchant{}
+	for rows.Next() {
+		var i Merchant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Profession,
+			&i.Title,
+			&i.About,
+			&i.ImageUrl,
+			&i.Rating,
+			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -158,6 +259,69 @@ func (q *Queries) ListMerchants(ctx context.Context, arg ListMerchantsParams) ([
 	return items, nil
 }
 
+const listMerchantsByOwner = `-- name: ListMerchantsByOwner :many
+SELECT id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version FROM merchants
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListMerchantsByOwner(ctx context.Context, owner string) ([]Merchant, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantsByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Merchant{}
+	for rows.Next() {
+		var i Merchant
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Profession,
+			&i.Title,
+			&i.About,
+			&i.ImageUrl,
+			&i.Rating,
+			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setMerchantImageVariants = `-- name: SetMerchantImageVariants :execrows
+UPDATE merchants
+SET image_variants = $1,
+    version = version + 1
+WHERE id = $2
+  AND image_url = $3
+`
+
+type SetMerchantImageVariantsParams struct {
+	ImageVariants json.RawMessage `json:"image_variants"`
+	ID            int64           `json:"id"`
+	ImageUrl      string          `json:"image_url"`
+}
+
+func (q *Queries) SetMerchantImageVariants(ctx context.Context, arg SetMerchantImageVariantsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setMerchantImageVariants, arg.ImageVariants, arg.ID, arg.ImageUrl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateMerchant = `-- name: UpdateMerchant :one
 UPDATE merchants
 SET balance = COALESCE($1, balance),
@@ -165,9 +329,11 @@ func (q *Queries) ListMerchants(ctx context.Context, arg ListMerchantsParams) ([
     title = COALESCE($3, title),
     about = COALESCE($4, about),
     image_url = COALESCE($5, image_url),
-    rating = COALESCE($6, rating)
+    rating = COALESCE($6, rating),
+    version = version + 1
 WHERE id = $7
-RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at
+  AND ($8::bigint IS NULL OR version = $8)
+RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version
 `
 
 type UpdateMerchantParams struct {
@@ -178,6 +344,7 @@ type UpdateMerchantParams struct {
 	ImageUrl   sql.NullString  `json:"image_url"`
 	Rating     sql.NullFloat64 `json:"rating"`
 	ID         int64           `json:"id"`
+	Version    sql.NullInt64   `json:"version"`
 }
 
 func (q *Queries) UpdateMerchant(ctx context.Context, arg UpdateMerchantParams) (Merchant, error) {
@@ -189,6 +356,48 @@ func (q *Queries) UpdateMerchant(ctx context.Context, arg UpdateMerchantParams)
 		arg.ImageUrl,
 		arg.Rating,
 		arg.ID,
+		arg.Version,
+	)
+	var i Merchant
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Profession,
+		&i.Title,
+		&i.About,
+		&i.ImageUrl,
+		&i.Rating,
+		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateMerchantImage = `-- name: UpdateMerchantImage :one
+UPDATE merchants
+SET image_url = $1,
+    image_variants = $2,
+    version = version + 1
+WHERE id = $3
+  AND ($4::bigint IS NULL OR version = $4)
+RETURNING id, owner, balance, profession, title, about, image_url, rating, created_at, image_variants, version
+`
+
+type UpdateMerchantImageParams struct {
+	ImageUrl      string          `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
+	ID            int64           `json:"id"`
+	Version       sql.NullInt64   `json:"version"`
+}
+
+func (q *Queries) UpdateMerchantImage(ctx context.Context, arg UpdateMerchantImageParams) (Merchant, error) {
+	row := q.db.QueryRowContext(ctx, updateMerchantImage,
+		arg.ImageUrl,
+		arg.ImageVariants,
+		arg.ID,
+		arg.Version,
 	)
 	var i Merchant
 	err := row.Scan(
@@ -201,6 +410,8 @@ func (q *Queries) UpdateMerchant(ctx context.Context, arg UpdateMerchantParams)
 		&i.ImageUrl,
 		&i.Rating,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Version,
 	)
 	return i, err
 }
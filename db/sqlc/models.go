@@ -7,6 +7,7 @@
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -62,6 +63,21 @@ type AppVersion struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username of the user, or cli:<name> for the admin commands
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	// the changed resource, like user:<username> or merchant:<id>
+	Target    string          `json:"target"`
+	Diff      json.RawMessage `json:"diff"`
+	CreatedAt time.Time       `json:"created_at"`
+	// client IP of the request, empty for the admin commands
+	Ip        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	RequestID string `json:"request_id"`
+}
+
 type Comment struct {
 	ID          int64       `json:"id"`
 	CommentType CommentType `json:"comment_type"`
@@ -88,6 +104,52 @@ type Customer struct {
 	Owner     string    `json:"owner"`
 	ImageUrl  string    `json:"image_url"`
 	CreatedAt time.Time `json:"created_at"`
+	// thumbnail urls of image_url keyed by variant name
+	ImageVariants json.RawMessage `json:"image_variants"`
+	// incremented on every update, the ETag of the customer
+	Version int64 `json:"version"`
+}
+
+type DeviceToken struct {
+	SessionID uuid.UUID `json:"session_id"`
+	Username  string    `json:"username"`
+	// fcm for android, apns for ios
+	Platform string `json:"platform"`
+	// a token moves to the session registering it last
+	Token string `json:"token"`
+	// the locale of the push texts, from the Accept-Language of the registration
+	Locale    string    `json:"locale"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	Username      string `json:"username"`
+	Key           string `json:"key"`
+	RequestMethod string `json:"request_method"`
+	RequestPath   string `json:"request_path"`
+	// sha256 of the method, the path and the body of the first request
+	RequestHash string `json:"request_hash"`
+	// null while the first request is in progress
+	ResponseStatus sql.NullInt32 `json:"response_status"`
+	ResponseBody   []byte        `json:"response_body"`
+	CreatedAt      time.Time     `json:"created_at"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+}
+
+type Job struct {
+	ID      int64           `json:"id"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload"`
+	// the finished jobs are deleted, the dead ones failed every attempt and wait for a manual retry
+	Status      string    `json:"status"`
+	Attempts    int32     `json:"attempts"`
+	MaxAttempts int32     `json:"max_attempts"`
+	RunAt       time.Time `json:"run_at"`
+	// a running job locked before the lease is taken over, since its worker is gone
+	LockedAt  sql.NullTime   `json:"locked_at"`
+	LastError sql.NullString `json:"last_error"`
+	CreatedAt time.Time      `json:"created_at"`
 }
 
 type Merchant struct {
@@ -100,6 +162,58 @@ type Merchant struct {
 	ImageUrl   string    `json:"image_url"`
 	Rating     float64   `json:"rating"`
 	CreatedAt  time.Time `json:"created_at"`
+	// thumbnail urls of image_url keyed by variant name
+	ImageVariants json.RawMessage `json:"image_variants"`
+	// incremented on every update, the ETag of the merchant
+	Version int64 `json:"version"`
+}
+
+type Notification struct {
+	ID        int64  `json:"id"`
+	Recipient string `json:"recipient"`
+	// one of post_commented, post_liked or consultancy_booked
+	EventType string `json:"event_type"`
+	// the user whose action is notified
+	Actor string `json:"actor"`
+	// the resource of the event, like post:42
+	Target string          `json:"target"`
+	Data   json.RawMessage `json:"data"`
+	// null while the notification is unread
+	ReadAt    sql.NullTime `json:"read_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	Username string `json:"username"`
+	// one of the notification types, or marketing
+	EventType string `json:"event_type"`
+	Channel   string `json:"channel"`
+	// the users without a row get the default of the event type
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type NotificationSetting struct {
+	Username string `json:"username"`
+	// the IANA time zone the quiet hours are in
+	TimeZone string `json:"time_zone"`
+	// minutes after midnight, the quiet hours wrap around midnight when they end earlier
+	QuietHoursStart sql.NullInt32 `json:"quiet_hours_start"`
+	QuietHoursEnd   sql.NullInt32 `json:"quiet_hours_end"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+type OutboxEvent struct {
+	// the events are deleted once the relay delivers them
+	ID int64 `json:"id"`
+	// the domain event, like user.registered
+	EventType string `json:"event_type"`
+	// the resource of the event, like user:alice
+	Aggregate string          `json:"aggregate"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	// the transaction appending the event. The relay delivers the events of the finished transactions only, in the order of (xact_id, id), so an event committed late is never passed over.
+	XactID int64 `json:"xact_id"`
 }
 
 type Post struct {
@@ -111,6 +225,23 @@ type Post struct {
 	ImageUrl  sql.NullString `json:"image_url"`
 	Likes     int32          `json:"likes"`
 	CreatedAt time.Time      `json:"created_at"`
+	// thumbnail urls of image_url keyed by variant name
+	ImageVariants json.RawMessage `json:"image_variants"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	// posts are soft deleted, null while the post is visible
+	DeletedAt sql.NullTime `json:"deleted_at"`
+}
+
+type PostRevision struct {
+	ID     int64  `json:"id"`
+	PostID int64  `json:"post_id"`
+	Editor string `json:"editor"`
+	// title of the post before the edit
+	Title sql.NullString `json:"title"`
+	// image_url of the post before the edit
+	ImageUrl      sql.NullString  `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
+	CreatedAt     time.Time       `json:"created_at"`
 }
 
 type Session struct {
@@ -136,4 +267,12 @@ type User struct {
 	BirthDate         time.Time `json:"birth_date"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// thumbnail urls of image_url keyed by variant name
+	ImageVariants json.RawMessage `json:"image_variants"`
+	// one of user, moderator or admin
+	Role string `json:"role"`
+	// the account is purged once this time passes, null if no deletion is requested
+	DeletionScheduledAt sql.NullTime `json:"deletion_scheduled_at"`
+	// incremented on every update, the ETag of the user
+	Version int64 `json:"version"`
 }
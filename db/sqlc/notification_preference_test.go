@@ -0,0 +1,55 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertNotificationSettings(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := UpsertNotificationSettingsParams{
+		Username:        user.Username,
+		TimeZone:        "Europe/Istanbul",
+		QuietHoursStart: sql.NullInt32{Int32: 22 * 60, Valid: true},
+		QuietHoursEnd:   sql.NullInt32{Int32: 7 * 60, Valid: true},
+	}
+	settings, err := testQueries.UpsertNotificationSettings(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.TimeZone, settings.TimeZone)
+	require.Equal(t, arg.QuietHoursStart, settings.QuietHoursStart)
+	require.Equal(t, arg.QuietHoursEnd, settings.QuietHoursEnd)
+
+	got, err := testQueries.GetNotificationSettings(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, settings.TimeZone, got.TimeZone)
+
+	require.NoError(t, testQueries.DeleteUserNotificationSettings(context.Background(), user.Username))
+}
+
+func TestUpsertNotificationPreference(t *testing.T) {
+	user := createRandomUser(t)
+
+	arg := UpsertNotificationPreferenceParams{
+		Username:  user.Username,
+		EventType: "post_commented",
+		Channel:   "push",
+		Enabled:   false,
+	}
+	_, err := testQueries.UpsertNotificationPreference(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.Enabled = true
+	preference, err := testQueries.UpsertNotificationPreference(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, preference.Enabled)
+
+	preferences, err := testQueries.ListNotificationPreferences(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, preferences, 1)
+
+	require.NoError(t, testQueries.DeleteUserNotificationPreferences(context.Background(), user.Username))
+}
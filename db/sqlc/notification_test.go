@@ -0,0 +1,52 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomNotification(t *testing.T, recipient, actor User) Notification {
+	arg := CreateNotificationParams{
+		Recipient: recipient.Username,
+		EventType: "post_commented",
+		Actor:     actor.Username,
+		Target:    "post:1",
+		Data:      json.RawMessage(`{"comment_id": 1}`),
+	}
+
+	notification, err := testQueries.CreateNotification(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, notification.ID)
+	require.Equal(t, arg.Recipient, notification.Recipient)
+	require.Equal(t, arg.Actor, notification.Actor)
+	require.False(t, notification.ReadAt.Valid)
+	require.WithinDuration(t, time.Now(), notification.CreatedAt, time.Second)
+
+	return notification
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	recipient := createRandomUser(t)
+	actor := createRandomUser(t)
+	notification := createRandomNotification(t, recipient, actor)
+	createRandomNotification(t, recipient, actor)
+
+	read, err := testQueries.MarkNotificationRead(context.Background(), MarkNotificationReadParams{
+		ID:        notification.ID,
+		Recipient: recipient.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, read.ReadAt.Valid)
+
+	count, err := testQueries.CountUnreadNotifications(context.Background(), recipient.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	marked, err := testQueries.MarkAllNotificationsRead(context.Background(), recipient.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), marked)
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Types of the domain events appended to the outbox
+const (
+	EventUserRegistered    = "user.registered"
+	EventUserDeleted       = "user.deleted"
+	EventPostCreated       = "post.created"
+	EventPostUpdated       = "post.updated"
+	EventCommentCreated    = "comment.created"
+	EventConsultancyBooked = "consultancy.booked"
+)
+
+// Event is a domain event, its payload is stored as JSON
+type Event struct {
+	Type      string
+	Aggregate string
+	Payload   interface{}
+}
+
+// UserRegisteredPayload is the payload of the user.registered events. The
+// personal data of the user is left out, the events may outlive the user.
+type UserRegisteredPayload struct {
+	Username string `json:"username"`
+}
+
+// UserRegistered returns the event of the registration of the user
+func UserRegistered(user User) Event {
+	return Event{
+		Type:      EventUserRegistered,
+		Aggregate: "user:" + user.Username,
+		Payload: UserRegisteredPayload{
+			Username: user.Username,
+		},
+	}
+}
+
+// UserDeletedPayload is the payload of the user.deleted events
+type UserDeletedPayload struct {
+	Username string `json:"username"`
+}
+
+// UserDeleted returns the event of the deletion of the user
+func UserDeleted(username string) Event {
+	return Event{
+		Type:      EventUserDeleted,
+		Aggregate: "user:" + username,
+		Payload: UserDeletedPayload{
+			Username: username,
+		},
+	}
+}
+
+// PostCreatedPayload is the payload of the post.created events
+type PostCreatedPayload struct {
+	PostID     int64  `json:"post_id"`
+	MerchantID int64  `json:"merchant_id"`
+	Title      string `json:"title,omitempty"`
+}
+
+// PostCreated returns the event of the creation of the post
+func PostCreated(post Post) Event {
+	return Event{
+		Type:      EventPostCreated,
+		Aggregate: fmt.Sprintf("post:%d", post.ID),
+		Payload: PostCreatedPayload{
+			PostID:     post.ID,
+			MerchantID: post.MerchantID,
+			Title:      post.Title.String,
+		},
+	}
+}
+
+// PostUpdatedPayload is the payload of the post.updated events
+type PostUpdatedPayload struct {
+	PostID     int64  `json:"post_id"`
+	MerchantID int64  `json:"merchant_id"`
+	RevisionID int64  `json:"revision_id"`
+	Title      string `json:"title,omitempty"`
+}
+
+// PostUpdated returns the event of the update of the post, the revision holds
+// the content before the update
+func PostUpdated(post Post, revision PostRevision) Event {
+	return Event{
+		Type:      EventPostUpdated,
+		Aggregate: fmt.Sprintf("post:%d", post.ID),
+		Payload: PostUpdatedPayload{
+			PostID:     post.ID,
+			MerchantID: post.MerchantID,
+			RevisionID: revision.ID,
+			Title:      post.Title.String,
+		},
+	}
+}
+
+// CommentCreatedPayload is the payload of the comment.created events, either
+// the post or the merchant is set depending on the comment type
+type CommentCreatedPayload struct {
+	CommentID   int64       `json:"comment_id"`
+	CommentType CommentType `json:"comment_type"`
+	PostID      int64       `json:"post_id,omitempty"`
+	MerchantID  int64       `json:"merchant_id,omitempty"`
+}
+
+// CommentCreated returns the event of the creation of the comment
+func CommentCreated(comment Comment) Event {
+	return Event{
+		Type:      EventCommentCreated,
+		Aggregate: fmt.Sprintf("comment:%d", comment.ID),
+		Payload: CommentCreatedPayload{
+			CommentID:   comment.ID,
+			CommentType: comment.CommentType,
+			PostID:      comment.PostID.Int64,
+			MerchantID:  comment.MerchantID.Int64,
+		},
+	}
+}
+
+// ConsultancyBookedPayload is the payload of the consultancy.booked events
+type ConsultancyBookedPayload struct {
+	ConsultancyID int64 `json:"consultancy_id"`
+	MerchantID    int64 `json:"merchant_id"`
+	CustomerID    int64 `json:"customer_id"`
+	Cost          int64 `json:"cost"`
+}
+
+// ConsultancyBooked returns the event of the booking of the consultancy
+func ConsultancyBooked(consultancy Consultancy) Event {
+	return Event{
+		Type:      EventConsultancyBooked,
+		Aggregate: fmt.Sprintf("consultancy:%d", consultancy.ID),
+		Payload: ConsultancyBookedPayload{
+			ConsultancyID: consultancy.ID,
+			MerchantID:    consultancy.MerchantID,
+			CustomerID:    consultancy.CustomerID,
+			Cost:          consultancy.Cost,
+		},
+	}
+}
+
+// AppendEvent appends the event to the outbox. It is meant to run in the
+// transaction making the change of the event, so that the event is stored if
+// and only if the change is. The event records its transaction, which the
+// relay waits for, so the concurrent transactions appending events do not
+// wait for each other.
+func AppendEvent(ctx context.Context, q Querier, event Event) (OutboxEvent, error) {
+	payload, err := json.Marshal(event.Payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	return q.AppendOutboxEvent(ctx, AppendOutboxEventParams{
+		EventType: event.Type,
+		Aggregate: event.Aggregate,
+		Payload:   payload,
+	})
+}
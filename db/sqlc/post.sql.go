@@ -8,26 +8,34 @@
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 )
 
 const createPost = `-- name: CreatePost :one
 INSERT INTO posts (
   merchant_id,
   title,
-  image_url
+  image_url,
+  image_variants
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, merchant_id, title, image_url, likes, created_at
+  $1, $2, $3, $4
+) RETURNING id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at
 `
 
 type CreatePostParams struct {
-	MerchantID int64          `json:"merchant_id"`
-	Title      sql.NullString `json:"title"`
-	ImageUrl   sql.NullString `json:"image_url"`
+	MerchantID    int64           `json:"merchant_id"`
+	Title         sql.NullString  `json:"title"`
+	ImageUrl      sql.NullString  `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost, arg.MerchantID, arg.Title, arg.ImageUrl)
+	row := q.db.QueryRowContext(ctx, createPost,
+		arg.MerchantID,
+		arg.Title,
+		arg.ImageUrl,
+		arg.ImageVariants,
+	)
 	var i Post
 	err := row.Scan(
 		&i.ID,
@@ -36,13 +44,30 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.ImageUrl,
 		&i.Likes,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
+const deleteOwnerPosts = `-- name: DeleteOwnerPosts :exec
+UPDATE posts
+SET deleted_at = now()
+WHERE deleted_at IS NULL AND merchant_id IN (
+  SELECT id FROM merchants WHERE owner = $1
+)
+`
+
+func (q *Queries) DeleteOwnerPosts(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, deleteOwnerPosts, owner)
+	return err
+}
+
 const deletePost = `-- name: DeletePost :exec
-DELETE FROM posts
-WHERE id = $1
+UPDATE posts
+SET deleted_at = now()
+WHERE id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) DeletePost(ctx context.Context, id int64) error {
@@ -51,8 +76,9 @@ func (q *Queries) DeletePost(ctx context.Context, id int64) error {
 }
 
 const getPost = `-- name: GetPost :one
-SELECT id, merchant_id, title, image_url, likes, created_at FROM posts
-WHERE id = $1 LIMIT 1
+SELECT id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at FROM posts
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
 `
 
 func (q *Queries) GetPost(ctx context.Context, id int64) (Post, error) {
@@ -65,13 +91,40 @@ func (q *Queries) GetPost(ctx context.Context, id int64) (Post, error) {
 		&i.ImageUrl,
 		&i.Likes,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getPostForUpdate = `-- name: GetPostForUpdate :one
+SELECT id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at FROM posts
+WHERE id = $1 AND deleted_at IS NULL
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetPostForUpdate(ctx context.Context, id int64) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostForUpdate, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Title,
+		&i.ImageUrl,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.UpdatedAt,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const listMerchantPosts = `-- name: ListMerchantPosts :many
-SELECT id, merchant_id, title, image_url, likes, created_at FROM posts
-WHERE merchant_id = $1
+SELECT id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at FROM posts
+WHERE merchant_id = $1 AND deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $2
 OFFSET $3
@@ -99,6 +152,64 @@ func (q *Queries) ListMerchantPosts(ctx context.Context, arg ListMerchantPostsPa
 			&i.ImageUrl,
 			&i.Likes,
 			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMerchantPostsByCursor = `-- name: ListMerchantPostsByCursor :many
+SELECT id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at FROM posts
+WHERE merchant_id = $1 AND deleted_at IS NULL AND (
+  $2::timestamptz IS NULL OR
+  (created_at, id) < ($2::timestamptz, $3::bigint)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListMerchantPostsByCursorParams struct {
+	MerchantID      int64         `json:"merchant_id"`
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	LimitCount      int32         `json:"limit_count"`
+}
+
+func (q *Queries) ListMerchantPostsByCursor(ctx context.Context, arg ListMerchantPostsByCursorParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, listMerchantPostsByCursor,
+		arg.MerchantID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.LimitCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Post{}
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Title,
+			&i.ImageUrl,
+			&i.Likes,
+			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -114,7 +225,8 @@ func (q *Queries) ListMerchantPosts(ctx context.Context, arg ListMerchantPostsPa
 }
 
 const listPosts = `-- name: ListPosts :many
-SELECT id, merchant_id, title, image_url, likes, created_at FROM posts
+SELECT id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at FROM posts
+WHERE deleted_at IS NULL
 ORDER BY created_at DESC
 LIMIT $1
 OFFSET $2
@@ -141,6 +253,9 @@ func (q *Queries) ListPosts(ctx context.Context, arg ListPostsParams) ([]Post, e
 			&i.ImageUrl,
 			&i.Likes,
 			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -154,3 +269,150 @@ func (q *Queries) ListPosts(ctx context.Context, arg ListPostsParams) ([]Post, e
 	}
 	return items, nil
 }
+
+const listPostsByCursor = `-- name: ListPostsByCursor :many
+SELECT id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at FROM posts
+WHERE deleted_at IS NULL AND (
+  $1::timestamptz IS NULL OR
+  (created_at, id) < ($1::timestamptz, $2::bigint)
+)
+ORDER BY created_at DESC, id DESC
+LIMIT $3
+`
+
+type ListPostsByCursorParams struct {
+	CursorCreatedAt sql.NullTime  `json:"cursor_created_at"`
+	CursorID        sql.NullInt64 `json:"cursor_id"`
+	LimitCount      int32         `json:"limit_count"`
+}
+
+func (q *Queries) ListPostsByCursor(ctx context.Context, arg ListPostsByCursorParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, listPostsByCursor, arg.CursorCreatedAt, arg.CursorID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Post{}
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Title,
+			&i.ImageUrl,
+			&i.Likes,
+			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPostsByOwner = `-- name: ListPostsByOwner :many
+SELECT posts.id, posts.merchant_id, posts.title, posts.image_url, posts.likes, posts.created_at, posts.image_variants, posts.updated_at, posts.deleted_at FROM posts
+JOIN merchants ON merchants.id = posts.merchant_id
+WHERE merchants.owner = $1
+ORDER BY posts.id
+`
+
+func (q *Queries) ListPostsByOwner(ctx context.Context, owner string) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, listPostsByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Post{}
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.MerchantID,
+			&i.Title,
+			&i.ImageUrl,
+			&i.Likes,
+			&i.CreatedAt,
+			&i.ImageVariants,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setPostImageVariants = `-- name: SetPostImageVariants :execrows
+UPDATE posts
+SET image_variants = $1
+WHERE id = $2
+  AND image_url = $3::varchar
+  AND deleted_at IS NULL
+`
+
+type SetPostImageVariantsParams struct {
+	ImageVariants json.RawMessage `json:"image_variants"`
+	ID            int64           `json:"id"`
+	ImageUrl      string          `json:"image_url"`
+}
+
+func (q *Queries) SetPostImageVariants(ctx context.Context, arg SetPostImageVariantsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setPostImageVariants, arg.ImageVariants, arg.ID, arg.ImageUrl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const updatePost = `-- name: UpdatePost :one
+UPDATE posts
+SET title = $2, image_url = $3, image_variants = $4, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, merchant_id, title, image_url, likes, created_at, image_variants, updated_at, deleted_at
+`
+
+type UpdatePostParams struct {
+	ID            int64           `json:"id"`
+	Title         sql.NullString  `json:"title"`
+	ImageUrl      sql.NullString  `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
+}
+
+func (q *Queries) UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, updatePost,
+		arg.ID,
+		arg.Title,
+		arg.ImageUrl,
+		arg.ImageVariants,
+	)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.MerchantID,
+		&i.Title,
+		&i.ImageUrl,
+		&i.Likes,
+		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
@@ -3,6 +3,8 @@
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"sort"
 	"testing"
 	"time"
@@ -11,6 +13,14 @@
 	"github.com/stretchr/testify/require"
 )
 
+func randomImageVariants() json.RawMessage {
+	return json.RawMessage(fmt.Sprintf(`{"small": "%s", "medium": "%s"}`, utils.RandomImageUrl(), utils.RandomImageUrl()))
+}
+
+func emptyImageVariants() json.RawMessage {
+	return json.RawMessage("{}")
+}
+
 func createRandomPost(t *testing.T, merchant Merchant) Post {
 	arg := CreatePostParams{
 		MerchantID: merchant.ID,
@@ -22,6 +32,7 @@ func createRandomPost(t *testing.T, merchant Merchant) Post {
 			String: utils.RandomImageUrl(),
 			Valid:  true,
 		},
+		ImageVariants: randomImageVariants(),
 	}
 
 	post, err := testQueries.CreatePost(context.Background(), arg)
@@ -31,6 +42,7 @@ func createRandomPost(t *testing.T, merchant Merchant) Post {
 	require.Equal(t, arg.ImageUrl, post.ImageUrl)
 	require.Equal(t, arg.MerchantID, post.MerchantID)
 	require.Equal(t, arg.Title, post.Title)
+	require.JSONEq(t, string(arg.ImageVariants), string(post.ImageVariants))
 	require.NotEmpty(t, post.CreatedAt)
 	require.NotEmpty(t, post.ID)
 	require.Equal(t, int32(0), post.Likes)
@@ -50,6 +62,7 @@ func TestCreatePost(t *testing.T) {
 			String: utils.RandomImageUrl(),
 			Valid:  true,
 		},
+		ImageVariants: randomImageVariants(),
 	}
 
 	post, err := testQueries.CreatePost(context.Background(), arg)
@@ -59,6 +72,7 @@ func TestCreatePost(t *testing.T) {
 	require.Equal(t, arg.ImageUrl, post.ImageUrl)
 	require.Equal(t, arg.MerchantID, post.MerchantID)
 	require.Equal(t, arg.Title, post.Title)
+	require.JSONEq(t, string(arg.ImageVariants), string(post.ImageVariants))
 	require.NotEmpty(t, post.CreatedAt)
 	require.NotEmpty(t, post.ID)
 	require.Equal(t, int32(0), post.Likes)
@@ -75,6 +89,49 @@ func TestDeletePost(t *testing.T) {
 	require.Empty(t, post)
 }
 
+func TestDeletePostHidesFromLists(t *testing.T) {
+	merchant := createRandomMerchant(t)
+	post := createRandomPost(t, merchant)
+	deleted := createRandomPost(t, merchant)
+
+	user := createRandomUser(t)
+	_, err := testQueries.CreateComment(context.Background(), CreateCommentParams{
+		CommentType: CommentTypePost,
+		PostID:      sql.NullInt64{Int64: deleted.ID, Valid: true},
+		Owner:       user.Username,
+		Comment:     utils.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	// a post referenced by comments can still be deleted
+	err = testQueries.DeletePost(context.Background(), deleted.ID)
+	require.NoError(t, err)
+
+	posts, err := testQueries.ListMerchantPosts(context.Background(), ListMerchantPostsParams{
+		MerchantID: merchant.ID,
+		Limit:      10,
+		Offset:     0,
+	})
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	require.Equal(t, post.ID, posts[0].ID)
+
+	comments, err := testQueries.ListPostComments(context.Background(), ListPostCommentsParams{
+		PostID: sql.NullInt64{Int64: deleted.ID, Valid: true},
+		Limit:  10,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, comments)
+
+	_, err = testQueries.UpdatePost(context.Background(), UpdatePostParams{
+		ID:            deleted.ID,
+		Title:         deleted.Title,
+		ImageVariants: emptyImageVariants(),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
 func TestGetPost(t *testing.T) {
 	merchant := createRandomMerchant(t)
 	expected := createRandomPost(t, merchant)
@@ -153,3 +210,39 @@ func TestListPosts(t *testing.T) {
 		}
 	}
 }
+
+func TestListMerchantPostsByCursor(t *testing.T) {
+	merchant := createRandomMerchant(t)
+	for i := 0; i < 10; i++ {
+		createRandomPost(t, merchant)
+	}
+
+	arg := ListMerchantPostsByCursorParams{
+		MerchantID: merchant.ID,
+		LimitCount: 5,
+	}
+
+	firstPage, err := testQueries.ListMerchantPostsByCursor(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, firstPage, 5)
+
+	last := firstPage[len(firstPage)-1]
+	arg.CursorCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+	arg.CursorID = sql.NullInt64{Int64: last.ID, Valid: true}
+
+	secondPage, err := testQueries.ListMerchantPostsByCursor(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, secondPage, 5)
+
+	posts := append(firstPage, secondPage...)
+	seen := make(map[int64]bool)
+	for i, post := range posts {
+		require.False(t, seen[post.ID])
+		seen[post.ID] = true
+		if i > 0 {
+			prev := posts[i-1]
+			require.True(t, post.CreatedAt.Before(prev.CreatedAt) ||
+				post.CreatedAt.Equal(prev.CreatedAt) && post.ID < prev.ID)
+		}
+	}
+}
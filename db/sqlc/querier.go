@@ -6,46 +6,119 @@
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type Querier interface {
 	AddMerchantBalance(ctx context.Context, arg AddMerchantBalanceParams) (Merchant, error)
+	AnonymizeUserComments(ctx context.Context, owner string) error
+	AnonymizeUserCustomers(ctx context.Context, owner string) ([]int64, error)
+	AnonymizeUserMerchants(ctx context.Context, owner string) ([]int64, error)
+	AnonymizeUserNotifications(ctx context.Context, actor string) error
+	AnonymizeUserPostRevisions(ctx context.Context, editor string) error
+	AppendOutboxEvent(ctx context.Context, arg AppendOutboxEventParams) (OutboxEvent, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	ClaimJobs(ctx context.Context, arg ClaimJobsParams) ([]Job, error)
+	CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) (IdempotencyKey, error)
+	CompleteJob(ctx context.Context, arg CompleteJobParams) (int64, error)
+	CountUnreadNotifications(ctx context.Context, recipient string) (int64, error)
 	CreateAppVersion(ctx context.Context, arg CreateAppVersionParams) (AppVersion, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
 	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
 	CreateConsultancy(ctx context.Context, arg CreateConsultancyParams) (Consultancy, error)
 	CreateCustomer(ctx context.Context, owner string) (Customer, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
 	CreateMerchant(ctx context.Context, arg CreateMerchantParams) (Merchant, error)
+	CreateNotification(ctx context.Context, arg CreateNotificationParams) (Notification, error)
 	CreatePost(ctx context.Context, arg CreatePostParams) (Post, error)
+	CreatePostRevision(ctx context.Context, arg CreatePostRevisionParams) (PostRevision, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) error
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
 	DeleteComment(ctx context.Context, id int64) error
 	DeleteCustomer(ctx context.Context, id int64) error
+	DeleteDeviceToken(ctx context.Context, sessionID uuid.UUID) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
 	DeleteMerchant(ctx context.Context, id int64) error
+	DeleteOutboxEvent(ctx context.Context, id int64) error
+	DeleteOwnerPosts(ctx context.Context, owner string) error
 	DeletePost(ctx context.Context, id int64) error
 	DeleteUser(ctx context.Context, username string) error
+	DeleteUserDeviceTokens(ctx context.Context, username string) error
+	DeleteUserIdempotencyKeys(ctx context.Context, username string) error
+	DeleteUserNotificationPreferences(ctx context.Context, username string) error
+	DeleteUserNotificationSettings(ctx context.Context, username string) error
+	DeleteUserNotifications(ctx context.Context, recipient string) error
+	DeleteUserSessions(ctx context.Context, username string) error
+	EnqueueJob(ctx context.Context, arg EnqueueJobParams) (Job, error)
 	GetAppVersion(ctx context.Context, tag string) (AppVersion, error)
 	GetComment(ctx context.Context, id int64) (Comment, error)
 	GetConsultancy(ctx context.Context, id int64) (Consultancy, error)
 	GetCustomer(ctx context.Context, id int64) (Customer, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
 	GetMerchant(ctx context.Context, id int64) (Merchant, error)
+	GetNotificationSettings(ctx context.Context, username string) (NotificationSetting, error)
 	GetPost(ctx context.Context, id int64) (Post, error)
+	GetPostForUpdate(ctx context.Context, id int64) (Post, error)
 	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	KillJob(ctx context.Context, arg KillJobParams) (Job, error)
+	KillStaleJobs(ctx context.Context, arg KillStaleJobsParams) ([]Job, error)
+	ListAllUserDeviceTokens(ctx context.Context, username string) ([]DeviceToken, error)
 	ListAppVersions(ctx context.Context) ([]AppVersion, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsByCursor(ctx context.Context, arg ListAuditEventsByCursorParams) ([]AuditEvent, error)
+	ListCommentsByOwner(ctx context.Context, owner string) ([]Comment, error)
 	ListConsultancies(ctx context.Context, arg ListConsultanciesParams) ([]Consultancy, error)
+	ListConsultanciesByOwner(ctx context.Context, owner string) ([]Consultancy, error)
+	ListCustomersByOwner(ctx context.Context, owner string) ([]Customer, error)
+	ListDeadJobs(ctx context.Context, arg ListDeadJobsParams) ([]Job, error)
 	ListMerchantComments(ctx context.Context, arg ListMerchantCommentsParams) ([]Comment, error)
 	ListMerchantPosts(ctx context.Context, arg ListMerchantPostsParams) ([]Post, error)
+	ListMerchantPostsByCursor(ctx context.Context, arg ListMerchantPostsByCursorParams) ([]Post, error)
 	ListMerchants(ctx context.Context, arg ListMerchantsParams) ([]Merchant, error)
+	ListMerchantsByCursor(ctx context.Context, arg ListMerchantsByCursorParams) ([]Merchant, error)
+	ListMerchantsByOwner(ctx context.Context, owner string) ([]Merchant, error)
+	ListNotificationPreferences(ctx context.Context, username string) ([]NotificationPreference, error)
+	ListNotifications(ctx context.Context, arg ListNotificationsParams) ([]Notification, error)
+	ListNotificationsByCursor(ctx context.Context, arg ListNotificationsByCursorParams) ([]Notification, error)
+	ListOutboxEventsForUpdate(ctx context.Context, limit int32) ([]OutboxEvent, error)
 	ListPostComments(ctx context.Context, arg ListPostCommentsParams) ([]Comment, error)
+	ListPostCommentsByCursor(ctx context.Context, arg ListPostCommentsByCursorParams) ([]Comment, error)
+	ListPostRevisions(ctx context.Context, arg ListPostRevisionsParams) ([]PostRevision, error)
+	ListPostRevisionsByCursor(ctx context.Context, arg ListPostRevisionsByCursorParams) ([]PostRevision, error)
 	ListPosts(ctx context.Context, arg ListPostsParams) ([]Post, error)
+	ListPostsByCursor(ctx context.Context, arg ListPostsByCursorParams) ([]Post, error)
+	ListPostsByOwner(ctx context.Context, owner string) ([]Post, error)
+	ListUserAuditEvents(ctx context.Context, username string) ([]AuditEvent, error)
+	ListUserDeviceTokens(ctx context.Context, username string) ([]DeviceToken, error)
+	ListUserNotifications(ctx context.Context, recipient string) ([]Notification, error)
+	ListUserSessions(ctx context.Context, username string) ([]Session, error)
+	ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]string, error)
+	MarkAllNotificationsRead(ctx context.Context, recipient string) (int64, error)
+	MarkNotificationRead(ctx context.Context, arg MarkNotificationReadParams) (Notification, error)
+	RequeueDeadJob(ctx context.Context, id int64) (Job, error)
+	RetryJob(ctx context.Context, arg RetryJobParams) (Job, error)
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (User, error)
+	SetCustomerImageVariants(ctx context.Context, arg SetCustomerImageVariantsParams) (int64, error)
+	SetMerchantImageVariants(ctx context.Context, arg SetMerchantImageVariantsParams) (int64, error)
+	SetPostImageVariants(ctx context.Context, arg SetPostImageVariantsParams) (int64, error)
+	SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) (User, error)
+	SetUserImageVariants(ctx context.Context, arg SetUserImageVariantsParams) (int64, error)
 	UpdateAppVersion(ctx context.Context, arg UpdateAppVersionParams) (AppVersion, error)
 	UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error)
 	UpdateEmail(ctx context.Context, arg UpdateEmailParams) (User, error)
 	UpdateMerchant(ctx context.Context, arg UpdateMerchantParams) (Merchant, error)
+	UpdateMerchantImage(ctx context.Context, arg UpdateMerchantImageParams) (Merchant, error)
 	UpdatePassword(ctx context.Context, arg UpdatePasswordParams) (User, error)
+	UpdatePost(ctx context.Context, arg UpdatePostParams) (Post, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserImage(ctx context.Context, arg UpdateUserImageParams) (User, error)
+	UpsertDeviceToken(ctx context.Context, arg UpsertDeviceTokenParams) (DeviceToken, error)
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error)
+	UpsertNotificationSettings(ctx context.Context, arg UpsertNotificationSettingsParams) (NotificationSetting, error)
 }
 
 var _ Querier = (*Queries)(nil)
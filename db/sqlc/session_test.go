@@ -69,3 +69,29 @@ func TestGetSession(t *testing.T) {
 	require.NotEmpty(t, session)
 	require.Equal(t, expected, session)
 }
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	session1 := createRandomSession(t, user)
+	session2 := createRandomSession(t, user)
+	otherSession := createRandomSession(t, createRandomUser(t))
+
+	blocked, err := testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), blocked)
+
+	for _, session := range []Session{session1, session2} {
+		session, err = testQueries.GetSession(context.Background(), session.ID)
+		require.NoError(t, err)
+		require.True(t, session.IsBlocked)
+	}
+
+	otherSession, err = testQueries.GetSession(context.Background(), otherSession.ID)
+	require.NoError(t, err)
+	require.False(t, otherSession.IsBlocked)
+
+	// blocked sessions are not counted again
+	blocked, err = testQueries.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, blocked)
+}
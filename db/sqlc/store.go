@@ -1,13 +1,29 @@
 package db
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
 
-// Store provides all functions to execute db queries
+// Store provides all functions to execute db queries and transactions
 type Store interface {
 	Querier
+	UpdatePostTx(ctx context.Context, arg UpdatePostTxParams) (UpdatePostTxResult, error)
+	DeleteUserTx(ctx context.Context, username string) (DeleteUserTxResult, error)
+	ExportUserTx(ctx context.Context, username string) (ExportUserTxResult, error)
+	AuditTx(ctx context.Context, arg AuditTxParams) (AuditEvent, error)
+	UpdateNotificationPreferencesTx(ctx context.Context, arg UpdateNotificationPreferencesTxParams) (UpdateNotificationPreferencesTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error)
+	CreatePostTx(ctx context.Context, arg CreatePostParams) (Post, error)
+	CreateCommentTx(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	CreateConsultancyTx(ctx context.Context, arg CreateConsultancyParams) (Consultancy, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (int, error)
+	Ping(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (SchemaVersion, error)
 }
 
-// SQLStore provides all functions to execute SQL queries
+// SQLStore provides all functions to execute SQL queries and transactions
 type SQLStore struct {
 	*Queries
 	db *sql.DB
@@ -20,3 +36,28 @@ func NewStore(db *sql.DB) Store {
 		Queries: New(db),
 	}
 }
+
+// execTx executes a function within a database transaction
+func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+	return store.execTxOptions(ctx, nil, fn)
+}
+
+// execTxOptions executes a function within a database transaction started
+// with the given options, such as a stricter isolation level
+func (store *SQLStore) execTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	q := New(tx)
+	err = fn(q)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
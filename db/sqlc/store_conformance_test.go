@@ -0,0 +1,27 @@
+package db_test
+
+import (
+	"database/sql"
+	"testing"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/db/storetest"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// The suite imports this package, so it runs from the external test package
+// with a connection of its own
+func TestStoreConformance(t *testing.T) {
+	config, err := utils.LoadConfig("../..")
+	require.NoError(t, err)
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	store := db.NewStore(conn)
+	storetest.Run(t, func(t *testing.T) db.Store {
+		return store
+	})
+}
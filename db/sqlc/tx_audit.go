@@ -0,0 +1,43 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// AuditTxParams contains the input parameters of the audit transaction
+type AuditTxParams struct {
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	Target string `json:"target"`
+	// Apply makes the audited change and returns its diff, which is stored as JSON
+	Apply func(q Querier) (interface{}, error) `json:"-"`
+}
+
+// AuditTx makes a change and records it as an audit event within a single
+// database transaction, so that no change is left without its event
+func (store *SQLStore) AuditTx(ctx context.Context, arg AuditTxParams) (AuditEvent, error) {
+	var event AuditEvent
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		diff, err := arg.Apply(q)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.Marshal(diff)
+		if err != nil {
+			return err
+		}
+
+		event, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+			Actor:  arg.Actor,
+			Action: arg.Action,
+			Target: arg.Target,
+			Diff:   data,
+		})
+		return err
+	})
+
+	return event, err
+}
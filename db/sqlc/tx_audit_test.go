@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	actor := "cli:" + utils.RandomOwner()
+
+	event, err := store.AuditTx(context.Background(), AuditTxParams{
+		Actor:  actor,
+		Action: "user.disable",
+		Target: "user:" + user.Username,
+		Apply: func(q Querier) (interface{}, error) {
+			_, err := q.SetUserDisabled(context.Background(), SetUserDisabledParams{
+				Username: user.Username,
+				Disabled: true,
+			})
+			return map[string]bool{"disabled": true}, err
+		},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, event.ID)
+	require.Equal(t, actor, event.Actor)
+	require.Equal(t, "user.disable", event.Action)
+	require.Equal(t, "user:"+user.Username, event.Target)
+	require.NotZero(t, event.CreatedAt)
+
+	var diff map[string]bool
+	require.NoError(t, json.Unmarshal(event.Diff, &diff))
+	require.True(t, diff["disabled"])
+
+	updatedUser, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.True(t, updatedUser.Disabled)
+}
+
+func TestAuditTxRollback(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	applyErr := errors.New("apply failed")
+
+	_, err := store.AuditTx(context.Background(), AuditTxParams{
+		Actor:  "cli:" + utils.RandomOwner(),
+		Action: "user.disable",
+		Target: "user:" + user.Username,
+		Apply: func(q Querier) (interface{}, error) {
+			_, err := q.SetUserDisabled(context.Background(), SetUserDisabledParams{
+				Username: user.Username,
+				Disabled: true,
+			})
+			require.NoError(t, err)
+			return nil, applyErr
+		},
+	})
+	require.ErrorIs(t, err, applyErr)
+
+	unchangedUser, err := store.GetUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.False(t, unchangedUser.Disabled)
+}
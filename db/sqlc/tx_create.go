@@ -0,0 +1,79 @@
+package db
+
+import "context"
+
+// CreateUserTx creates the user and appends its user.registered event within
+// a single database transaction
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = AppendEvent(ctx, q, UserRegistered(user))
+		return err
+	})
+
+	return user, err
+}
+
+// CreatePostTx creates the post and appends its post.created event within a
+// single database transaction
+func (store *SQLStore) CreatePostTx(ctx context.Context, arg CreatePostParams) (Post, error) {
+	var post Post
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		post, err = q.CreatePost(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = AppendEvent(ctx, q, PostCreated(post))
+		return err
+	})
+
+	return post, err
+}
+
+// CreateCommentTx creates the comment and appends its comment.created event
+// within a single database transaction
+func (store *SQLStore) CreateCommentTx(ctx context.Context, arg CreateCommentParams) (Comment, error) {
+	var comment Comment
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		comment, err = q.CreateComment(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = AppendEvent(ctx, q, CommentCreated(comment))
+		return err
+	})
+
+	return comment, err
+}
+
+// CreateConsultancyTx creates the consultancy and appends its
+// consultancy.booked event within a single database transaction
+func (store *SQLStore) CreateConsultancyTx(ctx context.Context, arg CreateConsultancyParams) (Consultancy, error) {
+	var consultancy Consultancy
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		consultancy, err = q.CreateConsultancy(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = AppendEvent(ctx, q, ConsultancyBooked(consultancy))
+		return err
+	})
+
+	return consultancy, err
+}
@@ -0,0 +1,71 @@
+package db
+
+import "context"
+
+// DeleteUserTxResult holds the anonymized accounts of the deleted user, whose
+// uploads are left for the caller to remove from the blob storage
+type DeleteUserTxResult struct {
+	MerchantIDs []int64 `json:"merchant_ids"`
+	CustomerIDs []int64 `json:"customer_ids"`
+}
+
+// DeleteUserTx purges the user within a single database transaction. Comments,
+// post revisions and the notifications the user caused are reassigned to the
+// deleted user placeholder, customer and merchant accounts are anonymized to
+// keep the consultancy records, posts are soft deleted, and device tokens,
+// sessions, idempotency keys, the inbox and the notification preferences are
+// removed before the user row is removed. Its user.deleted event is appended in
+// the same transaction.
+func (store *SQLStore) DeleteUserTx(ctx context.Context, username string) (DeleteUserTxResult, error) {
+	var result DeleteUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		if err := q.DeleteUserDeviceTokens(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserSessions(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserIdempotencyKeys(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserNotifications(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserNotificationPreferences(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteUserNotificationSettings(ctx, username); err != nil {
+			return err
+		}
+		if err := q.AnonymizeUserNotifications(ctx, username); err != nil {
+			return err
+		}
+		if err := q.AnonymizeUserComments(ctx, username); err != nil {
+			return err
+		}
+		if err := q.AnonymizeUserPostRevisions(ctx, username); err != nil {
+			return err
+		}
+		if err := q.DeleteOwnerPosts(ctx, username); err != nil {
+			return err
+		}
+		var err error
+		result.CustomerIDs, err = q.AnonymizeUserCustomers(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.MerchantIDs, err = q.AnonymizeUserMerchants(ctx, username)
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteUser(ctx, username); err != nil {
+			return err
+		}
+
+		_, err = AppendEvent(ctx, q, UserDeleted(username))
+		return err
+	})
+
+	return result, err
+}
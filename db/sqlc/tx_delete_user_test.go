@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+const deletedUsername = "deleted_user"
+
+func TestDeleteUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	merchant := createRandomMerchant(t)
+	user, err := store.GetUser(context.Background(), merchant.Owner)
+	require.NoError(t, err)
+
+	customer, err := store.CreateCustomer(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	post := createRandomPost(t, merchant)
+	comment := createRandomComment(t, createRandomPost(t, createRandomMerchant(t)), merchant, user)
+	consultancy := createRandomConsultancy(t, createRandomMerchant(t), customer)
+	session := createRandomSession(t, user)
+
+	result, err := store.DeleteUserTx(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []int64{merchant.ID}, result.MerchantIDs)
+	require.Equal(t, []int64{customer.ID}, result.CustomerIDs)
+
+	_, err = store.GetUser(context.Background(), user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetSession(context.Background(), session.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetPost(context.Background(), post.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	anonymizedComment, err := store.GetComment(context.Background(), comment.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUsername, anonymizedComment.Owner)
+	require.Equal(t, comment.Comment, anonymizedComment.Comment)
+
+	anonymizedMerchant, err := store.GetMerchant(context.Background(), merchant.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUsername, anonymizedMerchant.Owner)
+	require.Equal(t, merchant.Balance, anonymizedMerchant.Balance)
+	require.NotEqual(t, merchant.Title, anonymizedMerchant.Title)
+
+	anonymizedCustomer, err := store.GetCustomer(context.Background(), customer.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUsername, anonymizedCustomer.Owner)
+
+	// financial records are kept
+	keptConsultancy, err := store.GetConsultancy(context.Background(), consultancy.ID)
+	require.NoError(t, err)
+	require.Equal(t, consultancy, keptConsultancy)
+}
+
+func TestListUsersDueForDeletion(t *testing.T) {
+	store := NewStore(testDB)
+	due := createRandomUser(t)
+	pending := createRandomUser(t)
+
+	_, err := store.ScheduleUserDeletion(context.Background(), ScheduleUserDeletionParams{
+		Username:            due.Username,
+		DeletionScheduledAt: sql.NullTime{Time: time.Now().Add(-time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.ScheduleUserDeletion(context.Background(), ScheduleUserDeletionParams{
+		Username:            pending.Username,
+		DeletionScheduledAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+
+	usernames, err := store.ListUsersDueForDeletion(context.Background(), ListUsersDueForDeletionParams{
+		Now:        time.Now(),
+		LimitCount: 1000,
+	})
+	require.NoError(t, err)
+	require.Contains(t, usernames, due.Username)
+	require.NotContains(t, usernames, pending.Username)
+}
+
+func TestExportUserTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	merchant := createRandomMerchant(t)
+	post := createRandomPost(t, merchant)
+	user, err := store.GetUser(context.Background(), merchant.Owner)
+	require.NoError(t, err)
+	session := createRandomSession(t, user)
+
+	result, err := store.ExportUserTx(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user, result.User)
+	require.Len(t, result.Merchants, 1)
+	require.Equal(t, merchant.ID, result.Merchants[0].ID)
+	require.Len(t, result.Posts, 1)
+	require.Equal(t, post.ID, result.Posts[0].ID)
+	require.Empty(t, result.Customers)
+	require.Empty(t, result.Comments)
+	require.Empty(t, result.Consultancies)
+	require.Len(t, result.Sessions, 1)
+	require.Equal(t, session.ID, result.Sessions[0].ID)
+}
+
+func TestExportUserTxSnapshot(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	user := createRandomUser(t)
+
+	err := store.execTxOptions(context.Background(), exportUserTxOptions, func(q *Queries) error {
+		before, err := q.GetUser(context.Background(), user.Username)
+		require.NoError(t, err)
+
+		// a change committed during the export is not part of it
+		_, err = testQueries.UpdateEmail(context.Background(), UpdateEmailParams{
+			Username: user.Username,
+			Email:    utils.RandomEmail(),
+		})
+		require.NoError(t, err)
+
+		after, err := q.GetUser(context.Background(), user.Username)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+
+		_, err = q.UpdateEmail(context.Background(), UpdateEmailParams{
+			Username: user.Username,
+			Email:    utils.RandomEmail(),
+		})
+		return err
+	})
+	require.ErrorContains(t, err, "read-only transaction")
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// ExportUserTxResult holds every record stored about a user
+type ExportUserTxResult struct {
+	User          User           `json:"user"`
+	Customers     []Customer     `json:"customers"`
+	Merchants     []Merchant     `json:"merchants"`
+	Posts         []Post         `json:"posts"`
+	Comments      []Comment      `json:"comments"`
+	Consultancies []Consultancy  `json:"consultancies"`
+	Sessions      []Session      `json:"sessions"`
+	Notifications []Notification `json:"notifications"`
+	DeviceTokens  []DeviceToken  `json:"device_tokens"`
+	// NotificationSettings is nil when the user has never set them
+	NotificationSettings    *NotificationSetting     `json:"notification_settings"`
+	NotificationPreferences []NotificationPreference `json:"notification_preferences"`
+	// AuditEvents are the events of the user and the ones of the admins on the user
+	AuditEvents []AuditEvent `json:"audit_events"`
+}
+
+// exportUserTxOptions run the export in a read-only repeatable read transaction,
+// every query of which sees the snapshot taken by the first one
+var exportUserTxOptions = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+// ExportUserTx reads all records of the user within a single database
+// transaction so the export is a consistent snapshot
+func (store *SQLStore) ExportUserTx(ctx context.Context, username string) (ExportUserTxResult, error) {
+	var result ExportUserTxResult
+
+	err := store.execTxOptions(ctx, exportUserTxOptions, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.GetUser(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Customers, err = q.ListCustomersByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Merchants, err = q.ListMerchantsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Posts, err = q.ListPostsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Comments, err = q.ListCommentsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Consultancies, err = q.ListConsultanciesByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Sessions, err = q.ListUserSessions(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.Notifications, err = q.ListUserNotifications(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.DeviceTokens, err = q.ListAllUserDeviceTokens(ctx, username)
+		if err != nil {
+			return err
+		}
+		settings, err := q.GetNotificationSettings(ctx, username)
+		if err == nil {
+			result.NotificationSettings = &settings
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		result.NotificationPreferences, err = q.ListNotificationPreferences(ctx, username)
+		if err != nil {
+			return err
+		}
+		result.AuditEvents, err = q.ListUserAuditEvents(ctx, username)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,52 @@
+package db
+
+import "context"
+
+// RelayOutboxTxParams contains the input parameters of the relay outbox transaction
+type RelayOutboxTxParams struct {
+	Limit int32 `json:"limit"`
+	// Deliver is called with the events in the order of their transactions and
+	// ids, the relay stops at its first error
+	Deliver func(event OutboxEvent) error `json:"-"`
+}
+
+// RelayOutboxTx delivers the oldest events of the outbox and deletes the
+// delivered ones within a single database transaction. Only the events of the
+// transactions older than every running one are delivered, since a running
+// transaction may still commit events ordered before the later ones. A long
+// running transaction holds the delivery back until it ends. The events stay locked
+// while they are delivered, so concurrent relays deliver them one batch at a
+// time and in order. The number of the delivered events is returned along with
+// the error of the delivery, the events delivered before the error are deleted
+// anyway. An event whose deletion fails is delivered again, the delivery is at
+// least once.
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (int, error) {
+	var delivered int
+	var deliverErr error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		events, err := q.ListOutboxEventsForUpdate(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			deliverErr = arg.Deliver(event)
+			if deliverErr != nil {
+				break
+			}
+
+			err = q.DeleteOutboxEvent(ctx, event.ID)
+			if err != nil {
+				return err
+			}
+			delivered++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return delivered, deliverErr
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayOutboxTx(t *testing.T) {
+	store := NewStore(testDB)
+	merchant := createRandomMerchant(t)
+
+	post, err := store.CreatePostTx(context.Background(), CreatePostParams{
+		MerchantID:    merchant.ID,
+		Title:         sql.NullString{String: utils.RandomString(12), Valid: true},
+		ImageVariants: json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+
+	// the failed delivery leaves the event in the outbox
+	aggregate := fmt.Sprintf("post:%d", post.ID)
+	sinkErr := errors.New("sink is down")
+	_, err = store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+		Limit: 1000,
+		Deliver: func(event OutboxEvent) error {
+			if event.Aggregate == aggregate {
+				return sinkErr
+			}
+			return nil
+		},
+	})
+	require.ErrorIs(t, err, sinkErr)
+
+	var found []OutboxEvent
+	for {
+		delivered, err := store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Limit: 1000,
+			Deliver: func(event OutboxEvent) error {
+				if event.Aggregate == aggregate {
+					found = append(found, event)
+				}
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		if delivered == 0 {
+			break
+		}
+	}
+	require.Len(t, found, 1)
+	require.Equal(t, EventPostCreated, found[0].EventType)
+
+	var payload PostCreatedPayload
+	require.NoError(t, json.Unmarshal(found[0].Payload, &payload))
+	require.Equal(t, post.ID, payload.PostID)
+	require.Equal(t, merchant.ID, payload.MerchantID)
+}
+
+func TestRelayOutboxTxWaitsForRunningTransactions(t *testing.T) {
+	store := NewStore(testDB)
+	merchant := createRandomMerchant(t)
+
+	relay := func() []OutboxEvent {
+		var events []OutboxEvent
+		_, err := store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Limit: 1000,
+			Deliver: func(event OutboxEvent) error {
+				events = append(events, event)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		return events
+	}
+	relay()
+
+	// the event of the running transaction is appended first but commits last
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	running, err := AppendEvent(context.Background(), New(tx), Event{
+		Type:      EventPostCreated,
+		Aggregate: "post:" + utils.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	post, err := store.CreatePostTx(context.Background(), CreatePostParams{
+		MerchantID:    merchant.ID,
+		Title:         sql.NullString{String: utils.RandomString(12), Valid: true},
+		ImageVariants: json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+	committed := fmt.Sprintf("post:%d", post.ID)
+
+	// the committed event waits for the running transaction, which may still
+	// commit the events ordered before it
+	for _, event := range relay() {
+		require.NotEqual(t, committed, event.Aggregate)
+	}
+
+	require.NoError(t, tx.Commit())
+
+	var aggregates []string
+	for _, event := range relay() {
+		if event.Aggregate == running.Aggregate || event.Aggregate == committed {
+			aggregates = append(aggregates, event.Aggregate)
+		}
+	}
+	require.Equal(t, []string{running.Aggregate, committed}, aggregates)
+}
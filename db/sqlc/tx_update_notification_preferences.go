@@ -0,0 +1,45 @@
+package db
+
+import "context"
+
+// UpdateNotificationPreferencesTxParams contains the input parameters of the
+// update notification preferences transaction
+type UpdateNotificationPreferencesTxParams struct {
+	Settings    UpsertNotificationSettingsParams     `json:"settings"`
+	Preferences []UpsertNotificationPreferenceParams `json:"preferences"`
+}
+
+// UpdateNotificationPreferencesTxResult is the result of the update
+// notification preferences transaction
+type UpdateNotificationPreferencesTxResult struct {
+	Settings    NotificationSetting      `json:"settings"`
+	Preferences []NotificationPreference `json:"preferences"`
+}
+
+// UpdateNotificationPreferencesTx replaces the notification settings of the
+// user and sets the given preferences within a single database transaction.
+// The preferences that are not given are kept. The result has every stored
+// preference of the user.
+func (store *SQLStore) UpdateNotificationPreferencesTx(ctx context.Context, arg UpdateNotificationPreferencesTxParams) (UpdateNotificationPreferencesTxResult, error) {
+	var result UpdateNotificationPreferencesTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Settings, err = q.UpsertNotificationSettings(ctx, arg.Settings)
+		if err != nil {
+			return err
+		}
+
+		for _, preference := range arg.Preferences {
+			preference.Username = arg.Settings.Username
+			if _, err := q.UpsertNotificationPreference(ctx, preference); err != nil {
+				return err
+			}
+		}
+
+		result.Preferences, err = q.ListNotificationPreferences(ctx, arg.Settings.Username)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+// UpdatePostTxParams contains the input parameters of the update post transaction
+type UpdatePostTxParams struct {
+	ID     int64  `json:"id"`
+	Editor string `json:"editor"`
+	// Title replaces the title unless it is null, ClearTitle removes it
+	Title      sql.NullString `json:"title"`
+	ClearTitle bool           `json:"clear_title"`
+	// ImageUrl and ImageVariants replace the image unless ImageUrl is null,
+	// ClearImage removes it
+	ImageUrl      sql.NullString  `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
+	ClearImage    bool            `json:"clear_image"`
+}
+
+// Apply returns the content of the post with the changes of the update
+func (arg UpdatePostTxParams) Apply(post Post) UpdatePostParams {
+	update := UpdatePostParams{
+		ID:            post.ID,
+		Title:         post.Title,
+		ImageUrl:      post.ImageUrl,
+		ImageVariants: post.ImageVariants,
+	}
+	if arg.Title.Valid {
+		update.Title = arg.Title
+	}
+	if arg.ClearTitle {
+		update.Title = sql.NullString{}
+	}
+	if arg.ImageUrl.Valid {
+		update.ImageUrl = arg.ImageUrl
+		update.ImageVariants = arg.ImageVariants
+	}
+	if arg.ClearImage {
+		update.ImageUrl = sql.NullString{}
+		update.ImageVariants = json.RawMessage("{}")
+	}
+	return update
+}
+
+// UpdatePostTxResult is the result of the update post transaction
+type UpdatePostTxResult struct {
+	Post     Post         `json:"post"`
+	Revision PostRevision `json:"revision"`
+}
+
+// UpdatePostTx stores the current content of the post as a revision, applies
+// the changes to it and appends its post.updated event within a single
+// database transaction. The changes are applied to the locked post, so the
+// concurrent updates do not overwrite each other.
+func (store *SQLStore) UpdatePostTx(ctx context.Context, arg UpdatePostTxParams) (UpdatePostTxResult, error) {
+	var result UpdatePostTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		post, err := q.GetPostForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Revision, err = q.CreatePostRevision(ctx, CreatePostRevisionParams{
+			PostID:        post.ID,
+			Editor:        arg.Editor,
+			Title:         post.Title,
+			ImageUrl:      post.ImageUrl,
+			ImageVariants: post.ImageVariants,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Post, err = q.UpdatePost(ctx, arg.Apply(post))
+		if err != nil {
+			return err
+		}
+
+		_, err = AppendEvent(ctx, q, PostUpdated(result.Post, result.Revision))
+		return err
+	})
+
+	return result, err
+}
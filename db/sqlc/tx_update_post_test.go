@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePostTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	merchant := createRandomMerchant(t)
+	post := createRandomPost(t, merchant)
+
+	arg := UpdatePostTxParams{
+		ID:     post.ID,
+		Editor: merchant.Owner,
+		Title: sql.NullString{
+			String: utils.RandomString(6),
+			Valid:  true,
+		},
+		ClearImage: true,
+	}
+
+	result, err := store.UpdatePostTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	updated := result.Post
+	require.Equal(t, post.ID, updated.ID)
+	require.Equal(t, arg.Title, updated.Title)
+	require.False(t, updated.ImageUrl.Valid)
+	require.JSONEq(t, "{}", string(updated.ImageVariants))
+	require.True(t, updated.UpdatedAt.After(post.UpdatedAt))
+
+	revision := result.Revision
+	require.NotZero(t, revision.ID)
+	require.Equal(t, post.ID, revision.PostID)
+	require.Equal(t, merchant.Owner, revision.Editor)
+	require.Equal(t, post.Title, revision.Title)
+	require.Equal(t, post.ImageUrl, revision.ImageUrl)
+	require.JSONEq(t, string(post.ImageVariants), string(revision.ImageVariants))
+
+	revisions, err := store.ListPostRevisions(context.Background(), ListPostRevisionsParams{
+		PostID: post.ID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, revision.ID, revisions[0].ID)
+}
+
+func TestUpdatePostTxKeepsTitleOrImage(t *testing.T) {
+	store := NewStore(testDB)
+
+	merchant := createRandomMerchant(t)
+	post := createRandomPost(t, merchant)
+
+	_, err := store.UpdatePostTx(context.Background(), UpdatePostTxParams{
+		ID:         post.ID,
+		Editor:     merchant.Owner,
+		ClearTitle: true,
+		ClearImage: true,
+	})
+	require.Error(t, err)
+
+	// the transaction is rolled back along with the revision
+	revisions, err := store.ListPostRevisions(context.Background(), ListPostRevisionsParams{
+		PostID: post.ID,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, revisions)
+
+	current, err := store.GetPost(context.Background(), post.ID)
+	require.NoError(t, err)
+	require.Equal(t, post.Title, current.Title)
+}
+
+func TestUpdatePostTxDeletedPost(t *testing.T) {
+	store := NewStore(testDB)
+
+	merchant := createRandomMerchant(t)
+	post := createRandomPost(t, merchant)
+
+	err := store.DeletePost(context.Background(), post.ID)
+	require.NoError(t, err)
+
+	_, err = store.UpdatePostTx(context.Background(), UpdatePostTxParams{
+		ID:     post.ID,
+		Editor: merchant.Owner,
+		Title:  post.Title,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -8,9 +8,32 @@
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+const anonymizeUserComments = `-- name: AnonymizeUserComments :exec
+UPDATE comments
+SET owner = 'deleted_user'
+WHERE owner = $1
+`
+
+func (q *Queries) AnonymizeUserComments(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, anonymizeUserComments, owner)
+	return err
+}
+
+const anonymizeUserPostRevisions = `-- name: AnonymizeUserPostRevisions :exec
+UPDATE post_revisions
+SET editor = 'deleted_user'
+WHERE editor = $1
+`
+
+func (q *Queries) AnonymizeUserPostRevisions(ctx context.Context, editor string) error {
+	_, err := q.db.ExecContext(ctx, anonymizeUserPostRevisions, editor)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (
   username,
@@ -22,7 +45,7 @@
   birth_date
 ) VALUES (
   $1, $2, $3, $4, $5, $6, $7
-) RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at
+) RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
 `
 
 type CreateUserParams struct {
@@ -58,6 +81,10 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.BirthDate,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -73,7 +100,7 @@ func (q *Queries) DeleteUser(ctx context.Context, username string) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at FROM users
+SELECT username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version FROM users
 WHERE username = $1 LIMIT 1
 `
 
@@ -92,15 +119,146 @@ func (q *Queries) GetUser(ctx context.Context, username string) (User, error) {
 		&i.BirthDate,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT username FROM users
+WHERE deletion_scheduled_at <= $1::timestamptz
+ORDER BY deletion_scheduled_at
+LIMIT $2
+`
+
+type ListUsersDueForDeletionParams struct {
+	Now        time.Time `json:"now"`
+	LimitCount int32     `json:"limit_count"`
+}
+
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listUsersDueForDeletion, arg.Now, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		items = append(items, username)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users
+SET deletion_scheduled_at = $2, version = version + 1
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
+`
+
+type ScheduleUserDeletionParams struct {
+	Username            string       `json:"username"`
+	DeletionScheduledAt sql.NullTime `json:"deletion_scheduled_at"`
+}
+
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, scheduleUserDeletion, arg.Username, arg.DeletionScheduledAt)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.ImageUrl,
+		&i.Gender,
+		&i.Disabled,
+		&i.BirthDate,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
 	)
 	return i, err
 }
 
+const setUserDisabled = `-- name: SetUserDisabled :one
+UPDATE users
+SET disabled = $2, version = version + 1
+WHERE username = $1
+RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
+`
+
+type SetUserDisabledParams struct {
+	Username string `json:"username"`
+	Disabled bool   `json:"disabled"`
+}
+
+func (q *Queries) SetUserDisabled(ctx context.Context, arg SetUserDisabledParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, setUserDisabled, arg.Username, arg.Disabled)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.ImageUrl,
+		&i.Gender,
+		&i.Disabled,
+		&i.BirthDate,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const setUserImageVariants = `-- name: SetUserImageVariants :execrows
+UPDATE users
+SET image_variants = $1,
+    version = version + 1
+WHERE username = $2
+  AND image_url = $3
+`
+
+type SetUserImageVariantsParams struct {
+	ImageVariants json.RawMessage `json:"image_variants"`
+	Username      string          `json:"username"`
+	ImageUrl      string          `json:"image_url"`
+}
+
+func (q *Queries) SetUserImageVariants(ctx context.Context, arg SetUserImageVariantsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, setUserImageVariants, arg.ImageVariants, arg.Username, arg.ImageUrl)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const updateEmail = `-- name: UpdateEmail :one
 UPDATE users
-SET email = $2
+SET email = $2, version = version + 1
 WHERE username = $1
-RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at
+RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
 `
 
 type UpdateEmailParams struct {
@@ -123,15 +281,19 @@ func (q *Queries) UpdateEmail(ctx context.Context, arg UpdateEmailParams) (User,
 		&i.BirthDate,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const updatePassword = `-- name: UpdatePassword :one
 UPDATE users
-SET hashed_password = $2, password_changed_at = $3
+SET hashed_password = $2, password_changed_at = $3, version = version + 1
 WHERE username = $1
-RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at
+RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
 `
 
 type UpdatePasswordParams struct {
@@ -155,6 +317,10 @@ func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams)
 		&i.BirthDate,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -165,9 +331,11 @@ func (q *Queries) UpdatePassword(ctx context.Context, arg UpdatePasswordParams)
     phone_number = COALESCE($2, phone_number),
     gender = COALESCE($3, gender), 
     birth_date = COALESCE($4, birth_date),
-    image_url = COALESCE($5, image_url)
+    image_url = COALESCE($5, image_url),
+    version = version + 1
 WHERE username = $6
-RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at
+  AND ($7::bigint IS NULL OR version = $7)
+RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
 `
 
 type UpdateUserParams struct {
@@ -177,6 +345,7 @@ type UpdateUserParams struct {
 	BirthDate   sql.NullTime   `json:"birth_date"`
 	ImageUrl    sql.NullString `json:"image_url"`
 	Username    string         `json:"username"`
+	Version     sql.NullInt64  `json:"version"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -187,6 +356,52 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.BirthDate,
 		arg.ImageUrl,
 		arg.Username,
+		arg.Version,
+	)
+	var i User
+	err := row.Scan(
+		&i.Username,
+		&i.HashedPassword,
+		&i.FullName,
+		&i.Email,
+		&i.PhoneNumber,
+		&i.ImageUrl,
+		&i.Gender,
+		&i.Disabled,
+		&i.BirthDate,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateUserImage = `-- name: UpdateUserImage :one
+UPDATE users
+SET image_url = $1,
+    image_variants = $2,
+    version = version + 1
+WHERE username = $3
+  AND ($4::bigint IS NULL OR version = $4)
+RETURNING username, hashed_password, full_name, email, phone_number, image_url, gender, disabled, birth_date, password_changed_at, created_at, image_variants, role, deletion_scheduled_at, version
+`
+
+type UpdateUserImageParams struct {
+	ImageUrl      string          `json:"image_url"`
+	ImageVariants json.RawMessage `json:"image_variants"`
+	Username      string          `json:"username"`
+	Version       sql.NullInt64   `json:"version"`
+}
+
+func (q *Queries) UpdateUserImage(ctx context.Context, arg UpdateUserImageParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserImage,
+		arg.ImageUrl,
+		arg.ImageVariants,
+		arg.Username,
+		arg.Version,
 	)
 	var i User
 	err := row.Scan(
@@ -201,6 +416,10 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.BirthDate,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.ImageVariants,
+		&i.Role,
+		&i.DeletionScheduledAt,
+		&i.Version,
 	)
 	return i, err
 }
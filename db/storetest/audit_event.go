@@ -0,0 +1,224 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var auditEventChecks = []check{
+	{"CreateAuditEvent", testCreateAuditEvent},
+	{"ListAuditEvents", testListAuditEvents},
+	{"ListAuditEventsByCursor", testListAuditEventsByCursor},
+	{"ListUserAuditEvents", testListUserAuditEvents},
+}
+
+func requireAuditEvent(t *testing.T, expected, actual db.AuditEvent) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Actor, actual.Actor)
+	require.Equal(t, expected.Action, actual.Action)
+	require.Equal(t, expected.Target, actual.Target)
+	require.Equal(t, expected.Ip, actual.Ip)
+	require.Equal(t, expected.UserAgent, actual.UserAgent)
+	require.Equal(t, expected.RequestID, actual.RequestID)
+	require.JSONEq(t, string(expected.Diff), string(actual.Diff))
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+// createAuditEvents creates the events of an actor of its own, the last one
+// is the newest. The actions alternate between create and delete.
+func createAuditEvents(t *testing.T, store db.Store, n int) []db.AuditEvent {
+	actor := "cli:" + utils.RandomOwner()
+	events := make([]db.AuditEvent, n)
+	for i := range events {
+		action := "merchant.create"
+		if i%2 == 1 {
+			action = "merchant.delete"
+		}
+
+		tick()
+		var err error
+		events[i], err = store.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+			Actor:  actor,
+			Action: action,
+			Target: "merchant:" + utils.RandomString(6),
+			Diff:   []byte(`{"index": ` + string(rune('0'+i)) + `}`),
+		})
+		require.NoError(t, err)
+	}
+	return events
+}
+
+func auditEventIDs(events ...db.AuditEvent) []int64 {
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func testCreateAuditEvent(t *testing.T, store db.Store) {
+	arg := db.CreateAuditEventParams{
+		Actor:     utils.RandomOwner(),
+		Action:    "user.update",
+		Target:    "user:" + utils.RandomOwner(),
+		Ip:        "10.0.0.1",
+		UserAgent: utils.RandomString(10),
+		RequestID: utils.RandomString(16),
+		Diff:      []byte(`{"full_name": "new"}`),
+	}
+	event, err := store.CreateAuditEvent(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, event.ID)
+	require.Equal(t, arg.Actor, event.Actor)
+	require.Equal(t, arg.Action, event.Action)
+	require.Equal(t, arg.Target, event.Target)
+	require.Equal(t, arg.Ip, event.Ip)
+	require.Equal(t, arg.UserAgent, event.UserAgent)
+	require.Equal(t, arg.RequestID, event.RequestID)
+	require.JSONEq(t, string(arg.Diff), string(event.Diff))
+	require.WithinDuration(t, time.Now(), event.CreatedAt, time.Minute)
+
+	arg.Diff = nil
+	_, err = store.CreateAuditEvent(ctx, arg)
+	requireCode(t, err, "not_null_violation")
+
+	arg.Diff = []byte("{")
+	_, err = store.CreateAuditEvent(ctx, arg)
+	requireCode(t, err, "invalid_text_representation")
+}
+
+func testListAuditEvents(t *testing.T, store db.Store) {
+	events := createAuditEvents(t, store, 5)
+	actor := nullString(events[0].Actor)
+
+	list := func(arg db.ListAuditEventsParams) []db.AuditEvent {
+		arg.Actor = actor
+		if arg.LimitCount == 0 {
+			arg.LimitCount = 10
+		}
+		got, err := store.ListAuditEvents(ctx, arg)
+		require.NoError(t, err)
+		return got
+	}
+
+	// the newest events come first
+	got := list(db.ListAuditEventsParams{})
+	require.Len(t, got, len(events))
+	for i := range got {
+		requireAuditEvent(t, events[len(events)-1-i], got[i])
+	}
+
+	got = list(db.ListAuditEventsParams{LimitCount: 2, OffsetCount: 1})
+	require.Equal(t, auditEventIDs(events[3], events[2]), auditEventIDs(got...))
+
+	got = list(db.ListAuditEventsParams{OffsetCount: 5})
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	// the filters are combined
+	got = list(db.ListAuditEventsParams{Action: nullString("merchant.delete")})
+	require.Equal(t, auditEventIDs(events[3], events[1]), auditEventIDs(got...))
+
+	got = list(db.ListAuditEventsParams{Target: nullString(events[2].Target)})
+	require.Equal(t, auditEventIDs(events[2]), auditEventIDs(got...))
+
+	got = list(db.ListAuditEventsParams{
+		Action: nullString("merchant.delete"),
+		Target: nullString(events[2].Target),
+	})
+	require.Empty(t, got)
+
+	// the time range includes its start but not its end
+	got = list(db.ListAuditEventsParams{
+		FromTime: nullTime(events[1].CreatedAt),
+		ToTime:   nullTime(events[3].CreatedAt),
+	})
+	require.Equal(t, auditEventIDs(events[2], events[1]), auditEventIDs(got...))
+
+	got, err := store.ListAuditEvents(ctx, db.ListAuditEventsParams{Actor: actor})
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+			Actor:       actor,
+			LimitCount:  limit,
+			OffsetCount: offset,
+		})
+		return err
+	})
+}
+
+func testListAuditEventsByCursor(t *testing.T, store db.Store) {
+	events := createAuditEvents(t, store, 5)
+	actor := nullString(events[0].Actor)
+
+	// the pages are newest first, each one starts after the last event of
+	// the previous one
+	var got []db.AuditEvent
+	arg := db.ListAuditEventsByCursorParams{Actor: actor, LimitCount: 2}
+	for {
+		page, err := store.ListAuditEventsByCursor(ctx, arg)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, page...)
+
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = nullTime(last.CreatedAt)
+		arg.CursorID = nullInt64(last.ID)
+	}
+	require.Equal(t, auditEventIDs(events[4], events[3], events[2], events[1], events[0]), auditEventIDs(got...))
+
+	// the filters apply to the pages too
+	page, err := store.ListAuditEventsByCursor(ctx, db.ListAuditEventsByCursorParams{
+		Actor:           actor,
+		Action:          nullString("merchant.create"),
+		CursorCreatedAt: nullTime(events[4].CreatedAt),
+		CursorID:        nullInt64(events[4].ID),
+		LimitCount:      10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, auditEventIDs(events[2], events[0]), auditEventIDs(page...))
+
+	page, err = store.ListAuditEventsByCursor(ctx, db.ListAuditEventsByCursorParams{Actor: actor})
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Empty(t, page)
+
+	_, err = store.ListAuditEventsByCursor(ctx, db.ListAuditEventsByCursorParams{Actor: actor, LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+func testListUserAuditEvents(t *testing.T, store db.Store) {
+	username := utils.RandomOwner()
+
+	create := func(actor, target string) db.AuditEvent {
+		event, err := store.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+			Actor:  actor,
+			Action: "user.update",
+			Target: target,
+			Diff:   []byte(`{}`),
+		})
+		require.NoError(t, err)
+		return event
+	}
+
+	// the events of the user and the ones of the admins on the user
+	own := create(username, "merchant:"+utils.RandomString(6))
+	admin := create("cli:"+utils.RandomOwner(), "user:"+username)
+	create(utils.RandomOwner(), "user:"+utils.RandomOwner())
+
+	events, err := store.ListUserAuditEvents(ctx, username)
+	require.NoError(t, err)
+	require.Equal(t, auditEventIDs(own, admin), auditEventIDs(events...))
+	requireAuditEvent(t, own, events[0])
+}
@@ -0,0 +1,275 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var commentChecks = []check{
+	{"CreateComment", testCreateComment},
+	{"GetComment", testGetComment},
+	{"ListPostComments", testListPostComments},
+	{"ListPostCommentsByCursor", testListPostCommentsByCursor},
+	{"ListMerchantComments", testListMerchantComments},
+	{"ListCommentsByOwner", testListCommentsByOwner},
+	{"DeleteComment", testDeleteComment},
+}
+
+func requireComment(t *testing.T, expected, actual db.Comment) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.CommentType, actual.CommentType)
+	require.Equal(t, expected.PostID, actual.PostID)
+	require.Equal(t, expected.MerchantID, actual.MerchantID)
+	require.Equal(t, expected.Owner, actual.Owner)
+	require.Equal(t, expected.Comment, actual.Comment)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func createRandomComments(t *testing.T, store db.Store, postID int64, owner string, n int) []db.Comment {
+	comments := make([]db.Comment, n)
+	for i := range comments {
+		comments[i] = createRandomComment(t, store, postID, owner)
+	}
+	return comments
+}
+
+func testCreateComment(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+
+	arg := db.CreateCommentParams{
+		CommentType: db.CommentTypePost,
+		PostID:      nullInt64(post.ID),
+		Owner:       user.Username,
+		Comment:     utils.RandomString(20),
+	}
+	comment, err := store.CreateComment(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, comment.ID)
+	require.Equal(t, arg.CommentType, comment.CommentType)
+	require.Equal(t, arg.PostID, comment.PostID)
+	require.False(t, comment.MerchantID.Valid)
+	require.Equal(t, arg.Owner, comment.Owner)
+	require.Equal(t, arg.Comment, comment.Comment)
+	require.WithinDuration(t, time.Now(), comment.CreatedAt, time.Minute)
+
+	comment, err = store.CreateComment(ctx, db.CreateCommentParams{
+		CommentType: db.CommentTypeMerchant,
+		MerchantID:  nullInt64(merchant.ID),
+		Owner:       user.Username,
+		Comment:     utils.RandomString(20),
+	})
+	require.NoError(t, err)
+	require.Equal(t, db.CommentTypeMerchant, comment.CommentType)
+	require.False(t, comment.PostID.Valid)
+	require.Equal(t, merchant.ID, comment.MerchantID.Int64)
+
+	invalid := arg
+	invalid.CommentType = "user"
+	_, err = store.CreateComment(ctx, invalid)
+	requireCode(t, err, "invalid_text_representation")
+
+	invalid = arg
+	invalid.PostID = nullInt64(-1)
+	_, err = store.CreateComment(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+
+	invalid = arg
+	invalid.Owner = utils.RandomOwner()
+	_, err = store.CreateComment(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetComment(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+	comment := createRandomComment(t, store, post.ID, user.Username)
+
+	got, err := store.GetComment(ctx, comment.ID)
+	require.NoError(t, err)
+	requireComment(t, comment, got)
+
+	_, err = store.GetComment(ctx, -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListPostComments(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	createRandomComment(t, store, createRandomPost(t, store, merchant.ID).ID, user.Username)
+	comments := createRandomComments(t, store, post.ID, user.Username, 5)
+
+	list := func(limit, offset int32) ([]db.Comment, error) {
+		return store.ListPostComments(ctx, db.ListPostCommentsParams{
+			PostID: nullInt64(post.ID),
+			Limit:  limit,
+			Offset: offset,
+		})
+	}
+
+	// the oldest comments come first
+	got, err := list(10, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(comments))
+	for i := range comments {
+		requireComment(t, comments[i], got[i])
+	}
+
+	got, err = list(2, 3)
+	require.NoError(t, err)
+	require.Equal(t, commentIDs(comments[3:]), commentIDs(got))
+
+	got, err = list(10, 5)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := list(limit, offset)
+		return err
+	})
+
+	// the comments of the deleted posts are hidden
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	got, err = list(10, 0)
+	require.NoError(t, err)
+	require.Empty(t, got)
+}
+
+func testListPostCommentsByCursor(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	createRandomComment(t, store, createRandomPost(t, store, merchant.ID).ID, user.Username)
+	comments := createRandomComments(t, store, post.ID, user.Username, 5)
+
+	// the pages are oldest first, each one starts after the last comment of
+	// the previous one
+	var got []db.Comment
+	arg := db.ListPostCommentsByCursorParams{PostID: nullInt64(post.ID), LimitCount: 2}
+	for {
+		page, err := store.ListPostCommentsByCursor(ctx, arg)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, page...)
+
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = nullTime(last.CreatedAt)
+		arg.CursorID = nullInt64(last.ID)
+	}
+	require.Equal(t, commentIDs(comments), commentIDs(got))
+
+	_, err := store.ListPostCommentsByCursor(ctx, db.ListPostCommentsByCursorParams{PostID: nullInt64(post.ID), LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	page, err := store.ListPostCommentsByCursor(ctx, db.ListPostCommentsByCursorParams{PostID: nullInt64(post.ID), LimitCount: 10})
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Empty(t, page)
+}
+
+func testListMerchantComments(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	createRandomComment(t, store, createRandomPost(t, store, merchant.ID).ID, user.Username)
+
+	comments := make([]db.Comment, 3)
+	for i := range comments {
+		var err error
+		comments[i], err = store.CreateComment(ctx, db.CreateCommentParams{
+			CommentType: db.CommentTypeMerchant,
+			MerchantID:  nullInt64(merchant.ID),
+			Owner:       user.Username,
+			Comment:     utils.RandomString(20),
+		})
+		require.NoError(t, err)
+	}
+
+	list := func(limit, offset int32) ([]db.Comment, error) {
+		return store.ListMerchantComments(ctx, db.ListMerchantCommentsParams{
+			MerchantID: nullInt64(merchant.ID),
+			Limit:      limit,
+			Offset:     offset,
+		})
+	}
+
+	got, err := list(10, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(comments))
+	for i := range comments {
+		requireComment(t, comments[i], got[i])
+	}
+
+	got, err = list(1, 1)
+	require.NoError(t, err)
+	require.Equal(t, commentIDs(comments[1:2]), commentIDs(got))
+
+	got, err = list(10, 3)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := list(limit, offset)
+		return err
+	})
+}
+
+func testListCommentsByOwner(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	other := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, other.Username)
+
+	var comments []db.Comment
+	for i := 0; i < 2; i++ {
+		post := createRandomPost(t, store, merchant.ID)
+		createRandomComment(t, store, post.ID, other.Username)
+		comments = append(comments, createRandomComments(t, store, post.ID, user.Username, 2)...)
+	}
+
+	// the comments of the deleted posts are listed too, since the list is an
+	// export of the user data
+	require.NoError(t, store.DeletePost(ctx, comments[0].PostID.Int64))
+
+	got, err := store.ListCommentsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, got, len(comments))
+	for i := range comments {
+		requireComment(t, comments[i], got[i])
+	}
+
+	got, err = store.ListCommentsByOwner(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+}
+
+func testDeleteComment(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+	comment := createRandomComment(t, store, post.ID, user.Username)
+	other := createRandomComment(t, store, post.ID, user.Username)
+
+	require.NoError(t, store.DeleteComment(ctx, comment.ID))
+	_, err := store.GetComment(ctx, comment.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetComment(ctx, other.ID)
+	require.NoError(t, err)
+
+	// deleting a missing comment is not an error
+	require.NoError(t, store.DeleteComment(ctx, comment.ID))
+}
@@ -0,0 +1,160 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var consultancyChecks = []check{
+	{"CreateConsultancy", testCreateConsultancy},
+	{"GetConsultancy", testGetConsultancy},
+	{"ListConsultancies", testListConsultancies},
+	{"ListConsultanciesByOwner", testListConsultanciesByOwner},
+}
+
+func requireConsultancy(t *testing.T, expected, actual db.Consultancy) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.MerchantID, actual.MerchantID)
+	require.Equal(t, expected.CustomerID, actual.CustomerID)
+	require.Equal(t, expected.Cost, actual.Cost)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func createRandomConsultancy(t *testing.T, store db.Store, merchantID, customerID int64) db.Consultancy {
+	consultancy, err := store.CreateConsultancy(ctx, db.CreateConsultancyParams{
+		MerchantID: merchantID,
+		CustomerID: customerID,
+		Cost:       utils.RandomMoney(),
+	})
+	require.NoError(t, err)
+	return consultancy
+}
+
+func consultancyIDs(consultancies []db.Consultancy) []int64 {
+	ids := make([]int64, len(consultancies))
+	for i, consultancy := range consultancies {
+		ids[i] = consultancy.ID
+	}
+	return ids
+}
+
+func testCreateConsultancy(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	arg := db.CreateConsultancyParams{
+		MerchantID: merchant.ID,
+		CustomerID: customer.ID,
+		Cost:       utils.RandomMoney(),
+	}
+	consultancy, err := store.CreateConsultancy(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, consultancy.ID)
+	require.Equal(t, arg.MerchantID, consultancy.MerchantID)
+	require.Equal(t, arg.CustomerID, consultancy.CustomerID)
+	require.Equal(t, arg.Cost, consultancy.Cost)
+	require.WithinDuration(t, time.Now(), consultancy.CreatedAt, time.Minute)
+
+	invalid := arg
+	invalid.MerchantID = -1
+	_, err = store.CreateConsultancy(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+
+	invalid = arg
+	invalid.CustomerID = -1
+	_, err = store.CreateConsultancy(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetConsultancy(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+	consultancy := createRandomConsultancy(t, store, merchant.ID, customer.ID)
+
+	got, err := store.GetConsultancy(ctx, consultancy.ID)
+	require.NoError(t, err)
+	requireConsultancy(t, consultancy, got)
+
+	_, err = store.GetConsultancy(ctx, -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListConsultancies(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	otherMerchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+	otherCustomer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	// the consultancies of the merchant or of the customer are listed
+	consultancies := []db.Consultancy{
+		createRandomConsultancy(t, store, merchant.ID, otherCustomer.ID),
+		createRandomConsultancy(t, store, otherMerchant.ID, customer.ID),
+		createRandomConsultancy(t, store, merchant.ID, customer.ID),
+	}
+	createRandomConsultancy(t, store, otherMerchant.ID, otherCustomer.ID)
+
+	list := func(limit, offset int32) ([]db.Consultancy, error) {
+		return store.ListConsultancies(ctx, db.ListConsultanciesParams{
+			MerchantID: merchant.ID,
+			CustomerID: customer.ID,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	}
+
+	got, err := list(10, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(consultancies))
+	for i := range consultancies {
+		requireConsultancy(t, consultancies[i], got[i])
+	}
+
+	got, err = list(1, 1)
+	require.NoError(t, err)
+	require.Equal(t, consultancyIDs(consultancies[1:2]), consultancyIDs(got))
+
+	got, err = list(10, 3)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := list(limit, offset)
+		return err
+	})
+}
+
+func testListConsultanciesByOwner(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	customer := createRandomCustomer(t, store, user.Username)
+	otherMerchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	otherCustomer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	// the user is on either side of the consultancies, or on both
+	consultancies := []db.Consultancy{
+		createRandomConsultancy(t, store, merchant.ID, otherCustomer.ID),
+		createRandomConsultancy(t, store, otherMerchant.ID, customer.ID),
+		createRandomConsultancy(t, store, merchant.ID, customer.ID),
+	}
+	createRandomConsultancy(t, store, otherMerchant.ID, otherCustomer.ID)
+
+	got, err := store.ListConsultanciesByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, got, len(consultancies))
+	for i := range consultancies {
+		requireConsultancy(t, consultancies[i], got[i])
+	}
+
+	got, err = store.ListConsultanciesByOwner(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+}
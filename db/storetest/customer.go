@@ -0,0 +1,222 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var customerChecks = []check{
+	{"CreateCustomer", testCreateCustomer},
+	{"GetCustomer", testGetCustomer},
+	{"UpdateCustomer", testUpdateCustomer},
+	{"SetCustomerImageVariants", testSetCustomerImageVariants},
+	{"DeleteCustomer", testDeleteCustomer},
+	{"ListCustomersByOwner", testListCustomersByOwner},
+	{"AnonymizeUserCustomers", testAnonymizeUserCustomers},
+	{"CustomerVersion", testCustomerVersion},
+}
+
+func requireCustomer(t *testing.T, expected, actual db.Customer) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Owner, actual.Owner)
+	require.Equal(t, expected.ImageUrl, actual.ImageUrl)
+	require.JSONEq(t, string(expected.ImageVariants), string(actual.ImageVariants))
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func testCreateCustomer(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	customer, err := store.CreateCustomer(ctx, user.Username)
+	require.NoError(t, err)
+	require.NotZero(t, customer.ID)
+	require.Equal(t, user.Username, customer.Owner)
+	require.Equal(t, "/default/user/avatar.jpg", customer.ImageUrl)
+	require.JSONEq(t, "{}", string(customer.ImageVariants))
+	require.WithinDuration(t, time.Now(), customer.CreatedAt, time.Minute)
+
+	// a user may have many customers
+	second, err := store.CreateCustomer(ctx, user.Username)
+	require.NoError(t, err)
+	require.Greater(t, second.ID, customer.ID)
+
+	_, err = store.CreateCustomer(ctx, utils.RandomOwner())
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetCustomer(t *testing.T, store db.Store) {
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	got, err := store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	requireCustomer(t, customer, got)
+
+	_, err = store.GetCustomer(ctx, -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testUpdateCustomer(t *testing.T, store db.Store) {
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	arg := db.UpdateCustomerParams{
+		ID:            customer.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	updated, err := store.UpdateCustomer(ctx, arg)
+	require.NoError(t, err)
+	expected := customer
+	expected.ImageUrl = arg.ImageUrl
+	expected.ImageVariants = arg.ImageVariants
+	requireCustomer(t, expected, updated)
+
+	got, err := store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	requireCustomer(t, expected, got)
+
+	arg.ID = -1
+	_, err = store.UpdateCustomer(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testSetCustomerImageVariants(t *testing.T, store db.Store) {
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	// the variants of a replaced image are not stored
+	arg := db.SetCustomerImageVariantsParams{
+		ID:            customer.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	n, err := store.SetCustomerImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg.ImageUrl = customer.ImageUrl
+	n, err = store.SetCustomerImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err := store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(arg.ImageVariants), string(got.ImageVariants))
+	require.Equal(t, customer.Version+1, got.Version)
+}
+
+func testDeleteCustomer(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	customer := createRandomCustomer(t, store, user.Username)
+
+	require.NoError(t, store.DeleteCustomer(ctx, customer.ID))
+	_, err := store.GetCustomer(ctx, customer.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// deleting a missing customer is not an error
+	require.NoError(t, store.DeleteCustomer(ctx, customer.ID))
+
+	// the customers with consultancies are not deleted
+	customer = createRandomCustomer(t, store, user.Username)
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	_, err = store.CreateConsultancy(ctx, db.CreateConsultancyParams{
+		MerchantID: merchant.ID,
+		CustomerID: customer.ID,
+		Cost:       utils.RandomMoney(),
+	})
+	require.NoError(t, err)
+
+	err = store.DeleteCustomer(ctx, customer.ID)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testListCustomersByOwner(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	customers := make([]db.Customer, 3)
+	for i := range customers {
+		customers[i] = createRandomCustomer(t, store, user.Username)
+	}
+
+	got, err := store.ListCustomersByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, got, len(customers))
+	for i := range customers {
+		requireCustomer(t, customers[i], got[i])
+	}
+
+	got, err = store.ListCustomersByOwner(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+}
+
+func testAnonymizeUserCustomers(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	customer := createRandomCustomer(t, store, user.Username)
+	customer, err := store.UpdateCustomer(ctx, db.UpdateCustomerParams{
+		ID:            customer.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	})
+	require.NoError(t, err)
+
+	other := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	ids, err := store.AnonymizeUserCustomers(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []int64{customer.ID}, ids)
+
+	got, err := store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, got.Owner)
+	require.Equal(t, "/default/user/avatar.jpg", got.ImageUrl)
+	require.JSONEq(t, "{}", string(got.ImageVariants))
+
+	got, err = store.GetCustomer(ctx, other.ID)
+	require.NoError(t, err)
+	requireCustomer(t, other, got)
+}
+
+func testCustomerVersion(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	customer := createRandomCustomer(t, store, user.Username)
+	require.Equal(t, int64(1), customer.Version)
+
+	arg := db.UpdateCustomerParams{
+		ID:            customer.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+		Version:       nullInt64(customer.Version),
+	}
+	updated, err := store.UpdateCustomer(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, customer.Version+1, updated.Version)
+
+	// the update with a stale version does not find the customer
+	_, err = store.UpdateCustomer(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	requireCustomer(t, updated, got)
+	require.Equal(t, updated.Version, got.Version)
+
+	// the update without a version always applies
+	arg.Version = sql.NullInt64{}
+	updated, err = store.UpdateCustomer(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, got.Version+1, updated.Version)
+
+	_, err = store.AnonymizeUserCustomers(ctx, user.Username)
+	require.NoError(t, err)
+	got, err = store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated.Version+1, got.Version)
+}
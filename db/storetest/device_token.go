@@ -0,0 +1,164 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var deviceTokenChecks = []check{
+	{"UpsertDeviceToken", testUpsertDeviceToken},
+	{"ListUserDeviceTokens", testListUserDeviceTokens},
+	{"ListAllUserDeviceTokens", testListAllUserDeviceTokens},
+	{"DeleteDeviceToken", testDeleteDeviceToken},
+	{"DeleteUserDeviceTokens", testDeleteUserDeviceTokens},
+}
+
+func createRandomDeviceToken(t *testing.T, store db.Store, session db.Session) db.DeviceToken {
+	token, err := store.UpsertDeviceToken(ctx, db.UpsertDeviceTokenParams{
+		SessionID: session.ID,
+		Username:  session.Username,
+		Platform:  "fcm",
+		Token:     utils.RandomString(64),
+		Locale:    "en",
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func deviceTokenValues(tokens []db.DeviceToken) []string {
+	values := make([]string, len(tokens))
+	for i, token := range tokens {
+		values[i] = token.Token
+	}
+	return values
+}
+
+func testUpsertDeviceToken(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	session := createRandomSession(t, store, user.Username)
+
+	arg := db.UpsertDeviceTokenParams{
+		SessionID: session.ID,
+		Username:  user.Username,
+		Platform:  "apns",
+		Token:     utils.RandomString(64),
+		Locale:    "tr",
+	}
+	token, err := store.UpsertDeviceToken(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.SessionID, token.SessionID)
+	require.Equal(t, arg.Username, token.Username)
+	require.Equal(t, arg.Platform, token.Platform)
+	require.Equal(t, arg.Token, token.Token)
+	require.Equal(t, arg.Locale, token.Locale)
+	require.WithinDuration(t, time.Now(), token.CreatedAt, time.Minute)
+
+	// the session has one token, the new one replaces the old
+	tick()
+	arg.Platform = "fcm"
+	arg.Token = utils.RandomString(64)
+	arg.Locale = "en"
+	updated, err := store.UpsertDeviceToken(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Token, updated.Token)
+	require.Equal(t, arg.Platform, updated.Platform)
+	require.Equal(t, arg.Locale, updated.Locale)
+	requireTime(t, token.CreatedAt, updated.CreatedAt)
+	require.True(t, updated.UpdatedAt.After(token.UpdatedAt))
+
+	// the token moves to the session registering it last
+	other := createRandomSession(t, store, user.Username)
+	moved := arg
+	moved.SessionID = other.ID
+	_, err = store.UpsertDeviceToken(ctx, moved)
+	require.NoError(t, err)
+
+	tokens, err := store.ListUserDeviceTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	require.Equal(t, other.ID, tokens[0].SessionID)
+
+	invalid := arg
+	invalid.Platform = "sms"
+	_, err = store.UpsertDeviceToken(ctx, invalid)
+	requireCode(t, err, "check_violation")
+
+	missing := arg
+	missing.SessionID = uuid.New()
+	_, err = store.UpsertDeviceToken(ctx, missing)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testListUserDeviceTokens(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	first := createRandomDeviceToken(t, store, createRandomSession(t, store, user.Username))
+	tick()
+	second := createRandomDeviceToken(t, store, createRandomSession(t, store, user.Username))
+	createRandomDeviceToken(t, store, createRandomSession(t, store, createRandomUser(t, store).Username))
+
+	tokens, err := store.ListUserDeviceTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []string{first.Token, second.Token}, deviceTokenValues(tokens))
+
+	// the tokens of the blocked sessions are not pushed to
+	_, err = store.BlockUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+
+	tokens, err = store.ListUserDeviceTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+}
+
+func testListAllUserDeviceTokens(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	first := createRandomDeviceToken(t, store, createRandomSession(t, store, user.Username))
+	tick()
+	second := createRandomDeviceToken(t, store, createRandomSession(t, store, user.Username))
+	createRandomDeviceToken(t, store, createRandomSession(t, store, createRandomUser(t, store).Username))
+
+	// the tokens of the blocked sessions are listed too
+	_, err := store.BlockUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+
+	tokens, err := store.ListAllUserDeviceTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []string{first.Token, second.Token}, deviceTokenValues(tokens))
+}
+
+func testDeleteDeviceToken(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	token := createRandomDeviceToken(t, store, createRandomSession(t, store, user.Username))
+	other := createRandomDeviceToken(t, store, createRandomSession(t, store, user.Username))
+
+	require.NoError(t, store.DeleteDeviceToken(ctx, token.SessionID))
+
+	tokens, err := store.ListUserDeviceTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []string{other.Token}, deviceTokenValues(tokens))
+
+	// deleting a missing token is not an error
+	require.NoError(t, store.DeleteDeviceToken(ctx, token.SessionID))
+}
+
+func testDeleteUserDeviceTokens(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	session := createRandomSession(t, store, user.Username)
+	createRandomDeviceToken(t, store, session)
+	other := createRandomUser(t, store)
+	otherToken := createRandomDeviceToken(t, store, createRandomSession(t, store, other.Username))
+
+	// the sessions are not deleted before their tokens
+	requireCode(t, store.DeleteUserSessions(ctx, user.Username), "foreign_key_violation")
+
+	require.NoError(t, store.DeleteUserDeviceTokens(ctx, user.Username))
+	require.NoError(t, store.DeleteUserSessions(ctx, user.Username))
+
+	tokens, err := store.ListUserDeviceTokens(ctx, other.Username)
+	require.NoError(t, err)
+	require.Equal(t, []string{otherToken.Token}, deviceTokenValues(tokens))
+}
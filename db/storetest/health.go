@@ -0,0 +1,33 @@
+package storetest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/asdsec/thenut/db/migration"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+var healthChecks = []check{
+	{"Ping", testPing},
+	{"GetSchemaVersion", testGetSchemaVersion},
+}
+
+func testPing(t *testing.T, store db.Store) {
+	require.NoError(t, store.Ping(ctx))
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, store.Ping(canceled), context.Canceled)
+}
+
+func testGetSchemaVersion(t *testing.T, store db.Store) {
+	version, err := store.GetSchemaVersion(ctx)
+	require.NoError(t, err)
+	require.False(t, version.Dirty)
+
+	latest, err := migration.LatestVersion()
+	require.NoError(t, err)
+	require.Equal(t, int64(latest), version.Version)
+}
@@ -0,0 +1,244 @@
+package storetest
+
+import (
+	"database/sql"
+	"net/http"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var idempotencyKeyChecks = []check{
+	{"CreateIdempotencyKey", testCreateIdempotencyKey},
+	{"GetIdempotencyKey", testGetIdempotencyKey},
+	{"CompleteIdempotencyKey", testCompleteIdempotencyKey},
+	{"DeleteIdempotencyKey", testDeleteIdempotencyKey},
+	{"DeleteUserIdempotencyKeys", testDeleteUserIdempotencyKeys},
+	{"DeleteExpiredIdempotencyKeys", testDeleteExpiredIdempotencyKeys},
+}
+
+func randomIdempotencyKeyParams(username string) db.CreateIdempotencyKeyParams {
+	return db.CreateIdempotencyKeyParams{
+		Username:      username,
+		Key:           utils.RandomString(16),
+		RequestMethod: http.MethodPost,
+		RequestPath:   "/posts",
+		RequestHash:   utils.RandomString(64),
+		ExpiresAt:     time.Now().Add(time.Hour).Truncate(time.Microsecond),
+	}
+}
+
+func createRandomIdempotencyKey(t *testing.T, store db.Store, username string) db.IdempotencyKey {
+	key, err := store.CreateIdempotencyKey(ctx, randomIdempotencyKeyParams(username))
+	require.NoError(t, err)
+	return key
+}
+
+func requireIdempotencyKey(t *testing.T, expected, actual db.IdempotencyKey) {
+	t.Helper()
+
+	require.Equal(t, expected.Username, actual.Username)
+	require.Equal(t, expected.Key, actual.Key)
+	require.Equal(t, expected.RequestMethod, actual.RequestMethod)
+	require.Equal(t, expected.RequestPath, actual.RequestPath)
+	require.Equal(t, expected.RequestHash, actual.RequestHash)
+	require.Equal(t, expected.ResponseStatus, actual.ResponseStatus)
+	require.Equal(t, expected.ResponseBody, actual.ResponseBody)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+	requireTime(t, expected.ExpiresAt, actual.ExpiresAt)
+}
+
+func testCreateIdempotencyKey(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	arg := randomIdempotencyKeyParams(user.Username)
+	key, err := store.CreateIdempotencyKey(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestMethod, key.RequestMethod)
+	require.Equal(t, arg.RequestPath, key.RequestPath)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	requireTime(t, arg.ExpiresAt, key.ExpiresAt)
+	require.WithinDuration(t, time.Now(), key.CreatedAt, time.Minute)
+	// the key is in progress until it is completed
+	require.False(t, key.ResponseStatus.Valid)
+	require.Nil(t, key.ResponseBody)
+
+	// the live key is not taken over
+	retry := arg
+	retry.RequestHash = utils.RandomString(64)
+	_, err = store.CreateIdempotencyKey(ctx, retry)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: arg.Username, Key: arg.Key})
+	require.NoError(t, err)
+	requireIdempotencyKey(t, key, got)
+
+	// the same key of another user is another key
+	other := arg
+	other.Username = createRandomUser(t, store).Username
+	_, err = store.CreateIdempotencyKey(ctx, other)
+	require.NoError(t, err)
+
+	// the expired key is taken over as a new one
+	expired := randomIdempotencyKeyParams(user.Username)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = store.CreateIdempotencyKey(ctx, expired)
+	require.NoError(t, err)
+	_, err = store.CompleteIdempotencyKey(ctx, db.CompleteIdempotencyKeyParams{
+		Username:       expired.Username,
+		Key:            expired.Key,
+		ResponseStatus: sql.NullInt32{Int32: http.StatusOK, Valid: true},
+		ResponseBody:   []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	renewed := expired
+	renewed.RequestHash = utils.RandomString(64)
+	renewed.ExpiresAt = arg.ExpiresAt
+	key, err = store.CreateIdempotencyKey(ctx, renewed)
+	require.NoError(t, err)
+	require.Equal(t, renewed.RequestHash, key.RequestHash)
+	requireTime(t, renewed.ExpiresAt, key.ExpiresAt)
+	require.False(t, key.ResponseStatus.Valid)
+	require.Nil(t, key.ResponseBody)
+
+	arg.Username = utils.RandomOwner()
+	_, err = store.CreateIdempotencyKey(ctx, arg)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetIdempotencyKey(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	key := createRandomIdempotencyKey(t, store, user.Username)
+
+	got, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: key.Key})
+	require.NoError(t, err)
+	requireIdempotencyKey(t, key, got)
+
+	_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: createRandomUser(t, store).Username, Key: key.Key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the expired keys are not found
+	arg := randomIdempotencyKeyParams(user.Username)
+	arg.ExpiresAt = time.Now().Add(-time.Minute)
+	_, err = store.CreateIdempotencyKey(ctx, arg)
+	require.NoError(t, err)
+
+	_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: arg.Key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testCompleteIdempotencyKey(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	key := createRandomIdempotencyKey(t, store, user.Username)
+
+	arg := db.CompleteIdempotencyKeyParams{
+		Username:       user.Username,
+		Key:            key.Key,
+		ResponseStatus: sql.NullInt32{Int32: http.StatusCreated, Valid: true},
+		ResponseBody:   []byte(`{"id": 1}`),
+	}
+	completed, err := store.CompleteIdempotencyKey(ctx, arg)
+	require.NoError(t, err)
+	expected := key
+	expected.ResponseStatus = arg.ResponseStatus
+	expected.ResponseBody = arg.ResponseBody
+	requireIdempotencyKey(t, expected, completed)
+
+	got, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: key.Key})
+	require.NoError(t, err)
+	requireIdempotencyKey(t, expected, got)
+
+	arg.Key = utils.RandomString(16)
+	_, err = store.CompleteIdempotencyKey(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteIdempotencyKey(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	key := createRandomIdempotencyKey(t, store, user.Username)
+	other := createRandomIdempotencyKey(t, store, user.Username)
+
+	arg := db.DeleteIdempotencyKeyParams{Username: user.Username, Key: key.Key}
+	require.NoError(t, store.DeleteIdempotencyKey(ctx, arg))
+
+	_, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: key.Key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: other.Key})
+	require.NoError(t, err)
+
+	// the deleted key can be used again
+	_, err = store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+		Username:    user.Username,
+		Key:         key.Key,
+		RequestHash: key.RequestHash,
+		ExpiresAt:   key.ExpiresAt,
+	})
+	require.NoError(t, err)
+
+	// deleting a missing key is not an error
+	require.NoError(t, store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{Username: user.Username, Key: utils.RandomString(16)}))
+}
+
+func testDeleteUserIdempotencyKeys(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	keys := []db.IdempotencyKey{
+		createRandomIdempotencyKey(t, store, user.Username),
+		createRandomIdempotencyKey(t, store, user.Username),
+	}
+	other := createRandomIdempotencyKey(t, store, createRandomUser(t, store).Username)
+
+	// the keys keep the user from being deleted
+	err := store.DeleteUser(ctx, user.Username)
+	requireCode(t, err, "foreign_key_violation")
+
+	require.NoError(t, store.DeleteUserIdempotencyKeys(ctx, user.Username))
+	for _, key := range keys {
+		_, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: key.Key})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+	}
+
+	_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: other.Username, Key: other.Key})
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteUser(ctx, user.Username))
+}
+
+func testDeleteExpiredIdempotencyKeys(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	live := createRandomIdempotencyKey(t, store, user.Username)
+
+	// a time of the past no other check expires a key at
+	now := time.Now().Add(-time.Duration(utils.RandomInt(1000, 100000)) * time.Hour).Truncate(time.Microsecond)
+
+	expired := make([]db.CreateIdempotencyKeyParams, 2)
+	for i := range expired {
+		expired[i] = randomIdempotencyKeyParams(user.Username)
+		expired[i].ExpiresAt = now.Add(-time.Duration(i) * time.Second)
+		_, err := store.CreateIdempotencyKey(ctx, expired[i])
+		require.NoError(t, err)
+	}
+
+	// the keys expiring at the given time are deleted too
+	deleted, err := store.DeleteExpiredIdempotencyKeys(ctx, now.Add(-time.Second))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	deleted, err = store.DeleteExpiredIdempotencyKeys(ctx, now.Add(-time.Second))
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = store.DeleteExpiredIdempotencyKeys(ctx, now)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	// only the live key is left to the user
+	require.NoError(t, store.DeleteIdempotencyKey(ctx, db.DeleteIdempotencyKeyParams{Username: user.Username, Key: live.Key}))
+	require.NoError(t, store.DeleteUser(ctx, user.Username))
+}
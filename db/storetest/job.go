@@ -0,0 +1,287 @@
+package storetest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var jobChecks = []check{
+	{"EnqueueJob", testEnqueueJob},
+	{"ClaimJobs", testClaimJobs},
+	{"CompleteJob", testCompleteJob},
+	{"RetryJob", testRetryJob},
+	{"KillJob", testKillJob},
+	{"KillStaleJobs", testKillStaleJobs},
+	{"RequeueDeadJob", testRequeueDeadJob},
+}
+
+// The stores may share their jobs, so the checks claim with a large limit and
+// only look at the jobs they enqueue themselves
+const claimLimit = 1000
+
+func createRandomJob(t *testing.T, store db.Store, runAt time.Time) db.Job {
+	job, err := store.EnqueueJob(ctx, db.EnqueueJobParams{
+		Kind:        "test." + utils.RandomString(6),
+		Payload:     json.RawMessage(`{"n": 1}`),
+		MaxAttempts: 3,
+		RunAt:       runAt,
+	})
+	require.NoError(t, err)
+	return job
+}
+
+// claimJob claims the due jobs and returns the given one if it is claimed
+func claimJob(t *testing.T, store db.Store, id int64, now, staleBefore time.Time) (db.Job, bool) {
+	jobs, err := store.ClaimJobs(ctx, db.ClaimJobsParams{
+		Now:         now,
+		StaleBefore: staleBefore,
+		LimitCount:  claimLimit,
+	})
+	require.NoError(t, err)
+
+	for _, job := range jobs {
+		if job.ID == id {
+			return job, true
+		}
+	}
+	return db.Job{}, false
+}
+
+func testEnqueueJob(t *testing.T, store db.Store) {
+	arg := db.EnqueueJobParams{
+		Kind:        "test." + utils.RandomString(6),
+		Payload:     json.RawMessage(`{"username": "user1"}`),
+		MaxAttempts: 5,
+		RunAt:       time.Now().Add(time.Hour),
+	}
+	job, err := store.EnqueueJob(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, job.ID)
+	require.Equal(t, arg.Kind, job.Kind)
+	require.JSONEq(t, string(arg.Payload), string(job.Payload))
+	require.Equal(t, "pending", job.Status)
+	require.Zero(t, job.Attempts)
+	require.Equal(t, arg.MaxAttempts, job.MaxAttempts)
+	requireTime(t, arg.RunAt, job.RunAt)
+	require.False(t, job.LockedAt.Valid)
+	require.False(t, job.LastError.Valid)
+	require.WithinDuration(t, time.Now(), job.CreatedAt, time.Minute)
+
+	invalid := arg
+	invalid.MaxAttempts = 0
+	_, err = store.EnqueueJob(ctx, invalid)
+	requireCode(t, err, "check_violation")
+}
+
+func testClaimJobs(t *testing.T, store db.Store) {
+	now := time.Now()
+	due := createRandomJob(t, store, now.Add(-time.Minute))
+	future := createRandomJob(t, store, now.Add(time.Hour))
+
+	claimed, ok := claimJob(t, store, due.ID, now, now.Add(-time.Hour))
+	require.True(t, ok)
+	require.Equal(t, "running", claimed.Status)
+	require.Equal(t, int32(1), claimed.Attempts)
+	require.True(t, claimed.LockedAt.Valid)
+	requireTime(t, now, claimed.LockedAt.Time)
+
+	_, ok = claimJob(t, store, future.ID, now, now.Add(-time.Hour))
+	require.False(t, ok)
+
+	// a running job is not claimed again until its lease is over
+	_, ok = claimJob(t, store, due.ID, now, now.Add(-time.Hour))
+	require.False(t, ok)
+
+	later := now.Add(time.Minute)
+	reclaimed, ok := claimJob(t, store, due.ID, later, now)
+	require.True(t, ok)
+	require.Equal(t, int32(2), reclaimed.Attempts)
+	requireTime(t, later, reclaimed.LockedAt.Time)
+
+	_, err := store.ClaimJobs(ctx, db.ClaimJobsParams{Now: now, StaleBefore: now, LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+func testKillStaleJobs(t *testing.T, store db.Store) {
+	now := time.Now()
+	job := createRandomJob(t, store, now)
+
+	// every attempt is taken over after its lease
+	var lockedAt time.Time
+	for i := 0; i < int(job.MaxAttempts); i++ {
+		lockedAt = now.Add(time.Duration(i) * time.Minute)
+		claimed, ok := claimJob(t, store, job.ID, lockedAt, lockedAt.Add(-time.Minute))
+		require.True(t, ok)
+		require.Equal(t, int32(i+1), claimed.Attempts)
+	}
+
+	// the job is not taken over once it is out of attempts
+	_, ok := claimJob(t, store, job.ID, lockedAt.Add(time.Hour), lockedAt)
+	require.False(t, ok)
+
+	killStale := func(staleBefore time.Time) (db.Job, bool) {
+		jobs, err := store.KillStaleJobs(ctx, db.KillStaleJobsParams{
+			LastError:   "lease expired",
+			StaleBefore: staleBefore,
+		})
+		require.NoError(t, err)
+		for _, killed := range jobs {
+			if killed.ID == job.ID {
+				return killed, true
+			}
+		}
+		return db.Job{}, false
+	}
+
+	// the last attempt still has its lease
+	_, ok = killStale(lockedAt.Add(-time.Second))
+	require.False(t, ok)
+
+	killed, ok := killStale(lockedAt)
+	require.True(t, ok)
+	require.Equal(t, "dead", killed.Status)
+	require.Equal(t, job.MaxAttempts, killed.Attempts)
+	require.False(t, killed.LockedAt.Valid)
+	require.Equal(t, nullString("lease expired"), killed.LastError)
+
+	_, ok = killStale(lockedAt)
+	require.False(t, ok)
+}
+
+func testCompleteJob(t *testing.T, store db.Store) {
+	now := time.Now()
+	job := createRandomJob(t, store, now)
+
+	// a pending job is not completed
+	completed, err := store.CompleteJob(ctx, db.CompleteJobParams{ID: job.ID, LockedAt: now})
+	require.NoError(t, err)
+	require.Zero(t, completed)
+	claimed, ok := claimJob(t, store, job.ID, now, now.Add(-time.Hour))
+	require.True(t, ok)
+
+	// the worker whose claim is taken over cannot complete the job
+	later := now.Add(time.Hour)
+	reclaimed, ok := claimJob(t, store, job.ID, later, now)
+	require.True(t, ok)
+	completed, err = store.CompleteJob(ctx, db.CompleteJobParams{ID: job.ID, LockedAt: claimed.LockedAt.Time})
+	require.NoError(t, err)
+	require.Zero(t, completed)
+
+	completed, err = store.CompleteJob(ctx, db.CompleteJobParams{ID: job.ID, LockedAt: reclaimed.LockedAt.Time})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), completed)
+
+	// the completed job is gone, even after its lease
+	_, ok = claimJob(t, store, job.ID, later.Add(time.Hour), later.Add(time.Hour))
+	require.False(t, ok)
+}
+
+func testRetryJob(t *testing.T, store db.Store) {
+	now := time.Now()
+	job := createRandomJob(t, store, now)
+
+	arg := db.RetryJobParams{
+		ID:        job.ID,
+		RunAt:     now.Add(time.Minute),
+		LastError: nullString("unavailable"),
+		LockedAt:  now,
+	}
+	_, err := store.RetryJob(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	claimed, ok := claimJob(t, store, job.ID, now, now.Add(-time.Hour))
+	require.True(t, ok)
+
+	// only the lock of the claim retries the job
+	stale := arg
+	stale.LockedAt = now.Add(-time.Minute)
+	_, err = store.RetryJob(ctx, stale)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.LockedAt = claimed.LockedAt.Time
+	retried, err := store.RetryJob(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, "pending", retried.Status)
+	require.Equal(t, int32(1), retried.Attempts)
+	requireTime(t, arg.RunAt, retried.RunAt)
+	require.False(t, retried.LockedAt.Valid)
+	require.Equal(t, arg.LastError, retried.LastError)
+
+	// the job waits for its next run
+	_, ok = claimJob(t, store, job.ID, now, now.Add(-time.Hour))
+	require.False(t, ok)
+
+	claimed, ok = claimJob(t, store, job.ID, arg.RunAt, now.Add(-time.Hour))
+	require.True(t, ok)
+	require.Equal(t, int32(2), claimed.Attempts)
+}
+
+func testKillJob(t *testing.T, store db.Store) {
+	now := time.Now()
+	job := createRandomJob(t, store, now)
+	claimed, ok := claimJob(t, store, job.ID, now, now.Add(-time.Hour))
+	require.True(t, ok)
+
+	arg := db.KillJobParams{ID: job.ID, LastError: nullString("failed"), LockedAt: now.Add(-time.Minute)}
+	_, err := store.KillJob(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.LockedAt = claimed.LockedAt.Time
+	killed, err := store.KillJob(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, "dead", killed.Status)
+	require.False(t, killed.LockedAt.Valid)
+	require.Equal(t, "failed", killed.LastError.String)
+
+	// the dead jobs are never claimed
+	_, ok = claimJob(t, store, job.ID, now.Add(time.Hour), now.Add(time.Hour))
+	require.False(t, ok)
+
+	_, err = store.KillJob(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testRequeueDeadJob(t *testing.T, store db.Store) {
+	now := time.Now()
+	job := createRandomJob(t, store, now)
+	claimed, ok := claimJob(t, store, job.ID, now, now.Add(-time.Hour))
+	require.True(t, ok)
+	_, err := store.KillJob(ctx, db.KillJobParams{ID: job.ID, LastError: nullString("failed"), LockedAt: claimed.LockedAt.Time})
+	require.NoError(t, err)
+
+	var found bool
+	for offset := int32(0); !found; offset += claimLimit {
+		dead, err := store.ListDeadJobs(ctx, db.ListDeadJobsParams{Limit: claimLimit, Offset: offset})
+		require.NoError(t, err)
+		require.NotEmpty(t, dead)
+		for _, deadJob := range dead {
+			require.Equal(t, "dead", deadJob.Status)
+			found = found || deadJob.ID == job.ID
+		}
+	}
+
+	requeued, err := store.RequeueDeadJob(ctx, job.ID)
+	require.NoError(t, err)
+	require.Equal(t, "pending", requeued.Status)
+	require.Zero(t, requeued.Attempts)
+	// the failure is kept until the job runs again
+	require.Equal(t, "failed", requeued.LastError.String)
+
+	_, err = store.RequeueDeadJob(ctx, job.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	claimed, ok = claimJob(t, store, job.ID, time.Now().Add(time.Minute), now.Add(-time.Hour))
+	require.True(t, ok)
+	require.Equal(t, int32(1), claimed.Attempts)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := store.ListDeadJobs(ctx, db.ListDeadJobsParams{Limit: limit, Offset: offset})
+		return err
+	})
+}
@@ -0,0 +1,408 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var merchantChecks = []check{
+	{"CreateMerchant", testCreateMerchant},
+	{"GetMerchant", testGetMerchant},
+	{"ListMerchants", testListMerchants},
+	{"ListMerchantsByCursor", testListMerchantsByCursor},
+	{"ListMerchantsByOwner", testListMerchantsByOwner},
+	{"UpdateMerchant", testUpdateMerchant},
+	{"UpdateMerchantImage", testUpdateMerchantImage},
+	{"SetMerchantImageVariants", testSetMerchantImageVariants},
+	{"AddMerchantBalance", testAddMerchantBalance},
+	{"DeleteMerchant", testDeleteMerchant},
+	{"AnonymizeUserMerchants", testAnonymizeUserMerchants},
+	{"MerchantVersion", testMerchantVersion},
+}
+
+func requireMerchant(t *testing.T, expected, actual db.Merchant) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Owner, actual.Owner)
+	require.Equal(t, expected.Balance, actual.Balance)
+	require.Equal(t, expected.Profession, actual.Profession)
+	require.Equal(t, expected.Title, actual.Title)
+	require.Equal(t, expected.About, actual.About)
+	require.Equal(t, expected.ImageUrl, actual.ImageUrl)
+	require.Equal(t, expected.Rating, actual.Rating)
+	require.JSONEq(t, string(expected.ImageVariants), string(actual.ImageVariants))
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func createRandomMerchants(t *testing.T, store db.Store, owner string, n int) []db.Merchant {
+	merchants := make([]db.Merchant, n)
+	for i := range merchants {
+		merchants[i] = createRandomMerchant(t, store, owner)
+	}
+	return merchants
+}
+
+func testCreateMerchant(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	arg := db.CreateMerchantParams{
+		Owner:      user.Username,
+		Profession: utils.RandomString(8),
+		Title:      utils.RandomString(8),
+		About:      utils.RandomString(20),
+	}
+	merchant, err := store.CreateMerchant(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, merchant.ID)
+	require.Equal(t, arg.Owner, merchant.Owner)
+	require.Equal(t, arg.Profession, merchant.Profession)
+	require.Equal(t, arg.Title, merchant.Title)
+	require.Equal(t, arg.About, merchant.About)
+	require.WithinDuration(t, time.Now(), merchant.CreatedAt, time.Minute)
+
+	// the defaults of the columns
+	require.Zero(t, merchant.Balance)
+	require.Zero(t, merchant.Rating)
+	require.Equal(t, "/default/merchant/avatar.jpg", merchant.ImageUrl)
+	require.JSONEq(t, "{}", string(merchant.ImageVariants))
+
+	arg.Owner = utils.RandomOwner()
+	_, err = store.CreateMerchant(ctx, arg)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetMerchant(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	got, err := store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	requireMerchant(t, merchant, got)
+
+	_, err = store.GetMerchant(ctx, -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListMerchants(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	merchants := createRandomMerchants(t, store, user.Username, 5)
+
+	list := func(limit, offset int32) ([]db.Merchant, error) {
+		return store.ListMerchants(ctx, db.ListMerchantsParams{
+			Owner:  user.Username,
+			Limit:  limit,
+			Offset: offset,
+		})
+	}
+
+	got, err := list(10, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(merchants))
+	for i := range merchants {
+		requireMerchant(t, merchants[i], got[i])
+	}
+
+	got, err = list(2, 1)
+	require.NoError(t, err)
+	require.Equal(t, merchantIDs(merchants[1:3]), merchantIDs(got))
+
+	got, err = list(10, 4)
+	require.NoError(t, err)
+	require.Equal(t, merchantIDs(merchants[4:]), merchantIDs(got))
+
+	got, err = list(10, 5)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	got, err = list(0, 0)
+	require.NoError(t, err)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := list(limit, offset)
+		return err
+	})
+}
+
+func testListMerchantsByCursor(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	merchants := createRandomMerchants(t, store, user.Username, 5)
+
+	// the pages are in creation order, each one starts after the last
+	// merchant of the previous one
+	var got []db.Merchant
+	arg := db.ListMerchantsByCursorParams{Owner: user.Username, LimitCount: 2}
+	for {
+		page, err := store.ListMerchantsByCursor(ctx, arg)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, page...)
+
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = nullTime(last.CreatedAt)
+		arg.CursorID = nullInt64(last.ID)
+	}
+	require.Equal(t, merchantIDs(merchants), merchantIDs(got))
+
+	page, err := store.ListMerchantsByCursor(ctx, db.ListMerchantsByCursorParams{Owner: user.Username})
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Empty(t, page)
+
+	_, err = store.ListMerchantsByCursor(ctx, db.ListMerchantsByCursorParams{Owner: user.Username, LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+func testListMerchantsByOwner(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	merchants := createRandomMerchants(t, store, user.Username, 3)
+
+	got, err := store.ListMerchantsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, got, len(merchants))
+	for i := range merchants {
+		requireMerchant(t, merchants[i], got[i])
+	}
+
+	got, err = store.ListMerchantsByOwner(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+}
+
+func testUpdateMerchant(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	// only the given fields change
+	title := utils.RandomString(8)
+	updated, err := store.UpdateMerchant(ctx, db.UpdateMerchantParams{
+		ID:    merchant.ID,
+		Title: nullString(title),
+	})
+	require.NoError(t, err)
+	expected := merchant
+	expected.Title = title
+	requireMerchant(t, expected, updated)
+
+	arg := db.UpdateMerchantParams{
+		ID:         merchant.ID,
+		Balance:    nullInt64(utils.RandomMoney()),
+		Profession: nullString(utils.RandomString(8)),
+		About:      nullString(utils.RandomString(20)),
+		ImageUrl:   nullString(utils.RandomImageUrl()),
+		Rating:     sql.NullFloat64{Float64: 4.5, Valid: true},
+	}
+	updated, err = store.UpdateMerchant(ctx, arg)
+	require.NoError(t, err)
+	expected.Balance = arg.Balance.Int64
+	expected.Profession = arg.Profession.String
+	expected.About = arg.About.String
+	expected.ImageUrl = arg.ImageUrl.String
+	expected.Rating = arg.Rating.Float64
+	requireMerchant(t, expected, updated)
+
+	// the zero values are set, unlike the null ones
+	updated, err = store.UpdateMerchant(ctx, db.UpdateMerchantParams{
+		ID:      merchant.ID,
+		Balance: nullInt64(0),
+		About:   nullString(""),
+	})
+	require.NoError(t, err)
+	expected.Balance = 0
+	expected.About = ""
+	requireMerchant(t, expected, updated)
+
+	got, err := store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	requireMerchant(t, expected, got)
+
+	_, err = store.UpdateMerchant(ctx, db.UpdateMerchantParams{ID: -1, Title: nullString(title)})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testUpdateMerchantImage(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	arg := db.UpdateMerchantImageParams{
+		ID:            merchant.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	updated, err := store.UpdateMerchantImage(ctx, arg)
+	require.NoError(t, err)
+	expected := merchant
+	expected.ImageUrl = arg.ImageUrl
+	expected.ImageVariants = arg.ImageVariants
+	requireMerchant(t, expected, updated)
+
+	arg.ID = -1
+	_, err = store.UpdateMerchantImage(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testSetMerchantImageVariants(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	// the variants of a replaced image are not stored
+	arg := db.SetMerchantImageVariantsParams{
+		ID:            merchant.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	n, err := store.SetMerchantImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg.ImageUrl = merchant.ImageUrl
+	n, err = store.SetMerchantImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err := store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(arg.ImageVariants), string(got.ImageVariants))
+	require.Equal(t, merchant.Version+1, got.Version)
+}
+
+func testAddMerchantBalance(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	updated, err := store.AddMerchantBalance(ctx, db.AddMerchantBalanceParams{ID: merchant.ID, Amount: 30})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), updated.Balance)
+
+	updated, err = store.AddMerchantBalance(ctx, db.AddMerchantBalanceParams{ID: merchant.ID, Amount: -50})
+	require.NoError(t, err)
+	expected := merchant
+	expected.Balance = -20
+	requireMerchant(t, expected, updated)
+
+	_, err = store.AddMerchantBalance(ctx, db.AddMerchantBalanceParams{ID: -1, Amount: 10})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteMerchant(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+
+	require.NoError(t, store.DeleteMerchant(ctx, merchant.ID))
+	_, err := store.GetMerchant(ctx, merchant.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// deleting a missing merchant is not an error
+	require.NoError(t, store.DeleteMerchant(ctx, merchant.ID))
+
+	// the merchants with posts are not deleted, even soft deleted ones
+	merchant = createRandomMerchant(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+
+	err = store.DeleteMerchant(ctx, merchant.ID)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testAnonymizeUserMerchants(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	merchant, err := store.UpdateMerchantImage(ctx, db.UpdateMerchantImageParams{
+		ID:            merchant.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	})
+	require.NoError(t, err)
+
+	other := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	ids, err := store.AnonymizeUserMerchants(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []int64{merchant.ID}, ids)
+
+	got, err := store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, got.Owner)
+	require.Equal(t, "Deleted merchant", got.Title)
+	require.Empty(t, got.About)
+	require.Equal(t, "/default/merchant/avatar.jpg", got.ImageUrl)
+	require.JSONEq(t, "{}", string(got.ImageVariants))
+	// the business data is kept
+	require.Equal(t, merchant.Profession, got.Profession)
+	require.Equal(t, merchant.Balance, got.Balance)
+
+	got, err = store.GetMerchant(ctx, other.ID)
+	require.NoError(t, err)
+	requireMerchant(t, other, got)
+}
+
+func testMerchantVersion(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	require.Equal(t, int64(1), merchant.Version)
+
+	// every update increments the version
+	updated, err := store.UpdateMerchant(ctx, db.UpdateMerchantParams{
+		ID:    merchant.ID,
+		Title: nullString(utils.RandomString(8)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, merchant.Version+1, updated.Version)
+
+	updated, err = store.AddMerchantBalance(ctx, db.AddMerchantBalanceParams{ID: merchant.ID, Amount: 10})
+	require.NoError(t, err)
+	require.Equal(t, merchant.Version+2, updated.Version)
+
+	// the update with a stale version does not find the merchant
+	_, err = store.UpdateMerchant(ctx, db.UpdateMerchantParams{
+		ID:      merchant.ID,
+		Title:   nullString(utils.RandomString(8)),
+		Version: nullInt64(merchant.Version),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.UpdateMerchantImage(ctx, db.UpdateMerchantImageParams{
+		ID:            merchant.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+		Version:       nullInt64(merchant.Version),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	requireMerchant(t, updated, got)
+	require.Equal(t, updated.Version, got.Version)
+
+	imaged, err := store.UpdateMerchantImage(ctx, db.UpdateMerchantImageParams{
+		ID:            merchant.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+		Version:       nullInt64(got.Version),
+	})
+	require.NoError(t, err)
+	require.Equal(t, got.Version+1, imaged.Version)
+
+	updated, err = store.UpdateMerchant(ctx, db.UpdateMerchantParams{
+		ID:      merchant.ID,
+		Title:   nullString(utils.RandomString(8)),
+		Version: nullInt64(imaged.Version),
+	})
+	require.NoError(t, err)
+	require.Equal(t, imaged.Version+1, updated.Version)
+
+	_, err = store.AnonymizeUserMerchants(ctx, user.Username)
+	require.NoError(t, err)
+	got, err = store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	require.Equal(t, updated.Version+1, got.Version)
+}
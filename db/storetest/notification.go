@@ -0,0 +1,286 @@
+package storetest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var notificationChecks = []check{
+	{"CreateNotification", testCreateNotification},
+	{"ListNotifications", testListNotifications},
+	{"ListNotificationsByCursor", testListNotificationsByCursor},
+	{"ListUserNotifications", testListUserNotifications},
+	{"CountUnreadNotifications", testCountUnreadNotifications},
+	{"MarkNotificationRead", testMarkNotificationRead},
+	{"MarkAllNotificationsRead", testMarkAllNotificationsRead},
+	{"DeleteUserNotifications", testDeleteUserNotifications},
+	{"AnonymizeUserNotifications", testAnonymizeUserNotifications},
+}
+
+func createRandomNotification(t *testing.T, store db.Store, recipient, actor string) db.Notification {
+	notification, err := store.CreateNotification(ctx, db.CreateNotificationParams{
+		Recipient: recipient,
+		EventType: "post_commented",
+		Actor:     actor,
+		Target:    fmt.Sprintf("post:%d", utils.RandomInt(1, 1000)),
+		Data:      json.RawMessage(`{"comment_id": 1}`),
+	})
+	require.NoError(t, err)
+	return notification
+}
+
+func notificationIDs(notifications []db.Notification) []int64 {
+	ids := make([]int64, len(notifications))
+	for i, notification := range notifications {
+		ids[i] = notification.ID
+	}
+	return ids
+}
+
+func requireNotification(t *testing.T, expected, actual db.Notification) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Recipient, actual.Recipient)
+	require.Equal(t, expected.EventType, actual.EventType)
+	require.Equal(t, expected.Actor, actual.Actor)
+	require.Equal(t, expected.Target, actual.Target)
+	require.JSONEq(t, string(expected.Data), string(actual.Data))
+	require.Equal(t, expected.ReadAt.Valid, actual.ReadAt.Valid)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func testCreateNotification(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+
+	arg := db.CreateNotificationParams{
+		Recipient: recipient.Username,
+		EventType: "consultancy_booked",
+		Actor:     actor.Username,
+		Target:    "consultancy:7",
+		Data:      json.RawMessage(`{"merchant_id": 3}`),
+	}
+	notification, err := store.CreateNotification(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, notification.ID)
+	require.Equal(t, arg.Recipient, notification.Recipient)
+	require.Equal(t, arg.EventType, notification.EventType)
+	require.Equal(t, arg.Actor, notification.Actor)
+	require.Equal(t, arg.Target, notification.Target)
+	require.JSONEq(t, string(arg.Data), string(notification.Data))
+	require.False(t, notification.ReadAt.Valid)
+	require.WithinDuration(t, time.Now(), notification.CreatedAt, time.Minute)
+
+	missing := arg
+	missing.Recipient = utils.RandomOwner()
+	_, err = store.CreateNotification(ctx, missing)
+	requireCode(t, err, "foreign_key_violation")
+
+	missing = arg
+	missing.Actor = utils.RandomOwner()
+	_, err = store.CreateNotification(ctx, missing)
+	requireCode(t, err, "foreign_key_violation")
+
+	arg.Data = json.RawMessage(`{`)
+	_, err = store.CreateNotification(ctx, arg)
+	requireCode(t, err, "invalid_text_representation")
+}
+
+func testListNotifications(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+
+	var created []db.Notification
+	for i := 0; i < 4; i++ {
+		created = append(created, createRandomNotification(t, store, recipient.Username, actor.Username))
+		tick()
+	}
+	createRandomNotification(t, store, actor.Username, recipient.Username)
+
+	_, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: created[1].ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+
+	notifications, err := store.ListNotifications(ctx, db.ListNotificationsParams{
+		Recipient:  recipient.Username,
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	// the newest notification comes first
+	require.Equal(t, []int64{created[3].ID, created[2].ID, created[1].ID, created[0].ID}, notificationIDs(notifications))
+	requireNotification(t, created[3], notifications[0])
+
+	notifications, err = store.ListNotifications(ctx, db.ListNotificationsParams{
+		Recipient:   recipient.Username,
+		UnreadOnly:  true,
+		OffsetCount: 1,
+		LimitCount:  10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{created[2].ID, created[0].ID}, notificationIDs(notifications))
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := store.ListNotifications(ctx, db.ListNotificationsParams{
+			Recipient:   recipient.Username,
+			LimitCount:  limit,
+			OffsetCount: offset,
+		})
+		return err
+	})
+}
+
+func testListNotificationsByCursor(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+
+	var created []db.Notification
+	for i := 0; i < 4; i++ {
+		created = append(created, createRandomNotification(t, store, recipient.Username, actor.Username))
+		tick()
+	}
+
+	_, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: created[1].ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+
+	notifications, err := store.ListNotificationsByCursor(ctx, db.ListNotificationsByCursorParams{
+		Recipient:       recipient.Username,
+		CursorCreatedAt: nullTime(created[3].CreatedAt),
+		CursorID:        nullInt64(created[3].ID),
+		LimitCount:      2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{created[2].ID, created[1].ID}, notificationIDs(notifications))
+
+	notifications, err = store.ListNotificationsByCursor(ctx, db.ListNotificationsByCursorParams{
+		Recipient:       recipient.Username,
+		UnreadOnly:      true,
+		CursorCreatedAt: nullTime(created[2].CreatedAt),
+		CursorID:        nullInt64(created[2].ID),
+		LimitCount:      10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{created[0].ID}, notificationIDs(notifications))
+}
+
+func testListUserNotifications(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+
+	first := createRandomNotification(t, store, recipient.Username, actor.Username)
+	second := createRandomNotification(t, store, recipient.Username, actor.Username)
+	createRandomNotification(t, store, actor.Username, recipient.Username)
+
+	// the read notifications are listed too
+	_, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: first.ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+
+	notifications, err := store.ListUserNotifications(ctx, recipient.Username)
+	require.NoError(t, err)
+	require.Equal(t, []int64{first.ID, second.ID}, notificationIDs(notifications))
+}
+
+func testCountUnreadNotifications(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+
+	count, err := store.CountUnreadNotifications(ctx, recipient.Username)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	first := createRandomNotification(t, store, recipient.Username, actor.Username)
+	createRandomNotification(t, store, recipient.Username, actor.Username)
+	createRandomNotification(t, store, actor.Username, recipient.Username)
+
+	_, err = store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: first.ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+
+	count, err = store.CountUnreadNotifications(ctx, recipient.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
+
+func testMarkNotificationRead(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+	notification := createRandomNotification(t, store, recipient.Username, actor.Username)
+
+	// only the recipient marks the notification
+	_, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: notification.ID, Recipient: actor.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	read, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: notification.ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+	require.True(t, read.ReadAt.Valid)
+	require.WithinDuration(t, time.Now(), read.ReadAt.Time, time.Minute)
+
+	// marking it again keeps the first read time
+	again, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: notification.ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+	requireTime(t, read.ReadAt.Time, again.ReadAt.Time)
+
+	_, err = store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: notification.ID + 1000000, Recipient: recipient.Username})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testMarkAllNotificationsRead(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+	first := createRandomNotification(t, store, recipient.Username, actor.Username)
+	createRandomNotification(t, store, recipient.Username, actor.Username)
+	other := createRandomNotification(t, store, actor.Username, recipient.Username)
+
+	_, err := store.MarkNotificationRead(ctx, db.MarkNotificationReadParams{ID: first.ID, Recipient: recipient.Username})
+	require.NoError(t, err)
+
+	marked, err := store.MarkAllNotificationsRead(ctx, recipient.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), marked)
+
+	count, err := store.CountUnreadNotifications(ctx, recipient.Username)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	notifications, err := store.ListNotifications(ctx, db.ListNotificationsParams{Recipient: actor.Username, LimitCount: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	requireNotification(t, other, notifications[0])
+}
+
+func testDeleteUserNotifications(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+	createRandomNotification(t, store, recipient.Username, actor.Username)
+	other := createRandomNotification(t, store, actor.Username, recipient.Username)
+
+	require.NoError(t, store.DeleteUserNotifications(ctx, recipient.Username))
+
+	notifications, err := store.ListNotifications(ctx, db.ListNotificationsParams{Recipient: recipient.Username, LimitCount: 10})
+	require.NoError(t, err)
+	require.Empty(t, notifications)
+
+	// the notifications the user caused are kept
+	notifications, err = store.ListNotifications(ctx, db.ListNotificationsParams{Recipient: actor.Username, LimitCount: 10})
+	require.NoError(t, err)
+	require.Equal(t, []int64{other.ID}, notificationIDs(notifications))
+}
+
+func testAnonymizeUserNotifications(t *testing.T, store db.Store) {
+	recipient := createRandomUser(t, store)
+	actor := createRandomUser(t, store)
+	notification := createRandomNotification(t, store, recipient.Username, actor.Username)
+
+	require.NoError(t, store.AnonymizeUserNotifications(ctx, actor.Username))
+
+	notifications, err := store.ListNotifications(ctx, db.ListNotificationsParams{Recipient: recipient.Username, LimitCount: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	notification.Actor = deletedUser
+	requireNotification(t, notification, notifications[0])
+}
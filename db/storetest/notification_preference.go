@@ -0,0 +1,177 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var notificationPreferenceChecks = []check{
+	{"UpsertNotificationSettings", testUpsertNotificationSettings},
+	{"UpsertNotificationPreference", testUpsertNotificationPreference},
+	{"ListNotificationPreferences", testListNotificationPreferences},
+	{"DeleteUserNotificationPreferences", testDeleteUserNotificationPreferences},
+}
+
+func createRandomNotificationPreference(t *testing.T, store db.Store, username, eventType, channel string) db.NotificationPreference {
+	preference, err := store.UpsertNotificationPreference(ctx, db.UpsertNotificationPreferenceParams{
+		Username:  username,
+		EventType: eventType,
+		Channel:   channel,
+		Enabled:   utils.RandomInt(0, 1) == 1,
+	})
+	require.NoError(t, err)
+	return preference
+}
+
+func testUpsertNotificationSettings(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	_, err := store.GetNotificationSettings(ctx, user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg := db.UpsertNotificationSettingsParams{
+		Username:        user.Username,
+		TimeZone:        "Europe/Istanbul",
+		QuietHoursStart: nullInt32(22 * 60),
+		QuietHoursEnd:   nullInt32(7 * 60),
+	}
+	settings, err := store.UpsertNotificationSettings(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, settings.Username)
+	require.Equal(t, arg.TimeZone, settings.TimeZone)
+	require.Equal(t, arg.QuietHoursStart, settings.QuietHoursStart)
+	require.Equal(t, arg.QuietHoursEnd, settings.QuietHoursEnd)
+	require.WithinDuration(t, time.Now(), settings.UpdatedAt, time.Minute)
+
+	// the quiet hours are turned off
+	tick()
+	arg.TimeZone = "UTC"
+	arg.QuietHoursStart = sql.NullInt32{}
+	arg.QuietHoursEnd = sql.NullInt32{}
+	updated, err := store.UpsertNotificationSettings(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, "UTC", updated.TimeZone)
+	require.False(t, updated.QuietHoursStart.Valid)
+	require.False(t, updated.QuietHoursEnd.Valid)
+	require.True(t, updated.UpdatedAt.After(settings.UpdatedAt))
+
+	got, err := store.GetNotificationSettings(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, updated.TimeZone, got.TimeZone)
+	requireTime(t, updated.UpdatedAt, got.UpdatedAt)
+
+	for _, quietHours := range [][2]sql.NullInt32{
+		{nullInt32(60), {}},
+		{nullInt32(60), nullInt32(60)},
+		{nullInt32(-1), nullInt32(60)},
+		{nullInt32(60), nullInt32(24 * 60)},
+	} {
+		invalid := arg
+		invalid.QuietHoursStart = quietHours[0]
+		invalid.QuietHoursEnd = quietHours[1]
+		_, err = store.UpsertNotificationSettings(ctx, invalid)
+		requireCode(t, err, "check_violation")
+	}
+
+	missing := arg
+	missing.Username = utils.RandomOwner()
+	_, err = store.UpsertNotificationSettings(ctx, missing)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testUpsertNotificationPreference(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	arg := db.UpsertNotificationPreferenceParams{
+		Username:  user.Username,
+		EventType: "post_commented",
+		Channel:   "push",
+		Enabled:   false,
+	}
+	preference, err := store.UpsertNotificationPreference(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, preference.Username)
+	require.Equal(t, arg.EventType, preference.EventType)
+	require.Equal(t, arg.Channel, preference.Channel)
+	require.False(t, preference.Enabled)
+	require.WithinDuration(t, time.Now(), preference.UpdatedAt, time.Minute)
+
+	tick()
+	arg.Enabled = true
+	updated, err := store.UpsertNotificationPreference(ctx, arg)
+	require.NoError(t, err)
+	require.True(t, updated.Enabled)
+	require.True(t, updated.UpdatedAt.After(preference.UpdatedAt))
+
+	preferences, err := store.ListNotificationPreferences(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, preferences, 1)
+
+	invalid := arg
+	invalid.Channel = "sms"
+	_, err = store.UpsertNotificationPreference(ctx, invalid)
+	requireCode(t, err, "check_violation")
+
+	missing := arg
+	missing.Username = utils.RandomOwner()
+	_, err = store.UpsertNotificationPreference(ctx, missing)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testListNotificationPreferences(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomNotificationPreference(t, store, user.Username, "post_liked", "push")
+	createRandomNotificationPreference(t, store, user.Username, "marketing", "email")
+	createRandomNotificationPreference(t, store, user.Username, "post_liked", "email")
+	createRandomNotificationPreference(t, store, createRandomUser(t, store).Username, "post_liked", "push")
+
+	preferences, err := store.ListNotificationPreferences(ctx, user.Username)
+	require.NoError(t, err)
+
+	keys := make([][2]string, len(preferences))
+	for i, preference := range preferences {
+		require.Equal(t, user.Username, preference.Username)
+		keys[i] = [2]string{preference.EventType, preference.Channel}
+	}
+	require.Equal(t, [][2]string{
+		{"marketing", "email"},
+		{"post_liked", "email"},
+		{"post_liked", "push"},
+	}, keys)
+
+	preferences, err = store.ListNotificationPreferences(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.Empty(t, preferences)
+	require.NotNil(t, preferences)
+}
+
+func testDeleteUserNotificationPreferences(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	other := createRandomUser(t, store)
+	createRandomNotificationPreference(t, store, user.Username, "post_liked", "push")
+	createRandomNotificationPreference(t, store, other.Username, "post_liked", "push")
+	_, err := store.UpsertNotificationSettings(ctx, db.UpsertNotificationSettingsParams{
+		Username: user.Username,
+		TimeZone: "UTC",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteUserNotificationPreferences(ctx, user.Username))
+	require.NoError(t, store.DeleteUserNotificationSettings(ctx, user.Username))
+
+	preferences, err := store.ListNotificationPreferences(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, preferences)
+
+	_, err = store.GetNotificationSettings(ctx, user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	preferences, err = store.ListNotificationPreferences(ctx, other.Username)
+	require.NoError(t, err)
+	require.Len(t, preferences, 1)
+}
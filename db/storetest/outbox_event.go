@@ -0,0 +1,328 @@
+package storetest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var outboxEventChecks = []check{
+	{"CreateUserTx", testCreateUserTx},
+	{"CreateUserTxRollback", testCreateUserTxRollback},
+	{"CreatePostTx", testCreatePostTx},
+	{"UpdatePostTxEvent", testUpdatePostTxEvent},
+	{"CreateCommentTx", testCreateCommentTx},
+	{"DeleteUserTxEvent", testDeleteUserTxEvent},
+	{"CreateConsultancyTx", testCreateConsultancyTx},
+	{"RelayOutboxTx", testRelayOutboxTx},
+	{"RelayOutboxTxFailure", testRelayOutboxTxFailure},
+}
+
+// relayLimit is large enough for the events of the other checks sharing the
+// store to be relayed in a few batches
+const relayLimit = 1000
+
+// relayAll delivers every event of the outbox and returns them
+func relayAll(t *testing.T, store db.Store) []db.OutboxEvent {
+	var events []db.OutboxEvent
+	for {
+		delivered, err := store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Limit: relayLimit,
+			Deliver: func(event db.OutboxEvent) error {
+				events = append(events, event)
+				return nil
+			},
+		})
+		require.NoError(t, err)
+		if delivered == 0 {
+			return events
+		}
+	}
+}
+
+// findEvents returns the events of the aggregate
+func findEvents(events []db.OutboxEvent, aggregate string) []db.OutboxEvent {
+	var found []db.OutboxEvent
+	for _, event := range events {
+		if event.Aggregate == aggregate {
+			found = append(found, event)
+		}
+	}
+	return found
+}
+
+func testCreateUserTx(t *testing.T, store db.Store) {
+	arg := db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(32),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		Gender:         utils.RandomGender(),
+		BirthDate:      utils.RandomBirthDate(),
+	}
+	user, err := store.CreateUserTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, user.Username)
+	require.Equal(t, arg.Email, user.Email)
+
+	events := findEvents(relayAll(t, store), "user:"+user.Username)
+	require.Len(t, events, 1)
+	require.Equal(t, db.EventUserRegistered, events[0].EventType)
+	require.NotZero(t, events[0].CreatedAt)
+
+	var payload db.UserRegisteredPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, db.UserRegisteredPayload{Username: user.Username}, payload)
+	// the personal data is not kept in the outbox
+	require.NotContains(t, string(events[0].Payload), user.Email)
+}
+
+func testCreateUserTxRollback(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	// the event of the failed registration is not appended
+	_, err := store.CreateUserTx(ctx, db.CreateUserParams{
+		Username:       user.Username,
+		HashedPassword: utils.RandomString(32),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		Gender:         utils.RandomGender(),
+		BirthDate:      utils.RandomBirthDate(),
+	})
+	requireCode(t, err, "unique_violation")
+	require.Empty(t, findEvents(relayAll(t, store), "user:"+user.Username))
+}
+
+func testCreatePostTx(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	arg := db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		Title:         nullString(utils.RandomString(12)),
+		ImageVariants: randomVariants(),
+	}
+	post, err := store.CreatePostTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Title, post.Title)
+
+	events := findEvents(relayAll(t, store), fmt.Sprintf("post:%d", post.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, db.EventPostCreated, events[0].EventType)
+
+	var payload db.PostCreatedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, db.PostCreatedPayload{
+		PostID:     post.ID,
+		MerchantID: merchant.ID,
+		Title:      post.Title.String,
+	}, payload)
+
+	// the post breaking the constraints is not created, so neither is its event
+	_, err = store.CreatePostTx(ctx, db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		ImageVariants: randomVariants(),
+	})
+	requireCode(t, err, "check_violation")
+}
+
+func testUpdatePostTxEvent(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+	aggregate := fmt.Sprintf("post:%d", post.ID)
+	relayAll(t, store)
+
+	result, err := store.UpdatePostTx(ctx, db.UpdatePostTxParams{
+		ID:     post.ID,
+		Editor: user.Username,
+		Title:  nullString(utils.RandomString(12)),
+	})
+	require.NoError(t, err)
+
+	events := findEvents(relayAll(t, store), aggregate)
+	require.Len(t, events, 1)
+	require.Equal(t, db.EventPostUpdated, events[0].EventType)
+
+	var payload db.PostUpdatedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, db.PostUpdatedPayload{
+		PostID:     post.ID,
+		MerchantID: post.MerchantID,
+		RevisionID: result.Revision.ID,
+		Title:      result.Post.Title.String,
+	}, payload)
+
+	// the failed update appends no event
+	_, err = store.UpdatePostTx(ctx, db.UpdatePostTxParams{
+		ID:         post.ID,
+		Editor:     user.Username,
+		ClearTitle: true,
+		ClearImage: true,
+	})
+	requireCode(t, err, "check_violation")
+	require.Empty(t, findEvents(relayAll(t, store), aggregate))
+}
+
+func testCreateCommentTx(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+
+	arg := db.CreateCommentParams{
+		CommentType: db.CommentTypePost,
+		PostID:      nullInt64(post.ID),
+		Owner:       user.Username,
+		Comment:     utils.RandomString(20),
+	}
+	comment, err := store.CreateCommentTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Comment, comment.Comment)
+
+	events := findEvents(relayAll(t, store), fmt.Sprintf("comment:%d", comment.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, db.EventCommentCreated, events[0].EventType)
+
+	var payload db.CommentCreatedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, db.CommentCreatedPayload{
+		CommentID:   comment.ID,
+		CommentType: db.CommentTypePost,
+		PostID:      post.ID,
+	}, payload)
+
+	// the comment of a missing post is not created, so neither is its event
+	invalid := arg
+	invalid.PostID = nullInt64(-1)
+	_, err = store.CreateCommentTx(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testDeleteUserTxEvent(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	aggregate := "user:" + user.Username
+	relayAll(t, store)
+
+	_, err := store.DeleteUserTx(ctx, user.Username)
+	require.NoError(t, err)
+
+	events := findEvents(relayAll(t, store), aggregate)
+	require.Len(t, events, 1)
+	require.Equal(t, db.EventUserDeleted, events[0].EventType)
+
+	var payload db.UserDeletedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, db.UserDeletedPayload{Username: user.Username}, payload)
+}
+
+func testCreateConsultancyTx(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	customer := createRandomCustomer(t, store, createRandomUser(t, store).Username)
+
+	arg := db.CreateConsultancyParams{
+		MerchantID: merchant.ID,
+		CustomerID: customer.ID,
+		Cost:       utils.RandomMoney(),
+	}
+	consultancy, err := store.CreateConsultancyTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Cost, consultancy.Cost)
+
+	events := findEvents(relayAll(t, store), fmt.Sprintf("consultancy:%d", consultancy.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, db.EventConsultancyBooked, events[0].EventType)
+
+	var payload db.ConsultancyBookedPayload
+	require.NoError(t, json.Unmarshal(events[0].Payload, &payload))
+	require.Equal(t, db.ConsultancyBookedPayload{
+		ConsultancyID: consultancy.ID,
+		MerchantID:    merchant.ID,
+		CustomerID:    customer.ID,
+		Cost:          arg.Cost,
+	}, payload)
+}
+
+func testRelayOutboxTx(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	posts := make([]db.Post, 3)
+	for i := range posts {
+		var err error
+		posts[i], err = store.CreatePostTx(ctx, db.CreatePostParams{
+			MerchantID:    merchant.ID,
+			Title:         nullString(utils.RandomString(12)),
+			ImageVariants: randomVariants(),
+		})
+		require.NoError(t, err)
+	}
+
+	// the events are delivered in the order of their transactions, and in the
+	// order they are appended within one
+	events := relayAll(t, store)
+	for i := 1; i < len(events); i++ {
+		prev, event := events[i-1], events[i]
+		require.LessOrEqual(t, prev.XactID, event.XactID)
+		if prev.XactID == event.XactID {
+			require.Less(t, prev.ID, event.ID)
+		}
+	}
+
+	var postIDs []int64
+	for _, event := range events {
+		if event.EventType != db.EventPostCreated {
+			continue
+		}
+		var payload db.PostCreatedPayload
+		require.NoError(t, json.Unmarshal(event.Payload, &payload))
+		if payload.MerchantID == merchant.ID {
+			postIDs = append(postIDs, payload.PostID)
+		}
+	}
+	require.Equal(t, []int64{posts[0].ID, posts[1].ID, posts[2].ID}, postIDs)
+
+	// the delivered events are gone
+	require.Empty(t, findEvents(relayAll(t, store), fmt.Sprintf("post:%d", posts[0].ID)))
+
+	_, err := store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+		Limit:   -1,
+		Deliver: func(event db.OutboxEvent) error { return nil },
+	})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+func testRelayOutboxTxFailure(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	posts := make([]db.Post, 2)
+	for i := range posts {
+		var err error
+		posts[i], err = store.CreatePostTx(ctx, db.CreatePostParams{
+			MerchantID:    merchant.ID,
+			Title:         nullString(utils.RandomString(12)),
+			ImageVariants: randomVariants(),
+		})
+		require.NoError(t, err)
+	}
+
+	// the relay stops at the failed event, the ones delivered before it are
+	// deleted and the failed one is delivered again
+	deliverErr := errors.New("sink is down")
+	failed := fmt.Sprintf("post:%d", posts[1].ID)
+	delivered, err := store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+		Limit: relayLimit,
+		Deliver: func(event db.OutboxEvent) error {
+			if event.Aggregate == failed {
+				return deliverErr
+			}
+			return nil
+		},
+	})
+	require.ErrorIs(t, err, deliverErr)
+	require.Positive(t, delivered)
+
+	events := relayAll(t, store)
+	require.Empty(t, findEvents(events, fmt.Sprintf("post:%d", posts[0].ID)))
+	require.Len(t, findEvents(events, failed), 1)
+}
@@ -0,0 +1,415 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var postChecks = []check{
+	{"CreatePost", testCreatePost},
+	{"GetPost", testGetPost},
+	{"GetPostForUpdate", testGetPostForUpdate},
+	{"ListMerchantPosts", testListMerchantPosts},
+	{"ListMerchantPostsByCursor", testListMerchantPostsByCursor},
+	{"ListPosts", testListPosts},
+	{"ListPostsByCursor", testListPostsByCursor},
+	{"ListPostsByOwner", testListPostsByOwner},
+	{"UpdatePost", testUpdatePost},
+	{"SetPostImageVariants", testSetPostImageVariants},
+	{"DeletePost", testDeletePost},
+	{"DeleteOwnerPosts", testDeleteOwnerPosts},
+}
+
+func requirePost(t *testing.T, expected, actual db.Post) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.MerchantID, actual.MerchantID)
+	require.Equal(t, expected.Title, actual.Title)
+	require.Equal(t, expected.ImageUrl, actual.ImageUrl)
+	require.Equal(t, expected.Likes, actual.Likes)
+	require.JSONEq(t, string(expected.ImageVariants), string(actual.ImageVariants))
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+	requireTime(t, expected.UpdatedAt, actual.UpdatedAt)
+	require.Equal(t, expected.DeletedAt.Valid, actual.DeletedAt.Valid)
+	requireTime(t, expected.DeletedAt.Time, actual.DeletedAt.Time)
+}
+
+// createRandomPosts creates n posts of the merchant, the last one is the newest
+func createRandomPosts(t *testing.T, store db.Store, merchantID int64, n int) []db.Post {
+	posts := make([]db.Post, n)
+	for i := range posts {
+		tick()
+		posts[i] = createRandomPost(t, store, merchantID)
+	}
+	return posts
+}
+
+// newestFirst returns the posts in the reverse order
+func newestFirst(posts []db.Post) []db.Post {
+	reversed := make([]db.Post, len(posts))
+	for i, post := range posts {
+		reversed[len(posts)-1-i] = post
+	}
+	return reversed
+}
+
+func testCreatePost(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+
+	arg := db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		Title:         nullString(utils.RandomString(12)),
+		ImageUrl:      nullString(utils.RandomImageUrl()),
+		ImageVariants: randomVariants(),
+	}
+	post, err := store.CreatePost(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, post.ID)
+	require.Equal(t, arg.MerchantID, post.MerchantID)
+	require.Equal(t, arg.Title, post.Title)
+	require.Equal(t, arg.ImageUrl, post.ImageUrl)
+	require.JSONEq(t, string(arg.ImageVariants), string(post.ImageVariants))
+	require.Zero(t, post.Likes)
+	require.WithinDuration(t, time.Now(), post.CreatedAt, time.Minute)
+	require.WithinDuration(t, post.CreatedAt, post.UpdatedAt, time.Second)
+	require.False(t, post.DeletedAt.Valid)
+
+	// either the title or the image is enough
+	_, err = store.CreatePost(ctx, db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		Title:         nullString(utils.RandomString(12)),
+		ImageVariants: randomVariants(),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CreatePost(ctx, db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		ImageUrl:      nullString(utils.RandomImageUrl()),
+		ImageVariants: randomVariants(),
+	})
+	require.NoError(t, err)
+
+	_, err = store.CreatePost(ctx, db.CreatePostParams{
+		MerchantID:    merchant.ID,
+		ImageVariants: randomVariants(),
+	})
+	requireCode(t, err, "check_violation")
+
+	_, err = store.CreatePost(ctx, db.CreatePostParams{
+		MerchantID: merchant.ID,
+		Title:      nullString(utils.RandomString(12)),
+	})
+	requireCode(t, err, "not_null_violation")
+
+	arg.MerchantID = -1
+	_, err = store.CreatePost(ctx, arg)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetPost(t *testing.T, store db.Store) {
+	post := createRandomPost(t, store, createRandomMerchant(t, store, createRandomUser(t, store).Username).ID)
+
+	got, err := store.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	requirePost(t, post, got)
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	_, err = store.GetPost(ctx, post.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetPost(ctx, -1)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testGetPostForUpdate(t *testing.T, store db.Store) {
+	post := createRandomPost(t, store, createRandomMerchant(t, store, createRandomUser(t, store).Username).ID)
+
+	got, err := store.GetPostForUpdate(ctx, post.ID)
+	require.NoError(t, err)
+	requirePost(t, post, got)
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	_, err = store.GetPostForUpdate(ctx, post.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListMerchantPosts(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	createRandomPost(t, store, createRandomMerchant(t, store, merchant.Owner).ID)
+	posts := createRandomPosts(t, store, merchant.ID, 6)
+
+	// the deleted posts are not listed
+	require.NoError(t, store.DeletePost(ctx, posts[2].ID))
+	posts = newestFirst(append(posts[:2], posts[3:]...))
+
+	list := func(limit, offset int32) ([]db.Post, error) {
+		return store.ListMerchantPosts(ctx, db.ListMerchantPostsParams{
+			MerchantID: merchant.ID,
+			Limit:      limit,
+			Offset:     offset,
+		})
+	}
+
+	got, err := list(10, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(posts))
+	for i := range posts {
+		requirePost(t, posts[i], got[i])
+	}
+
+	got, err = list(2, 1)
+	require.NoError(t, err)
+	require.Equal(t, postIDs(posts[1:3]), postIDs(got))
+
+	got, err = list(10, 5)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := list(limit, offset)
+		return err
+	})
+}
+
+func testListMerchantPostsByCursor(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	createRandomPost(t, store, createRandomMerchant(t, store, merchant.Owner).ID)
+	posts := createRandomPosts(t, store, merchant.ID, 6)
+
+	require.NoError(t, store.DeletePost(ctx, posts[2].ID))
+	posts = newestFirst(append(posts[:2], posts[3:]...))
+
+	// the pages are newest first, each one starts after the last post of the
+	// previous one
+	var got []db.Post
+	arg := db.ListMerchantPostsByCursorParams{MerchantID: merchant.ID, LimitCount: 2}
+	for {
+		page, err := store.ListMerchantPostsByCursor(ctx, arg)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, page...)
+
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = nullTime(last.CreatedAt)
+		arg.CursorID = nullInt64(last.ID)
+	}
+	require.Equal(t, postIDs(posts), postIDs(got))
+
+	page, err := store.ListMerchantPostsByCursor(ctx, db.ListMerchantPostsByCursorParams{MerchantID: merchant.ID})
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Empty(t, page)
+
+	_, err = store.ListMerchantPostsByCursor(ctx, db.ListMerchantPostsByCursorParams{MerchantID: merchant.ID, LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+// The posts of all the merchants are listed, so the checks of ListPosts and
+// ListPostsByCursor only rely on their posts being the newest ones
+
+func testListPosts(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	posts := createRandomPosts(t, store, merchant.ID, 4)
+
+	require.NoError(t, store.DeletePost(ctx, posts[3].ID))
+	posts = newestFirst(posts[:3])
+
+	got, err := store.ListPosts(ctx, db.ListPostsParams{Limit: 3})
+	require.NoError(t, err)
+	require.Len(t, got, len(posts))
+	for i := range posts {
+		requirePost(t, posts[i], got[i])
+	}
+
+	got, err = store.ListPosts(ctx, db.ListPostsParams{Limit: 2, Offset: 1})
+	require.NoError(t, err)
+	require.Equal(t, postIDs(posts[1:3]), postIDs(got))
+
+	got, err = store.ListPosts(ctx, db.ListPostsParams{})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := store.ListPosts(ctx, db.ListPostsParams{Limit: limit, Offset: offset})
+		return err
+	})
+}
+
+func testListPostsByCursor(t *testing.T, store db.Store) {
+	merchant := createRandomMerchant(t, store, createRandomUser(t, store).Username)
+	posts := createRandomPosts(t, store, merchant.ID, 5)
+
+	require.NoError(t, store.DeletePost(ctx, posts[3].ID))
+	posts = newestFirst(append(posts[:3], posts[4]))
+
+	got, err := store.ListPostsByCursor(ctx, db.ListPostsByCursorParams{LimitCount: 2})
+	require.NoError(t, err)
+	require.Equal(t, postIDs(posts[:2]), postIDs(got))
+
+	// the cursor post itself is not listed again
+	got, err = store.ListPostsByCursor(ctx, db.ListPostsByCursorParams{
+		CursorCreatedAt: nullTime(got[1].CreatedAt),
+		CursorID:        nullInt64(got[1].ID),
+		LimitCount:      2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, postIDs(posts[2:4]), postIDs(got))
+	for i := range got {
+		requirePost(t, posts[2+i], got[i])
+	}
+
+	got, err = store.ListPostsByCursor(ctx, db.ListPostsByCursorParams{})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	_, err = store.ListPostsByCursor(ctx, db.ListPostsByCursorParams{LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+func testListPostsByOwner(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomPost(t, store, createRandomMerchant(t, store, createRandomUser(t, store).Username).ID)
+
+	var posts []db.Post
+	for i := 0; i < 2; i++ {
+		merchant := createRandomMerchant(t, store, user.Username)
+		posts = append(posts, createRandomPosts(t, store, merchant.ID, 2)...)
+	}
+
+	// the deleted posts are listed too, since the list is an export of the
+	// user data
+	require.NoError(t, store.DeletePost(ctx, posts[1].ID))
+	deleted, err := store.ListPostsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, postIDs(posts), postIDs(deleted))
+	require.True(t, deleted[1].DeletedAt.Valid)
+	requirePost(t, posts[0], deleted[0])
+
+	got, err := store.ListPostsByOwner(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+}
+
+func testUpdatePost(t *testing.T, store db.Store) {
+	post := createRandomPost(t, store, createRandomMerchant(t, store, createRandomUser(t, store).Username).ID)
+
+	// every field is set, the null title removes the title
+	arg := db.UpdatePostParams{
+		ID:            post.ID,
+		ImageUrl:      nullString(utils.RandomImageUrl()),
+		ImageVariants: randomVariants(),
+	}
+	updated, err := store.UpdatePost(ctx, arg)
+	require.NoError(t, err)
+	require.False(t, updated.Title.Valid)
+	require.Equal(t, arg.ImageUrl, updated.ImageUrl)
+	require.JSONEq(t, string(arg.ImageVariants), string(updated.ImageVariants))
+	requireTime(t, post.CreatedAt, updated.CreatedAt)
+	require.False(t, updated.UpdatedAt.Before(post.UpdatedAt))
+
+	got, err := store.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	requirePost(t, updated, got)
+
+	_, err = store.UpdatePost(ctx, db.UpdatePostParams{ID: post.ID, ImageVariants: randomVariants()})
+	requireCode(t, err, "check_violation")
+
+	got, err = store.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	requirePost(t, updated, got)
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	_, err = store.UpdatePost(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.ID = -1
+	_, err = store.UpdatePost(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testSetPostImageVariants(t *testing.T, store db.Store) {
+	post := createRandomPost(t, store, createRandomMerchant(t, store, createRandomUser(t, store).Username).ID)
+
+	// the variants of a replaced image are not stored
+	arg := db.SetPostImageVariantsParams{
+		ID:            post.ID,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	n, err := store.SetPostImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg.ImageUrl = post.ImageUrl.String
+	n, err = store.SetPostImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err := store.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	require.JSONEq(t, string(arg.ImageVariants), string(got.ImageVariants))
+	requireTime(t, post.UpdatedAt, got.UpdatedAt)
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	n, err = store.SetPostImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func testDeletePost(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	posts, err := store.ListPostsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, posts, 1)
+	require.True(t, posts[0].DeletedAt.Valid)
+	require.WithinDuration(t, time.Now(), posts[0].DeletedAt.Time, time.Minute)
+
+	// deleting the post again keeps the first deletion time
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	again, err := store.ListPostsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	requirePost(t, posts[0], again[0])
+
+	require.NoError(t, store.DeletePost(ctx, -1))
+}
+
+func testDeleteOwnerPosts(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	posts := createRandomPosts(t, store, createRandomMerchant(t, store, user.Username).ID, 3)
+	other := createRandomPost(t, store, createRandomMerchant(t, store, createRandomUser(t, store).Username).ID)
+
+	require.NoError(t, store.DeletePost(ctx, posts[0].ID))
+	before, err := store.ListPostsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+
+	require.NoError(t, store.DeleteOwnerPosts(ctx, user.Username))
+
+	after, err := store.ListPostsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, after, len(posts))
+	for _, post := range after {
+		require.True(t, post.DeletedAt.Valid)
+	}
+	// the already deleted post keeps its deletion time
+	requirePost(t, before[0], after[0])
+
+	got, err := store.GetPost(ctx, other.ID)
+	require.NoError(t, err)
+	requirePost(t, other, got)
+}
@@ -0,0 +1,150 @@
+package storetest
+
+import (
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var postRevisionChecks = []check{
+	{"CreatePostRevision", testCreatePostRevision},
+	{"ListPostRevisions", testListPostRevisions},
+	{"ListPostRevisionsByCursor", testListPostRevisionsByCursor},
+}
+
+func createRandomRevision(t *testing.T, store db.Store, post db.Post, editor string) db.PostRevision {
+	revision, err := store.CreatePostRevision(ctx, db.CreatePostRevisionParams{
+		PostID:        post.ID,
+		Editor:        editor,
+		Title:         post.Title,
+		ImageUrl:      post.ImageUrl,
+		ImageVariants: post.ImageVariants,
+	})
+	require.NoError(t, err)
+	return revision
+}
+
+func testCreatePostRevision(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+
+	arg := db.CreatePostRevisionParams{
+		PostID:        post.ID,
+		Editor:        user.Username,
+		ImageUrl:      post.ImageUrl,
+		ImageVariants: post.ImageVariants,
+	}
+	revision, err := store.CreatePostRevision(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, revision.ID)
+	require.Equal(t, arg.PostID, revision.PostID)
+	require.Equal(t, arg.Editor, revision.Editor)
+	require.False(t, revision.Title.Valid)
+	require.Equal(t, arg.ImageUrl, revision.ImageUrl)
+	require.JSONEq(t, string(arg.ImageVariants), string(revision.ImageVariants))
+	require.WithinDuration(t, time.Now(), revision.CreatedAt, time.Minute)
+
+	invalid := arg
+	invalid.PostID = -1
+	_, err = store.CreatePostRevision(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+
+	invalid = arg
+	invalid.Editor = utils.RandomOwner()
+	_, err = store.CreatePostRevision(ctx, invalid)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testListPostRevisions(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	createRandomRevision(t, store, createRandomPost(t, store, merchant.ID), user.Username)
+
+	revisions := make([]db.PostRevision, 4)
+	for i := range revisions {
+		revisions[i] = createRandomRevision(t, store, post, user.Username)
+	}
+
+	list := func(limit, offset int32) ([]db.PostRevision, error) {
+		return store.ListPostRevisions(ctx, db.ListPostRevisionsParams{
+			PostID: post.ID,
+			Limit:  limit,
+			Offset: offset,
+		})
+	}
+
+	// the latest revisions come first
+	got, err := list(10, 0)
+	require.NoError(t, err)
+	require.Len(t, got, len(revisions))
+	for i := range got {
+		expected := revisions[len(revisions)-1-i]
+		require.Equal(t, expected.ID, got[i].ID)
+		require.Equal(t, expected.Editor, got[i].Editor)
+		require.Equal(t, expected.Title, got[i].Title)
+		requireTime(t, expected.CreatedAt, got[i].CreatedAt)
+	}
+
+	got, err = list(2, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, revisions[2].ID, got[0].ID)
+	require.Equal(t, revisions[1].ID, got[1].ID)
+
+	got, err = list(10, 4)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+
+	requireLimitErrors(t, func(limit, offset int32) error {
+		_, err := list(limit, offset)
+		return err
+	})
+}
+
+func testListPostRevisionsByCursor(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	merchant := createRandomMerchant(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	createRandomRevision(t, store, createRandomPost(t, store, merchant.ID), user.Username)
+
+	revisions := make([]db.PostRevision, 5)
+	for i := range revisions {
+		revisions[len(revisions)-1-i] = createRandomRevision(t, store, post, user.Username)
+	}
+
+	// the pages are newest first, each one starts after the last revision of
+	// the previous one
+	var got []db.PostRevision
+	arg := db.ListPostRevisionsByCursorParams{PostID: post.ID, LimitCount: 2}
+	for {
+		page, err := store.ListPostRevisionsByCursor(ctx, arg)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		got = append(got, page...)
+
+		last := page[len(page)-1]
+		arg.CursorCreatedAt = nullTime(last.CreatedAt)
+		arg.CursorID = nullInt64(last.ID)
+	}
+	require.Len(t, got, len(revisions))
+	for i := range got {
+		require.Equal(t, revisions[i].ID, got[i].ID)
+		requireTime(t, revisions[i].CreatedAt, got[i].CreatedAt)
+	}
+
+	page, err := store.ListPostRevisionsByCursor(ctx, db.ListPostRevisionsByCursorParams{PostID: post.ID})
+	require.NoError(t, err)
+	require.NotNil(t, page)
+	require.Empty(t, page)
+
+	_, err = store.ListPostRevisionsByCursor(ctx, db.ListPostRevisionsByCursorParams{PostID: post.ID, LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
@@ -0,0 +1,157 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+var sessionChecks = []check{
+	{"CreateSession", testCreateSession},
+	{"GetSession", testGetSession},
+	{"ListUserSessions", testListUserSessions},
+	{"DeleteUserSessions", testDeleteUserSessions},
+	{"BlockUserSessions", testBlockUserSessions},
+}
+
+func requireSession(t *testing.T, expected, actual db.Session) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Username, actual.Username)
+	require.Equal(t, expected.RefreshToken, actual.RefreshToken)
+	require.Equal(t, expected.UserAgent, actual.UserAgent)
+	require.Equal(t, expected.ClientIp, actual.ClientIp)
+	require.Equal(t, expected.IsBlocked, actual.IsBlocked)
+	requireTime(t, expected.ExpiresAt, actual.ExpiresAt)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func testCreateSession(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	arg := db.CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     user.Username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     "10.0.0.1",
+		IsBlocked:    true,
+		ExpiresAt:    time.Now().Add(time.Hour).Truncate(time.Microsecond),
+	}
+	require.NoError(t, store.CreateSession(ctx, arg))
+
+	session, err := store.GetSession(ctx, arg.ID)
+	require.NoError(t, err)
+	requireSession(t, db.Session{
+		ID:           arg.ID,
+		Username:     arg.Username,
+		RefreshToken: arg.RefreshToken,
+		UserAgent:    arg.UserAgent,
+		ClientIp:     arg.ClientIp,
+		IsBlocked:    arg.IsBlocked,
+		ExpiresAt:    arg.ExpiresAt,
+		CreatedAt:    session.CreatedAt,
+	}, session)
+	require.WithinDuration(t, time.Now(), session.CreatedAt, time.Minute)
+
+	err = store.CreateSession(ctx, arg)
+	requireCode(t, err, "unique_violation")
+
+	arg.ID = uuid.New()
+	arg.Username = utils.RandomOwner()
+	err = store.CreateSession(ctx, arg)
+	requireCode(t, err, "foreign_key_violation")
+}
+
+func testGetSession(t *testing.T, store db.Store) {
+	session := createRandomSession(t, store, createRandomUser(t, store).Username)
+
+	got, err := store.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	requireSession(t, session, got)
+
+	_, err = store.GetSession(ctx, uuid.New())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListUserSessions(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomSession(t, store, createRandomUser(t, store).Username)
+
+	// the oldest sessions come first
+	sessions := make([]db.Session, 3)
+	for i := range sessions {
+		tick()
+		sessions[i] = createRandomSession(t, store, user.Username)
+	}
+
+	got, err := store.ListUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+	require.Len(t, got, len(sessions))
+	for i := range sessions {
+		requireSession(t, sessions[i], got[i])
+	}
+
+	got, err = store.ListUserSessions(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	require.Empty(t, got)
+}
+
+func testDeleteUserSessions(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	createRandomSession(t, store, user.Username)
+	createRandomSession(t, store, user.Username)
+	other := createRandomSession(t, store, createRandomUser(t, store).Username)
+
+	require.NoError(t, store.DeleteUserSessions(ctx, user.Username))
+
+	sessions, err := store.ListUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	_, err = store.GetSession(ctx, other.ID)
+	require.NoError(t, err)
+
+	// the user without sessions can be deleted now
+	require.NoError(t, store.DeleteUser(ctx, user.Username))
+	require.NoError(t, store.DeleteUserSessions(ctx, user.Username))
+}
+
+func testBlockUserSessions(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	sessions := []db.Session{
+		createRandomSession(t, store, user.Username),
+		createRandomSession(t, store, user.Username),
+	}
+	other := createRandomSession(t, store, createRandomUser(t, store).Username)
+
+	// only the sessions not blocked yet are counted
+	blocked, err := store.BlockUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(sessions)), blocked)
+
+	blocked, err = store.BlockUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+	require.Zero(t, blocked)
+
+	for _, session := range sessions {
+		got, err := store.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		require.True(t, got.IsBlocked)
+	}
+
+	got, err := store.GetSession(ctx, other.ID)
+	require.NoError(t, err)
+	require.False(t, got.IsBlocked)
+
+	blocked, err = store.BlockUserSessions(ctx, utils.RandomOwner())
+	require.NoError(t, err)
+	require.Zero(t, blocked)
+}
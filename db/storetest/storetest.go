@@ -0,0 +1,212 @@
+// Package storetest is the contract test suite of db.Store. Every store
+// implementation and every store decorator is expected to pass it, so that
+// they can replace each other without the handlers noticing.
+package storetest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns the store under test. It is called once for every check.
+// The returned stores may share their data, like the ones of a single
+// database, so the checks only rely on the rows they create themselves.
+type Factory func(t *testing.T) db.Store
+
+type check struct {
+	name string
+	run  func(t *testing.T, store db.Store)
+}
+
+// Run runs the checks of every store method against the stores of newStore
+func Run(t *testing.T, newStore Factory) {
+	checks := [][]check{
+		userChecks,
+		customerChecks,
+		merchantChecks,
+		postChecks,
+		postRevisionChecks,
+		commentChecks,
+		consultancyChecks,
+		sessionChecks,
+		versionChecks,
+		auditEventChecks,
+		idempotencyKeyChecks,
+		notificationChecks,
+		deviceTokenChecks,
+		notificationPreferenceChecks,
+		jobChecks,
+		outboxEventChecks,
+		txChecks,
+		healthChecks,
+	}
+
+	for _, group := range checks {
+		for _, c := range group {
+			c := c
+			t.Run(c.name, func(t *testing.T) {
+				c.run(t, newStore(t))
+			})
+		}
+	}
+}
+
+var ctx = context.Background()
+
+// requireCode requires err to be a postgres error with the condition name,
+// like unique_violation
+func requireCode(t *testing.T, err error, name string) {
+	t.Helper()
+
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr)
+	require.Equal(t, name, pqErr.Code.Name())
+}
+
+// requireLimitErrors requires list to reject the negative limits and offsets
+// like postgres does
+func requireLimitErrors(t *testing.T, list func(limit, offset int32) error) {
+	t.Helper()
+
+	requireCode(t, list(-1, 0), "invalid_row_count_in_limit_clause")
+	requireCode(t, list(1, -1), "invalid_row_count_in_result_offset_clause")
+}
+
+// requireTime requires the times to be the same instant, since a store may
+// return them in another location
+func requireTime(t *testing.T, expected, actual time.Time) {
+	t.Helper()
+	require.WithinDuration(t, expected, actual, time.Microsecond)
+}
+
+// tick waits for the clock to move on, so that the rows created before and
+// after it have different creation times. The lists ordered only by the
+// creation time are not stable otherwise.
+func tick() {
+	time.Sleep(time.Millisecond)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+func nullInt32(i int32) sql.NullInt32 {
+	return sql.NullInt32{Int32: i, Valid: true}
+}
+
+func nullInt64(i int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: i, Valid: true}
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: true}
+}
+
+func randomVariants() json.RawMessage {
+	return json.RawMessage(`{"thumb": "` + utils.RandomImageUrl() + `"}`)
+}
+
+func createRandomUser(t *testing.T, store db.Store) db.User {
+	arg := db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(32),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		Gender:         utils.RandomGender(),
+		BirthDate:      utils.RandomBirthDate(),
+	}
+
+	user, err := store.CreateUser(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, user.Username)
+	return user
+}
+
+func createRandomCustomer(t *testing.T, store db.Store, owner string) db.Customer {
+	customer, err := store.CreateCustomer(ctx, owner)
+	require.NoError(t, err)
+	return customer
+}
+
+func createRandomMerchant(t *testing.T, store db.Store, owner string) db.Merchant {
+	merchant, err := store.CreateMerchant(ctx, db.CreateMerchantParams{
+		Owner:      owner,
+		Profession: utils.RandomString(8),
+		Title:      utils.RandomString(8),
+		About:      utils.RandomString(20),
+	})
+	require.NoError(t, err)
+	return merchant
+}
+
+func createRandomPost(t *testing.T, store db.Store, merchantID int64) db.Post {
+	post, err := store.CreatePost(ctx, db.CreatePostParams{
+		MerchantID:    merchantID,
+		Title:         nullString(utils.RandomString(12)),
+		ImageUrl:      nullString(utils.RandomImageUrl()),
+		ImageVariants: randomVariants(),
+	})
+	require.NoError(t, err)
+	return post
+}
+
+func createRandomComment(t *testing.T, store db.Store, postID int64, owner string) db.Comment {
+	comment, err := store.CreateComment(ctx, db.CreateCommentParams{
+		CommentType: db.CommentTypePost,
+		PostID:      nullInt64(postID),
+		Owner:       owner,
+		Comment:     utils.RandomString(20),
+	})
+	require.NoError(t, err)
+	return comment
+}
+
+func createRandomSession(t *testing.T, store db.Store, username string) db.Session {
+	arg := db.CreateSessionParams{
+		ID:           uuid.New(),
+		Username:     username,
+		RefreshToken: utils.RandomString(32),
+		UserAgent:    utils.RandomString(10),
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour).Truncate(time.Microsecond),
+	}
+	require.NoError(t, store.CreateSession(ctx, arg))
+
+	session, err := store.GetSession(ctx, arg.ID)
+	require.NoError(t, err)
+	return session
+}
+
+func postIDs(posts []db.Post) []int64 {
+	ids := make([]int64, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	return ids
+}
+
+func commentIDs(comments []db.Comment) []int64 {
+	ids := make([]int64, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+	return ids
+}
+
+func merchantIDs(merchants []db.Merchant) []int64 {
+	ids := make([]int64, len(merchants))
+	for i, merchant := range merchants {
+		ids[i] = merchant.ID
+	}
+	return ids
+}
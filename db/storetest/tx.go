@@ -0,0 +1,389 @@
+package storetest
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var txChecks = []check{
+	{"UpdatePostTx", testUpdatePostTx},
+	{"UpdatePostTxMerge", testUpdatePostTxMerge},
+	{"DeleteUserTx", testDeleteUserTx},
+	{"UpdateNotificationPreferencesTx", testUpdateNotificationPreferencesTx},
+	{"ExportUserTx", testExportUserTx},
+	{"AuditTx", testAuditTx},
+	{"AuditTxRollback", testAuditTxRollback},
+}
+
+func testUpdatePostTx(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+
+	arg := db.UpdatePostTxParams{
+		ID:         post.ID,
+		Editor:     user.Username,
+		Title:      nullString(utils.RandomString(12)),
+		ClearImage: true,
+	}
+	result, err := store.UpdatePostTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Title, result.Post.Title)
+	require.False(t, result.Post.ImageUrl.Valid)
+	require.JSONEq(t, "{}", string(result.Post.ImageVariants))
+
+	// the revision keeps the post as it was before the edit
+	require.Equal(t, post.ID, result.Revision.PostID)
+	require.Equal(t, user.Username, result.Revision.Editor)
+	require.Equal(t, post.Title, result.Revision.Title)
+	require.Equal(t, post.ImageUrl, result.Revision.ImageUrl)
+	require.JSONEq(t, string(post.ImageVariants), string(result.Revision.ImageVariants))
+
+	// the failed update leaves no revision behind
+	_, err = store.UpdatePostTx(ctx, db.UpdatePostTxParams{
+		ID:         post.ID,
+		Editor:     user.Username,
+		ClearTitle: true,
+	})
+	requireCode(t, err, "check_violation")
+
+	revisions, err := store.ListPostRevisions(ctx, db.ListPostRevisionsParams{PostID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+
+	got, err := store.GetPost(ctx, post.ID)
+	require.NoError(t, err)
+	requirePost(t, result.Post, got)
+
+	require.NoError(t, store.DeletePost(ctx, post.ID))
+	_, err = store.UpdatePostTx(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testUpdatePostTxMerge(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+
+	// the updates change only what they are given, so the one made after
+	// another keeps its changes even if both read the same post
+	titled, err := store.UpdatePostTx(ctx, db.UpdatePostTxParams{
+		ID:     post.ID,
+		Editor: user.Username,
+		Title:  nullString(utils.RandomString(12)),
+	})
+	require.NoError(t, err)
+	require.Equal(t, post.ImageUrl, titled.Post.ImageUrl)
+	require.JSONEq(t, string(post.ImageVariants), string(titled.Post.ImageVariants))
+
+	image := db.UpdatePostTxParams{
+		ID:            post.ID,
+		Editor:        user.Username,
+		ImageUrl:      nullString(utils.RandomImageUrl()),
+		ImageVariants: randomVariants(),
+	}
+	result, err := store.UpdatePostTx(ctx, image)
+	require.NoError(t, err)
+	require.Equal(t, titled.Post.Title, result.Post.Title)
+	require.Equal(t, image.ImageUrl, result.Post.ImageUrl)
+	require.JSONEq(t, string(image.ImageVariants), string(result.Post.ImageVariants))
+
+	// the title can be cleared once the post has an image
+	result, err = store.UpdatePostTx(ctx, db.UpdatePostTxParams{
+		ID:         post.ID,
+		Editor:     user.Username,
+		ClearTitle: true,
+	})
+	require.NoError(t, err)
+	require.False(t, result.Post.Title.Valid)
+	require.Equal(t, image.ImageUrl, result.Post.ImageUrl)
+}
+
+func testDeleteUserTx(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	other := createRandomUser(t, store)
+
+	merchant := createRandomMerchant(t, store, user.Username)
+	customer := createRandomCustomer(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	otherPost := createRandomPost(t, store, createRandomMerchant(t, store, other.Username).ID)
+	comment := createRandomComment(t, store, otherPost.ID, user.Username)
+	revision := createRandomRevision(t, store, otherPost, user.Username)
+	consultancy := createRandomConsultancy(t, store, merchant.ID, customer.ID)
+	session := createRandomSession(t, store, user.Username)
+	createRandomDeviceToken(t, store, session)
+	key := createRandomIdempotencyKey(t, store, user.Username)
+	createRandomNotification(t, store, user.Username, other.Username)
+	caused := createRandomNotification(t, store, other.Username, user.Username)
+	createRandomNotificationPreference(t, store, user.Username, "post_liked", "push")
+	_, err := store.UpsertNotificationSettings(ctx, db.UpsertNotificationSettingsParams{
+		Username: user.Username,
+		TimeZone: "UTC",
+	})
+	require.NoError(t, err)
+
+	result, err := store.DeleteUserTx(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, []int64{merchant.ID}, result.MerchantIDs)
+	require.Equal(t, []int64{customer.ID}, result.CustomerIDs)
+
+	_, err = store.GetUser(ctx, user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the rows the others rely on are kept, without the personal data
+	gotMerchant, err := store.GetMerchant(ctx, merchant.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, gotMerchant.Owner)
+
+	gotCustomer, err := store.GetCustomer(ctx, customer.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, gotCustomer.Owner)
+
+	_, err = store.GetPost(ctx, post.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	gotComment, err := store.GetComment(ctx, comment.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, gotComment.Owner)
+
+	revisions, err := store.ListPostRevisions(ctx, db.ListPostRevisionsParams{PostID: otherPost.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, revision.ID, revisions[0].ID)
+	require.Equal(t, deletedUser, revisions[0].Editor)
+
+	_, err = store.GetConsultancy(ctx, consultancy.ID)
+	require.NoError(t, err)
+
+	sessions, err := store.ListUserSessions(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, sessions)
+
+	_, err = store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{Username: user.Username, Key: key.Key})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	notifications, err := store.ListNotifications(ctx, db.ListNotificationsParams{Recipient: user.Username, LimitCount: 10})
+	require.NoError(t, err)
+	require.Empty(t, notifications)
+
+	notifications, err = store.ListNotifications(ctx, db.ListNotificationsParams{Recipient: other.Username, LimitCount: 10})
+	require.NoError(t, err)
+	require.Equal(t, []int64{caused.ID}, notificationIDs(notifications))
+	require.Equal(t, deletedUser, notifications[0].Actor)
+
+	tokens, err := store.ListUserDeviceTokens(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	preferences, err := store.ListNotificationPreferences(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, preferences)
+
+	_, err = store.GetNotificationSettings(ctx, user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// the other user is left alone
+	_, err = store.GetPost(ctx, otherPost.ID)
+	require.NoError(t, err)
+
+	// deleting a missing user is not an error
+	result, err = store.DeleteUserTx(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, result.MerchantIDs)
+}
+
+func testUpdateNotificationPreferencesTx(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	kept := createRandomNotificationPreference(t, store, user.Username, "marketing", "email")
+
+	arg := db.UpdateNotificationPreferencesTxParams{
+		Settings: db.UpsertNotificationSettingsParams{
+			Username:        user.Username,
+			TimeZone:        "Europe/Istanbul",
+			QuietHoursStart: nullInt32(23 * 60),
+			QuietHoursEnd:   nullInt32(8 * 60),
+		},
+		Preferences: []db.UpsertNotificationPreferenceParams{
+			{EventType: "post_commented", Channel: "push", Enabled: false},
+			{EventType: "post_commented", Channel: "in_app", Enabled: true},
+		},
+	}
+	result, err := store.UpdateNotificationPreferencesTx(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Settings.TimeZone, result.Settings.TimeZone)
+	require.Equal(t, arg.Settings.QuietHoursStart, result.Settings.QuietHoursStart)
+
+	// the preferences that are not given are kept
+	require.Len(t, result.Preferences, 3)
+	require.Equal(t, kept.EventType, result.Preferences[0].EventType)
+	require.Equal(t, "in_app", result.Preferences[1].Channel)
+	require.True(t, result.Preferences[1].Enabled)
+	require.Equal(t, "push", result.Preferences[2].Channel)
+	require.False(t, result.Preferences[2].Enabled)
+
+	// the invalid preference rolls the settings back
+	_, err = store.UpdateNotificationPreferencesTx(ctx, db.UpdateNotificationPreferencesTxParams{
+		Settings: db.UpsertNotificationSettingsParams{Username: user.Username, TimeZone: "UTC"},
+		Preferences: []db.UpsertNotificationPreferenceParams{
+			{EventType: "post_commented", Channel: "push", Enabled: true},
+			{EventType: "post_commented", Channel: "sms", Enabled: true},
+		},
+	})
+	requireCode(t, err, "check_violation")
+
+	settings, err := store.GetNotificationSettings(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, arg.Settings.TimeZone, settings.TimeZone)
+
+	preferences, err := store.ListNotificationPreferences(ctx, user.Username)
+	require.NoError(t, err)
+	require.False(t, preferences[2].Enabled)
+}
+
+func testExportUserTx(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	other := createRandomUser(t, store)
+
+	merchant := createRandomMerchant(t, store, user.Username)
+	customer := createRandomCustomer(t, store, user.Username)
+	post := createRandomPost(t, store, merchant.ID)
+	comment := createRandomComment(t, store, post.ID, user.Username)
+	createRandomComment(t, store, post.ID, other.Username)
+	consultancy := createRandomConsultancy(t, store, createRandomMerchant(t, store, other.Username).ID, customer.ID)
+	session := createRandomSession(t, store, user.Username)
+	notification := createRandomNotification(t, store, user.Username, other.Username)
+	createRandomNotification(t, store, other.Username, user.Username)
+	deviceToken := createRandomDeviceToken(t, store, session)
+	settings, err := store.UpsertNotificationSettings(ctx, db.UpsertNotificationSettingsParams{
+		Username: user.Username,
+		TimeZone: "Europe/Istanbul",
+	})
+	require.NoError(t, err)
+	preference := createRandomNotificationPreference(t, store, user.Username, "marketing", "push")
+	auditEvent, err := store.CreateAuditEvent(ctx, db.CreateAuditEventParams{
+		Actor:  user.Username,
+		Action: "user.update",
+		Target: "user:" + user.Username,
+		Diff:   []byte(`{}`),
+	})
+	require.NoError(t, err)
+
+	result, err := store.ExportUserTx(ctx, user.Username)
+	require.NoError(t, err)
+	requireUser(t, user, result.User)
+
+	require.Len(t, result.Customers, 1)
+	requireCustomer(t, customer, result.Customers[0])
+	require.Len(t, result.Merchants, 1)
+	requireMerchant(t, merchant, result.Merchants[0])
+	require.Len(t, result.Posts, 1)
+	requirePost(t, post, result.Posts[0])
+	require.Len(t, result.Comments, 1)
+	requireComment(t, comment, result.Comments[0])
+	require.Len(t, result.Consultancies, 1)
+	requireConsultancy(t, consultancy, result.Consultancies[0])
+	require.Len(t, result.Sessions, 1)
+	requireSession(t, session, result.Sessions[0])
+	require.Len(t, result.Notifications, 1)
+	requireNotification(t, notification, result.Notifications[0])
+	require.Equal(t, []string{deviceToken.Token}, deviceTokenValues(result.DeviceTokens))
+	require.NotNil(t, result.NotificationSettings)
+	require.Equal(t, settings.TimeZone, result.NotificationSettings.TimeZone)
+	require.Len(t, result.NotificationPreferences, 1)
+	require.Equal(t, preference.EventType, result.NotificationPreferences[0].EventType)
+	require.Equal(t, preference.Enabled, result.NotificationPreferences[0].Enabled)
+	require.Len(t, result.AuditEvents, 1)
+	requireAuditEvent(t, auditEvent, result.AuditEvents[0])
+
+	// the user without any rows has empty lists
+	result, err = store.ExportUserTx(ctx, other.Username)
+	require.NoError(t, err)
+	require.Empty(t, result.Customers)
+	require.NotNil(t, result.Customers)
+	require.Len(t, result.Comments, 1)
+	require.Len(t, result.Notifications, 1)
+	require.Empty(t, result.DeviceTokens)
+	require.Nil(t, result.NotificationSettings)
+	require.Empty(t, result.NotificationPreferences)
+	require.Empty(t, result.AuditEvents)
+
+	_, err = store.ExportUserTx(ctx, utils.RandomOwner())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testAuditTx(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	actor := "cli:" + utils.RandomOwner()
+
+	event, err := store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  actor,
+		Action: "user.disable",
+		Target: "user:" + user.Username,
+		Apply: func(q db.Querier) (interface{}, error) {
+			_, err := q.SetUserDisabled(ctx, db.SetUserDisabledParams{
+				Username: user.Username,
+				Disabled: true,
+			})
+			return map[string]bool{"disabled": true}, err
+		},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, event.ID)
+	require.Equal(t, actor, event.Actor)
+	require.Equal(t, "user.disable", event.Action)
+	require.Equal(t, "user:"+user.Username, event.Target)
+
+	var diff map[string]bool
+	require.NoError(t, json.Unmarshal(event.Diff, &diff))
+	require.True(t, diff["disabled"])
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	require.True(t, got.Disabled)
+
+	events, err := store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		Actor:      nullString(actor),
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	requireAuditEvent(t, event, events[0])
+}
+
+func testAuditTxRollback(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	actor := "cli:" + utils.RandomOwner()
+	applyErr := errors.New("apply failed")
+
+	// the error of Apply is returned as is and undoes its changes
+	_, err := store.AuditTx(ctx, db.AuditTxParams{
+		Actor:  actor,
+		Action: "user.disable",
+		Target: "user:" + user.Username,
+		Apply: func(q db.Querier) (interface{}, error) {
+			if _, err := q.SetUserDisabled(ctx, db.SetUserDisabledParams{
+				Username: user.Username,
+				Disabled: true,
+			}); err != nil {
+				return nil, err
+			}
+			return nil, applyErr
+		},
+	})
+	require.ErrorIs(t, err, applyErr)
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	require.False(t, got.Disabled)
+
+	events, err := store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		Actor:      nullString(actor),
+		LimitCount: 10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
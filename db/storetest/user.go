@@ -0,0 +1,500 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// deletedUser is the user the rows of the deleted users are moved to
+const deletedUser = "deleted_user"
+
+var userChecks = []check{
+	{"CreateUser", testCreateUser},
+	{"GetUser", testGetUser},
+	{"UpdateUser", testUpdateUser},
+	{"UpdateUserImage", testUpdateUserImage},
+	{"SetUserImageVariants", testSetUserImageVariants},
+	{"UpdatePassword", testUpdatePassword},
+	{"UpdateEmail", testUpdateEmail},
+	{"DeleteUser", testDeleteUser},
+	{"ScheduleUserDeletion", testScheduleUserDeletion},
+	{"ListUsersDueForDeletion", testListUsersDueForDeletion},
+	{"SetUserDisabled", testSetUserDisabled},
+	{"AnonymizeUserComments", testAnonymizeUserComments},
+	{"AnonymizeUserPostRevisions", testAnonymizeUserPostRevisions},
+	{"UserVersion", testUserVersion},
+}
+
+func requireUser(t *testing.T, expected, actual db.User) {
+	t.Helper()
+
+	require.Equal(t, expected.Username, actual.Username)
+	require.Equal(t, expected.HashedPassword, actual.HashedPassword)
+	require.Equal(t, expected.FullName, actual.FullName)
+	require.Equal(t, expected.Email, actual.Email)
+	require.Equal(t, expected.PhoneNumber, actual.PhoneNumber)
+	require.Equal(t, expected.ImageUrl, actual.ImageUrl)
+	require.Equal(t, expected.Gender, actual.Gender)
+	require.Equal(t, expected.Disabled, actual.Disabled)
+	require.Equal(t, expected.Role, actual.Role)
+	require.JSONEq(t, string(expected.ImageVariants), string(actual.ImageVariants))
+	requireTime(t, expected.BirthDate, actual.BirthDate)
+	requireTime(t, expected.PasswordChangedAt, actual.PasswordChangedAt)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+	require.Equal(t, expected.DeletionScheduledAt.Valid, actual.DeletionScheduledAt.Valid)
+	requireTime(t, expected.DeletionScheduledAt.Time, actual.DeletionScheduledAt.Time)
+}
+
+func testCreateUser(t *testing.T, store db.Store) {
+	arg := db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(32),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		Gender:         "f",
+		BirthDate:      time.Date(1990, 5, 17, 0, 0, 0, 0, time.UTC),
+	}
+
+	user, err := store.CreateUser(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, user.Username)
+	require.Equal(t, arg.HashedPassword, user.HashedPassword)
+	require.Equal(t, arg.FullName, user.FullName)
+	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, arg.PhoneNumber, user.PhoneNumber)
+	require.Equal(t, arg.Gender, user.Gender)
+	requireTime(t, arg.BirthDate, user.BirthDate)
+	require.WithinDuration(t, time.Now(), user.CreatedAt, time.Minute)
+
+	// the defaults of the columns
+	require.Equal(t, "/default/user/avatar.jpg", user.ImageUrl)
+	require.Equal(t, "user", user.Role)
+	require.JSONEq(t, "{}", string(user.ImageVariants))
+	require.False(t, user.Disabled)
+	require.True(t, user.PasswordChangedAt.Equal(time.Date(1, 1, 1, 0, 0, 0, 0, time.UTC)))
+	require.False(t, user.DeletionScheduledAt.Valid)
+
+	duplicate := arg
+	duplicate.Email = utils.RandomEmail()
+	duplicate.PhoneNumber = utils.RandomPhoneNumber()
+	_, err = store.CreateUser(ctx, duplicate)
+	requireCode(t, err, "unique_violation")
+
+	duplicate = arg
+	duplicate.Username = utils.RandomOwner()
+	duplicate.PhoneNumber = utils.RandomPhoneNumber()
+	_, err = store.CreateUser(ctx, duplicate)
+	requireCode(t, err, "unique_violation")
+
+	duplicate = arg
+	duplicate.Username = utils.RandomOwner()
+	duplicate.Email = utils.RandomEmail()
+	_, err = store.CreateUser(ctx, duplicate)
+	requireCode(t, err, "unique_violation")
+
+	tooLong := db.CreateUserParams{
+		Username:    utils.RandomOwner(),
+		Email:       utils.RandomEmail(),
+		PhoneNumber: utils.RandomPhoneNumber(),
+		Gender:      "mf",
+	}
+	_, err = store.CreateUser(ctx, tooLong)
+	requireCode(t, err, "string_data_right_truncation")
+
+	_, err = store.GetUser(ctx, tooLong.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testGetUser(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	requireUser(t, user, got)
+
+	_, err = store.GetUser(ctx, utils.RandomOwner())
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testUpdateUser(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	// only the given fields change
+	fullName := utils.RandomOwner()
+	updated, err := store.UpdateUser(ctx, db.UpdateUserParams{
+		Username: user.Username,
+		FullName: nullString(fullName),
+	})
+	require.NoError(t, err)
+	expected := user
+	expected.FullName = fullName
+	requireUser(t, expected, updated)
+
+	arg := db.UpdateUserParams{
+		Username:    user.Username,
+		PhoneNumber: nullString(utils.RandomPhoneNumber()),
+		Gender:      nullString("f"),
+		BirthDate:   nullTime(time.Date(1985, 1, 2, 0, 0, 0, 0, time.UTC)),
+		ImageUrl:    nullString(utils.RandomImageUrl()),
+	}
+	updated, err = store.UpdateUser(ctx, arg)
+	require.NoError(t, err)
+	expected.PhoneNumber = arg.PhoneNumber.String
+	expected.Gender = arg.Gender.String
+	expected.BirthDate = arg.BirthDate.Time
+	expected.ImageUrl = arg.ImageUrl.String
+	requireUser(t, expected, updated)
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	requireUser(t, expected, got)
+
+	other := createRandomUser(t, store)
+	_, err = store.UpdateUser(ctx, db.UpdateUserParams{
+		Username:    user.Username,
+		PhoneNumber: nullString(other.PhoneNumber),
+	})
+	requireCode(t, err, "unique_violation")
+
+	_, err = store.UpdateUser(ctx, db.UpdateUserParams{
+		Username: user.Username,
+		Gender:   nullString("mf"),
+	})
+	requireCode(t, err, "string_data_right_truncation")
+
+	// the failed updates leave the user as it was
+	got, err = store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	requireUser(t, expected, got)
+
+	_, err = store.UpdateUser(ctx, db.UpdateUserParams{
+		Username: utils.RandomOwner(),
+		FullName: nullString(fullName),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testUpdateUserImage(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	arg := db.UpdateUserImageParams{
+		Username:      user.Username,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	updated, err := store.UpdateUserImage(ctx, arg)
+	require.NoError(t, err)
+	expected := user
+	expected.ImageUrl = arg.ImageUrl
+	expected.ImageVariants = arg.ImageVariants
+	requireUser(t, expected, updated)
+
+	_, err = store.UpdateUserImage(ctx, db.UpdateUserImageParams{
+		Username:      user.Username,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: []byte("{"),
+	})
+	requireCode(t, err, "invalid_text_representation")
+
+	arg.Username = utils.RandomOwner()
+	_, err = store.UpdateUserImage(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testSetUserImageVariants(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	// the variants of a replaced image are not stored
+	arg := db.SetUserImageVariantsParams{
+		Username:      user.Username,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+	}
+	n, err := store.SetUserImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	arg.ImageUrl = user.ImageUrl
+	n, err = store.SetUserImageVariants(ctx, arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	require.JSONEq(t, string(arg.ImageVariants), string(got.ImageVariants))
+	require.Equal(t, user.Version+1, got.Version)
+}
+
+func testUpdatePassword(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	arg := db.UpdatePasswordParams{
+		Username:          user.Username,
+		HashedPassword:    utils.RandomString(32),
+		PasswordChangedAt: time.Now().Truncate(time.Microsecond),
+	}
+	updated, err := store.UpdatePassword(ctx, arg)
+	require.NoError(t, err)
+	expected := user
+	expected.HashedPassword = arg.HashedPassword
+	expected.PasswordChangedAt = arg.PasswordChangedAt
+	requireUser(t, expected, updated)
+
+	arg.Username = utils.RandomOwner()
+	_, err = store.UpdatePassword(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testUpdateEmail(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	email := utils.RandomEmail()
+	updated, err := store.UpdateEmail(ctx, db.UpdateEmailParams{Username: user.Username, Email: email})
+	require.NoError(t, err)
+	expected := user
+	expected.Email = email
+	requireUser(t, expected, updated)
+
+	// the unchanged email is not a duplicate of itself
+	_, err = store.UpdateEmail(ctx, db.UpdateEmailParams{Username: user.Username, Email: email})
+	require.NoError(t, err)
+
+	other := createRandomUser(t, store)
+	_, err = store.UpdateEmail(ctx, db.UpdateEmailParams{Username: user.Username, Email: other.Email})
+	requireCode(t, err, "unique_violation")
+
+	_, err = store.UpdateEmail(ctx, db.UpdateEmailParams{Username: utils.RandomOwner(), Email: utils.RandomEmail()})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testDeleteUser(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	require.NoError(t, store.DeleteUser(ctx, user.Username))
+	_, err := store.GetUser(ctx, user.Username)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// deleting a missing user is not an error
+	require.NoError(t, store.DeleteUser(ctx, user.Username))
+
+	// the users with rows are not deleted
+	owner := createRandomUser(t, store)
+	createRandomMerchant(t, store, owner.Username)
+	err = store.DeleteUser(ctx, owner.Username)
+	requireCode(t, err, "foreign_key_violation")
+
+	_, err = store.GetUser(ctx, owner.Username)
+	require.NoError(t, err)
+}
+
+func testScheduleUserDeletion(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	scheduledAt := time.Now().Add(24 * time.Hour).Truncate(time.Microsecond)
+	updated, err := store.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		Username:            user.Username,
+		DeletionScheduledAt: nullTime(scheduledAt),
+	})
+	require.NoError(t, err)
+	expected := user
+	expected.DeletionScheduledAt = nullTime(scheduledAt)
+	requireUser(t, expected, updated)
+
+	// the null time cancels the deletion
+	updated, err = store.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{Username: user.Username})
+	require.NoError(t, err)
+	requireUser(t, user, updated)
+
+	_, err = store.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{Username: utils.RandomOwner()})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testListUsersDueForDeletion(t *testing.T, store db.Store) {
+	// a random day of the past no other check schedules a deletion at
+	now := time.Date(1900+int(utils.RandomInt(0, 99)), 1, 1, 0, 0, 0, 0, time.UTC).
+		Add(time.Duration(utils.RandomInt(0, 364)) * 24 * time.Hour)
+
+	due := make([]db.User, 3)
+	for i := range due {
+		due[i] = createRandomUser(t, store)
+		_, err := store.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+			Username: due[i].Username,
+			// the later users are scheduled earlier
+			DeletionScheduledAt: nullTime(now.Add(-time.Duration(i) * time.Hour)),
+		})
+		require.NoError(t, err)
+	}
+
+	later := createRandomUser(t, store)
+	_, err := store.ScheduleUserDeletion(ctx, db.ScheduleUserDeletionParams{
+		Username:            later.Username,
+		DeletionScheduledAt: nullTime(now.Add(time.Second)),
+	})
+	require.NoError(t, err)
+
+	usernames, err := store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{
+		Now:        now.Add(-90 * time.Minute),
+		LimitCount: 100,
+	})
+	require.NoError(t, err)
+	require.Contains(t, usernames, due[2].Username)
+	require.NotContains(t, usernames, due[1].Username)
+
+	usernames, err = store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{
+		Now:        now,
+		LimitCount: 100,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, usernames, later.Username)
+
+	// the earliest scheduled users come first, including the ones due just now
+	var found []string
+	for _, username := range usernames {
+		for _, user := range due {
+			if user.Username == username {
+				found = append(found, username)
+			}
+		}
+	}
+	require.Equal(t, []string{due[2].Username, due[1].Username, due[0].Username}, found)
+
+	usernames, err = store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{Now: now})
+	require.NoError(t, err)
+	require.NotNil(t, usernames)
+	require.Empty(t, usernames)
+
+	_, err = store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{Now: now, LimitCount: -1})
+	requireCode(t, err, "invalid_row_count_in_limit_clause")
+}
+
+func testSetUserDisabled(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+
+	updated, err := store.SetUserDisabled(ctx, db.SetUserDisabledParams{Username: user.Username, Disabled: true})
+	require.NoError(t, err)
+	expected := user
+	expected.Disabled = true
+	requireUser(t, expected, updated)
+
+	updated, err = store.SetUserDisabled(ctx, db.SetUserDisabledParams{Username: user.Username})
+	require.NoError(t, err)
+	requireUser(t, user, updated)
+
+	_, err = store.SetUserDisabled(ctx, db.SetUserDisabledParams{Username: utils.RandomOwner(), Disabled: true})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func testAnonymizeUserComments(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	other := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, other.Username).ID)
+
+	comment := createRandomComment(t, store, post.ID, user.Username)
+	otherComment := createRandomComment(t, store, post.ID, other.Username)
+
+	require.NoError(t, store.AnonymizeUserComments(ctx, user.Username))
+
+	got, err := store.GetComment(ctx, comment.ID)
+	require.NoError(t, err)
+	require.Equal(t, deletedUser, got.Owner)
+	require.Equal(t, comment.Comment, got.Comment)
+
+	got, err = store.GetComment(ctx, otherComment.ID)
+	require.NoError(t, err)
+	require.Equal(t, other.Username, got.Owner)
+
+	comments, err := store.ListCommentsByOwner(ctx, user.Username)
+	require.NoError(t, err)
+	require.Empty(t, comments)
+}
+
+func testAnonymizeUserPostRevisions(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	post := createRandomPost(t, store, createRandomMerchant(t, store, user.Username).ID)
+
+	revision, err := store.CreatePostRevision(ctx, db.CreatePostRevisionParams{
+		PostID:        post.ID,
+		Editor:        user.Username,
+		Title:         post.Title,
+		ImageUrl:      post.ImageUrl,
+		ImageVariants: post.ImageVariants,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, store.AnonymizeUserPostRevisions(ctx, user.Username))
+
+	revisions, err := store.ListPostRevisions(ctx, db.ListPostRevisionsParams{PostID: post.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	require.Equal(t, revision.ID, revisions[0].ID)
+	require.Equal(t, deletedUser, revisions[0].Editor)
+	require.Equal(t, revision.Title, revisions[0].Title)
+}
+
+func testUserVersion(t *testing.T, store db.Store) {
+	user := createRandomUser(t, store)
+	require.Equal(t, int64(1), user.Version)
+
+	// every update increments the version
+	updated, err := store.UpdateUser(ctx, db.UpdateUserParams{
+		Username: user.Username,
+		FullName: nullString(utils.RandomOwner()),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Version+1, updated.Version)
+
+	updated, err = store.UpdateEmail(ctx, db.UpdateEmailParams{
+		Username: user.Username,
+		Email:    utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Version+2, updated.Version)
+
+	updated, err = store.SetUserDisabled(ctx, db.SetUserDisabledParams{Username: user.Username, Disabled: true})
+	require.NoError(t, err)
+	require.Equal(t, user.Version+3, updated.Version)
+
+	// the update with a stale version does not find the user
+	_, err = store.UpdateUser(ctx, db.UpdateUserParams{
+		Username: user.Username,
+		FullName: nullString(utils.RandomOwner()),
+		Version:  nullInt64(user.Version),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.UpdateUserImage(ctx, db.UpdateUserImageParams{
+		Username:      user.Username,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+		Version:       nullInt64(user.Version),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	requireUser(t, updated, got)
+	require.Equal(t, updated.Version, got.Version)
+
+	imaged, err := store.UpdateUserImage(ctx, db.UpdateUserImageParams{
+		Username:      user.Username,
+		ImageUrl:      utils.RandomImageUrl(),
+		ImageVariants: randomVariants(),
+		Version:       nullInt64(got.Version),
+	})
+	require.NoError(t, err)
+	require.Equal(t, got.Version+1, imaged.Version)
+
+	updated, err = store.UpdateUser(ctx, db.UpdateUserParams{
+		Username: user.Username,
+		FullName: nullString(utils.RandomOwner()),
+		Version:  nullInt64(imaged.Version),
+	})
+	require.NoError(t, err)
+	require.Equal(t, imaged.Version+1, updated.Version)
+}
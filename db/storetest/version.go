@@ -0,0 +1,101 @@
+package storetest
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+var versionChecks = []check{
+	{"CreateAppVersion", testCreateAppVersion},
+	{"GetAppVersion", testGetAppVersion},
+	{"ListAppVersions", testListAppVersions},
+	{"UpdateAppVersion", testUpdateAppVersion},
+}
+
+func createRandomAppVersion(t *testing.T, store db.Store) db.AppVersion {
+	version, err := store.CreateAppVersion(ctx, db.CreateAppVersionParams{
+		Tag:     utils.RandomString(12),
+		Version: utils.RandomString(6),
+	})
+	require.NoError(t, err)
+	return version
+}
+
+func requireAppVersion(t *testing.T, expected, actual db.AppVersion) {
+	t.Helper()
+
+	require.Equal(t, expected.ID, actual.ID)
+	require.Equal(t, expected.Tag, actual.Tag)
+	require.Equal(t, expected.Version, actual.Version)
+	requireTime(t, expected.CreatedAt, actual.CreatedAt)
+}
+
+func testCreateAppVersion(t *testing.T, store db.Store) {
+	arg := db.CreateAppVersionParams{
+		Tag:     utils.RandomString(12),
+		Version: utils.RandomString(6),
+	}
+	version, err := store.CreateAppVersion(ctx, arg)
+	require.NoError(t, err)
+	require.NotZero(t, version.ID)
+	require.Equal(t, arg.Tag, version.Tag)
+	require.Equal(t, arg.Version, version.Version)
+	require.WithinDuration(t, time.Now(), version.CreatedAt, time.Minute)
+}
+
+func testGetAppVersion(t *testing.T, store db.Store) {
+	version := createRandomAppVersion(t, store)
+
+	got, err := store.GetAppVersion(ctx, version.Tag)
+	require.NoError(t, err)
+	requireAppVersion(t, version, got)
+
+	_, err = store.GetAppVersion(ctx, utils.RandomString(12))
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+// The versions of all the tags are listed, so the check only relies on its
+// versions being the newest ones
+func testListAppVersions(t *testing.T, store db.Store) {
+	older := createRandomAppVersion(t, store)
+	tick()
+	newer := createRandomAppVersion(t, store)
+
+	versions, err := store.ListAppVersions(ctx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(versions), 2)
+	requireAppVersion(t, newer, versions[0])
+	requireAppVersion(t, older, versions[1])
+}
+
+func testUpdateAppVersion(t *testing.T, store db.Store) {
+	version := createRandomAppVersion(t, store)
+
+	arg := db.UpdateAppVersionParams{
+		ID:      version.ID,
+		Tag:     utils.RandomString(12),
+		Version: utils.RandomString(6),
+	}
+	updated, err := store.UpdateAppVersion(ctx, arg)
+	require.NoError(t, err)
+	expected := version
+	expected.Tag = arg.Tag
+	expected.Version = arg.Version
+	requireAppVersion(t, expected, updated)
+
+	got, err := store.GetAppVersion(ctx, arg.Tag)
+	require.NoError(t, err)
+	requireAppVersion(t, expected, got)
+
+	_, err = store.GetAppVersion(ctx, version.Tag)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	arg.ID = -1
+	_, err = store.UpdateAppVersion(ctx, arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
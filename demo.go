@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/utils"
+)
+
+// credentials of the user created in demo mode
+const (
+	demoUsername = "demouser"
+	demoPassword = "demopassword"
+)
+
+// newDemoStore creates an in-memory store with a user, a merchant and a few
+// posts, so that the API can be tried without a database. Everything is lost
+// when the server stops.
+func newDemoStore(ctx context.Context) (db.Store, error) {
+	store := memstore.NewStore()
+
+	hashedPassword, err := utils.HashPassword(demoPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:       demoUsername,
+		HashedPassword: hashedPassword,
+		FullName:       "Demo User",
+		Email:          "demo@thenut.invalid",
+		PhoneNumber:    "+900000000000",
+		Gender:         "m",
+		BirthDate:      time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merchant, err := store.CreateMerchant(ctx, db.CreateMerchantParams{
+		Owner:      user.Username,
+		Profession: "psychologist",
+		Title:      "Demo Consulting",
+		About:      "A merchant created for the demo mode",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := store.CreateCustomer(ctx, user.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = store.CreateConsultancy(ctx, db.CreateConsultancyParams{
+		MerchantID: merchant.ID,
+		CustomerID: customer.ID,
+		Cost:       100,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, title := range []string{"Welcome to the nut", "Try the API with the demo user"} {
+		post, err := store.CreatePost(ctx, db.CreatePostParams{
+			MerchantID:    merchant.ID,
+			Title:         sql.NullString{String: title, Valid: true},
+			ImageVariants: json.RawMessage("{}"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = store.CreateComment(ctx, db.CreateCommentParams{
+			CommentType: db.CommentTypePost,
+			PostID:      sql.NullInt64{Int64: post.ID, Valid: true},
+			Owner:       user.Username,
+			Comment:     "A comment of the demo user",
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the password is not logged as an attribute since the logger redacts it
+	slog.Info("running in demo mode with an in-memory store, log in as " + demoUsername + " with " + demoPassword)
+	return store, nil
+}
@@ -0,0 +1,25 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/asdsec/thenut/audit"
+	"github.com/asdsec/thenut/logger"
+)
+
+// recordAuditEvent records the action of the call. A failure is only logged
+// since the audited action is already done.
+func (server *Server) recordAuditEvent(ctx context.Context, actor, action, target string, diff interface{}) {
+	err := server.auditor.Record(ctx, audit.Event{
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		IP:        clientIP(ctx),
+		UserAgent: userAgent(ctx),
+		RequestID: callFromContext(ctx).requestID,
+		Diff:      diff,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("cannot record audit event", "action", action, "err", err)
+	}
+}
@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func convertUser(user db.User) *pb.User {
+	return &pb.User{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		PhoneNumber:       user.PhoneNumber,
+		ImageUrl:          user.ImageUrl,
+		Gender:            user.Gender,
+		Disabled:          user.Disabled,
+		BirthDate:         timestamppb.New(user.BirthDate),
+		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		CreatedAt:         timestamppb.New(user.CreatedAt),
+	}
+}
+
+func convertMerchant(merchant db.Merchant) *pb.Merchant {
+	return &pb.Merchant{
+		Id:         merchant.ID,
+		Owner:      merchant.Owner,
+		Balance:    merchant.Balance,
+		Profession: merchant.Profession,
+		Title:      merchant.Title,
+		About:      merchant.About,
+		ImageUrl:   merchant.ImageUrl,
+		Rating:     merchant.Rating,
+		CreatedAt:  timestamppb.New(merchant.CreatedAt),
+	}
+}
+
+func convertPost(post db.Post) *pb.Post {
+	return &pb.Post{
+		Id:         post.ID,
+		MerchantId: post.MerchantID,
+		Title:      post.Title.String,
+		ImageUrl:   post.ImageUrl.String,
+		Likes:      post.Likes,
+		CreatedAt:  timestamppb.New(post.CreatedAt),
+		UpdatedAt:  timestamppb.New(post.UpdatedAt),
+	}
+}
+
+func convertConsultancy(consultancy db.Consultancy) *pb.Consultancy {
+	return &pb.Consultancy{
+		Id:         consultancy.ID,
+		MerchantId: consultancy.MerchantID,
+		CustomerId: consultancy.CustomerID,
+		Cost:       consultancy.Cost,
+		CreatedAt:  timestamppb.New(consultancy.CreatedAt),
+	}
+}
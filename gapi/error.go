@@ -0,0 +1,91 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/asdsec/thenut/i18n"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errAccountNotOwned  = i18n.NewError("error.account_not_owned")
+	errUsernameNotOwned = i18n.NewError("error.username_not_owned")
+	errUserDisabled     = i18n.NewError("error.user_disabled")
+)
+
+// locale returns the locale of the accept-language metadata
+func locale(ctx context.Context) i18n.Locale {
+	return i18n.Match(firstMetadata(ctx, acceptLanguageHeader))
+}
+
+// statusError converts the error into a status with the message in the locale
+// of the call. Validation, database and postgres errors are mapped the same
+// way the HTTP API maps them, so that the code is only picked for the errors
+// of the handlers, and the messages of internal errors never reach clients.
+func statusError(ctx context.Context, code codes.Code, err error) error {
+	loc := locale(ctx)
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return invalidArgumentError(loc, validationErrs)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, loc.T("error.resource_not_found"))
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return status.Error(codes.AlreadyExists, loc.T("error.resource_already_exists"))
+		case "foreign_key_violation":
+			return status.Error(codes.FailedPrecondition, loc.T("error.invalid_reference"))
+		case "check_violation":
+			return status.Error(codes.InvalidArgument, loc.T("error.constraint_violation"))
+		}
+		code = codes.Internal
+	}
+
+	if code == codes.Internal {
+		// keep the original error for the logs since the status hides it
+		callFromContext(ctx).err = err
+		return status.Error(codes.Internal, loc.T("error.internal"))
+	}
+
+	message := err.Error()
+	var localizedErr *i18n.Error
+	if errors.As(err, &localizedErr) {
+		message = localizedErr.Message(loc)
+	}
+	return status.Error(code, message)
+}
+
+// storeError converts the error of a failed store call into a status
+func storeError(ctx context.Context, err error) error {
+	return statusError(ctx, codes.Internal, err)
+}
+
+// invalidArgumentError lists the failed fields in the bad request details
+func invalidArgumentError(loc i18n.Locale, validationErrs validator.ValidationErrors) error {
+	badRequest := &errdetails.BadRequest{}
+	for _, fe := range validationErrs {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field(),
+			Description: loc.TranslateField(fe),
+		})
+	}
+
+	st := status.New(codes.InvalidArgument, loc.T("error.request_validation_failed"))
+	detailed, err := st.WithDetails(badRequest)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,141 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/token"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+	requestIDHeader     = "x-request-id"
+	maxRequestIDLength  = 128
+)
+
+// publicMethods can be called without an access token
+var publicMethods = map[string]bool{
+	pb.TheNut_RegisterUser_FullMethodName:     true,
+	pb.TheNut_LoginUser_FullMethodName:        true,
+	pb.TheNut_RenewAccessToken_FullMethodName: true,
+}
+
+type payloadKey struct{}
+
+type callKey struct{}
+
+// call collects what the log entry of a call needs from the inner interceptors
+// and the handlers
+type call struct {
+	requestID string
+	username  string
+	err       error
+}
+
+func callFromContext(ctx context.Context) *call {
+	if c, ok := ctx.Value(callKey{}).(*call); ok {
+		return c
+	}
+	return &call{}
+}
+
+// loggerInterceptor assigns the request ID and writes a structured log entry
+// for every call
+func (server *Server) loggerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	requestID := firstMetadata(ctx, requestIDHeader)
+	if requestID == "" || len(requestID) > maxRequestIDLength || strings.ContainsFunc(requestID, isUnsafeRune) {
+		requestID = uuid.NewString()
+	}
+
+	c := &call{requestID: requestID}
+	ctx = context.WithValue(ctx, callKey{}, c)
+	callLogger := logger.FromContext(ctx).With(slog.String("request_id", requestID))
+	ctx = logger.WithContext(ctx, callLogger)
+
+	resp, err := handler(ctx, req)
+
+	code := status.Code(err)
+	attrs := []slog.Attr{
+		slog.String("method", info.FullMethod),
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+		slog.String("client_ip", clientIP(ctx)),
+	}
+	if c.username != "" {
+		attrs = append(attrs, slog.String("username", c.username))
+	}
+	if c.err != nil {
+		attrs = append(attrs, slog.String("error", c.err.Error()))
+	}
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	callLogger.LogAttrs(ctx, level, "grpc call", attrs...)
+	return resp, err
+}
+
+// authInterceptor verifies the bearer access token of the non-public methods
+func (server *Server) authInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := server.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	callFromContext(ctx).username = payload.Username
+	return handler(context.WithValue(ctx, payloadKey{}, payload), req)
+}
+
+func (server *Server) authorize(ctx context.Context) (*token.TokenPayload, error) {
+	authorization := firstMetadata(ctx, authorizationHeader)
+	if authorization == "" {
+		return nil, statusError(ctx, codes.Unauthenticated, i18n.NewError("error.authorization_header_missing"))
+	}
+
+	fields := strings.Fields(authorization)
+	if len(fields) < 2 {
+		return nil, statusError(ctx, codes.Unauthenticated, i18n.NewError("error.authorization_header_invalid"))
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationBearer {
+		return nil, statusError(ctx, codes.Unauthenticated, i18n.NewError("error.authorization_type_unsupported", authorizationType))
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, statusError(ctx, codes.Unauthenticated, err)
+	}
+	return payload, nil
+}
+
+// getAuthPayload returns the payload of the access token of the call
+func getAuthPayload(ctx context.Context) *token.TokenPayload {
+	return ctx.Value(payloadKey{}).(*token.TokenPayload)
+}
+
+func isUnsafeRune(r rune) bool {
+	return r < '!' || r > '~'
+}
@@ -0,0 +1,153 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/token"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildContext  func(t *testing.T, tokenMaker token.TokenMaker) context.Context
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.UserResponse, err error)
+	}{
+		{
+			name: "Ok",
+			buildContext: func(t *testing.T, tokenMaker token.TokenMaker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+			},
+		},
+		{
+			name: "NoAuthorization",
+			buildContext: func(t *testing.T, tokenMaker token.TokenMaker) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidAuthorizationFormat",
+			buildContext: func(t *testing.T, tokenMaker token.TokenMaker) context.Context {
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, authorizationBearer)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "UnsupportedAuthorization",
+			buildContext: func(t *testing.T, tokenMaker token.TokenMaker) context.Context {
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, time.Minute)
+				require.NoError(t, err)
+
+				return metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, "basic "+accessToken)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "ExpiredToken",
+			buildContext: func(t *testing.T, tokenMaker token.TokenMaker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, -time.Minute)
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+
+			ctx := tc.buildContext(t, tokenMaker)
+			rsp, err := client.GetUser(ctx, &pb.GetUserRequest{Username: user.Username})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestAuthInterceptorPublicMethod(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUser(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(db.User{}, sql.ErrNoRows)
+
+	client := newTestClient(t, newTestServer(t, store, newTestTokenMaker(t)))
+
+	// the login does not need an access token
+	_, err := client.LoginUser(context.Background(), &pb.LoginUserRequest{
+		Username: "username",
+		Password: "password",
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestLocalizedStatus(t *testing.T) {
+	client := newTestClient(t, newTestServer(t, mock_db.NewMockStore(gomock.NewController(t)), newTestTokenMaker(t)))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), acceptLanguageHeader, "tr-TR")
+	_, err := client.GetUser(ctx, &pb.GetUserRequest{})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Equal(t, "yetkilendirme başlığı sağlanmadı", status.Convert(err).Message())
+}
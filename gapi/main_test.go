@@ -0,0 +1,102 @@
+package gapi
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/audit"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/token"
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var testConfig = utils.Config{
+	TokenSymmetricKey:    utils.RandomString(32),
+	AccessTokenDuration:  time.Minute,
+	RefreshTokenDuration: time.Hour,
+}
+
+func newTestServer(t *testing.T, store db.Store, tokenMaker token.TokenMaker) *Server {
+	server, err := NewServer(testConfig, store, tokenMaker, metrics.New())
+	require.NoError(t, err)
+
+	// keep the audit events and the notifications away from the mock store
+	server.auditor = &testAuditor{}
+	server.notifier = &testNotifier{}
+
+	return server
+}
+
+// newTestClient serves the server on an in-memory listener, so that the calls
+// of the client run through the interceptors
+func newTestClient(t *testing.T, server *Server, opts ...grpc.DialOption) pb.TheNutClient {
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(server.grpcServer.Stop)
+
+	opts = append(opts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.Dial("bufnet", opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewTheNutClient(conn)
+}
+
+// testAuditor keeps the recorded audit events in memory
+type testAuditor struct {
+	events []audit.Event
+}
+
+func (auditor *testAuditor) Record(ctx context.Context, event audit.Event) error {
+	auditor.events = append(auditor.events, event)
+	return nil
+}
+
+// testNotifier keeps the notified events in memory
+type testNotifier struct {
+	events []notification.Event
+}
+
+func (notifier *testNotifier) Notify(ctx context.Context, event notification.Event) error {
+	notifier.events = append(notifier.events, event)
+	return nil
+}
+
+func newTestTokenMaker(t *testing.T) token.TokenMaker {
+	tokenMaker, err := token.NewPasetoMaker(testConfig.TokenSymmetricKey)
+	require.NoError(t, err)
+
+	return tokenMaker
+}
+
+// newContextWithBearerToken returns a context with an access token of the user
+// in the outgoing metadata
+func newContextWithBearerToken(t *testing.T, tokenMaker token.TokenMaker, username string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	return metadata.AppendToOutgoingContext(context.Background(), authorizationHeader, authorizationBearer+" "+accessToken)
+}
+
+func TestMain(m *testing.M) {
+	slog.SetDefault(slog.New(slog.NewJSONHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
@@ -0,0 +1,42 @@
+package gapi
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	userAgentHeader      = "user-agent"
+	acceptLanguageHeader = "accept-language"
+)
+
+// firstMetadata returns the first value of the incoming metadata key
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func userAgent(ctx context.Context) string {
+	return firstMetadata(ctx, userAgentHeader)
+}
+
+// clientIP returns the address of the peer of the call
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+		return host
+	}
+	return p.Addr.String()
+}
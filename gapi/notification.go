@@ -0,0 +1,17 @@
+package gapi
+
+import (
+	"context"
+
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/notification"
+)
+
+// notify delivers the notification of the event. A failure is only logged
+// since the notified action is already done.
+func (server *Server) notify(ctx context.Context, event notification.Event) {
+	err := server.notifier.Notify(ctx, event)
+	if err != nil {
+		logger.FromContext(ctx).Error("cannot notify", "type", event.Type, "err", err)
+	}
+}
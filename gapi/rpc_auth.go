@@ -0,0 +1,205 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/asdsec/thenut/audit"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/utils"
+	"google.golang.org/grpc/codes"
+)
+
+type registerUserInput struct {
+	Username    string    `json:"username" binding:"required,alphanum,min=6"`
+	Password    string    `json:"password" binding:"required,min=6,max=72"`
+	Email       string    `json:"email" binding:"required,email"`
+	FullName    string    `json:"full_name" binding:"required"`
+	PhoneNumber string    `json:"phone_number" binding:"required,min=11"`
+	Gender      string    `json:"gender" binding:"required,gender"`
+	BirthDate   time.Time `json:"birth_date" binding:"required"`
+}
+
+func (server *Server) RegisterUser(ctx context.Context, req *pb.RegisterUserRequest) (*pb.AuthResponse, error) {
+	input := registerUserInput{
+		Username:    req.GetUsername(),
+		Password:    req.GetPassword(),
+		Email:       req.GetEmail(),
+		FullName:    req.GetFullName(),
+		PhoneNumber: req.GetPhoneNumber(),
+		Gender:      req.GetGender(),
+	}
+	if req.GetBirthDate() != nil {
+		input.BirthDate = req.GetBirthDate().AsTime()
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := utils.HashPassword(input.Password)
+	if err != nil {
+		return nil, statusError(ctx, codes.Internal, err)
+	}
+
+	user, err := server.store.CreateUserTx(ctx, db.CreateUserParams{
+		Username:       input.Username,
+		HashedPassword: hashedPassword,
+		FullName:       input.FullName,
+		Email:          input.Email,
+		PhoneNumber:    input.PhoneNumber,
+		Gender:         input.Gender,
+		BirthDate:      input.BirthDate,
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	rsp, _, err := server.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	server.metrics.IncEvent(metrics.EventRegistration)
+	return rsp, nil
+}
+
+type loginUserInput struct {
+	Username string `json:"username" binding:"required,alphanum,min=6,max=72"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.AuthResponse, error) {
+	input := loginUserInput{
+		Username: req.GetUsername(),
+		Password: req.GetPassword(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, input.Username)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	err = utils.CheckPassword(input.Password, user.HashedPassword)
+	if err != nil {
+		server.recordAuditEvent(ctx, user.Username, audit.ActionLoginFailed, audit.UserTarget(user.Username), map[string]interface{}{
+			"reason": "wrong_password",
+		})
+		server.metrics.IncEvent(metrics.EventLoginFailed)
+		return nil, statusError(ctx, codes.Unauthenticated, err)
+	}
+
+	if user.Disabled {
+		server.recordAuditEvent(ctx, user.Username, audit.ActionLoginFailed, audit.UserTarget(user.Username), map[string]interface{}{
+			"reason": "disabled",
+		})
+		server.metrics.IncEvent(metrics.EventLoginFailed)
+		return nil, statusError(ctx, codes.PermissionDenied, errUserDisabled)
+	}
+
+	rsp, session, err := server.startSession(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	server.recordAuditEvent(ctx, user.Username, audit.ActionLogin, audit.UserTarget(user.Username), map[string]interface{}{
+		"session_id": session.ID,
+	})
+	server.metrics.IncEvent(metrics.EventLogin)
+	return rsp, nil
+}
+
+// startSession creates the tokens and the session of the user
+func (server *Server) startSession(ctx context.Context, user db.User) (*pb.AuthResponse, db.CreateSessionParams, error) {
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, db.CreateSessionParams{}, statusError(ctx, codes.Internal, err)
+	}
+
+	refreshToken, refreshTokenPayload, err := server.tokenMaker.CreateToken(user.Username, server.config.RefreshTokenDuration)
+	if err != nil {
+		return nil, db.CreateSessionParams{}, statusError(ctx, codes.Internal, err)
+	}
+
+	session := db.CreateSessionParams{
+		ID:           refreshTokenPayload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		UserAgent:    userAgent(ctx),
+		ClientIp:     clientIP(ctx),
+		IsBlocked:    false,
+		ExpiresAt:    refreshTokenPayload.ExpiredAt,
+	}
+	err = server.store.CreateSession(ctx, session)
+	if err != nil {
+		return nil, db.CreateSessionParams{}, storeError(ctx, err)
+	}
+
+	rsp := &pb.AuthResponse{
+		User:         convertUser(user),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int32(server.config.AccessTokenDuration.Seconds()),
+	}
+	return rsp, session, nil
+}
+
+type renewAccessTokenInput struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (server *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	input := renewAccessTokenInput{
+		RefreshToken: req.GetRefreshToken(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	refreshTokenPayload, err := server.tokenMaker.VerifyToken(input.RefreshToken)
+	if err != nil {
+		return nil, statusError(ctx, codes.Unauthenticated, err)
+	}
+
+	session, err := server.store.GetSession(ctx, refreshTokenPayload.ID)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	switch {
+	case session.IsBlocked:
+		err = i18n.NewError("error.session_blocked")
+	case session.Username != refreshTokenPayload.Username:
+		err = i18n.NewError("error.session_user_mismatch")
+	case session.RefreshToken != input.RefreshToken:
+		err = i18n.NewError("error.session_token_mismatch")
+	case time.Now().After(session.ExpiresAt):
+		err = i18n.NewError("error.session_expired")
+	}
+	if err != nil {
+		return nil, statusError(ctx, codes.Unauthenticated, err)
+	}
+
+	accessToken, _, err := server.tokenMaker.CreateToken(refreshTokenPayload.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, statusError(ctx, codes.Internal, err)
+	}
+
+	server.recordAuditEvent(ctx, session.Username, audit.ActionRenewSession, audit.UserTarget(session.Username), map[string]interface{}{
+		"session_id": session.ID,
+	})
+	server.metrics.IncEvent(metrics.EventTokenRenewal)
+
+	rsp := &pb.RenewAccessTokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: session.RefreshToken,
+		ExpiresIn:    int32(server.config.AccessTokenDuration.Seconds()),
+		Username:     refreshTokenPayload.Username,
+	}
+	return rsp, nil
+}
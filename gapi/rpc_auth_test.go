@@ -0,0 +1,396 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/audit"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestRegisterUserRPC(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		req           *pb.RegisterUserRequest
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.AuthResponse, err error)
+	}{
+		{
+			name: "Ok",
+			req: &pb.RegisterUserRequest{
+				Username:    user.Username,
+				Password:    password,
+				Email:       user.Email,
+				FullName:    user.FullName,
+				PhoneNumber: user.PhoneNumber,
+				Gender:      user.Gender,
+				BirthDate:   timestamppb.New(user.BirthDate),
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), eqCreateUserParams(user.Username, password)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+				require.NotEmpty(t, rsp.GetAccessToken())
+				require.NotEmpty(t, rsp.GetRefreshToken())
+			},
+		},
+		{
+			name: "DuplicateUsername",
+			req: &pb.RegisterUserRequest{
+				Username:    user.Username,
+				Password:    password,
+				Email:       user.Email,
+				FullName:    user.FullName,
+				PhoneNumber: user.PhoneNumber,
+				Gender:      user.Gender,
+				BirthDate:   timestamppb.New(user.BirthDate),
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: "23505"})
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidEmail",
+			req: &pb.RegisterUserRequest{
+				Username:    user.Username,
+				Password:    password,
+				Email:       "invalid-email",
+				FullName:    user.FullName,
+				PhoneNumber: user.PhoneNumber,
+				Gender:      user.Gender,
+				BirthDate:   timestamppb.New(user.BirthDate),
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					CreateUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolations(t, err, "email")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client := newTestClient(t, newTestServer(t, store, newTestTokenMaker(t)))
+			rsp, err := client.RegisterUser(context.Background(), tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestLoginUserRPC(t *testing.T) {
+	user, password := randomUser(t)
+
+	// the stubs run on the server goroutines, so the session is checked by the test
+	var session db.CreateSessionParams
+
+	testCases := []struct {
+		name          string
+		req           *pb.LoginUserRequest
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.AuthResponse, err error, auditor *testAuditor)
+	}{
+		{
+			name: "Ok",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(ctx context.Context, arg db.CreateSessionParams) {
+						session = arg
+					}).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error, auditor *testAuditor) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, rsp.GetUser().GetUsername())
+				require.Equal(t, int32(testConfig.AccessTokenDuration.Seconds()), rsp.GetExpiresIn())
+
+				require.Equal(t, user.Username, session.Username)
+				require.Equal(t, rsp.GetRefreshToken(), session.RefreshToken)
+				require.Contains(t, session.UserAgent, "test-agent")
+				require.NotEmpty(t, session.ClientIp)
+
+				require.Len(t, auditor.events, 1)
+				require.Equal(t, audit.ActionLogin, auditor.events[0].Action)
+			},
+		},
+		{
+			name: "WrongPassword",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: "wrong-password",
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error, auditor *testAuditor) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+
+				require.Len(t, auditor.events, 1)
+				require.Equal(t, audit.ActionLoginFailed, auditor.events[0].Action)
+			},
+		},
+		{
+			name: "DisabledUser",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				disabledUser := user
+				disabledUser.Disabled = true
+
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(disabledUser, nil)
+
+				store.EXPECT().
+					CreateSession(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error, auditor *testAuditor) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InternalError",
+			req: &pb.LoginUserRequest{
+				Username: user.Username,
+				Password: password,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error, auditor *testAuditor) {
+				require.Equal(t, codes.Internal, status.Code(err))
+				require.NotContains(t, status.Convert(err).Message(), sql.ErrConnDone.Error())
+			},
+		},
+		{
+			name: "InvalidUsername",
+			req: &pb.LoginUserRequest{
+				Username: "invld",
+				Password: password,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.AuthResponse, err error, auditor *testAuditor) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolations(t, err, "username")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store, newTestTokenMaker(t))
+			client := newTestClient(t, server, grpc.WithUserAgent("test-agent"))
+
+			rsp, err := client.LoginUser(context.Background(), tc.req)
+			tc.checkResponse(t, rsp, err, server.auditor.(*testAuditor))
+		})
+	}
+}
+
+func TestRenewAccessTokenRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	tokenMaker := newTestTokenMaker(t)
+
+	refreshToken, payload, err := tokenMaker.CreateToken(user.Username, time.Hour)
+	require.NoError(t, err)
+
+	session := db.Session{
+		ID:           payload.ID,
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error)
+	}{
+		{
+			name: "Ok",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, rsp.GetAccessToken())
+				require.Equal(t, refreshToken, rsp.GetRefreshToken())
+				require.Equal(t, user.Username, rsp.GetUsername())
+			},
+		},
+		{
+			name: "BlockedSession",
+			buildStubs: func(store *mock_db.MockStore) {
+				blockedSession := session
+				blockedSession.IsBlocked = true
+
+				store.EXPECT().
+					GetSession(gomock.Any(), gomock.Eq(payload.ID)).
+					Times(1).
+					Return(blockedSession, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.RenewAccessTokenResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+			rsp, err := client.RenewAccessToken(context.Background(), &pb.RenewAccessTokenRequest{
+				RefreshToken: refreshToken,
+			})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+type eqCreateUserParamsMatcher struct {
+	username string
+	password string
+}
+
+func (e eqCreateUserParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateUserParams)
+	if !ok {
+		return false
+	}
+	return arg.Username == e.username && utils.CheckPassword(e.password, arg.HashedPassword) == nil
+}
+
+func (e eqCreateUserParamsMatcher) String() string {
+	return fmt.Sprintf("matches username %v and password %v", e.username, e.password)
+}
+
+// eqCreateUserParams matches the params by the username and the hash of the password
+func eqCreateUserParams(username, password string) gomock.Matcher {
+	return eqCreateUserParamsMatcher{username, password}
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = utils.RandomString(6)
+	hashedPassword, err := utils.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       utils.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		ImageUrl:       utils.RandomImageUrl(),
+		Gender:         utils.RandomGender(),
+		Role:           utils.UserRole,
+		BirthDate:      utils.RandomBirthDate(),
+	}
+	return
+}
+
+// requireFieldViolations checks the fields in the bad request details of the status
+func requireFieldViolations(t *testing.T, err error, fields ...string) {
+	var violated []string
+	for _, detail := range status.Convert(err).Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		require.True(t, ok)
+		for _, violation := range badRequest.GetFieldViolations() {
+			violated = append(violated, violation.GetField())
+		}
+	}
+	require.ElementsMatch(t, fields, violated)
+}
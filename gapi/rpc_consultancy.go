@@ -0,0 +1,161 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/pb"
+	"google.golang.org/grpc/codes"
+)
+
+type createConsultancyInput struct {
+	MerchantID int64 `json:"merchant_id" binding:"required,min=1"`
+	CustomerID int64 `json:"customer_id" binding:"required,min=1"`
+	Cost       int64 `json:"cost" binding:"required,min=1"`
+}
+
+// CreateConsultancy books a consultancy of a merchant for a customer of the
+// authenticated user
+func (server *Server) CreateConsultancy(ctx context.Context, req *pb.CreateConsultancyRequest) (*pb.ConsultancyResponse, error) {
+	input := createConsultancyInput{
+		MerchantID: req.GetMerchantId(),
+		CustomerID: req.GetCustomerId(),
+		Cost:       req.GetCost(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	_, err := server.getOwnedCustomer(ctx, input.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	consultancy, err := server.store.CreateConsultancyTx(ctx, db.CreateConsultancyParams{
+		MerchantID: input.MerchantID,
+		CustomerID: input.CustomerID,
+		Cost:       input.Cost,
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	server.metrics.IncEvent(metrics.EventConsultancyCreated)
+	server.notifyMerchantOwner(ctx, consultancy)
+	return &pb.ConsultancyResponse{Consultancy: convertConsultancy(consultancy)}, nil
+}
+
+// GetConsultancy returns the consultancy if the merchant or the customer of it
+// belongs to the authenticated user
+func (server *Server) GetConsultancy(ctx context.Context, req *pb.GetConsultancyRequest) (*pb.ConsultancyResponse, error) {
+	input := idInput{ID: req.GetId()}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	consultancy, err := server.store.GetConsultancy(ctx, input.ID)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	_, merchantErr := server.getOwnedMerchant(ctx, consultancy.MerchantID)
+	if merchantErr != nil {
+		_, err = server.getOwnedCustomer(ctx, consultancy.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &pb.ConsultancyResponse{Consultancy: convertConsultancy(consultancy)}, nil
+}
+
+type listConsultanciesInput struct {
+	MerchantID int64 `json:"merchant_id" binding:"min=0"`
+	CustomerID int64 `json:"customer_id" binding:"min=0"`
+	pageInput
+}
+
+// ListConsultancies lists the consultancies of a merchant or a customer of the
+// authenticated user
+func (server *Server) ListConsultancies(ctx context.Context, req *pb.ListConsultanciesRequest) (*pb.ListConsultanciesResponse, error) {
+	input := listConsultanciesInput{
+		MerchantID: req.GetMerchantId(),
+		CustomerID: req.GetCustomerId(),
+		pageInput: pageInput{
+			PageID:   req.GetPageId(),
+			PageSize: req.GetPageSize(),
+		},
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch {
+	case input.MerchantID != 0 && input.CustomerID == 0:
+		_, err = server.getOwnedMerchant(ctx, input.MerchantID)
+	case input.CustomerID != 0 && input.MerchantID == 0:
+		_, err = server.getOwnedCustomer(ctx, input.CustomerID)
+	default:
+		err = statusError(ctx, codes.InvalidArgument, i18n.NewError("error.consultancy_party_required"))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	consultancies, err := server.store.ListConsultancies(ctx, db.ListConsultanciesParams{
+		MerchantID: input.MerchantID,
+		CustomerID: input.CustomerID,
+		Limit:      input.PageSize,
+		Offset:     input.offset(),
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	rsp := &pb.ListConsultanciesResponse{
+		Consultancies: make([]*pb.Consultancy, len(consultancies)),
+	}
+	for i, consultancy := range consultancies {
+		rsp.Consultancies[i] = convertConsultancy(consultancy)
+	}
+	return rsp, nil
+}
+
+// notifyMerchantOwner tells the owner of the merchant about the booked
+// consultancy
+func (server *Server) notifyMerchantOwner(ctx context.Context, consultancy db.Consultancy) {
+	merchant, err := server.store.GetMerchant(ctx, consultancy.MerchantID)
+	if err != nil {
+		logger.FromContext(ctx).Error("cannot notify", "type", notification.TypeConsultancyBooked, "err", err)
+		return
+	}
+
+	server.notify(ctx, notification.Event{
+		Recipient: merchant.Owner,
+		Type:      notification.TypeConsultancyBooked,
+		Actor:     getAuthPayload(ctx).Username,
+		Target:    notification.ConsultancyTarget(consultancy.ID),
+		Data: map[string]interface{}{
+			"merchant_id": consultancy.MerchantID,
+			"cost":        consultancy.Cost,
+		},
+	})
+}
+
+// getOwnedCustomer returns the customer if it belongs to the authenticated user
+func (server *Server) getOwnedCustomer(ctx context.Context, id int64) (db.Customer, error) {
+	customer, err := server.store.GetCustomer(ctx, id)
+	if err != nil {
+		return customer, storeError(ctx, err)
+	}
+
+	if customer.Owner != getAuthPayload(ctx).Username {
+		return customer, statusError(ctx, codes.PermissionDenied, errAccountNotOwned)
+	}
+	return customer, nil
+}
@@ -0,0 +1,282 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateConsultancyRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	merchantOwner, _ := randomUser(t)
+	merchant := randomMerchant(merchantOwner.Username)
+	customer := db.Customer{
+		ID:    utils.RandomInt(1, 1000),
+		Owner: user.Username,
+	}
+	consultancy := db.Consultancy{
+		ID:         utils.RandomInt(1, 1000),
+		MerchantID: merchant.ID,
+		CustomerID: customer.ID,
+		Cost:       utils.RandomMoney(),
+	}
+	req := &pb.CreateConsultancyRequest{
+		MerchantId: merchant.ID,
+		CustomerId: customer.ID,
+		Cost:       consultancy.Cost,
+	}
+
+	testCases := []struct {
+		name          string
+		authUsername  string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ConsultancyResponse, err error, notifier *testNotifier)
+	}{
+		{
+			name:         "OK",
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Eq(customer.ID)).
+					Times(1).
+					Return(customer, nil)
+				store.EXPECT().
+					CreateConsultancyTx(gomock.Any(), gomock.Eq(db.CreateConsultancyParams{
+						MerchantID: merchant.ID,
+						CustomerID: customer.ID,
+						Cost:       consultancy.Cost,
+					})).
+					Times(1).
+					Return(consultancy, nil)
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConsultancyResponse, err error, notifier *testNotifier) {
+				require.NoError(t, err)
+				require.Equal(t, consultancy.ID, rsp.GetConsultancy().GetId())
+
+				// the merchant owner is told about the booking
+				require.Len(t, notifier.events, 1)
+				event := notifier.events[0]
+				require.Equal(t, merchantOwner.Username, event.Recipient)
+				require.Equal(t, notification.TypeConsultancyBooked, event.Type)
+				require.Equal(t, user.Username, event.Actor)
+				require.Equal(t, notification.ConsultancyTarget(consultancy.ID), event.Target)
+			},
+		},
+		{
+			name:         "NotifyError",
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Eq(customer.ID)).
+					Times(1).
+					Return(customer, nil)
+				store.EXPECT().
+					CreateConsultancyTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(consultancy, nil)
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(db.Merchant{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConsultancyResponse, err error, notifier *testNotifier) {
+				// the booking is done even if nobody is told about it
+				require.NoError(t, err)
+				require.Empty(t, notifier.events)
+			},
+		},
+		{
+			name:         "CustomerNotOwned",
+			authUsername: merchantOwner.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Eq(customer.ID)).
+					Times(1).
+					Return(customer, nil)
+				store.EXPECT().
+					CreateConsultancyTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ConsultancyResponse, err error, notifier *testNotifier) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+				require.Empty(t, notifier.events)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			server := newTestServer(t, store, tokenMaker)
+			client := newTestClient(t, server)
+
+			ctx := newContextWithBearerToken(t, tokenMaker, tc.authUsername, time.Minute)
+			rsp, err := client.CreateConsultancy(ctx, req)
+			tc.checkResponse(t, rsp, err, server.notifier.(*testNotifier))
+		})
+	}
+}
+
+func TestListConsultanciesRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+	customer := db.Customer{
+		ID:    utils.RandomInt(1, 1000),
+		Owner: user.Username,
+	}
+	consultancy := db.Consultancy{
+		ID:         utils.RandomInt(1, 1000),
+		MerchantID: merchant.ID,
+		CustomerID: customer.ID,
+		Cost:       utils.RandomMoney(),
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListConsultanciesRequest
+		authUsername  string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ListConsultanciesResponse, err error)
+	}{
+		{
+			name: "OkByMerchant",
+			req: &pb.ListConsultanciesRequest{
+				MerchantId: merchant.ID,
+				PageId:     1,
+				PageSize:   5,
+			},
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				arg := db.ListConsultanciesParams{
+					MerchantID: merchant.ID,
+					Limit:      5,
+					Offset:     0,
+				}
+				store.EXPECT().
+					ListConsultancies(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Consultancy{consultancy}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListConsultanciesResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetConsultancies(), 1)
+				require.Equal(t, consultancy.Cost, rsp.GetConsultancies()[0].GetCost())
+			},
+		},
+		{
+			name: "OkByCustomer",
+			req: &pb.ListConsultanciesRequest{
+				CustomerId: customer.ID,
+				PageId:     1,
+				PageSize:   5,
+			},
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetCustomer(gomock.Any(), gomock.Eq(customer.ID)).
+					Times(1).
+					Return(customer, nil)
+
+				arg := db.ListConsultanciesParams{
+					CustomerID: customer.ID,
+					Limit:      5,
+					Offset:     0,
+				}
+				store.EXPECT().
+					ListConsultancies(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.Consultancy{consultancy}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListConsultanciesResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetConsultancies(), 1)
+			},
+		},
+		{
+			name: "BothParties",
+			req: &pb.ListConsultanciesRequest{
+				MerchantId: merchant.ID,
+				CustomerId: customer.ID,
+				PageId:     1,
+				PageSize:   5,
+			},
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListConsultancies(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListConsultanciesResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			req: &pb.ListConsultanciesRequest{
+				MerchantId: merchant.ID,
+				PageId:     1,
+				PageSize:   5,
+			},
+			authUsername: "unauthorized_user",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+
+				store.EXPECT().
+					ListConsultancies(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListConsultanciesResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+
+			ctx := newContextWithBearerToken(t, tokenMaker, tc.authUsername, time.Minute)
+			rsp, err := client.ListConsultancies(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
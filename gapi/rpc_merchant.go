@@ -0,0 +1,102 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"google.golang.org/grpc/codes"
+)
+
+type createMerchantInput struct {
+	Owner      string `json:"owner" binding:"required,alphanum,min=6"`
+	Profession string `json:"profession" binding:"required"`
+	Title      string `json:"title" binding:"required"`
+	About      string `json:"about" binding:"required"`
+}
+
+func (server *Server) CreateMerchant(ctx context.Context, req *pb.CreateMerchantRequest) (*pb.MerchantResponse, error) {
+	input := createMerchantInput{
+		Owner:      req.GetOwner(),
+		Profession: req.GetProfession(),
+		Title:      req.GetTitle(),
+		About:      req.GetAbout(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	if input.Owner != getAuthPayload(ctx).Username {
+		return nil, statusError(ctx, codes.PermissionDenied, errAccountNotOwned)
+	}
+
+	merchant, err := server.store.CreateMerchant(ctx, db.CreateMerchantParams{
+		Owner:      input.Owner,
+		Profession: input.Profession,
+		Title:      input.Title,
+		About:      input.About,
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	return &pb.MerchantResponse{Merchant: convertMerchant(merchant)}, nil
+}
+
+type idInput struct {
+	ID int64 `json:"id" binding:"required,min=1"`
+}
+
+func (server *Server) GetMerchant(ctx context.Context, req *pb.GetMerchantRequest) (*pb.MerchantResponse, error) {
+	input := idInput{ID: req.GetId()}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	merchant, err := server.getOwnedMerchant(ctx, input.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.MerchantResponse{Merchant: convertMerchant(merchant)}, nil
+}
+
+// getOwnedMerchant returns the merchant if it belongs to the authenticated user
+func (server *Server) getOwnedMerchant(ctx context.Context, id int64) (db.Merchant, error) {
+	merchant, err := server.store.GetMerchant(ctx, id)
+	if err != nil {
+		return merchant, storeError(ctx, err)
+	}
+
+	if merchant.Owner != getAuthPayload(ctx).Username {
+		return merchant, statusError(ctx, codes.PermissionDenied, errAccountNotOwned)
+	}
+	return merchant, nil
+}
+
+func (server *Server) ListMerchants(ctx context.Context, req *pb.ListMerchantsRequest) (*pb.ListMerchantsResponse, error) {
+	page := pageInput{
+		PageID:   req.GetPageId(),
+		PageSize: req.GetPageSize(),
+	}
+	if err := server.validateInput(ctx, page); err != nil {
+		return nil, err
+	}
+
+	merchants, err := server.store.ListMerchants(ctx, db.ListMerchantsParams{
+		Owner:  getAuthPayload(ctx).Username,
+		Limit:  page.PageSize,
+		Offset: page.offset(),
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	rsp := &pb.ListMerchantsResponse{
+		Merchants: make([]*pb.Merchant, len(merchants)),
+	}
+	for i, merchant := range merchants {
+		rsp.Merchants[i] = convertMerchant(merchant)
+	}
+	return rsp, nil
+}
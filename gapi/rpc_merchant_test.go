@@ -0,0 +1,195 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGetMerchantRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	merchant := randomMerchant(user.Username)
+
+	testCases := []struct {
+		name          string
+		id            int64
+		authUsername  string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.MerchantResponse, err error)
+	}{
+		{
+			name:         "Ok",
+			id:           merchant.ID,
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.MerchantResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, merchant.ID, rsp.GetMerchant().GetId())
+				require.Equal(t, merchant.Title, rsp.GetMerchant().GetTitle())
+			},
+		},
+		{
+			name:         "UnauthorizedUser",
+			id:           merchant.ID,
+			authUsername: "unauthorized_user",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(merchant, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.MerchantResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:         "NotFound",
+			id:           merchant.ID,
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Eq(merchant.ID)).
+					Times(1).
+					Return(db.Merchant{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.MerchantResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name:         "InvalidID",
+			id:           0,
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetMerchant(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.MerchantResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolations(t, err, "id")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+
+			ctx := newContextWithBearerToken(t, tokenMaker, tc.authUsername, time.Minute)
+			rsp, err := client.GetMerchant(ctx, &pb.GetMerchantRequest{Id: tc.id})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestListMerchantsRPC(t *testing.T) {
+	user, _ := randomUser(t)
+
+	n := 5
+	merchants := make([]db.Merchant, n)
+	for i := range merchants {
+		merchants[i] = randomMerchant(user.Username)
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.ListMerchantsRequest
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.ListMerchantsResponse, err error)
+	}{
+		{
+			name: "Ok",
+			req: &pb.ListMerchantsRequest{
+				PageId:   2,
+				PageSize: int32(n),
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				arg := db.ListMerchantsParams{
+					Owner:  user.Username,
+					Limit:  int32(n),
+					Offset: int32(n),
+				}
+
+				store.EXPECT().
+					ListMerchants(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(merchants, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListMerchantsResponse, err error) {
+				require.NoError(t, err)
+				require.Len(t, rsp.GetMerchants(), n)
+			},
+		},
+		{
+			name: "InvalidPageSize",
+			req: &pb.ListMerchantsRequest{
+				PageId:   1,
+				PageSize: 100,
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListMerchants(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.ListMerchantsResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolations(t, err, "page_size")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+
+			ctx := newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			rsp, err := client.ListMerchants(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func randomMerchant(owner string) db.Merchant {
+	return db.Merchant{
+		ID:         utils.RandomInt(1, 1000),
+		Owner:      owner,
+		Profession: utils.RandomString(8),
+		Title:      utils.RandomString(8),
+		About:      utils.RandomString(20),
+		Rating:     4.5,
+		CreatedAt:  time.Now().UTC(),
+	}
+}
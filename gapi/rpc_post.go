@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/pb"
+)
+
+// emptyImageVariants is stored for the posts created without an image
+var emptyImageVariants = json.RawMessage("{}")
+
+type createPostInput struct {
+	MerchantID int64  `json:"merchant_id" binding:"required,min=1"`
+	Title      string `json:"title" binding:"required"`
+}
+
+// CreatePost creates a post with a title, the images are only uploaded
+// through the HTTP API
+func (server *Server) CreatePost(ctx context.Context, req *pb.CreatePostRequest) (*pb.PostResponse, error) {
+	input := createPostInput{
+		MerchantID: req.GetMerchantId(),
+		Title:      req.GetTitle(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	_, err := server.getOwnedMerchant(ctx, input.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := server.store.CreatePostTx(ctx, db.CreatePostParams{
+		MerchantID: input.MerchantID,
+		Title: sql.NullString{
+			String: input.Title,
+			Valid:  true,
+		},
+		ImageVariants: emptyImageVariants,
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	server.metrics.IncEvent(metrics.EventPostCreated)
+	return &pb.PostResponse{Post: convertPost(post)}, nil
+}
+
+// GetPost returns the post unless it is deleted, the posts are visible to
+// every authenticated user
+func (server *Server) GetPost(ctx context.Context, req *pb.GetPostRequest) (*pb.PostResponse, error) {
+	input := idInput{ID: req.GetId()}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	post, err := server.store.GetPost(ctx, input.ID)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	return &pb.PostResponse{Post: convertPost(post)}, nil
+}
+
+// ListPosts lists a page of the posts of all the merchants, the newest ones
+// first
+func (server *Server) ListPosts(ctx context.Context, req *pb.ListPostsRequest) (*pb.ListPostsResponse, error) {
+	page := pageInput{
+		PageID:   req.GetPageId(),
+		PageSize: req.GetPageSize(),
+	}
+	if err := server.validateInput(ctx, page); err != nil {
+		return nil, err
+	}
+
+	posts, err := server.store.ListPosts(ctx, db.ListPostsParams{
+		Limit:  page.PageSize,
+		Offset: page.offset(),
+	})
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	rsp := &pb.ListPostsResponse{
+		Posts: make([]*pb.Post, len(posts)),
+	}
+	for i, post := range posts {
+		rsp.Posts[i] = convertPost(post)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"google.golang.org/grpc/codes"
+)
+
+type getUserInput struct {
+	Username string `json:"username" binding:"required,min=6"`
+}
+
+func (server *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.UserResponse, error) {
+	input := getUserInput{
+		Username: req.GetUsername(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUser(ctx, input.Username)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	if user.Username != getAuthPayload(ctx).Username {
+		return nil, statusError(ctx, codes.PermissionDenied, errAccountNotOwned)
+	}
+
+	return &pb.UserResponse{User: convertUser(user)}, nil
+}
+
+type updateUserInput struct {
+	Username    string `json:"username" binding:"required,min=6"`
+	FullName    string `json:"full_name"`
+	PhoneNumber string `json:"phone_number" binding:"omitempty,min=11"`
+	Gender      string `json:"gender" binding:"omitempty,gender"`
+}
+
+func (server *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UserResponse, error) {
+	input := updateUserInput{
+		Username:    req.GetUsername(),
+		FullName:    req.GetFullName(),
+		PhoneNumber: req.GetPhoneNumber(),
+		Gender:      req.GetGender(),
+	}
+	if err := server.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	if input.Username != getAuthPayload(ctx).Username {
+		return nil, statusError(ctx, codes.PermissionDenied, errUsernameNotOwned)
+	}
+
+	arg := db.UpdateUserParams{
+		Username: input.Username,
+		FullName: sql.NullString{
+			String: input.FullName,
+			Valid:  req.FullName != nil,
+		},
+		PhoneNumber: sql.NullString{
+			String: input.PhoneNumber,
+			Valid:  req.PhoneNumber != nil,
+		},
+		Gender: sql.NullString{
+			String: input.Gender,
+			Valid:  req.Gender != nil,
+		},
+	}
+	if req.GetBirthDate() != nil {
+		arg.BirthDate = sql.NullTime{
+			Time:  req.GetBirthDate().AsTime(),
+			Valid: true,
+		}
+	}
+
+	user, err := server.store.UpdateUser(ctx, arg)
+	if err != nil {
+		return nil, storeError(ctx, err)
+	}
+
+	return &pb.UserResponse{User: convertUser(user)}, nil
+}
@@ -0,0 +1,187 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetUserRPC(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		username      string
+		authUsername  string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.UserResponse, err error)
+	}{
+		{
+			name:         "Ok",
+			username:     user.Username,
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Email, rsp.GetUser().GetEmail())
+				require.True(t, user.BirthDate.Equal(rsp.GetUser().GetBirthDate().AsTime()))
+			},
+		},
+		{
+			name:         "UnauthorizedUser",
+			username:     user.Username,
+			authUsername: "unauthorized_user",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:         "NotFound",
+			username:     user.Username,
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					GetUser(gomock.Any(), gomock.Eq(user.Username)).
+					Times(1).
+					Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+
+			ctx := newContextWithBearerToken(t, tokenMaker, tc.authUsername, time.Minute)
+			rsp, err := client.GetUser(ctx, &pb.GetUserRequest{Username: tc.username})
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestUpdateUserRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	newFullName := utils.RandomOwner()
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateUserRequest
+		authUsername  string
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.UserResponse, err error)
+	}{
+		{
+			name: "Ok",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: proto.String(newFullName),
+			},
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				arg := db.UpdateUserParams{
+					Username: user.Username,
+					FullName: sql.NullString{
+						String: newFullName,
+						Valid:  true,
+					},
+				}
+				updated := user
+				updated.FullName = newFullName
+
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newFullName, rsp.GetUser().GetFullName())
+			},
+		},
+		{
+			name: "UnauthorizedUser",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: proto.String(newFullName),
+			},
+			authUsername: "unauthorized_user",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidPhoneNumber",
+			req: &pb.UpdateUserRequest{
+				Username:    user.Username,
+				PhoneNumber: proto.String("123"),
+			},
+			authUsername: user.Username,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					UpdateUser(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.UserResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+				requireFieldViolations(t, err, "phone_number")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker := newTestTokenMaker(t)
+			client := newTestClient(t, newTestServer(t, store, tokenMaker))
+
+			ctx := newContextWithBearerToken(t, tokenMaker, tc.authUsername, time.Minute)
+			rsp, err := client.UpdateUser(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package gapi serves the gRPC API of the internal services
+package gapi
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+
+	"github.com/asdsec/thenut/audit"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/pb"
+	"github.com/asdsec/thenut/token"
+	"github.com/asdsec/thenut/utils"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves the gRPC requests. It shares the store and the token maker
+// with the HTTP server.
+type Server struct {
+	pb.UnimplementedTheNutServer
+	config     utils.Config
+	store      db.Store
+	tokenMaker token.TokenMaker
+	auditor    audit.Auditor
+	notifier   notification.Notifier
+	metrics    *metrics.Metrics
+	validate   *validator.Validate
+	grpcServer *grpc.Server
+}
+
+// NewServer creates a new gRPC server
+func NewServer(config utils.Config, store db.Store, tokenMaker token.TokenMaker, metrics *metrics.Metrics) (*Server, error) {
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		auditor:    audit.NewStoreAuditor(store),
+		notifier:   notification.NewDispatcher(store),
+		metrics:    metrics,
+	}
+
+	// the server has its own validator, the one of gin reports the field names
+	// of the HTTP requests. The rules are read from the binding tags like gin does.
+	server.validate = validator.New()
+	server.validate.SetTagName("binding")
+	server.validate.RegisterTagNameFunc(fieldName)
+	server.validate.RegisterValidation("gender", validGender)
+	err := i18n.RegisterValidatorTranslations(server.validate)
+	if err != nil {
+		return nil, err
+	}
+
+	server.grpcServer = grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			server.loggerInterceptor,
+			server.authInterceptor,
+		),
+	)
+	pb.RegisterTheNutServer(server.grpcServer, server)
+	reflection.Register(server.grpcServer)
+	return server, nil
+}
+
+// Start runs the gRPC server on a specific address. It blocks until the server
+// fails or is shut down, and returns nil after a shutdown.
+func (server *Server) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return server.Serve(listener)
+}
+
+// Serve runs the gRPC server on the listener
+func (server *Server) Serve(listener net.Listener) error {
+	return server.grpcServer.Serve(listener)
+}
+
+// Shutdown stops accepting new connections and waits for the in-flight
+// requests to finish. The remaining requests are canceled when the context is done.
+func (server *Server) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
+
+// fieldName reports the protobuf name of the input fields in validation errors
+func fieldName(field reflect.StructField) string {
+	name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+var validGender validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if gender, ok := fieldLevel.Field().Interface().(string); ok {
+		return utils.IsSupportedGender(gender)
+	}
+	return false
+}
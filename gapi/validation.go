@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+)
+
+// pageInput is the offset pagination of the list methods
+type pageInput struct {
+	PageID   int32 `json:"page_id" binding:"required,min=1"`
+	PageSize int32 `json:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (page pageInput) offset() int32 {
+	return (page.PageID - 1) * page.PageSize
+}
+
+// validateInput validates the input built from a request. The rules are in
+// the binding tags, like the ones of the HTTP requests.
+func (server *Server) validateInput(ctx context.Context, input interface{}) error {
+	if err := server.validate.Struct(input); err != nil {
+		return statusError(ctx, codes.InvalidArgument, err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Package i18n resolves the texts shown to the users from the message catalogs
+// of the supported locales.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/tr"
+	ut "github.com/go-playground/universal-translator"
+	"golang.org/x/text/language"
+)
+
+// DefaultLocale is used when none of the requested locales is supported
+const DefaultLocale = "en"
+
+// validationPrefix marks the catalog messages of the custom validation tags
+const validationPrefix = "validation."
+
+//go:embed locales/*.json
+var catalogs embed.FS
+
+var (
+	// supported is the list of locales having a catalog, the first one is the default
+	supported = []language.Tag{language.English, language.Turkish}
+	matcher   = language.NewMatcher(supported)
+
+	universal = ut.New(en.New(), en.New(), tr.New())
+	// translators holds the translator of every supported locale by name
+	translators    = make(map[string]ut.Translator)
+	validationTags []string
+)
+
+func init() {
+	err := loadCatalogs()
+	if err != nil {
+		panic(err)
+	}
+}
+
+func loadCatalogs() error {
+	for _, tag := range supported {
+		name := tag.String()
+		trans, ok := universal.GetTranslator(name)
+		if !ok {
+			return fmt.Errorf("i18n: no translator for locale %s", name)
+		}
+		translators[name] = sharedTranslator{trans}
+
+		messages, err := readCatalog(name)
+		if err != nil {
+			return err
+		}
+
+		for key, text := range messages {
+			err = trans.Add(key, text, false)
+			if err != nil {
+				return fmt.Errorf("i18n: cannot add message %s of locale %s: %w", key, name, err)
+			}
+			if name == DefaultLocale && strings.HasPrefix(key, validationPrefix) {
+				validationTags = append(validationTags, strings.TrimPrefix(key, validationPrefix))
+			}
+		}
+	}
+	return nil
+}
+
+func readCatalog(name string) (map[string]string, error) {
+	data, err := catalogs.ReadFile(path.Join("locales", name+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("i18n: cannot read catalog of locale %s: %w", name, err)
+	}
+
+	var messages map[string]string
+	err = json.Unmarshal(data, &messages)
+	if err != nil {
+		return nil, fmt.Errorf("i18n: cannot parse catalog of locale %s: %w", name, err)
+	}
+	return messages, nil
+}
+
+// Locale resolves the messages in a single language
+type Locale struct {
+	trans ut.Translator
+}
+
+// Default returns the locale of DefaultLocale
+func Default() Locale {
+	return Locale{trans: translators[DefaultLocale]}
+}
+
+// Match returns the supported locale that fits the Accept-Language header best.
+// The default locale is returned when the header is empty or malformed.
+func Match(acceptLanguage string) Locale {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default()
+	}
+
+	// the index of the default locale is returned when nothing matches
+	_, index, _ := matcher.Match(tags...)
+	return Locale{trans: translators[supported[index].String()]}
+}
+
+// Name returns the name of the locale, like "en"
+func (l Locale) Name() string {
+	return l.trans.Locale()
+}
+
+// T returns the message of the key filled with the params. The message of the
+// default locale is used when the catalog of the locale is missing the key.
+func (l Locale) T(key string, params ...string) string {
+	text, err := l.trans.T(key, params...)
+	if err == nil {
+		return text
+	}
+
+	text, err = Default().trans.T(key, params...)
+	if err == nil {
+		return text
+	}
+	return key
+}
+
+// Error is an error whose message is resolved from the catalogs, so that it
+// can be shown to the users in their own language
+type Error struct {
+	Key    string
+	Params []string
+}
+
+// NewError creates a new Error with the catalog key of its message
+func NewError(key string, params ...string) *Error {
+	return &Error{
+		Key:    key,
+		Params: params,
+	}
+}
+
+// Error returns the message in the default locale
+func (e *Error) Error() string {
+	return Default().T(e.Key, e.Params...)
+}
+
+// Message returns the message in the given locale
+func (e *Error) Message(locale Locale) string {
+	return locale.T(e.Key, e.Params...)
+}
@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalogsComplete(t *testing.T) {
+	defaultMessages, err := readCatalog(DefaultLocale)
+	require.NoError(t, err)
+	require.NotEmpty(t, defaultMessages)
+
+	for _, tag := range supported {
+		messages, err := readCatalog(tag.String())
+		require.NoError(t, err)
+		require.Len(t, messages, len(defaultMessages), tag.String())
+
+		for key, text := range defaultMessages {
+			translated, ok := messages[key]
+			require.True(t, ok, "%s is missing %s", tag, key)
+			require.Equal(t, strings.Count(text, "{"), strings.Count(translated, "{"), "%s has other params for %s", tag, key)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	testCases := []struct {
+		acceptLanguage string
+		locale         string
+	}{
+		{acceptLanguage: "", locale: "en"},
+		{acceptLanguage: "tr", locale: "tr"},
+		{acceptLanguage: "tr-TR,tr;q=0.9,en-US;q=0.8", locale: "tr"},
+		{acceptLanguage: "en-US,tr;q=0.5", locale: "en"},
+		{acceptLanguage: "de-DE,tr;q=0.7", locale: "tr"},
+		{acceptLanguage: "de-DE", locale: "en"},
+		{acceptLanguage: "not a ;; language", locale: "en"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.locale, Match(tc.acceptLanguage).Name(), tc.acceptLanguage)
+	}
+}
+
+func TestError(t *testing.T) {
+	err := NewError("error.role_not_allowed", "user")
+	require.EqualError(t, err, "role user is not allowed to access this resource")
+	require.Equal(t, "user rolünün bu kaynağa erişim izni yok", err.Message(Match("tr")))
+
+	err = NewError("error.missing_key")
+	require.EqualError(t, err, "error.missing_key")
+}
+
+func TestTranslateField(t *testing.T) {
+	v := validator.New()
+	v.RegisterValidation("gender", func(fl validator.FieldLevel) bool {
+		return fl.Field().String() == "female"
+	})
+	require.NoError(t, RegisterValidatorTranslations(v))
+	require.NoError(t, RegisterValidatorTranslations(v))
+
+	type request struct {
+		Email  string `validate:"required"`
+		Gender string `validate:"gender"`
+	}
+
+	err := v.Struct(request{Gender: "other"})
+	require.Error(t, err)
+
+	fieldErrs := err.(validator.ValidationErrors)
+	require.Len(t, fieldErrs, 2)
+
+	require.Equal(t, "Email is a required field", Default().TranslateField(fieldErrs[0]))
+	require.Equal(t, "Email zorunlu bir alandır", Match("tr").TranslateField(fieldErrs[0]))
+	require.Equal(t, "Gender must be a supported gender", Default().TranslateField(fieldErrs[1]))
+	require.Equal(t, "Gender desteklenen bir cinsiyet olmalıdır", Match("tr").TranslateField(fieldErrs[1]))
+}
+
+func TestTranslateFieldOfSeveralValidators(t *testing.T) {
+	type request struct {
+		Email string `validate:"required"`
+	}
+
+	// each server of the process has its own validator
+	for i := 0; i < 2; i++ {
+		v := validator.New()
+		require.NoError(t, RegisterValidatorTranslations(v))
+
+		err := v.Struct(request{})
+		require.Error(t, err)
+
+		fieldErrs := err.(validator.ValidationErrors)
+		require.Equal(t, "Email is a required field", Default().TranslateField(fieldErrs[0]))
+		require.Equal(t, "Email zorunlu bir alandır", Match("tr").TranslateField(fieldErrs[0]))
+	}
+}
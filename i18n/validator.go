@@ -0,0 +1,107 @@
+package i18n
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-playground/locales"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	tr_translations "github.com/go-playground/validator/v10/translations/tr"
+)
+
+type registerFunc func(v *validator.Validate, trans ut.Translator) error
+
+// validatorTranslations has the built-in validation messages of the supported locales
+var validatorTranslations = map[string]registerFunc{
+	"en": en_translations.RegisterDefaultTranslations,
+	"tr": tr_translations.RegisterDefaultTranslations,
+}
+
+var (
+	registeredMu sync.Mutex
+	registered   = make(map[*validator.Validate]bool)
+)
+
+// sharedTranslator lets several validators register the built-in validation
+// messages of a locale, the messages added by the first one are kept. The
+// validators look up their translations by the translator, so the locales use
+// the same sharedTranslator.
+type sharedTranslator struct {
+	ut.Translator
+}
+
+func (t sharedTranslator) Add(key interface{}, text string, override bool) error {
+	return ignoreConflict(t.Translator.Add(key, text, override))
+}
+
+func (t sharedTranslator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddCardinal(key, text, rule, override))
+}
+
+func (t sharedTranslator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddOrdinal(key, text, rule, override))
+}
+
+func (t sharedTranslator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddRange(key, text, rule, override))
+}
+
+func ignoreConflict(err error) error {
+	var conflict *ut.ErrConflictingTranslation
+	if errors.As(err, &conflict) {
+		return nil
+	}
+	return err
+}
+
+// RegisterValidatorTranslations adds the validation messages of every locale to
+// the validator. Custom tags are translated by the "validation.<tag>" messages
+// of the catalogs. Registering the same validator again is a no-op, and every
+// validator of the process can be registered.
+func RegisterValidatorTranslations(v *validator.Validate) error {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	if registered[v] {
+		return nil
+	}
+
+	for _, tag := range supported {
+		name := tag.String()
+		trans := translators[name]
+
+		register, ok := validatorTranslations[name]
+		if ok {
+			err := register(v, trans)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, validationTag := range validationTags {
+			err := v.RegisterTranslation(validationTag, trans, registerCatalogMessage, translateCatalogMessage)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	registered[v] = true
+	return nil
+}
+
+// registerCatalogMessage does nothing since the catalog messages are loaded already
+func registerCatalogMessage(ut.Translator) error {
+	return nil
+}
+
+func translateCatalogMessage(trans ut.Translator, fe validator.FieldError) string {
+	return Locale{trans: trans}.T(validationPrefix+fe.Tag(), fe.Field())
+}
+
+// TranslateField returns the validation message of the field error in the locale
+func (l Locale) TranslateField(fe validator.FieldError) string {
+	return fe.Translate(l.trans)
+}
@@ -0,0 +1,67 @@
+// Package logger creates the structured loggers of the service and passes
+// them through the contexts
+package logger
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Redacted replaces the values of the sensitive attributes
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are the attribute keys whose values are never logged
+var sensitiveKeys = map[string]bool{
+	"password":        true,
+	"old_password":    true,
+	"new_password":    true,
+	"hashed_password": true,
+	"token":           true,
+	"access_token":    true,
+	"refresh_token":   true,
+	"authorization":   true,
+	"secret":          true,
+}
+
+type contextKey struct{}
+
+// New creates a JSON logger writing to w. The level is one of debug, info,
+// warn or error and defaults to info.
+func New(w io.Writer, level string) *slog.Logger {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		l = slog.LevelInfo
+	}
+
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{
+		Level:       l,
+		ReplaceAttr: redact,
+	}))
+}
+
+// IsSensitive reports whether the values of the key must be redacted
+func IsSensitive(key string) bool {
+	return sensitiveKeys[strings.ToLower(key)]
+}
+
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+	return attr
+}
+
+// WithContext returns a copy of the context carrying the logger
+func WithContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger of the context, or the default logger if it has none
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
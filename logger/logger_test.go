@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "info")
+
+	logger.Info("login",
+		"username", "alice",
+		"password", "secret123",
+		slog.Group("request", "Refresh_Token", "v2.local.token"),
+	)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "login", entry["msg"])
+	require.Equal(t, "alice", entry["username"])
+	require.Equal(t, Redacted, entry["password"])
+	require.Equal(t, map[string]interface{}{"Refresh_Token": Redacted}, entry["request"])
+	require.NotContains(t, buf.String(), "secret123")
+}
+
+func TestLevel(t *testing.T) {
+	var buf bytes.Buffer
+
+	New(&buf, "warn").Info("hidden")
+	require.Empty(t, buf.String())
+
+	New(&buf, "invalid").Info("shown")
+	require.Contains(t, buf.String(), "shown")
+}
+
+func TestContext(t *testing.T) {
+	require.Equal(t, slog.Default(), FromContext(context.Background()))
+
+	logger := New(&bytes.Buffer{}, "debug")
+	ctx := WithContext(context.Background(), logger)
+	require.Same(t, logger, FromContext(ctx))
+}
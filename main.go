@@ -1,39 +1,291 @@
 package main
 
 import (
+	"context"
 	"database/sql"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/asdsec/thenut/api"
+	"github.com/asdsec/thenut/db/migration"
 	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/gapi"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/media"
+	"github.com/asdsec/thenut/metrics"
+	"github.com/asdsec/thenut/notification"
+	"github.com/asdsec/thenut/outbox"
+	"github.com/asdsec/thenut/storage"
 	"github.com/asdsec/thenut/token"
+	"github.com/asdsec/thenut/tracing"
 	"github.com/asdsec/thenut/utils"
+	"github.com/asdsec/thenut/worker"
 )
 
 func main() {
+	demo := flag.Bool("demo", false, "run the server on an in-memory store with demo data instead of the database")
+	flag.Parse()
+
 	config, err := utils.LoadConfig(".")
 	if err != nil {
-		log.Fatal("cannot load config:", err)
+		fatal("cannot load config", err)
 	}
 
-	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	slog.SetDefault(logger.New(os.Stdout, config.LogLevel))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:     config.TraceExporter,
+		OTLPEndpoint: config.OTLPEndpoint,
+		OTLPInsecure: config.OTLPInsecure,
+	})
 	if err != nil {
-		log.Fatal("cannot connect to db:", err)
+		fatal("cannot set up tracing", err)
+	}
+
+	var conn *sql.DB
+	if *demo {
+		if flag.NArg() > 0 {
+			fatal("cannot run command", errors.New("commands cannot run in demo mode"))
+		}
+	} else {
+		conn, err = sql.Open(config.DBDriver, config.DBSource)
+		if err != nil {
+			fatal("cannot connect to db", err)
+		}
+
+		if flag.NArg() > 0 {
+			err = runCommand(conn, flag.Args())
+			conn.Close()
+			if err != nil {
+				fatal("cannot run command", err)
+			}
+			return
+		}
 	}
 
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
-		log.Fatal("cannot create token maker:", err)
+		fatal("cannot create token maker", err)
+	}
+
+	blobStorage, err := newBlobStorage(config)
+	if err != nil {
+		fatal("cannot create blob storage", err)
+	}
+
+	pushSender, err := newPushSender(config)
+	if err != nil {
+		fatal("cannot create push sender", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverMetrics := metrics.New()
+
+	var store db.Store
+	if *demo {
+		store, err = newDemoStore(ctx)
+		if err != nil {
+			fatal("cannot create demo store", err)
+		}
+	} else {
+		if config.AutoMigrate {
+			err = autoMigrate(ctx, conn)
+			if err != nil {
+				fatal("cannot migrate db", err)
+			}
+		}
+
+		err = serverMetrics.RegisterDBStats(conn)
+		if err != nil {
+			fatal("cannot register db metrics", err)
+		}
+		store = db.NewStore(conn)
+	}
+	store = tracing.NewTracedStore(metrics.NewInstrumentedStore(store, serverMetrics))
+
+	server, err := api.NewServer(config, store, tokenMaker, blobStorage, serverMetrics)
+	if err != nil {
+		fatal("cannot create server", err)
+	}
+
+	grpcServer, err := gapi.NewServer(config, store, tokenMaker, serverMetrics)
+	if err != nil {
+		fatal("cannot create grpc server", err)
+	}
+
+	var workers sync.WaitGroup
+	purger := worker.NewAccountPurger(store, blobStorage, config.AccountPurgeInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		purger.Run(ctx)
+	}()
+
+	keyPurger := worker.NewIdempotencyKeyPurger(store, config.IdempotencyKeyPurgeInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		keyPurger.Run(ctx)
+	}()
+
+	// the running jobs are finished before the process exits
+	jobPool := worker.NewPool(store, config.JobWorkers, config.JobPollInterval, config.JobLease)
+	worker.Handle(jobPool, notification.PushJob, notification.NewPusher(store, pushSender).Push)
+	worker.Handle(jobPool, media.ThumbnailJob, media.NewThumbnailer(store, blobStorage).Generate)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		jobPool.Run(ctx)
+	}()
+
+	// the events are only logged until there is a sink reacting to them
+	relay := outbox.NewRelay(store, config.OutboxBatchSize, config.OutboxPollInterval, outbox.NewLogSink())
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		relay.Run(ctx)
+	}()
+
+	// both servers report to the same channel, so that either of them failing
+	// shuts down the other one
+	serverErr := make(chan error, 2)
+	go func() {
+		err := server.Start(config.ServerAddress)
+		if err != nil {
+			err = fmt.Errorf("http server: %w", err)
+		}
+		serverErr <- err
+	}()
+	go func() {
+		err := grpcServer.Start(config.GRPCServerAddress)
+		if err != nil {
+			err = fmt.Errorf("grpc server: %w", err)
+		}
+		serverErr <- err
+	}()
+
+	// a server that fails to start still lets the others shut down in order,
+	// the process exits with an error afterwards
+	var startErr error
+	select {
+	case startErr = <-serverErr:
+		if startErr != nil {
+			slog.Error("cannot start server", "err", startErr)
+		}
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	}
+
+	// stop listening for the signals, so a second one kills the process
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error("cannot shut down server", "err", err)
+	}
+
+	err = grpcServer.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error("cannot shut down grpc server", "err", err)
 	}
 
-	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store, tokenMaker)
+	workers.Wait()
+
+	err = shutdownTracing(shutdownCtx)
 	if err != nil {
-		log.Fatal("cannot create server:", err)
+		slog.Error("cannot flush traces", "err", err)
+	}
+
+	if conn != nil {
+		err = conn.Close()
+		if err != nil {
+			slog.Error("cannot close db", "err", err)
+		}
+	}
+
+	if startErr != nil {
+		os.Exit(1)
 	}
+}
+
+// fatal logs the error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
 
-	err = server.Start(config.ServerAddress)
+func autoMigrate(ctx context.Context, conn *sql.DB) error {
+	migrator, err := migration.NewMigrator(ctx, conn)
 	if err != nil {
-		log.Fatal("cannot start server:", err)
+		return err
+	}
+	defer migrator.Close()
+
+	return migrator.UpLocked(ctx)
+}
+
+func newBlobStorage(config utils.Config) (storage.BlobStorage, error) {
+	switch config.StorageBackend {
+	case "local":
+		return storage.NewLocalStorage(config.StorageLocalDir, config.StoragePublicURL)
+	case "s3":
+		return storage.NewS3Storage(
+			config.S3Endpoint,
+			config.S3AccessKeyID,
+			config.S3SecretAccessKey,
+			config.S3Bucket,
+			config.S3UseSSL,
+			config.StoragePublicURL,
+		)
+	}
+	return nil, fmt.Errorf("unsupported storage backend %s", config.StorageBackend)
+}
+
+// newPushSender returns the sender of the pushes to the devices of every
+// platform. The platforms without credentials only log their pushes.
+func newPushSender(config utils.Config) (notification.PushSender, error) {
+	router := notification.Router{
+		notification.PlatformFCM:  notification.NewLogSender(),
+		notification.PlatformAPNs: notification.NewLogSender(),
+	}
+
+	if config.FCMCredentialsFile != "" {
+		credentials, err := os.ReadFile(config.FCMCredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		router[notification.PlatformFCM], err = notification.NewFCMSender(config.FCMProjectID, credentials)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.APNsKeyFile != "" {
+		key, err := os.ReadFile(config.APNsKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		router[notification.PlatformAPNs], err = notification.NewAPNsSender(
+			key,
+			config.APNsKeyID,
+			config.APNsTeamID,
+			config.APNsTopic,
+			config.APNsProduction,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
+	return router, nil
 }
@@ -0,0 +1,206 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const jpegQuality = 85
+
+// ErrUnsupportedFormat is returned when the content is not a supported image
+var ErrUnsupportedFormat = errors.New("media: unsupported image format")
+
+// Variant describes a fixed-size thumbnail generated for every upload
+type Variant struct {
+	Name   string
+	Width  int
+	Height int
+	// Crop fills the whole box by cropping the center of the image,
+	// otherwise the image is scaled down to fit inside the box.
+	Crop bool
+}
+
+// Thumbnail sets generated for the different kinds of uploads
+var (
+	AvatarVariants = []Variant{
+		{Name: "small", Width: 64, Height: 64, Crop: true},
+		{Name: "medium", Width: 256, Height: 256, Crop: true},
+	}
+	PostVariants = []Variant{
+		{Name: "small", Width: 320, Height: 320},
+		{Name: "medium", Width: 720, Height: 720},
+	}
+)
+
+// Image is an encoded image ready to be stored
+type Image struct {
+	Content     []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// ProcessedImage contains the normalized upload along with its thumbnails
+type ProcessedImage struct {
+	Original Image
+	Variants map[string]Image
+}
+
+// Process decodes a JPEG, PNG or WebP image and re-encodes it, together with
+// the requested thumbnails, as JPEG, or as PNG when it has transparency.
+// Re-encoding from pixels drops every metadata block of the upload, EXIF and
+// GPS included, so the EXIF orientation is applied to the pixels beforehand.
+func Process(content []byte, variants []Variant) (*ProcessedImage, error) {
+	img, format, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, fmt.Errorf("media: cannot decode image: %w", err)
+	}
+
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(content))
+	}
+
+	original, err := encode(img)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbnails, err := generate(img, variants)
+	if err != nil {
+		return nil, err
+	}
+
+	processed := &ProcessedImage{
+		Original: original,
+		Variants: thumbnails,
+	}
+	return processed, nil
+}
+
+// Thumbnails decodes an image normalized by Process and generates the
+// requested thumbnails of it.
+func Thumbnails(content []byte, variants []Variant) (map[string]Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, fmt.Errorf("media: cannot decode image: %w", err)
+	}
+
+	return generate(img, variants)
+}
+
+func generate(img image.Image, variants []Variant) (map[string]Image, error) {
+	thumbnails := make(map[string]Image, len(variants))
+	for _, variant := range variants {
+		thumbnail, err := encode(resize(img, variant))
+		if err != nil {
+			return nil, err
+		}
+		thumbnails[variant.Name] = thumbnail
+	}
+	return thumbnails, nil
+}
+
+func encode(img image.Image) (Image, error) {
+	var buf bytes.Buffer
+	encoded := Image{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	if isOpaque(img) {
+		err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+		if err != nil {
+			return encoded, fmt.Errorf("media: cannot encode jpeg: %w", err)
+		}
+		encoded.ContentType = "image/jpeg"
+		encoded.Extension = ".jpg"
+	} else {
+		err := png.Encode(&buf, img)
+		if err != nil {
+			return encoded, fmt.Errorf("media: cannot encode png: %w", err)
+		}
+		encoded.ContentType = "image/png"
+		encoded.Extension = ".png"
+	}
+
+	encoded.Content = buf.Bytes()
+	return encoded, nil
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// resize scales the image into the variant box without ever upscaling it
+func resize(img image.Image, variant Variant) image.Image {
+	src := img.Bounds()
+	srcWidth, srcHeight := src.Dx(), src.Dy()
+
+	var width, height int
+	if variant.Crop {
+		// crop the largest centered region with the aspect ratio of the box
+		if srcWidth*variant.Height > srcHeight*variant.Width {
+			cropWidth := srcHeight * variant.Width / variant.Height
+			src.Min.X += (srcWidth - cropWidth) / 2
+			src.Max.X = src.Min.X + cropWidth
+		} else {
+			cropHeight := srcWidth * variant.Height / variant.Width
+			src.Min.Y += (srcHeight - cropHeight) / 2
+			src.Max.Y = src.Min.Y + cropHeight
+		}
+		width, height = variant.Width, variant.Height
+		if src.Dx() < width {
+			width, height = src.Dx(), src.Dy()
+		}
+	} else {
+		width, height = srcWidth, srcHeight
+		if width > variant.Width {
+			width, height = variant.Width, height*variant.Width/width
+		}
+		if height > variant.Height {
+			width, height = width*variant.Height/height, variant.Height
+		}
+	}
+
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, src, draw.Src, nil)
+
+	if isOpaque(img) {
+		return opaqueRGBA{dst}
+	}
+	return dst
+}
+
+// opaqueRGBA marks a resized image of an opaque source as opaque, since
+// resampling can leave alpha values just below fully opaque at the edges.
+type opaqueRGBA struct {
+	*image.RGBA
+}
+
+func (opaqueRGBA) Opaque() bool {
+	return true
+}
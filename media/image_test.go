@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomImage(width, height int, alpha uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 0x80, A: alpha})
+		}
+	}
+	return img
+}
+
+// exifSegment builds an APP1 segment holding an orientation and a GPS IFD pointer
+func exifSegment(orientation uint16) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("II")
+	binary.Write(&tiff, binary.LittleEndian, uint16(42))
+	binary.Write(&tiff, binary.LittleEndian, uint32(8))
+	binary.Write(&tiff, binary.LittleEndian, uint16(2))
+	// orientation entry
+	binary.Write(&tiff, binary.LittleEndian, uint16(exifOrientationTag))
+	binary.Write(&tiff, binary.LittleEndian, uint16(3))
+	binary.Write(&tiff, binary.LittleEndian, uint32(1))
+	binary.Write(&tiff, binary.LittleEndian, uint32(orientation))
+	// GPS info entry
+	binary.Write(&tiff, binary.LittleEndian, uint16(0x8825))
+	binary.Write(&tiff, binary.LittleEndian, uint16(4))
+	binary.Write(&tiff, binary.LittleEndian, uint32(1))
+	binary.Write(&tiff, binary.LittleEndian, uint32(0))
+	binary.Write(&tiff, binary.LittleEndian, uint32(0))
+	tiff.WriteString("GPS 41.0082N 28.9784E")
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+func TestProcessJPEGStripsExif(t *testing.T) {
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, randomImage(200, 100, 0xff), nil)
+	require.NoError(t, err)
+
+	// insert the EXIF block right after the SOI marker
+	content := append([]byte{0xFF, 0xD8}, exifSegment(6)...)
+	content = append(content, buf.Bytes()[2:]...)
+	require.Equal(t, 6, jpegOrientation(content))
+
+	processed, err := Process(content, AvatarVariants)
+	require.NoError(t, err)
+
+	original := processed.Original
+	require.Equal(t, "image/jpeg", original.ContentType)
+	require.Equal(t, ".jpg", original.Extension)
+	require.NotContains(t, string(original.Content), "Exif")
+	require.NotContains(t, string(original.Content), "GPS")
+	require.Equal(t, 1, jpegOrientation(original.Content))
+
+	// orientation 6 is rotated by 90 degrees
+	require.Equal(t, 100, original.Width)
+	require.Equal(t, 200, original.Height)
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(original.Content))
+	require.NoError(t, err)
+	require.Equal(t, 100, cfg.Width)
+	require.Equal(t, 200, cfg.Height)
+
+	require.Len(t, processed.Variants, len(AvatarVariants))
+	for _, thumbnail := range processed.Variants {
+		require.NotContains(t, string(thumbnail.Content), "Exif")
+	}
+	require.Equal(t, 64, processed.Variants["small"].Width)
+	require.Equal(t, 64, processed.Variants["small"].Height)
+	// cropped to a square but never upscaled
+	require.Equal(t, 100, processed.Variants["medium"].Width)
+	require.Equal(t, 100, processed.Variants["medium"].Height)
+}
+
+func TestProcessPNGWithTransparency(t *testing.T) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, randomImage(1000, 500, 0x80))
+	require.NoError(t, err)
+
+	processed, err := Process(buf.Bytes(), PostVariants)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", processed.Original.ContentType)
+	require.Equal(t, ".png", processed.Original.Extension)
+	require.Equal(t, 1000, processed.Original.Width)
+	require.Equal(t, 500, processed.Original.Height)
+
+	small := processed.Variants["small"]
+	require.Equal(t, "image/png", small.ContentType)
+	require.Equal(t, 320, small.Width)
+	require.Equal(t, 160, small.Height)
+
+	medium := processed.Variants["medium"]
+	require.Equal(t, 720, medium.Width)
+	require.Equal(t, 360, medium.Height)
+}
+
+func TestProcessDoesNotUpscale(t *testing.T) {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, randomImage(100, 80, 0xff))
+	require.NoError(t, err)
+
+	processed, err := Process(buf.Bytes(), PostVariants)
+	require.NoError(t, err)
+	require.Equal(t, "image/jpeg", processed.Original.ContentType)
+
+	for _, thumbnail := range processed.Variants {
+		require.Equal(t, 100, thumbnail.Width)
+		require.Equal(t, 80, thumbnail.Height)
+	}
+}
+
+func TestProcessWebP(t *testing.T) {
+	content, err := os.ReadFile("testdata/blue-purple-pink.lossy.webp")
+	require.NoError(t, err)
+
+	processed, err := Process(content, PostVariants)
+	require.NoError(t, err)
+	require.Equal(t, "image/jpeg", processed.Original.ContentType)
+	require.Equal(t, 150, processed.Original.Width)
+	require.Equal(t, 100, processed.Original.Height)
+}
+
+func TestProcessUnsupportedFormat(t *testing.T) {
+	_, err := Process([]byte("GIF89a"), AvatarVariants)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+}
@@ -0,0 +1,157 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/storage"
+	"github.com/asdsec/thenut/worker"
+)
+
+// Kinds of rows showing an uploaded image
+const (
+	OwnerUser     = "user"
+	OwnerMerchant = "merchant"
+	OwnerCustomer = "customer"
+	OwnerPost     = "post"
+)
+
+// ownerVariants are the thumbnail sets generated for the kinds of rows
+var ownerVariants = map[string][]Variant{
+	OwnerUser:     AvatarVariants,
+	OwnerMerchant: AvatarVariants,
+	OwnerCustomer: AvatarVariants,
+	OwnerPost:     PostVariants,
+}
+
+// ThumbnailArgs are the arguments of the ThumbnailJob
+type ThumbnailArgs struct {
+	Owner string `json:"owner"`
+	// ID identifies the row, Username does for the users
+	ID       int64  `json:"id,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Key and URL are of the stored original image
+	Key string `json:"key"`
+	URL string `json:"url"`
+}
+
+// ThumbnailJob generates the thumbnails of an uploaded image
+var ThumbnailJob = worker.NewKind[ThumbnailArgs]("media.thumbnail")
+
+// Thumbnailer generates the thumbnails of the ThumbnailJob
+type Thumbnailer struct {
+	store   db.Querier
+	storage storage.BlobStorage
+}
+
+// NewThumbnailer creates a new Thumbnailer
+func NewThumbnailer(store db.Querier, storage storage.BlobStorage) *Thumbnailer {
+	return &Thumbnailer{
+		store:   store,
+		storage: storage,
+	}
+}
+
+// Generate stores the thumbnails next to the original image and records their
+// URLs on the row. The row is updated only while it still shows the image,
+// the thumbnails of an image replaced in the meantime are deleted again.
+func (thumbnailer *Thumbnailer) Generate(ctx context.Context, args ThumbnailArgs) error {
+	variants, ok := ownerVariants[args.Owner]
+	if !ok {
+		return worker.Permanent(fmt.Errorf("unknown image owner %q", args.Owner))
+	}
+
+	reader, err := thumbnailer.storage.Get(ctx, args.Key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			// the image is deleted along with its row
+			return nil
+		}
+		return err
+	}
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return err
+	}
+
+	thumbnails, err := Thumbnails(content, variants)
+	if err != nil {
+		return worker.Permanent(err)
+	}
+
+	name := strings.TrimSuffix(args.Key, path.Ext(args.Key))
+	keys := make([]string, 0, len(thumbnails))
+	urls := make(map[string]string, len(thumbnails))
+	for variant, img := range thumbnails {
+		key := name + "_" + variant + img.Extension
+		urls[variant], err = thumbnailer.storage.Put(ctx, key, img.ContentType, bytes.NewReader(img.Content), int64(len(img.Content)))
+		if err != nil {
+			thumbnailer.delete(ctx, keys)
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	data, err := json.Marshal(urls)
+	if err != nil {
+		thumbnailer.delete(ctx, keys)
+		return err
+	}
+
+	updated, err := thumbnailer.setVariants(ctx, args, data)
+	if err != nil {
+		thumbnailer.delete(ctx, keys)
+		return err
+	}
+	if updated == 0 {
+		thumbnailer.delete(ctx, keys)
+	}
+	return nil
+}
+
+func (thumbnailer *Thumbnailer) setVariants(ctx context.Context, args ThumbnailArgs, variants json.RawMessage) (int64, error) {
+	switch args.Owner {
+	case OwnerUser:
+		return thumbnailer.store.SetUserImageVariants(ctx, db.SetUserImageVariantsParams{
+			Username:      args.Username,
+			ImageUrl:      args.URL,
+			ImageVariants: variants,
+		})
+	case OwnerMerchant:
+		return thumbnailer.store.SetMerchantImageVariants(ctx, db.SetMerchantImageVariantsParams{
+			ID:            args.ID,
+			ImageUrl:      args.URL,
+			ImageVariants: variants,
+		})
+	case OwnerCustomer:
+		return thumbnailer.store.SetCustomerImageVariants(ctx, db.SetCustomerImageVariantsParams{
+			ID:            args.ID,
+			ImageUrl:      args.URL,
+			ImageVariants: variants,
+		})
+	default:
+		return thumbnailer.store.SetPostImageVariants(ctx, db.SetPostImageVariantsParams{
+			ID:            args.ID,
+			ImageUrl:      args.URL,
+			ImageVariants: variants,
+		})
+	}
+}
+
+func (thumbnailer *Thumbnailer) delete(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		err := thumbnailer.storage.Delete(ctx, key)
+		if err != nil {
+			logger.FromContext(ctx).Error("cannot delete thumbnail", "key", key, "err", err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/png"
+	"testing"
+
+	"github.com/asdsec/thenut/db/memstore"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/storage"
+	"github.com/asdsec/thenut/utils"
+	"github.com/asdsec/thenut/worker"
+	"github.com/stretchr/testify/require"
+)
+
+// storeOriginal writes a normalized image as the handlers do and returns its key and url
+func storeOriginal(t *testing.T, blobs storage.BlobStorage, folder string) (string, string) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, randomImage(400, 300, 0xff)))
+
+	processed, err := Process(buf.Bytes(), nil)
+	require.NoError(t, err)
+	require.Empty(t, processed.Variants)
+
+	key := folder + "/" + utils.RandomString(8) + processed.Original.Extension
+	url, err := blobs.Put(context.Background(), key, processed.Original.ContentType,
+		bytes.NewReader(processed.Original.Content), int64(len(processed.Original.Content)))
+	require.NoError(t, err)
+	return key, url
+}
+
+func TestThumbnailerGenerate(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewStore()
+	blobs, err := storage.NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+	thumbnailer := NewThumbnailer(store, blobs)
+
+	user, err := store.CreateUser(ctx, db.CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: utils.RandomString(32),
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+		PhoneNumber:    utils.RandomPhoneNumber(),
+		Gender:         utils.RandomGender(),
+		BirthDate:      utils.RandomBirthDate(),
+	})
+	require.NoError(t, err)
+
+	folder := "users/" + user.Username
+	key, url := storeOriginal(t, blobs, folder)
+	user, err = store.UpdateUserImage(ctx, db.UpdateUserImageParams{
+		Username:      user.Username,
+		ImageUrl:      url,
+		ImageVariants: json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+
+	args := ThumbnailArgs{Owner: OwnerUser, Username: user.Username, Key: key, URL: url}
+	require.NoError(t, thumbnailer.Generate(ctx, args))
+
+	got, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, user.Version+1, got.Version)
+
+	var urls map[string]string
+	require.NoError(t, json.Unmarshal(got.ImageVariants, &urls))
+	require.Len(t, urls, len(AvatarVariants))
+	name := key[:len(key)-len(".jpg")]
+	for _, variant := range AvatarVariants {
+		thumbnailKey := name + "_" + variant.Name + ".jpg"
+		require.Equal(t, "/media/"+thumbnailKey, urls[variant.Name])
+
+		reader, err := blobs.Get(ctx, thumbnailKey)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+	}
+
+	// the thumbnails of an image replaced before the job runs are not kept
+	replacedKey, replacedUrl := storeOriginal(t, blobs, folder)
+	require.NoError(t, thumbnailer.Generate(ctx, ThumbnailArgs{Owner: OwnerUser, Username: user.Username, Key: replacedKey, URL: replacedUrl}))
+
+	unchanged, err := store.GetUser(ctx, user.Username)
+	require.NoError(t, err)
+	require.Equal(t, got, unchanged)
+	for _, variant := range AvatarVariants {
+		thumbnailKey := replacedKey[:len(replacedKey)-len(".jpg")] + "_" + variant.Name + ".jpg"
+		_, err := blobs.Get(ctx, thumbnailKey)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	}
+}
+
+func TestThumbnailerGenerateMissingImage(t *testing.T) {
+	blobs, err := storage.NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+	thumbnailer := NewThumbnailer(memstore.NewStore(), blobs)
+
+	// the image was deleted along with its row, there is nothing to generate
+	err = thumbnailer.Generate(context.Background(), ThumbnailArgs{Owner: OwnerPost, ID: 1, Key: "posts/1/missing.jpg", URL: "/media/posts/1/missing.jpg"})
+	require.NoError(t, err)
+
+	key, url := storeOriginal(t, blobs, "posts/1")
+	err = thumbnailer.Generate(context.Background(), ThumbnailArgs{Owner: "unknown", Key: key, URL: url})
+	require.True(t, worker.IsPermanent(err))
+}
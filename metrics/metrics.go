@@ -0,0 +1,121 @@
+// Package metrics exposes the Prometheus metrics of the HTTP server, the
+// database and the business events
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "thenut"
+
+// Business events counted by the metrics
+const (
+	EventRegistration       = "registration"
+	EventLogin              = "login"
+	EventLoginFailed        = "login_failed"
+	EventTokenRenewal       = "token_renewal"
+	EventPostCreated        = "post_created"
+	EventCommentCreated     = "comment_created"
+	EventConsultancyCreated = "consultancy_created"
+)
+
+var events = []string{
+	EventRegistration,
+	EventLogin,
+	EventLoginFailed,
+	EventTokenRenewal,
+	EventPostCreated,
+	EventCommentCreated,
+	EventConsultancyCreated,
+}
+
+// Metrics keeps the collectors in its own registry, so that more than one
+// server can be created in the same process
+type Metrics struct {
+	registry        *prometheus.Registry
+	requestDuration *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	businessEvents  *prometheus.CounterVec
+}
+
+// New creates the metrics along with the Go runtime and process collectors
+func New() *Metrics {
+	metrics := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the HTTP requests by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of the store calls by method and result.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method", "result"}),
+		businessEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "events_total",
+			Help:      "Number of the business events.",
+		}, []string{"event"}),
+	}
+
+	// report the events with zero before they happen
+	for _, event := range events {
+		metrics.businessEvents.WithLabelValues(event)
+	}
+
+	metrics.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		metrics.requestDuration,
+		metrics.queryDuration,
+		metrics.businessEvents,
+	)
+	return metrics
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (metrics *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{})
+}
+
+// Registry returns the registry of the metrics
+func (metrics *Metrics) Registry() *prometheus.Registry {
+	return metrics.registry
+}
+
+// RegisterDBStats exposes the connection pool stats of the database
+func (metrics *Metrics) RegisterDBStats(db *sql.DB) error {
+	return metrics.registry.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// ObserveRequest records the duration of an HTTP request. The route must be
+// the route template, so that the path parameters do not blow up the labels.
+func (metrics *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	metrics.requestDuration.
+		WithLabelValues(method, route, strconv.Itoa(status)).
+		Observe(duration.Seconds())
+}
+
+// ObserveQuery records the duration of a store call
+func (metrics *Metrics) ObserveQuery(method, result string, duration time.Duration) {
+	metrics.queryDuration.
+		WithLabelValues(method, result).
+		Observe(duration.Seconds())
+}
+
+// IncEvent counts a business event
+func (metrics *Metrics) IncEvent(event string) {
+	metrics.businessEvents.WithLabelValues(event).Inc()
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/db/storetest"
+	"github.com/golang/mock/gomock"
+	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	metrics := New()
+	metrics.ObserveRequest(http.MethodGet, "/posts/:id", http.StatusOK, 20*time.Millisecond)
+	metrics.IncEvent(EventLogin)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+
+	metrics.Handler().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	body := recorder.Body.String()
+	require.Contains(t, body, `thenut_http_request_duration_seconds_count{method="GET",route="/posts/:id",status="200"} 1`)
+	require.Contains(t, body, `thenut_events_total{event="login"} 1`)
+	require.Contains(t, body, `thenut_events_total{event="consultancy_created"} 0`)
+	require.Contains(t, body, "go_goroutines")
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	conn, err := sql.Open("postgres", "postgresql://localhost/thenut")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	metrics := New()
+	require.NoError(t, metrics.RegisterDBStats(conn))
+
+	count, err := testutil.GatherAndCount(metrics.Registry(), "go_sql_max_open_connections")
+	require.NoError(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestInstrumentedStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_db.NewMockStore(ctrl)
+	gomock.InOrder(
+		mockStore.EXPECT().GetUser(gomock.Any(), gomock.Eq("user1")).Return(db.User{Username: "user1"}, nil),
+		mockStore.EXPECT().GetUser(gomock.Any(), gomock.Eq("user2")).Return(db.User{}, sql.ErrNoRows),
+		mockStore.EXPECT().GetUser(gomock.Any(), gomock.Eq("user3")).Return(db.User{}, sql.ErrConnDone),
+		mockStore.EXPECT().DeleteUserTx(gomock.Any(), gomock.Eq("user1")).Return(db.DeleteUserTxResult{}, errors.New("tx failed")),
+	)
+
+	metrics := New()
+	store := NewInstrumentedStore(mockStore, metrics)
+
+	user, err := store.GetUser(context.Background(), "user1")
+	require.NoError(t, err)
+	require.Equal(t, "user1", user.Username)
+
+	_, err = store.GetUser(context.Background(), "user2")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.GetUser(context.Background(), "user3")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+
+	_, err = store.DeleteUserTx(context.Background(), "user1")
+	require.EqualError(t, err, "tx failed")
+
+	require.Equal(t, 3, testutil.CollectAndCount(metrics.queryDuration))
+	require.Equal(t, uint64(2), histogramCount(t, metrics, "GetUser", resultOK))
+	require.Equal(t, uint64(1), histogramCount(t, metrics, "GetUser", resultError))
+	require.Equal(t, uint64(1), histogramCount(t, metrics, "DeleteUserTx", resultError))
+}
+
+func TestInstrumentedStoreConformance(t *testing.T) {
+	metrics := New()
+	storetest.Run(t, func(t *testing.T) db.Store {
+		return NewInstrumentedStore(memstore.NewStore(), metrics)
+	})
+}
+
+func histogramCount(t *testing.T, metrics *Metrics, method, result string) uint64 {
+	families, err := metrics.Registry().Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "thenut_store_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["method"] == method && labels["result"] == result {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,638 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/google/uuid"
+)
+
+// Results of the store calls
+const (
+	resultOK    = "ok"
+	resultError = "error"
+)
+
+// InstrumentedStore is a db.Store that records the duration of every call of
+// the wrapped store. A method missing here is still served by the wrapped
+// store, only without the timing.
+type InstrumentedStore struct {
+	db.Store
+	metrics *Metrics
+}
+
+// NewInstrumentedStore wraps the store with the query timing
+func NewInstrumentedStore(store db.Store, metrics *Metrics) db.Store {
+	return &InstrumentedStore{
+		Store:   store,
+		metrics: metrics,
+	}
+}
+
+// observe records the duration of a store call. sql.ErrNoRows is not counted
+// as an error since it is an expected result of the lookups.
+func (store *InstrumentedStore) observe(method string, start time.Time, err *error) {
+	result := resultOK
+	if *err != nil && !errors.Is(*err, sql.ErrNoRows) {
+		result = resultError
+	}
+	store.metrics.ObserveQuery(method, result, time.Since(start))
+}
+
+func (store *InstrumentedStore) AddMerchantBalance(ctx context.Context, arg db.AddMerchantBalanceParams) (result db.Merchant, err error) {
+	defer store.observe("AddMerchantBalance", time.Now(), &err)
+	return store.Store.AddMerchantBalance(ctx, arg)
+}
+
+func (store *InstrumentedStore) AnonymizeUserComments(ctx context.Context, owner string) (err error) {
+	defer store.observe("AnonymizeUserComments", time.Now(), &err)
+	return store.Store.AnonymizeUserComments(ctx, owner)
+}
+
+func (store *InstrumentedStore) AnonymizeUserCustomers(ctx context.Context, owner string) (result []int64, err error) {
+	defer store.observe("AnonymizeUserCustomers", time.Now(), &err)
+	return store.Store.AnonymizeUserCustomers(ctx, owner)
+}
+
+func (store *InstrumentedStore) AnonymizeUserMerchants(ctx context.Context, owner string) (result []int64, err error) {
+	defer store.observe("AnonymizeUserMerchants", time.Now(), &err)
+	return store.Store.AnonymizeUserMerchants(ctx, owner)
+}
+
+func (store *InstrumentedStore) AnonymizeUserNotifications(ctx context.Context, actor string) (err error) {
+	defer store.observe("AnonymizeUserNotifications", time.Now(), &err)
+	return store.Store.AnonymizeUserNotifications(ctx, actor)
+}
+
+func (store *InstrumentedStore) AnonymizeUserPostRevisions(ctx context.Context, editor string) (err error) {
+	defer store.observe("AnonymizeUserPostRevisions", time.Now(), &err)
+	return store.Store.AnonymizeUserPostRevisions(ctx, editor)
+}
+
+func (store *InstrumentedStore) AppendOutboxEvent(ctx context.Context, arg db.AppendOutboxEventParams) (result db.OutboxEvent, err error) {
+	defer store.observe("AppendOutboxEvent", time.Now(), &err)
+	return store.Store.AppendOutboxEvent(ctx, arg)
+}
+
+func (store *InstrumentedStore) AuditTx(ctx context.Context, arg db.AuditTxParams) (result db.AuditEvent, err error) {
+	defer store.observe("AuditTx", time.Now(), &err)
+	return store.Store.AuditTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) BlockUserSessions(ctx context.Context, username string) (result int64, err error) {
+	defer store.observe("BlockUserSessions", time.Now(), &err)
+	return store.Store.BlockUserSessions(ctx, username)
+}
+
+func (store *InstrumentedStore) ClaimJobs(ctx context.Context, arg db.ClaimJobsParams) (result []db.Job, err error) {
+	defer store.observe("ClaimJobs", time.Now(), &err)
+	return store.Store.ClaimJobs(ctx, arg)
+}
+
+func (store *InstrumentedStore) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) (result db.IdempotencyKey, err error) {
+	defer store.observe("CompleteIdempotencyKey", time.Now(), &err)
+	return store.Store.CompleteIdempotencyKey(ctx, arg)
+}
+
+func (store *InstrumentedStore) CompleteJob(ctx context.Context, arg db.CompleteJobParams) (result int64, err error) {
+	defer store.observe("CompleteJob", time.Now(), &err)
+	return store.Store.CompleteJob(ctx, arg)
+}
+
+func (store *InstrumentedStore) CountUnreadNotifications(ctx context.Context, recipient string) (result int64, err error) {
+	defer store.observe("CountUnreadNotifications", time.Now(), &err)
+	return store.Store.CountUnreadNotifications(ctx, recipient)
+}
+
+func (store *InstrumentedStore) CreateAppVersion(ctx context.Context, arg db.CreateAppVersionParams) (result db.AppVersion, err error) {
+	defer store.observe("CreateAppVersion", time.Now(), &err)
+	return store.Store.CreateAppVersion(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) (result db.AuditEvent, err error) {
+	defer store.observe("CreateAuditEvent", time.Now(), &err)
+	return store.Store.CreateAuditEvent(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateComment(ctx context.Context, arg db.CreateCommentParams) (result db.Comment, err error) {
+	defer store.observe("CreateComment", time.Now(), &err)
+	return store.Store.CreateComment(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateCommentTx(ctx context.Context, arg db.CreateCommentParams) (result db.Comment, err error) {
+	defer store.observe("CreateCommentTx", time.Now(), &err)
+	return store.Store.CreateCommentTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateConsultancy(ctx context.Context, arg db.CreateConsultancyParams) (result db.Consultancy, err error) {
+	defer store.observe("CreateConsultancy", time.Now(), &err)
+	return store.Store.CreateConsultancy(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateConsultancyTx(ctx context.Context, arg db.CreateConsultancyParams) (result db.Consultancy, err error) {
+	defer store.observe("CreateConsultancyTx", time.Now(), &err)
+	return store.Store.CreateConsultancyTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateCustomer(ctx context.Context, owner string) (result db.Customer, err error) {
+	defer store.observe("CreateCustomer", time.Now(), &err)
+	return store.Store.CreateCustomer(ctx, owner)
+}
+
+func (store *InstrumentedStore) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (result db.IdempotencyKey, err error) {
+	defer store.observe("CreateIdempotencyKey", time.Now(), &err)
+	return store.Store.CreateIdempotencyKey(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateMerchant(ctx context.Context, arg db.CreateMerchantParams) (result db.Merchant, err error) {
+	defer store.observe("CreateMerchant", time.Now(), &err)
+	return store.Store.CreateMerchant(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (result db.Notification, err error) {
+	defer store.observe("CreateNotification", time.Now(), &err)
+	return store.Store.CreateNotification(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreatePost(ctx context.Context, arg db.CreatePostParams) (result db.Post, err error) {
+	defer store.observe("CreatePost", time.Now(), &err)
+	return store.Store.CreatePost(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreatePostRevision(ctx context.Context, arg db.CreatePostRevisionParams) (result db.PostRevision, err error) {
+	defer store.observe("CreatePostRevision", time.Now(), &err)
+	return store.Store.CreatePostRevision(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreatePostTx(ctx context.Context, arg db.CreatePostParams) (result db.Post, err error) {
+	defer store.observe("CreatePostTx", time.Now(), &err)
+	return store.Store.CreatePostTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateSession(ctx context.Context, arg db.CreateSessionParams) (err error) {
+	defer store.observe("CreateSession", time.Now(), &err)
+	return store.Store.CreateSession(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (result db.User, err error) {
+	defer store.observe("CreateUser", time.Now(), &err)
+	return store.Store.CreateUser(ctx, arg)
+}
+
+func (store *InstrumentedStore) CreateUserTx(ctx context.Context, arg db.CreateUserParams) (result db.User, err error) {
+	defer store.observe("CreateUserTx", time.Now(), &err)
+	return store.Store.CreateUserTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) DeleteComment(ctx context.Context, id int64) (err error) {
+	defer store.observe("DeleteComment", time.Now(), &err)
+	return store.Store.DeleteComment(ctx, id)
+}
+
+func (store *InstrumentedStore) DeleteCustomer(ctx context.Context, id int64) (err error) {
+	defer store.observe("DeleteCustomer", time.Now(), &err)
+	return store.Store.DeleteCustomer(ctx, id)
+}
+
+func (store *InstrumentedStore) DeleteDeviceToken(ctx context.Context, sessionID uuid.UUID) (err error) {
+	defer store.observe("DeleteDeviceToken", time.Now(), &err)
+	return store.Store.DeleteDeviceToken(ctx, sessionID)
+}
+
+func (store *InstrumentedStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (result int64, err error) {
+	defer store.observe("DeleteExpiredIdempotencyKeys", time.Now(), &err)
+	return store.Store.DeleteExpiredIdempotencyKeys(ctx, now)
+}
+
+func (store *InstrumentedStore) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) (err error) {
+	defer store.observe("DeleteIdempotencyKey", time.Now(), &err)
+	return store.Store.DeleteIdempotencyKey(ctx, arg)
+}
+
+func (store *InstrumentedStore) DeleteMerchant(ctx context.Context, id int64) (err error) {
+	defer store.observe("DeleteMerchant", time.Now(), &err)
+	return store.Store.DeleteMerchant(ctx, id)
+}
+
+func (store *InstrumentedStore) DeleteOutboxEvent(ctx context.Context, id int64) (err error) {
+	defer store.observe("DeleteOutboxEvent", time.Now(), &err)
+	return store.Store.DeleteOutboxEvent(ctx, id)
+}
+
+func (store *InstrumentedStore) DeleteOwnerPosts(ctx context.Context, owner string) (err error) {
+	defer store.observe("DeleteOwnerPosts", time.Now(), &err)
+	return store.Store.DeleteOwnerPosts(ctx, owner)
+}
+
+func (store *InstrumentedStore) DeletePost(ctx context.Context, id int64) (err error) {
+	defer store.observe("DeletePost", time.Now(), &err)
+	return store.Store.DeletePost(ctx, id)
+}
+
+func (store *InstrumentedStore) DeleteUser(ctx context.Context, username string) (err error) {
+	defer store.observe("DeleteUser", time.Now(), &err)
+	return store.Store.DeleteUser(ctx, username)
+}
+
+func (store *InstrumentedStore) DeleteUserDeviceTokens(ctx context.Context, username string) (err error) {
+	defer store.observe("DeleteUserDeviceTokens", time.Now(), &err)
+	return store.Store.DeleteUserDeviceTokens(ctx, username)
+}
+
+func (store *InstrumentedStore) DeleteUserIdempotencyKeys(ctx context.Context, username string) (err error) {
+	defer store.observe("DeleteUserIdempotencyKeys", time.Now(), &err)
+	return store.Store.DeleteUserIdempotencyKeys(ctx, username)
+}
+
+func (store *InstrumentedStore) DeleteUserNotificationPreferences(ctx context.Context, username string) (err error) {
+	defer store.observe("DeleteUserNotificationPreferences", time.Now(), &err)
+	return store.Store.DeleteUserNotificationPreferences(ctx, username)
+}
+
+func (store *InstrumentedStore) DeleteUserNotificationSettings(ctx context.Context, username string) (err error) {
+	defer store.observe("DeleteUserNotificationSettings", time.Now(), &err)
+	return store.Store.DeleteUserNotificationSettings(ctx, username)
+}
+
+func (store *InstrumentedStore) DeleteUserNotifications(ctx context.Context, recipient string) (err error) {
+	defer store.observe("DeleteUserNotifications", time.Now(), &err)
+	return store.Store.DeleteUserNotifications(ctx, recipient)
+}
+
+func (store *InstrumentedStore) DeleteUserSessions(ctx context.Context, username string) (err error) {
+	defer store.observe("DeleteUserSessions", time.Now(), &err)
+	return store.Store.DeleteUserSessions(ctx, username)
+}
+
+func (store *InstrumentedStore) DeleteUserTx(ctx context.Context, username string) (result db.DeleteUserTxResult, err error) {
+	defer store.observe("DeleteUserTx", time.Now(), &err)
+	return store.Store.DeleteUserTx(ctx, username)
+}
+
+func (store *InstrumentedStore) EnqueueJob(ctx context.Context, arg db.EnqueueJobParams) (result db.Job, err error) {
+	defer store.observe("EnqueueJob", time.Now(), &err)
+	return store.Store.EnqueueJob(ctx, arg)
+}
+
+func (store *InstrumentedStore) ExportUserTx(ctx context.Context, username string) (result db.ExportUserTxResult, err error) {
+	defer store.observe("ExportUserTx", time.Now(), &err)
+	return store.Store.ExportUserTx(ctx, username)
+}
+
+func (store *InstrumentedStore) GetAppVersion(ctx context.Context, tag string) (result db.AppVersion, err error) {
+	defer store.observe("GetAppVersion", time.Now(), &err)
+	return store.Store.GetAppVersion(ctx, tag)
+}
+
+func (store *InstrumentedStore) GetComment(ctx context.Context, id int64) (result db.Comment, err error) {
+	defer store.observe("GetComment", time.Now(), &err)
+	return store.Store.GetComment(ctx, id)
+}
+
+func (store *InstrumentedStore) GetConsultancy(ctx context.Context, id int64) (result db.Consultancy, err error) {
+	defer store.observe("GetConsultancy", time.Now(), &err)
+	return store.Store.GetConsultancy(ctx, id)
+}
+
+func (store *InstrumentedStore) GetCustomer(ctx context.Context, id int64) (result db.Customer, err error) {
+	defer store.observe("GetCustomer", time.Now(), &err)
+	return store.Store.GetCustomer(ctx, id)
+}
+
+func (store *InstrumentedStore) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (result db.IdempotencyKey, err error) {
+	defer store.observe("GetIdempotencyKey", time.Now(), &err)
+	return store.Store.GetIdempotencyKey(ctx, arg)
+}
+
+func (store *InstrumentedStore) GetMerchant(ctx context.Context, id int64) (result db.Merchant, err error) {
+	defer store.observe("GetMerchant", time.Now(), &err)
+	return store.Store.GetMerchant(ctx, id)
+}
+
+func (store *InstrumentedStore) GetNotificationSettings(ctx context.Context, username string) (result db.NotificationSetting, err error) {
+	defer store.observe("GetNotificationSettings", time.Now(), &err)
+	return store.Store.GetNotificationSettings(ctx, username)
+}
+
+func (store *InstrumentedStore) GetPost(ctx context.Context, id int64) (result db.Post, err error) {
+	defer store.observe("GetPost", time.Now(), &err)
+	return store.Store.GetPost(ctx, id)
+}
+
+func (store *InstrumentedStore) GetPostForUpdate(ctx context.Context, id int64) (result db.Post, err error) {
+	defer store.observe("GetPostForUpdate", time.Now(), &err)
+	return store.Store.GetPostForUpdate(ctx, id)
+}
+
+func (store *InstrumentedStore) GetSchemaVersion(ctx context.Context) (result db.SchemaVersion, err error) {
+	defer store.observe("GetSchemaVersion", time.Now(), &err)
+	return store.Store.GetSchemaVersion(ctx)
+}
+
+func (store *InstrumentedStore) GetSession(ctx context.Context, id uuid.UUID) (result db.Session, err error) {
+	defer store.observe("GetSession", time.Now(), &err)
+	return store.Store.GetSession(ctx, id)
+}
+
+func (store *InstrumentedStore) GetUser(ctx context.Context, username string) (result db.User, err error) {
+	defer store.observe("GetUser", time.Now(), &err)
+	return store.Store.GetUser(ctx, username)
+}
+
+func (store *InstrumentedStore) KillJob(ctx context.Context, arg db.KillJobParams) (result db.Job, err error) {
+	defer store.observe("KillJob", time.Now(), &err)
+	return store.Store.KillJob(ctx, arg)
+}
+
+func (store *InstrumentedStore) KillStaleJobs(ctx context.Context, arg db.KillStaleJobsParams) (result []db.Job, err error) {
+	defer store.observe("KillStaleJobs", time.Now(), &err)
+	return store.Store.KillStaleJobs(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListAllUserDeviceTokens(ctx context.Context, username string) (result []db.DeviceToken, err error) {
+	defer store.observe("ListAllUserDeviceTokens", time.Now(), &err)
+	return store.Store.ListAllUserDeviceTokens(ctx, username)
+}
+
+func (store *InstrumentedStore) ListAppVersions(ctx context.Context) (result []db.AppVersion, err error) {
+	defer store.observe("ListAppVersions", time.Now(), &err)
+	return store.Store.ListAppVersions(ctx)
+}
+
+func (store *InstrumentedStore) ListAuditEvents(ctx context.Context, arg db.ListAuditEventsParams) (result []db.AuditEvent, err error) {
+	defer store.observe("ListAuditEvents", time.Now(), &err)
+	return store.Store.ListAuditEvents(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListAuditEventsByCursor(ctx context.Context, arg db.ListAuditEventsByCursorParams) (result []db.AuditEvent, err error) {
+	defer store.observe("ListAuditEventsByCursor", time.Now(), &err)
+	return store.Store.ListAuditEventsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListCommentsByOwner(ctx context.Context, owner string) (result []db.Comment, err error) {
+	defer store.observe("ListCommentsByOwner", time.Now(), &err)
+	return store.Store.ListCommentsByOwner(ctx, owner)
+}
+
+func (store *InstrumentedStore) ListConsultancies(ctx context.Context, arg db.ListConsultanciesParams) (result []db.Consultancy, err error) {
+	defer store.observe("ListConsultancies", time.Now(), &err)
+	return store.Store.ListConsultancies(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListConsultanciesByOwner(ctx context.Context, owner string) (result []db.Consultancy, err error) {
+	defer store.observe("ListConsultanciesByOwner", time.Now(), &err)
+	return store.Store.ListConsultanciesByOwner(ctx, owner)
+}
+
+func (store *InstrumentedStore) ListCustomersByOwner(ctx context.Context, owner string) (result []db.Customer, err error) {
+	defer store.observe("ListCustomersByOwner", time.Now(), &err)
+	return store.Store.ListCustomersByOwner(ctx, owner)
+}
+
+func (store *InstrumentedStore) ListDeadJobs(ctx context.Context, arg db.ListDeadJobsParams) (result []db.Job, err error) {
+	defer store.observe("ListDeadJobs", time.Now(), &err)
+	return store.Store.ListDeadJobs(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListMerchantComments(ctx context.Context, arg db.ListMerchantCommentsParams) (result []db.Comment, err error) {
+	defer store.observe("ListMerchantComments", time.Now(), &err)
+	return store.Store.ListMerchantComments(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListMerchantPosts(ctx context.Context, arg db.ListMerchantPostsParams) (result []db.Post, err error) {
+	defer store.observe("ListMerchantPosts", time.Now(), &err)
+	return store.Store.ListMerchantPosts(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListMerchantPostsByCursor(ctx context.Context, arg db.ListMerchantPostsByCursorParams) (result []db.Post, err error) {
+	defer store.observe("ListMerchantPostsByCursor", time.Now(), &err)
+	return store.Store.ListMerchantPostsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListMerchants(ctx context.Context, arg db.ListMerchantsParams) (result []db.Merchant, err error) {
+	defer store.observe("ListMerchants", time.Now(), &err)
+	return store.Store.ListMerchants(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListMerchantsByCursor(ctx context.Context, arg db.ListMerchantsByCursorParams) (result []db.Merchant, err error) {
+	defer store.observe("ListMerchantsByCursor", time.Now(), &err)
+	return store.Store.ListMerchantsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListMerchantsByOwner(ctx context.Context, owner string) (result []db.Merchant, err error) {
+	defer store.observe("ListMerchantsByOwner", time.Now(), &err)
+	return store.Store.ListMerchantsByOwner(ctx, owner)
+}
+
+func (store *InstrumentedStore) ListNotificationPreferences(ctx context.Context, username string) (result []db.NotificationPreference, err error) {
+	defer store.observe("ListNotificationPreferences", time.Now(), &err)
+	return store.Store.ListNotificationPreferences(ctx, username)
+}
+
+func (store *InstrumentedStore) ListNotifications(ctx context.Context, arg db.ListNotificationsParams) (result []db.Notification, err error) {
+	defer store.observe("ListNotifications", time.Now(), &err)
+	return store.Store.ListNotifications(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListNotificationsByCursor(ctx context.Context, arg db.ListNotificationsByCursorParams) (result []db.Notification, err error) {
+	defer store.observe("ListNotificationsByCursor", time.Now(), &err)
+	return store.Store.ListNotificationsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListOutboxEventsForUpdate(ctx context.Context, limit int32) (result []db.OutboxEvent, err error) {
+	defer store.observe("ListOutboxEventsForUpdate", time.Now(), &err)
+	return store.Store.ListOutboxEventsForUpdate(ctx, limit)
+}
+
+func (store *InstrumentedStore) ListPostComments(ctx context.Context, arg db.ListPostCommentsParams) (result []db.Comment, err error) {
+	defer store.observe("ListPostComments", time.Now(), &err)
+	return store.Store.ListPostComments(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListPostCommentsByCursor(ctx context.Context, arg db.ListPostCommentsByCursorParams) (result []db.Comment, err error) {
+	defer store.observe("ListPostCommentsByCursor", time.Now(), &err)
+	return store.Store.ListPostCommentsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListPostRevisions(ctx context.Context, arg db.ListPostRevisionsParams) (result []db.PostRevision, err error) {
+	defer store.observe("ListPostRevisions", time.Now(), &err)
+	return store.Store.ListPostRevisions(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListPostRevisionsByCursor(ctx context.Context, arg db.ListPostRevisionsByCursorParams) (result []db.PostRevision, err error) {
+	defer store.observe("ListPostRevisionsByCursor", time.Now(), &err)
+	return store.Store.ListPostRevisionsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListPosts(ctx context.Context, arg db.ListPostsParams) (result []db.Post, err error) {
+	defer store.observe("ListPosts", time.Now(), &err)
+	return store.Store.ListPosts(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListPostsByCursor(ctx context.Context, arg db.ListPostsByCursorParams) (result []db.Post, err error) {
+	defer store.observe("ListPostsByCursor", time.Now(), &err)
+	return store.Store.ListPostsByCursor(ctx, arg)
+}
+
+func (store *InstrumentedStore) ListPostsByOwner(ctx context.Context, owner string) (result []db.Post, err error) {
+	defer store.observe("ListPostsByOwner", time.Now(), &err)
+	return store.Store.ListPostsByOwner(ctx, owner)
+}
+
+func (store *InstrumentedStore) ListUserAuditEvents(ctx context.Context, username string) (result []db.AuditEvent, err error) {
+	defer store.observe("ListUserAuditEvents", time.Now(), &err)
+	return store.Store.ListUserAuditEvents(ctx, username)
+}
+
+func (store *InstrumentedStore) ListUserDeviceTokens(ctx context.Context, username string) (result []db.DeviceToken, err error) {
+	defer store.observe("ListUserDeviceTokens", time.Now(), &err)
+	return store.Store.ListUserDeviceTokens(ctx, username)
+}
+
+func (store *InstrumentedStore) ListUserNotifications(ctx context.Context, recipient string) (result []db.Notification, err error) {
+	defer store.observe("ListUserNotifications", time.Now(), &err)
+	return store.Store.ListUserNotifications(ctx, recipient)
+}
+
+func (store *InstrumentedStore) ListUserSessions(ctx context.Context, username string) (result []db.Session, err error) {
+	defer store.observe("ListUserSessions", time.Now(), &err)
+	return store.Store.ListUserSessions(ctx, username)
+}
+
+func (store *InstrumentedStore) ListUsersDueForDeletion(ctx context.Context, arg db.ListUsersDueForDeletionParams) (result []string, err error) {
+	defer store.observe("ListUsersDueForDeletion", time.Now(), &err)
+	return store.Store.ListUsersDueForDeletion(ctx, arg)
+}
+
+func (store *InstrumentedStore) MarkAllNotificationsRead(ctx context.Context, recipient string) (result int64, err error) {
+	defer store.observe("MarkAllNotificationsRead", time.Now(), &err)
+	return store.Store.MarkAllNotificationsRead(ctx, recipient)
+}
+
+func (store *InstrumentedStore) MarkNotificationRead(ctx context.Context, arg db.MarkNotificationReadParams) (result db.Notification, err error) {
+	defer store.observe("MarkNotificationRead", time.Now(), &err)
+	return store.Store.MarkNotificationRead(ctx, arg)
+}
+
+func (store *InstrumentedStore) Ping(ctx context.Context) (err error) {
+	defer store.observe("Ping", time.Now(), &err)
+	return store.Store.Ping(ctx)
+}
+
+func (store *InstrumentedStore) RelayOutboxTx(ctx context.Context, arg db.RelayOutboxTxParams) (result int, err error) {
+	defer store.observe("RelayOutboxTx", time.Now(), &err)
+	return store.Store.RelayOutboxTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) RequeueDeadJob(ctx context.Context, id int64) (result db.Job, err error) {
+	defer store.observe("RequeueDeadJob", time.Now(), &err)
+	return store.Store.RequeueDeadJob(ctx, id)
+}
+
+func (store *InstrumentedStore) RetryJob(ctx context.Context, arg db.RetryJobParams) (result db.Job, err error) {
+	defer store.observe("RetryJob", time.Now(), &err)
+	return store.Store.RetryJob(ctx, arg)
+}
+
+func (store *InstrumentedStore) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (result db.User, err error) {
+	defer store.observe("ScheduleUserDeletion", time.Now(), &err)
+	return store.Store.ScheduleUserDeletion(ctx, arg)
+}
+
+func (store *InstrumentedStore) SetCustomerImageVariants(ctx context.Context, arg db.SetCustomerImageVariantsParams) (result int64, err error) {
+	defer store.observe("SetCustomerImageVariants", time.Now(), &err)
+	return store.Store.SetCustomerImageVariants(ctx, arg)
+}
+
+func (store *InstrumentedStore) SetMerchantImageVariants(ctx context.Context, arg db.SetMerchantImageVariantsParams) (result int64, err error) {
+	defer store.observe("SetMerchantImageVariants", time.Now(), &err)
+	return store.Store.SetMerchantImageVariants(ctx, arg)
+}
+
+func (store *InstrumentedStore) SetPostImageVariants(ctx context.Context, arg db.SetPostImageVariantsParams) (result int64, err error) {
+	defer store.observe("SetPostImageVariants", time.Now(), &err)
+	return store.Store.SetPostImageVariants(ctx, arg)
+}
+
+func (store *InstrumentedStore) SetUserDisabled(ctx context.Context, arg db.SetUserDisabledParams) (result db.User, err error) {
+	defer store.observe("SetUserDisabled", time.Now(), &err)
+	return store.Store.SetUserDisabled(ctx, arg)
+}
+
+func (store *InstrumentedStore) SetUserImageVariants(ctx context.Context, arg db.SetUserImageVariantsParams) (result int64, err error) {
+	defer store.observe("SetUserImageVariants", time.Now(), &err)
+	return store.Store.SetUserImageVariants(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateAppVersion(ctx context.Context, arg db.UpdateAppVersionParams) (result db.AppVersion, err error) {
+	defer store.observe("UpdateAppVersion", time.Now(), &err)
+	return store.Store.UpdateAppVersion(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateCustomer(ctx context.Context, arg db.UpdateCustomerParams) (result db.Customer, err error) {
+	defer store.observe("UpdateCustomer", time.Now(), &err)
+	return store.Store.UpdateCustomer(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateEmail(ctx context.Context, arg db.UpdateEmailParams) (result db.User, err error) {
+	defer store.observe("UpdateEmail", time.Now(), &err)
+	return store.Store.UpdateEmail(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateMerchant(ctx context.Context, arg db.UpdateMerchantParams) (result db.Merchant, err error) {
+	defer store.observe("UpdateMerchant", time.Now(), &err)
+	return store.Store.UpdateMerchant(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateMerchantImage(ctx context.Context, arg db.UpdateMerchantImageParams) (result db.Merchant, err error) {
+	defer store.observe("UpdateMerchantImage", time.Now(), &err)
+	return store.Store.UpdateMerchantImage(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateNotificationPreferencesTx(ctx context.Context, arg db.UpdateNotificationPreferencesTxParams) (result db.UpdateNotificationPreferencesTxResult, err error) {
+	defer store.observe("UpdateNotificationPreferencesTx", time.Now(), &err)
+	return store.Store.UpdateNotificationPreferencesTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdatePassword(ctx context.Context, arg db.UpdatePasswordParams) (result db.User, err error) {
+	defer store.observe("UpdatePassword", time.Now(), &err)
+	return store.Store.UpdatePassword(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdatePost(ctx context.Context, arg db.UpdatePostParams) (result db.Post, err error) {
+	defer store.observe("UpdatePost", time.Now(), &err)
+	return store.Store.UpdatePost(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdatePostTx(ctx context.Context, arg db.UpdatePostTxParams) (result db.UpdatePostTxResult, err error) {
+	defer store.observe("UpdatePostTx", time.Now(), &err)
+	return store.Store.UpdatePostTx(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (result db.User, err error) {
+	defer store.observe("UpdateUser", time.Now(), &err)
+	return store.Store.UpdateUser(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpdateUserImage(ctx context.Context, arg db.UpdateUserImageParams) (result db.User, err error) {
+	defer store.observe("UpdateUserImage", time.Now(), &err)
+	return store.Store.UpdateUserImage(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpsertDeviceToken(ctx context.Context, arg db.UpsertDeviceTokenParams) (result db.DeviceToken, err error) {
+	defer store.observe("UpsertDeviceToken", time.Now(), &err)
+	return store.Store.UpsertDeviceToken(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpsertNotificationPreference(ctx context.Context, arg db.UpsertNotificationPreferenceParams) (result db.NotificationPreference, err error) {
+	defer store.observe("UpsertNotificationPreference", time.Now(), &err)
+	return store.Store.UpsertNotificationPreference(ctx, arg)
+}
+
+func (store *InstrumentedStore) UpsertNotificationSettings(ctx context.Context, arg db.UpsertNotificationSettingsParams) (result db.NotificationSetting, err error) {
+	defer store.observe("UpsertNotificationSettings", time.Now(), &err)
+	return store.Store.UpsertNotificationSettings(ctx, arg)
+}
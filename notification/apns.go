@@ -0,0 +1,167 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	apnsProductionHost  = "https://api.push.apple.com"
+	apnsDevelopmentHost = "https://api.sandbox.push.apple.com"
+	// apnsTokenLifetime renews the provider token before APNs rejects it,
+	// which happens an hour after it is issued
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// APNsSender is a PushSender calling the HTTP/2 API of the Apple Push
+// Notification service with token based authentication
+type APNsSender struct {
+	client *http.Client
+	host   string
+	topic  string
+	key    *ecdsa.PrivateKey
+	keyID  string
+	teamID string
+
+	mu       sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+// NewAPNsSender creates a new APNsSender for the app of the topic, which is its
+// bundle ID. The key is the .p8 signing key of the team with the given key ID.
+func NewAPNsSender(key []byte, keyID, teamID, topic string, production bool) (PushSender, error) {
+	block, _ := pem.Decode(key)
+	if block == nil {
+		return nil, errors.New("apns key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse apns key: %w", err)
+	}
+	signingKey, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("apns key is not an ECDSA key")
+	}
+
+	host := apnsDevelopmentHost
+	if production {
+		host = apnsProductionHost
+	}
+	return newAPNsSender(&http.Client{Timeout: pushTimeout}, host, signingKey, keyID, teamID, topic), nil
+}
+
+func newAPNsSender(client *http.Client, host string, key *ecdsa.PrivateKey, keyID, teamID, topic string) *APNsSender {
+	return &APNsSender{
+		client: client,
+		host:   host,
+		topic:  topic,
+		key:    key,
+		keyID:  keyID,
+		teamID: teamID,
+	}
+}
+
+type apnsAlert struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type apnsErrorResponse struct {
+	Reason string `json:"reason"`
+}
+
+// Send sends the push as an APNs alert. The data is put next to the aps
+// dictionary of the payload, where the app reads its custom keys.
+func (sender *APNsSender) Send(ctx context.Context, push Push) error {
+	payload := make(map[string]interface{}, len(push.Data)+1)
+	for key, value := range push.Data {
+		payload[key] = value
+	}
+	payload["aps"] = map[string]interface{}{
+		"alert": apnsAlert{Title: push.Title, Body: push.Body},
+		"sound": "default",
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	providerToken, err := sender.providerToken()
+	if err != nil {
+		return err
+	}
+
+	url := sender.host + "/3/device/" + push.Token
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+providerToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", sender.topic)
+	req.Header.Set("apns-push-type", "alert")
+
+	rsp, err := sender.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot call apns: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var errRsp apnsErrorResponse
+	data, _ := io.ReadAll(io.LimitReader(rsp.Body, 1<<16))
+	_ = json.Unmarshal(data, &errRsp)
+
+	if rsp.StatusCode == http.StatusGone || errRsp.Reason == "BadDeviceToken" || errRsp.Reason == "Unregistered" {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("apns responded %d: %s", rsp.StatusCode, errRsp.Reason)
+}
+
+// providerToken returns the JWT authorizing the calls, signing a new one when
+// the current one is about to expire
+func (sender *APNsSender) providerToken() (string, error) {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	now := time.Now()
+	if sender.token != "" && now.Sub(sender.issuedAt) < apnsTokenLifetime {
+		return sender.token, nil
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": sender.keyID})
+	claims, _ := json.Marshal(map[string]interface{}{"iss": sender.teamID, "iat": now.Unix()})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, sender.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("cannot sign apns token: %w", err)
+	}
+
+	// ES256 signatures are the fixed size r and s, not the ASN.1 encoding
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	sender.token = unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)
+	sender.issuedAt = now
+	return sender.token, nil
+}
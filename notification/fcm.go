@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2/jwt"
+)
+
+const (
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+	// pushTimeout limits a single call to a push service
+	pushTimeout = 10 * time.Second
+)
+
+// FCMSender is a PushSender calling the HTTP v1 API of Firebase Cloud Messaging
+type FCMSender struct {
+	client   *http.Client
+	endpoint string
+}
+
+// serviceAccount is the part of the Google service account key file the
+// sender needs
+type serviceAccount struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// NewFCMSender creates a new FCMSender for the Firebase project, authorized
+// by the JSON key of a service account of the project
+func NewFCMSender(projectID string, credentials []byte) (PushSender, error) {
+	var account serviceAccount
+	if err := json.Unmarshal(credentials, &account); err != nil {
+		return nil, fmt.Errorf("cannot parse fcm credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" || account.TokenURI == "" {
+		return nil, fmt.Errorf("fcm credentials are not a service account key")
+	}
+
+	config := &jwt.Config{
+		Email:        account.ClientEmail,
+		PrivateKey:   []byte(account.PrivateKey),
+		PrivateKeyID: account.PrivateKeyID,
+		TokenURL:     account.TokenURI,
+		Scopes:       []string{fcmScope},
+	}
+	client := config.Client(context.Background())
+	client.Timeout = pushTimeout
+
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", projectID)
+	return newFCMSender(client, endpoint), nil
+}
+
+func newFCMSender(client *http.Client, endpoint string) *FCMSender {
+	return &FCMSender{
+		client:   client,
+		endpoint: endpoint,
+	}
+}
+
+type fcmRequest struct {
+	Message fcmMessage `json:"message"`
+}
+
+type fcmMessage struct {
+	Token        string            `json:"token"`
+	Notification fcmNotification   `json:"notification"`
+	Data         map[string]string `json:"data,omitempty"`
+}
+
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+type fcmErrorResponse struct {
+	Error struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// Send sends the push as an FCM message
+func (sender *FCMSender) Send(ctx context.Context, push Push) error {
+	body, err := json.Marshal(fcmRequest{
+		Message: fcmMessage{
+			Token: push.Token,
+			Notification: fcmNotification{
+				Title: push.Title,
+				Body:  push.Body,
+			},
+			Data: push.Data,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sender.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := sender.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("cannot call fcm: %w", err)
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var errRsp fcmErrorResponse
+	data, _ := io.ReadAll(io.LimitReader(rsp.Body, 1<<16))
+	_ = json.Unmarshal(data, &errRsp)
+
+	// the token of an uninstalled app is unregistered, while a malformed one
+	// is an invalid argument of the message
+	for _, detail := range errRsp.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrInvalidToken
+		}
+	}
+	if rsp.StatusCode == http.StatusNotFound {
+		return ErrInvalidToken
+	}
+	return fmt.Errorf("fcm responded %d %s: %s", rsp.StatusCode, errRsp.Error.Status, errRsp.Error.Message)
+}
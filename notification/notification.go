@@ -0,0 +1,150 @@
+// Package notification informs the users about the actions of the others on
+// their posts and merchants, through the in-app inbox and the push services
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/i18n"
+	"github.com/asdsec/thenut/logger"
+)
+
+// Types of the notifications
+const (
+	TypePostCommented     = "post_commented"
+	TypePostLiked         = "post_liked"
+	TypeConsultancyBooked = "consultancy_booked"
+	// TypeMerchantReviewed and TypeMarketing have no producer yet, the users
+	// can still choose how they get them
+	TypeMerchantReviewed = "merchant_reviewed"
+	TypeMarketing        = "marketing"
+)
+
+// PostTarget returns the target of the notifications about the post
+func PostTarget(id int64) string {
+	return fmt.Sprintf("post:%d", id)
+}
+
+// ConsultancyTarget returns the target of the notifications about the consultancy
+func ConsultancyTarget(id int64) string {
+	return fmt.Sprintf("consultancy:%d", id)
+}
+
+// Event is an action of the actor the recipient is told about
+type Event struct {
+	Recipient string
+	Type      string
+	Actor     string
+	Target    string
+	// Data is stored as JSON and shown to the recipient
+	Data interface{}
+}
+
+// Notifier delivers the notifications of the events
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Dispatcher is a Notifier storing the notifications in the inbox of the
+// recipient and queueing their pushes to the devices of the recipient, as far
+// as the preferences of the recipient allow
+type Dispatcher struct {
+	store db.Querier
+	now   func() time.Time
+}
+
+// NewDispatcher creates a new Dispatcher. The pushes are sent by the Pusher
+// handling the PushJob.
+func NewDispatcher(store db.Querier) Notifier {
+	return &Dispatcher{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Notify stores the notification of the event and queues its push, through the
+// channels the recipient has enabled for the type. The pushes in the quiet
+// hours of the recipient are dropped, not delayed. The users are not notified
+// about their own actions. Only the failure of the inbox is returned, a push
+// that cannot be queued is logged since the pushes are best effort anyway.
+func (dispatcher *Dispatcher) Notify(ctx context.Context, event Event) error {
+	if event.Recipient == event.Actor {
+		return nil
+	}
+
+	prefs, err := LoadPreferences(ctx, dispatcher.store, event.Recipient)
+	if err != nil {
+		return err
+	}
+	inApp := prefs.Enabled(event.Type, ChannelInApp)
+	push := prefs.Enabled(event.Type, ChannelPush) && !prefs.Quiet(dispatcher.now())
+	if !inApp && !push {
+		return nil
+	}
+
+	data, err := marshalData(event.Data)
+	if err != nil {
+		return err
+	}
+
+	// the notification is pushed without an id when it is not in the inbox
+	notification := db.Notification{
+		Recipient: event.Recipient,
+		EventType: event.Type,
+		Actor:     event.Actor,
+		Target:    event.Target,
+		Data:      data,
+	}
+	if inApp {
+		notification, err = dispatcher.store.CreateNotification(ctx, db.CreateNotificationParams{
+			Recipient: event.Recipient,
+			EventType: event.Type,
+			Actor:     event.Actor,
+			Target:    event.Target,
+			Data:      data,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if push {
+		_, err = PushJob.Enqueue(ctx, dispatcher.store, newPushArgs(notification))
+		if err != nil {
+			logger.FromContext(ctx).Error("cannot queue push", "notification_id", notification.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// newPush returns the push of the notification to the device
+func newPush(token db.DeviceToken, notification db.Notification) Push {
+	locale := i18n.Match(token.Locale)
+	push := Push{
+		Platform: token.Platform,
+		Token:    token.Token,
+		Title:    locale.T("notification.title"),
+		Body:     locale.T("notification."+notification.EventType, notification.Actor),
+		Data: map[string]string{
+			"type":   notification.EventType,
+			"target": notification.Target,
+		},
+	}
+	if notification.ID != 0 {
+		push.Data["notification_id"] = strconv.FormatInt(notification.ID, 10)
+	}
+	return push
+}
+
+// marshalData returns the data as JSON, an empty object if there is no data
+func marshalData(data interface{}) (json.RawMessage, error) {
+	if data == nil {
+		return json.RawMessage("{}"), nil
+	}
+	return json.Marshal(data)
+}
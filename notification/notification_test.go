@@ -0,0 +1,238 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// expectPreferences stubs the preferences of the recipient, the defaults when
+// the settings are empty
+func expectPreferences(store *mock_db.MockStore, settings db.NotificationSetting, preferences []db.NotificationPreference) {
+	getErr := error(nil)
+	if settings.Username == "" {
+		getErr = sql.ErrNoRows
+	}
+	store.EXPECT().
+		GetNotificationSettings(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(settings, getErr)
+	store.EXPECT().
+		ListNotificationPreferences(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(preferences, nil)
+}
+
+// expectPushJob stubs the queueing of the push job and keeps its arguments
+func expectPushJob(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+	store.EXPECT().
+		EnqueueJob(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.EnqueueJobParams) (db.Job, error) {
+			require.Equal(t, PushJob.Name, arg.Kind)
+			require.NoError(t, json.Unmarshal(arg.Payload, args))
+			return db.Job{ID: 1, Kind: arg.Kind, Payload: arg.Payload}, nil
+		})
+}
+
+func TestDispatcherNotify(t *testing.T) {
+	event := Event{
+		Recipient: "merchant1",
+		Type:      TypePostCommented,
+		Actor:     "customer1",
+		Target:    PostTarget(42),
+		Data:      map[string]int64{"comment_id": 7},
+	}
+	notification := db.Notification{
+		ID:        1,
+		Recipient: event.Recipient,
+		EventType: event.Type,
+		Actor:     event.Actor,
+		Target:    event.Target,
+		Data:      json.RawMessage(`{"comment_id":7}`),
+	}
+
+	testCases := []struct {
+		name          string
+		event         Event
+		buildStubs    func(t *testing.T, store *mock_db.MockStore, args *PushArgs)
+		checkResponse func(t *testing.T, err error, args PushArgs)
+	}{
+		{
+			name:  "OK",
+			event: event,
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				expectPreferences(store, db.NotificationSetting{}, nil)
+				store.EXPECT().
+					CreateNotification(gomock.Any(), gomock.Eq(db.CreateNotificationParams{
+						Recipient: event.Recipient,
+						EventType: event.Type,
+						Actor:     event.Actor,
+						Target:    event.Target,
+						Data:      json.RawMessage(`{"comment_id":7}`),
+					})).
+					Times(1).
+					Return(notification, nil)
+				expectPushJob(t, store, args)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				require.NoError(t, err)
+				require.Equal(t, PushArgs{
+					NotificationID: notification.ID,
+					Recipient:      event.Recipient,
+					Type:           TypePostCommented,
+					Actor:          event.Actor,
+					Target:         "post:42",
+				}, args)
+			},
+		},
+		{
+			name: "OwnAction",
+			event: Event{
+				Recipient: event.Recipient,
+				Type:      event.Type,
+				Actor:     event.Recipient,
+				Target:    event.Target,
+			},
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				store.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:  "PushDisabled",
+			event: event,
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				expectPreferences(store, db.NotificationSetting{}, []db.NotificationPreference{
+					{Username: event.Recipient, EventType: TypePostCommented, Channel: ChannelPush, Enabled: false},
+				})
+				store.EXPECT().
+					CreateNotification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(notification, nil)
+				store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:  "InAppDisabled",
+			event: event,
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				expectPreferences(store, db.NotificationSetting{}, []db.NotificationPreference{
+					{Username: event.Recipient, EventType: TypePostCommented, Channel: ChannelInApp, Enabled: false},
+				})
+				store.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Times(0)
+				expectPushJob(t, store, args)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				// the push has no notification to open in the inbox
+				require.NoError(t, err)
+				require.Zero(t, args.NotificationID)
+				require.Equal(t, "post:42", args.Target)
+			},
+		},
+		{
+			name:  "QuietHours",
+			event: event,
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				// 23:00 to 09:00 in Istanbul covers 05:00 UTC, 08:00 there
+				expectPreferences(store, db.NotificationSetting{
+					Username:        event.Recipient,
+					TimeZone:        "Europe/Istanbul",
+					QuietHoursStart: sql.NullInt32{Int32: 23 * 60, Valid: true},
+					QuietHoursEnd:   sql.NullInt32{Int32: 9 * 60, Valid: true},
+				}, nil)
+				store.EXPECT().
+					CreateNotification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(notification, nil)
+				store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "MarketingOptIn",
+			event: Event{
+				Recipient: event.Recipient,
+				Type:      TypeMarketing,
+				Actor:     event.Actor,
+			},
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				expectPreferences(store, db.NotificationSetting{}, nil)
+				store.EXPECT().CreateNotification(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:  "QueueError",
+			event: event,
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				expectPreferences(store, db.NotificationSetting{}, nil)
+				store.EXPECT().
+					CreateNotification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(notification, nil)
+				store.EXPECT().
+					EnqueueJob(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Job{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				// the notification is in the inbox anyway
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:  "StoreError",
+			event: event,
+			buildStubs: func(t *testing.T, store *mock_db.MockStore, args *PushArgs) {
+				expectPreferences(store, db.NotificationSetting{}, nil)
+				store.EXPECT().
+					CreateNotification(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Notification{}, sql.ErrConnDone)
+				store.EXPECT().EnqueueJob(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, args PushArgs) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var args PushArgs
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(t, store, &args)
+
+			dispatcher := NewDispatcher(store).(*Dispatcher)
+			dispatcher.now = func() time.Time {
+				return time.Date(2023, time.June, 1, 5, 0, 0, 0, time.UTC)
+			}
+			err := dispatcher.Notify(context.Background(), tc.event)
+			tc.checkResponse(t, err, args)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	// the time zones of the users do not depend on the zoneinfo of the host
+	_ "time/tzdata"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// Channels the notifications are delivered through. The email channel only
+// keeps the choice of the user for now, no email is sent yet.
+const (
+	ChannelInApp = "in_app"
+	ChannelPush  = "push"
+	ChannelEmail = "email"
+)
+
+// Types are the notification types the users set their preferences of
+var Types = []string{
+	TypePostCommented,
+	TypePostLiked,
+	TypeConsultancyBooked,
+	TypeMerchantReviewed,
+	TypeMarketing,
+}
+
+// Channels are the channels the users set their preferences of
+var Channels = []string{ChannelInApp, ChannelPush, ChannelEmail}
+
+// DefaultEnabled reports whether the notifications of the type are delivered
+// through the channel when the user has not chosen. The marketing messages
+// are opt-in, everything else is opt-out.
+func DefaultEnabled(eventType, channel string) bool {
+	return eventType != TypeMarketing
+}
+
+// QuietHours is the daily period the notifications are not pushed in. The
+// bounds are minutes after midnight, and the period wraps around midnight
+// when it ends earlier than it starts.
+type QuietHours struct {
+	Start int
+	End   int
+}
+
+// Contains reports whether the clock time of t is in the quiet hours. The end
+// is not in the quiet hours.
+func (quietHours QuietHours) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if quietHours.Start < quietHours.End {
+		return minute >= quietHours.Start && minute < quietHours.End
+	}
+	return minute >= quietHours.Start || minute < quietHours.End
+}
+
+// FormatMinute returns the minute after midnight as HH:MM
+func FormatMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// ParseMinute returns the minute after midnight of the HH:MM clock time
+func ParseMinute(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+type preferenceKey struct {
+	eventType string
+	channel   string
+}
+
+// Preferences are the choices of a user about the notifications
+type Preferences struct {
+	Location *time.Location
+	// QuietHours is nil when the user has no quiet hours
+	QuietHours *QuietHours
+	enabled    map[preferenceKey]bool
+}
+
+// NewPreferences returns the preferences of the stored settings and
+// preferences of a user. An unknown time zone falls back to UTC.
+func NewPreferences(settings db.NotificationSetting, preferences []db.NotificationPreference) Preferences {
+	prefs := Preferences{
+		Location: time.UTC,
+		enabled:  make(map[preferenceKey]bool, len(preferences)),
+	}
+	if location, err := time.LoadLocation(settings.TimeZone); err == nil {
+		prefs.Location = location
+	}
+	if settings.QuietHoursStart.Valid && settings.QuietHoursEnd.Valid {
+		prefs.QuietHours = &QuietHours{
+			Start: int(settings.QuietHoursStart.Int32),
+			End:   int(settings.QuietHoursEnd.Int32),
+		}
+	}
+	for _, preference := range preferences {
+		prefs.enabled[preferenceKey{preference.EventType, preference.Channel}] = preference.Enabled
+	}
+	return prefs
+}
+
+// LoadPreferences returns the preferences of the user, the defaults if the
+// user has not set any
+func LoadPreferences(ctx context.Context, store db.Querier, username string) (Preferences, error) {
+	settings, err := store.GetNotificationSettings(ctx, username)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return Preferences{}, err
+	}
+
+	preferences, err := store.ListNotificationPreferences(ctx, username)
+	if err != nil {
+		return Preferences{}, err
+	}
+
+	return NewPreferences(settings, preferences), nil
+}
+
+// Enabled reports whether the notifications of the type are delivered
+// through the channel
+func (prefs Preferences) Enabled(eventType, channel string) bool {
+	enabled, ok := prefs.enabled[preferenceKey{eventType, channel}]
+	if !ok {
+		return DefaultEnabled(eventType, channel)
+	}
+	return enabled
+}
+
+// Quiet reports whether t is in the quiet hours of the user
+func (prefs Preferences) Quiet(t time.Time) bool {
+	return prefs.QuietHours != nil && prefs.QuietHours.Contains(t.In(prefs.Location))
+}
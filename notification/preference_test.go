@@ -0,0 +1,76 @@
+package notification
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2023, time.June, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name       string
+		quietHours QuietHours
+		time       time.Time
+		expected   bool
+	}{
+		{name: "SameDayInside", quietHours: QuietHours{Start: 13 * 60, End: 15 * 60}, time: at(14, 0), expected: true},
+		{name: "SameDayStart", quietHours: QuietHours{Start: 13 * 60, End: 15 * 60}, time: at(13, 0), expected: true},
+		{name: "SameDayEnd", quietHours: QuietHours{Start: 13 * 60, End: 15 * 60}, time: at(15, 0), expected: false},
+		{name: "OvernightLate", quietHours: QuietHours{Start: 22 * 60, End: 7 * 60}, time: at(23, 30), expected: true},
+		{name: "OvernightEarly", quietHours: QuietHours{Start: 22 * 60, End: 7 * 60}, time: at(6, 59), expected: true},
+		{name: "OvernightOutside", quietHours: QuietHours{Start: 22 * 60, End: 7 * 60}, time: at(12, 0), expected: false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.quietHours.Contains(tc.time))
+		})
+	}
+}
+
+func TestParseMinute(t *testing.T) {
+	minute, err := ParseMinute("07:30")
+	require.NoError(t, err)
+	require.Equal(t, 7*60+30, minute)
+	require.Equal(t, "07:30", FormatMinute(minute))
+
+	_, err = ParseMinute("24:00")
+	require.Error(t, err)
+	_, err = ParseMinute("7pm")
+	require.Error(t, err)
+}
+
+func TestPreferences(t *testing.T) {
+	prefs := NewPreferences(db.NotificationSetting{
+		Username:        "user1",
+		TimeZone:        "America/New_York",
+		QuietHoursStart: sql.NullInt32{Int32: 22 * 60, Valid: true},
+		QuietHoursEnd:   sql.NullInt32{Int32: 7 * 60, Valid: true},
+	}, []db.NotificationPreference{
+		{Username: "user1", EventType: TypePostLiked, Channel: ChannelPush, Enabled: false},
+		{Username: "user1", EventType: TypeMarketing, Channel: ChannelEmail, Enabled: true},
+	})
+
+	require.False(t, prefs.Enabled(TypePostLiked, ChannelPush))
+	require.True(t, prefs.Enabled(TypePostLiked, ChannelInApp))
+	require.True(t, prefs.Enabled(TypeMarketing, ChannelEmail))
+	require.False(t, prefs.Enabled(TypeMarketing, ChannelPush))
+
+	// 03:00 UTC is 23:00 in New York in June
+	require.True(t, prefs.Quiet(time.Date(2023, time.June, 1, 3, 0, 0, 0, time.UTC)))
+	require.False(t, prefs.Quiet(time.Date(2023, time.June, 1, 15, 0, 0, 0, time.UTC)))
+
+	// the defaults have no quiet hours
+	defaults := NewPreferences(db.NotificationSetting{}, nil)
+	require.Equal(t, time.UTC, defaults.Location)
+	require.False(t, defaults.Quiet(time.Date(2023, time.June, 1, 3, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/asdsec/thenut/logger"
+)
+
+// Platforms of the device tokens
+const (
+	PlatformFCM  = "fcm"
+	PlatformAPNs = "apns"
+)
+
+// ErrInvalidToken is returned when the push service no longer accepts the
+// device token, like after the app is uninstalled
+var ErrInvalidToken = errors.New("notification: invalid device token")
+
+// IsInvalidToken reports whether the push failed because of the device token
+func IsInvalidToken(err error) bool {
+	return errors.Is(err, ErrInvalidToken)
+}
+
+// Push is a message to a single device
+type Push struct {
+	Platform string
+	Token    string
+	Title    string
+	Body     string
+	// Data is passed to the app along with the message
+	Data map[string]string
+}
+
+// PushSender is an interface delivering the pushes to the devices
+type PushSender interface {
+	Send(ctx context.Context, push Push) error
+}
+
+// Router is a PushSender sending each push with the sender of its platform
+type Router map[string]PushSender
+
+// Send sends the push with the sender of its platform
+func (router Router) Send(ctx context.Context, push Push) error {
+	sender, ok := router[push.Platform]
+	if !ok {
+		return fmt.Errorf("unsupported push platform %s", push.Platform)
+	}
+	return sender.Send(ctx, push)
+}
+
+// LogSender is a PushSender only logging the pushes, for the development and
+// the deployments without push credentials
+type LogSender struct{}
+
+// NewLogSender creates a new LogSender
+func NewLogSender() PushSender {
+	return &LogSender{}
+}
+
+// Send logs the push
+func (sender *LogSender) Send(ctx context.Context, push Push) error {
+	logger.FromContext(ctx).Info("push notification",
+		"platform", push.Platform,
+		"title", push.Title,
+		"body", push.Body,
+		"data", push.Data,
+	)
+	return nil
+}
@@ -0,0 +1,225 @@
+package notification
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testPush = Push{
+	Token: "device-token",
+	Title: "The Nut",
+	Body:  "customer1 commented on your post",
+	Data:  map[string]string{"type": TypePostCommented, "target": "post:42"},
+}
+
+func TestFCMSender(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   int
+		response string
+		checkErr func(t *testing.T, err error)
+	}{
+		{
+			name:     "OK",
+			status:   http.StatusOK,
+			response: `{"name": "projects/thenut/messages/1"}`,
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "Unregistered",
+			status:   http.StatusNotFound,
+			response: `{"error": {"code": 404, "status": "NOT_FOUND", "details": [{"errorCode": "UNREGISTERED"}]}}`,
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name:     "Unavailable",
+			status:   http.StatusServiceUnavailable,
+			response: `{"error": {"code": 503, "status": "UNAVAILABLE", "message": "try again"}}`,
+			checkErr: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, IsInvalidToken(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodPost, r.Method)
+				require.Equal(t, "/v1/projects/thenut/messages:send", r.URL.Path)
+
+				var req fcmRequest
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				require.Equal(t, testPush.Token, req.Message.Token)
+				require.Equal(t, testPush.Title, req.Message.Notification.Title)
+				require.Equal(t, testPush.Body, req.Message.Notification.Body)
+				require.Equal(t, testPush.Data, req.Message.Data)
+
+				w.WriteHeader(tc.status)
+				io.WriteString(w, tc.response)
+			}))
+			defer server.Close()
+
+			sender := newFCMSender(server.Client(), server.URL+"/v1/projects/thenut/messages:send")
+			tc.checkErr(t, sender.Send(context.Background(), testPush))
+		})
+	}
+}
+
+func TestNewFCMSender(t *testing.T) {
+	_, err := NewFCMSender("thenut", []byte(`{"type": "authorized_user"}`))
+	require.Error(t, err)
+
+	_, err = NewFCMSender("thenut", []byte(`{`))
+	require.Error(t, err)
+}
+
+func TestAPNsSender(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		status   int
+		response string
+		checkErr func(t *testing.T, err error)
+	}{
+		{
+			name:   "OK",
+			status: http.StatusOK,
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:     "Unregistered",
+			status:   http.StatusGone,
+			response: `{"reason": "Unregistered"}`,
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name:     "BadDeviceToken",
+			status:   http.StatusBadRequest,
+			response: `{"reason": "BadDeviceToken"}`,
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrInvalidToken)
+			},
+		},
+		{
+			name:     "TooManyRequests",
+			status:   http.StatusTooManyRequests,
+			response: `{"reason": "TooManyRequests"}`,
+			checkErr: func(t *testing.T, err error) {
+				require.Error(t, err)
+				require.False(t, IsInvalidToken(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, "/3/device/"+testPush.Token, r.URL.Path)
+				require.Equal(t, "com.thenut.app", r.Header.Get("apns-topic"))
+				require.Equal(t, "alert", r.Header.Get("apns-push-type"))
+				requireProviderToken(t, r.Header.Get("Authorization"), &key.PublicKey)
+
+				var payload struct {
+					Aps struct {
+						Alert apnsAlert `json:"alert"`
+					} `json:"aps"`
+					Type   string `json:"type"`
+					Target string `json:"target"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+				require.Equal(t, testPush.Title, payload.Aps.Alert.Title)
+				require.Equal(t, testPush.Body, payload.Aps.Alert.Body)
+				require.Equal(t, TypePostCommented, payload.Type)
+				require.Equal(t, "post:42", payload.Target)
+
+				w.WriteHeader(tc.status)
+				io.WriteString(w, tc.response)
+			}))
+			defer server.Close()
+
+			sender := newAPNsSender(server.Client(), server.URL, key, "KEY123", "TEAM123", "com.thenut.app")
+			tc.checkErr(t, sender.Send(context.Background(), testPush))
+		})
+	}
+}
+
+// requireProviderToken requires the authorization to be a valid ES256 token
+// of the team signed by the key
+func requireProviderToken(t *testing.T, authorization string, key *ecdsa.PublicKey) {
+	t.Helper()
+
+	token := strings.TrimPrefix(authorization, "bearer ")
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{"alg": "ES256", "kid": "KEY123"}`, string(header))
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(claims, &payload))
+	require.Equal(t, "TEAM123", payload["iss"])
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	require.True(t, ecdsa.Verify(key, digest[:], r, s))
+}
+
+func TestNewAPNsSender(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	sender, err := NewAPNsSender(keyPEM, "KEY123", "TEAM123", "com.thenut.app", true)
+	require.NoError(t, err)
+	require.Equal(t, apnsProductionHost, sender.(*APNsSender).host)
+
+	// the provider token is reused until it is about to expire
+	first, err := sender.(*APNsSender).providerToken()
+	require.NoError(t, err)
+	second, err := sender.(*APNsSender).providerToken()
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	_, err = NewAPNsSender([]byte("not a key"), "KEY123", "TEAM123", "com.thenut.app", false)
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/worker"
+)
+
+// PushArgs are the arguments of the PushJob
+type PushArgs struct {
+	// NotificationID is zero when the notification is not in the inbox
+	NotificationID int64  `json:"notification_id"`
+	Recipient      string `json:"recipient"`
+	Type           string `json:"type"`
+	Actor          string `json:"actor"`
+	Target         string `json:"target"`
+}
+
+func newPushArgs(notification db.Notification) PushArgs {
+	return PushArgs{
+		NotificationID: notification.ID,
+		Recipient:      notification.Recipient,
+		Type:           notification.EventType,
+		Actor:          notification.Actor,
+		Target:         notification.Target,
+	}
+}
+
+// PushJob pushes a notification to every device of its recipient
+var PushJob = worker.NewKind[PushArgs]("notification.push")
+
+var errPushFailed = errors.New("cannot push to any device")
+
+// Pusher sends the pushes of the PushJob
+type Pusher struct {
+	store  db.Querier
+	sender PushSender
+}
+
+// NewPusher creates a new Pusher
+func NewPusher(store db.Querier, sender PushSender) *Pusher {
+	return &Pusher{
+		store:  store,
+		sender: sender,
+	}
+}
+
+// Push sends the notification to every device of the recipient in the locale
+// the device is registered with. The tokens the push service rejects are
+// removed, so that they are not tried again. The job is retried only when no
+// device gets the push, so that the devices that got it do not get it twice.
+func (pusher *Pusher) Push(ctx context.Context, args PushArgs) error {
+	log := logger.FromContext(ctx)
+
+	tokens, err := pusher.store.ListUserDeviceTokens(ctx, args.Recipient)
+	if err != nil {
+		return err
+	}
+
+	notification := db.Notification{
+		ID:        args.NotificationID,
+		Recipient: args.Recipient,
+		EventType: args.Type,
+		Actor:     args.Actor,
+		Target:    args.Target,
+	}
+
+	sent, failed := 0, 0
+	for _, token := range tokens {
+		err := pusher.sender.Send(ctx, newPush(token, notification))
+		if err == nil {
+			sent++
+			continue
+		}
+		if !IsInvalidToken(err) {
+			log.Error("cannot push notification", "notification_id", notification.ID, "platform", token.Platform, "err", err)
+			failed++
+			continue
+		}
+
+		err = pusher.store.DeleteDeviceToken(ctx, token.SessionID)
+		if err != nil {
+			log.Error("cannot delete invalid device token", "session_id", token.SessionID, "err", err)
+		}
+	}
+
+	if failed > 0 && sent == 0 {
+		return errPushFailed
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// testSender keeps the pushes in memory and fails the ones of the given tokens
+type testSender struct {
+	pushes []Push
+	errs   map[string]error
+}
+
+func (sender *testSender) Send(ctx context.Context, push Push) error {
+	sender.pushes = append(sender.pushes, push)
+	return sender.errs[push.Token]
+}
+
+func TestPusherPush(t *testing.T) {
+	args := PushArgs{
+		NotificationID: 1,
+		Recipient:      "merchant1",
+		Type:           TypePostCommented,
+		Actor:          "customer1",
+		Target:         PostTarget(42),
+	}
+	enToken := db.DeviceToken{SessionID: uuid.New(), Username: args.Recipient, Platform: PlatformFCM, Token: "fcm-token", Locale: "en"}
+	trToken := db.DeviceToken{SessionID: uuid.New(), Username: args.Recipient, Platform: PlatformAPNs, Token: "apns-token", Locale: "tr"}
+
+	testCases := []struct {
+		name          string
+		args          PushArgs
+		errs          map[string]error
+		buildStubs    func(store *mock_db.MockStore)
+		checkResponse func(t *testing.T, err error, sender *testSender)
+	}{
+		{
+			name: "OK",
+			args: args,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUserDeviceTokens(gomock.Any(), gomock.Eq(args.Recipient)).
+					Times(1).
+					Return([]db.DeviceToken{enToken, trToken}, nil)
+				store.EXPECT().DeleteDeviceToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, sender *testSender) {
+				require.NoError(t, err)
+				require.Len(t, sender.pushes, 2)
+
+				// every device gets the texts of its own locale
+				require.Equal(t, Push{
+					Platform: PlatformFCM,
+					Token:    enToken.Token,
+					Title:    "The Nut",
+					Body:     "customer1 commented on your post",
+					Data: map[string]string{
+						"notification_id": "1",
+						"type":            TypePostCommented,
+						"target":          "post:42",
+					},
+				}, sender.pushes[0])
+				require.Equal(t, PlatformAPNs, sender.pushes[1].Platform)
+				require.Equal(t, "customer1 gönderinize yorum yaptı", sender.pushes[1].Body)
+			},
+		},
+		{
+			name: "NotInInbox",
+			args: PushArgs{Recipient: args.Recipient, Type: args.Type, Actor: args.Actor, Target: args.Target},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUserDeviceTokens(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.DeviceToken{enToken}, nil)
+			},
+			checkResponse: func(t *testing.T, err error, sender *testSender) {
+				require.NoError(t, err)
+				require.Len(t, sender.pushes, 1)
+				require.NotContains(t, sender.pushes[0].Data, "notification_id")
+			},
+		},
+		{
+			name: "InvalidToken",
+			args: args,
+			errs: map[string]error{enToken.Token: ErrInvalidToken},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUserDeviceTokens(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.DeviceToken{enToken, trToken}, nil)
+				store.EXPECT().
+					DeleteDeviceToken(gomock.Any(), gomock.Eq(enToken.SessionID)).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, err error, sender *testSender) {
+				require.NoError(t, err)
+				require.Len(t, sender.pushes, 2)
+			},
+		},
+		{
+			name: "PushError",
+			args: args,
+			errs: map[string]error{enToken.Token: errors.New("unavailable")},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUserDeviceTokens(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.DeviceToken{enToken, trToken}, nil)
+				store.EXPECT().DeleteDeviceToken(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, err error, sender *testSender) {
+				// the other device got it, so the job is not retried
+				require.NoError(t, err)
+				require.Len(t, sender.pushes, 2)
+			},
+		},
+		{
+			name: "EveryPushError",
+			args: args,
+			errs: map[string]error{
+				enToken.Token: errors.New("unavailable"),
+				trToken.Token: errors.New("unavailable"),
+			},
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUserDeviceTokens(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.DeviceToken{enToken, trToken}, nil)
+			},
+			checkResponse: func(t *testing.T, err error, sender *testSender) {
+				require.ErrorIs(t, err, errPushFailed)
+			},
+		},
+		{
+			name: "StoreError",
+			args: args,
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUserDeviceTokens(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, err error, sender *testSender) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Empty(t, sender.pushes)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			sender := &testSender{errs: tc.errs}
+			err := NewPusher(store, sender).Push(context.Background(), tc.args)
+			tc.checkResponse(t, err, sender)
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	fcm := &testSender{}
+	router := Router{PlatformFCM: fcm}
+
+	require.NoError(t, router.Send(context.Background(), Push{Platform: PlatformFCM, Token: "token"}))
+	require.Len(t, fcm.pushes, 1)
+
+	require.Error(t, router.Send(context.Background(), Push{Platform: PlatformAPNs, Token: "token"}))
+}
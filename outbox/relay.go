@@ -0,0 +1,77 @@
+// Package outbox relays the domain events the store transactions append to
+// the outbox to the sinks reacting to them. An event is appended in the
+// transaction of its change, so no event is lost when the process crashes
+// between the change and its delivery.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+)
+
+// Relay delivers the events of the outbox to every sink, in the order they are
+// appended. An event is deleted once every sink has it. When a sink fails, the
+// relay stops at the failed event and delivers it again to every sink after
+// the poll interval, so the later events wait for it.
+type Relay struct {
+	store        db.Store
+	sinks        []Sink
+	batchSize    int32
+	pollInterval time.Duration
+}
+
+// NewRelay creates a new Relay delivering the events in batches of the size.
+// The relay looks for the new events on every poll interval.
+func NewRelay(store db.Store, batchSize int32, pollInterval time.Duration, sinks ...Sink) *Relay {
+	return &Relay{
+		store:        store,
+		sinks:        sinks,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run relays the events until the context is canceled
+func (relay *Relay) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		delivered, err := relay.RelayNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("cannot relay outbox events", "err", err)
+		}
+		if err == nil && delivered == int(relay.batchSize) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(relay.pollInterval):
+		}
+	}
+}
+
+// RelayNext delivers the next batch of the events and returns the number of
+// the delivered ones. The delivery stops when the context is canceled, and
+// the events delivered until then are not delivered again.
+func (relay *Relay) RelayNext(ctx context.Context) (int, error) {
+	// the transaction is rolled back when its context is canceled, which
+	// would deliver the whole batch again
+	return relay.store.RelayOutboxTx(context.WithoutCancel(ctx), db.RelayOutboxTxParams{
+		Limit: relay.batchSize,
+		Deliver: func(event db.OutboxEvent) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			for i, sink := range relay.sinks {
+				if err := sink.Deliver(ctx, event); err != nil {
+					return fmt.Errorf("cannot deliver event %d to sink %d: %w", event.ID, i, err)
+				}
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,153 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// testSink records the aggregates of the delivered events, and fails the
+// deliveries of the aggregates in failing
+type testSink struct {
+	mu        sync.Mutex
+	delivered []string
+	failing   map[string]bool
+}
+
+func (sink *testSink) Deliver(ctx context.Context, event db.OutboxEvent) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if sink.failing[event.Aggregate] {
+		return errors.New("unavailable")
+	}
+	sink.delivered = append(sink.delivered, event.Aggregate)
+	return nil
+}
+
+func (sink *testSink) aggregates() []string {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	return append([]string(nil), sink.delivered...)
+}
+
+// appendEvents appends n events to the outbox, their aggregates are
+// test:0, test:1 and so on
+func appendEvents(t *testing.T, store db.Store, n int) []string {
+	aggregates := make([]string, n)
+	for i := range aggregates {
+		aggregates[i] = fmt.Sprintf("test:%d", i)
+		_, err := db.AppendEvent(context.Background(), store, db.Event{
+			Type:      "test.happened",
+			Aggregate: aggregates[i],
+		})
+		require.NoError(t, err)
+	}
+	return aggregates
+}
+
+func TestRelayNext(t *testing.T) {
+	store := memstore.NewStore()
+	aggregates := appendEvents(t, store, 5)
+
+	first := &testSink{}
+	second := &testSink{}
+	relay := NewRelay(store, 3, time.Millisecond, first, second)
+
+	delivered, err := relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, delivered)
+
+	delivered, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, delivered)
+
+	// every sink gets the events in order, and the delivered events are gone
+	require.Equal(t, aggregates, first.aggregates())
+	require.Equal(t, aggregates, second.aggregates())
+
+	delivered, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, delivered)
+}
+
+func TestRelayNextFailure(t *testing.T) {
+	store := memstore.NewStore()
+	aggregates := appendEvents(t, store, 3)
+
+	first := &testSink{}
+	second := &testSink{failing: map[string]bool{aggregates[1]: true}}
+	relay := NewRelay(store, 10, time.Millisecond, first, second)
+
+	// the relay stops at the failed event, the later ones wait for it
+	delivered, err := relay.RelayNext(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 1, delivered)
+	require.Equal(t, aggregates[:2], first.aggregates())
+	require.Equal(t, aggregates[:1], second.aggregates())
+
+	// the failed event is delivered again to every sink, at least once
+	second.failing = nil
+	delivered, err = relay.RelayNext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, delivered)
+	require.Equal(t, []string{aggregates[0], aggregates[1], aggregates[1], aggregates[2]}, first.aggregates())
+	require.Equal(t, aggregates, second.aggregates())
+}
+
+func TestRelayNextCanceled(t *testing.T) {
+	store := memstore.NewStore()
+	aggregates := appendEvents(t, store, 3)
+
+	// the events delivered before the cancellation are not delivered again
+	ctx, cancel := context.WithCancel(context.Background())
+	sink := &testSink{}
+	relay := NewRelay(store, 10, time.Millisecond, sink, SinkFunc(func(ctx context.Context, event db.OutboxEvent) error {
+		cancel()
+		return nil
+	}))
+
+	delivered, err := relay.RelayNext(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.Equal(t, 1, delivered)
+
+	delivered, err = NewRelay(store, 10, time.Millisecond, sink).RelayNext(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, delivered)
+	require.Equal(t, aggregates, sink.aggregates())
+}
+
+func TestRelayRun(t *testing.T) {
+	store := memstore.NewStore()
+	aggregates := appendEvents(t, store, 10)
+
+	sink := &testSink{}
+	relay := NewRelay(store, 3, time.Millisecond, sink)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(sink.aggregates()) == len(aggregates)
+	}, time.Second, time.Millisecond)
+	require.Equal(t, aggregates, sink.aggregates())
+
+	// the relay stops with the context
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay is not stopped")
+	}
+}
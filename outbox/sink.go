@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+)
+
+// Sink receives the events of the outbox. The events come in the order of
+// their ids and at least once, so a sink has to ignore the events it has
+// already seen, by their ids.
+type Sink interface {
+	Deliver(ctx context.Context, event db.OutboxEvent) error
+}
+
+// SinkFunc is a function used as a Sink
+type SinkFunc func(ctx context.Context, event db.OutboxEvent) error
+
+// Deliver calls the function with the event
+func (fn SinkFunc) Deliver(ctx context.Context, event db.OutboxEvent) error {
+	return fn(ctx, event)
+}
+
+// LogSink is a Sink only logging the events, for the development and until
+// there is a sink reacting to them
+type LogSink struct{}
+
+// NewLogSink creates a new LogSink
+func NewLogSink() Sink {
+	return &LogSink{}
+}
+
+// Deliver logs the event
+func (sink *LogSink) Deliver(ctx context.Context, event db.OutboxEvent) error {
+	logger.FromContext(ctx).Info("outbox event",
+		"event_id", event.ID,
+		"event_type", event.EventType,
+		"aggregate", event.Aggregate,
+	)
+	return nil
+}
@@ -0,0 +1,459 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        (unknown)
+// source: rpc_consultancy.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CreateConsultancyRequest books a consultancy of the merchant for a customer
+// of the authenticated user
+type CreateConsultancyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MerchantId int64 `protobuf:"varint,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	CustomerId int64 `protobuf:"varint,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Cost       int64 `protobuf:"varint,3,opt,name=cost,proto3" json:"cost,omitempty"`
+}
+
+func (x *CreateConsultancyRequest) Reset() {
+	*x = CreateConsultancyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_consultancy_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateConsultancyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateConsultancyRequest) ProtoMessage() {}
+
+func (x *CreateConsultancyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_consultancy_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateConsultancyRequest.ProtoReflect.Descriptor instead.
+func (*CreateConsultancyRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_consultancy_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateConsultancyRequest) GetMerchantId() int64 {
+	if x != nil {
+		return x.MerchantId
+	}
+	return 0
+}
+
+func (x *CreateConsultancyRequest) GetCustomerId() int64 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+func (x *CreateConsultancyRequest) GetCost() int64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+type GetConsultancyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id int64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *GetConsultancyRequest) Reset() {
+	*x = GetConsultancyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_consultancy_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetConsultancyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConsultancyRequest) ProtoMessage() {}
+
+func (x *GetConsultancyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_consultancy_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConsultancyRequest.ProtoReflect.Descriptor instead.
+func (*GetConsultancyRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_consultancy_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetConsultancyRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ConsultancyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Consultancy *Consultancy `protobuf:"bytes,1,opt,name=consultancy,proto3" json:"consultancy,omitempty"`
+}
+
+func (x *ConsultancyResponse) Reset() {
+	*x = ConsultancyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_consultancy_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ConsultancyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsultancyResponse) ProtoMessage() {}
+
+func (x *ConsultancyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_consultancy_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsultancyResponse.ProtoReflect.Descriptor instead.
+func (*ConsultancyResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_consultancy_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConsultancyResponse) GetConsultancy() *Consultancy {
+	if x != nil {
+		return x.Consultancy
+	}
+	return nil
+}
+
+// ListConsultanciesRequest lists the consultancies of a merchant or a customer
+// of the authenticated user, exactly one of them must be set
+type ListConsultanciesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MerchantId int64 `protobuf:"varint,1,opt,name=merchant_id,json=merchantId,proto3" json:"merchant_id,omitempty"`
+	CustomerId int64 `protobuf:"varint,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	PageId     int32 `protobuf:"varint,3,opt,name=page_id,json=pageId,proto3" json:"page_id,omitempty"`
+	PageSize   int32 `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+}
+
+func (x *ListConsultanciesRequest) Reset() {
+	*x = ListConsultanciesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_consultancy_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListConsultanciesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConsultanciesRequest) ProtoMessage() {}
+
+func (x *ListConsultanciesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_consultancy_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConsultanciesRequest.ProtoReflect.Descriptor instead.
+func (*ListConsultanciesRequest) Descriptor() ([]byte, []int) {
+	return file_rpc_consultancy_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListConsultanciesRequest) GetMerchantId() int64 {
+	if x != nil {
+		return x.MerchantId
+	}
+	return 0
+}
+
+func (x *ListConsultanciesRequest) GetCustomerId() int64 {
+	if x != nil {
+		return x.CustomerId
+	}
+	return 0
+}
+
+func (x *ListConsultanciesRequest) GetPageId() int32 {
+	if x != nil {
+		return x.PageId
+	}
+	return 0
+}
+
+func (x *ListConsultanciesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListConsultanciesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Consultancies []*Consultancy `protobuf:"bytes,1,rep,name=consultancies,proto3" json:"consultancies,omitempty"`
+}
+
+func (x *ListConsultanciesResponse) Reset() {
+	*x = ListConsultanciesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_rpc_consultancy_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListConsultanciesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListConsultanciesResponse) ProtoMessage() {}
+
+func (x *ListConsultanciesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_rpc_consultancy_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListConsultanciesResponse.ProtoReflect.Descriptor instead.
+func (*ListConsultanciesResponse) Descriptor() ([]byte, []int) {
+	return file_rpc_consultancy_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListConsultanciesResponse) GetConsultancies() []*Consultancy {
+	if x != nil {
+		return x.Consultancies
+	}
+	return nil
+}
+
+var File_rpc_consultancy_proto protoreflect.FileDescriptor
+
+var file_rpc_consultancy_proto_rawDesc = []byte{
+	0x0a, 0x15, 0x72, 0x70, 0x63, 0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63,
+	0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02, 0x70, 0x62, 0x1a, 0x11, 0x63, 0x6f, 0x6e,
+	0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x70,
+	0x0a, 0x18, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61,
+	0x6e, 0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65,
+	0x72, 0x63, 0x68, 0x61, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0a, 0x6d, 0x65, 0x72, 0x63, 0x68, 0x61, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x63,
+	0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0a, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x63, 0x6f, 0x73, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x63, 0x6f, 0x73, 0x74,
+	0x22, 0x27, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e,
+	0x63, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x48, 0x0a, 0x13, 0x43, 0x6f, 0x6e,
+	0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x31, 0x0a, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75,
+	0x6c, 0x74, 0x61, 0x6e, 0x63, 0x79, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61,
+	0x6e, 0x63, 0x79, 0x22, 0x92, 0x01, 0x0a, 0x18, 0x4c, 0x69, 0x73, 0x74, 0x43, 0x6f, 0x6e, 0x73,
+	0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6d, 0x65, 0x72, 0x63, 0x68, 0x61, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6d, 0x65, 0x72, 0x63, 0x68, 0x61, 0x6e, 0x74, 0x49,
+	0x64, 0x12, 0x1f, 0x0a, 0x0b, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x63, 0x75, 0x73, 0x74, 0x6f, 0x6d, 0x65, 0x72,
+	0x49, 0x64, 0x12, 0x17, 0x0a, 0x07, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x70, 0x61, 0x67, 0x65, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x70,
+	0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08,
+	0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x52, 0x0a, 0x19, 0x4c, 0x69, 0x73, 0x74,
+	0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x0d, 0x63, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74,
+	0x61, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x70,
+	0x62, 0x2e, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x79, 0x52, 0x0d, 0x63,
+	0x6f, 0x6e, 0x73, 0x75, 0x6c, 0x74, 0x61, 0x6e, 0x63, 0x69, 0x65, 0x73, 0x42, 0x1d, 0x5a, 0x1b,
+	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x61, 0x73, 0x64, 0x73, 0x65,
+	0x63, 0x2f, 0x74, 0x68, 0x65, 0x6e, 0x75, 0x74, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
+}
+
+var (
+	file_rpc_consultancy_proto_rawDescOnce sync.Once
+	file_rpc_consultancy_proto_rawDescData = file_rpc_consultancy_proto_rawDesc
+)
+
+func file_rpc_consultancy_proto_rawDescGZIP() []byte {
+	file_rpc_consultancy_proto_rawDescOnce.Do(func() {
+		file_rpc_consultancy_proto_rawDescData = protoimpl.X.CompressGZIP(file_rpc_consultancy_proto_rawDescData)
+	})
+	return file_rpc_consultancy_proto_rawDescData
+}
+
+var file_rpc_consultancy_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_rpc_consultancy_proto_goTypes = []interface{}{
+	(*CreateConsultancyRequest)(nil),  // 0: pb.CreateConsultancyRequest
+	(*GetConsultancyRequest)(nil),     // 1: pb.GetConsultancyRequest
+	(*ConsultancyResponse)(nil),       // 2: pb.ConsultancyResponse
+	(*ListConsultanciesRequest)(nil),  // 3: pb.ListConsultanciesRequest
+	(*ListConsultanciesResponse)(nil), // 4: pb.ListConsultanciesResponse
+	(*Consultancy)(nil),               // 5: pb.Consultancy
+}
+var file_rpc_consultancy_proto_depIdxs = []int32{
+	5, // 0: pb.ConsultancyResponse.consultancy:type_name -> pb.Consultancy
+	5, // 1: pb.ListConsultanciesResponse.consultancies:type_name -> pb.Consultancy
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_rpc_consultancy_proto_init() }
+func file_rpc_consultancy_proto_init() {
+	if File_rpc_consultancy_proto != nil {
+		return
+	}
+	file_consultancy_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_rpc_consultancy_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateConsultancyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_consultancy_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetConsultancyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_consultancy_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ConsultancyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_consultancy_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListConsultanciesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_rpc_consultancy_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ListConsultanciesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_rpc_consultancy_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_rpc_consultancy_proto_goTypes,
+		DependencyIndexes: file_rpc_consultancy_proto_depIdxs,
+		MessageInfos:      file_rpc_consultancy_proto_msgTypes,
+	}.Build()
+	File_rpc_consultancy_proto = out.File
+	file_rpc_consultancy_proto_rawDesc = nil
+	file_rpc_consultancy_proto_goTypes = nil
+	file_rpc_consultancy_proto_depIdxs = nil
+}
@@ -0,0 +1,590 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: service_thenut.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TheNut_RegisterUser_FullMethodName      = "/pb.TheNut/RegisterUser"
+	TheNut_LoginUser_FullMethodName         = "/pb.TheNut/LoginUser"
+	TheNut_RenewAccessToken_FullMethodName  = "/pb.TheNut/RenewAccessToken"
+	TheNut_GetUser_FullMethodName           = "/pb.TheNut/GetUser"
+	TheNut_UpdateUser_FullMethodName        = "/pb.TheNut/UpdateUser"
+	TheNut_CreateMerchant_FullMethodName    = "/pb.TheNut/CreateMerchant"
+	TheNut_GetMerchant_FullMethodName       = "/pb.TheNut/GetMerchant"
+	TheNut_ListMerchants_FullMethodName     = "/pb.TheNut/ListMerchants"
+	TheNut_CreatePost_FullMethodName        = "/pb.TheNut/CreatePost"
+	TheNut_GetPost_FullMethodName           = "/pb.TheNut/GetPost"
+	TheNut_ListPosts_FullMethodName         = "/pb.TheNut/ListPosts"
+	TheNut_CreateConsultancy_FullMethodName = "/pb.TheNut/CreateConsultancy"
+	TheNut_GetConsultancy_FullMethodName    = "/pb.TheNut/GetConsultancy"
+	TheNut_ListConsultancies_FullMethodName = "/pb.TheNut/ListConsultancies"
+)
+
+// TheNutClient is the client API for TheNut service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TheNutClient interface {
+	RegisterUser(ctx context.Context, in *RegisterUserRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	LoginUser(ctx context.Context, in *LoginUserRequest, opts ...grpc.CallOption) (*AuthResponse, error)
+	RenewAccessToken(ctx context.Context, in *RenewAccessTokenRequest, opts ...grpc.CallOption) (*RenewAccessTokenResponse, error)
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UserResponse, error)
+	CreateMerchant(ctx context.Context, in *CreateMerchantRequest, opts ...grpc.CallOption) (*MerchantResponse, error)
+	GetMerchant(ctx context.Context, in *GetMerchantRequest, opts ...grpc.CallOption) (*MerchantResponse, error)
+	ListMerchants(ctx context.Context, in *ListMerchantsRequest, opts ...grpc.CallOption) (*ListMerchantsResponse, error)
+	CreatePost(ctx context.Context, in *CreatePostRequest, opts ...grpc.CallOption) (*PostResponse, error)
+	GetPost(ctx context.Context, in *GetPostRequest, opts ...grpc.CallOption) (*PostResponse, error)
+	ListPosts(ctx context.Context, in *ListPostsRequest, opts ...grpc.CallOption) (*ListPostsResponse, error)
+	CreateConsultancy(ctx context.Context, in *CreateConsultancyRequest, opts ...grpc.CallOption) (*ConsultancyResponse, error)
+	GetConsultancy(ctx context.Context, in *GetConsultancyRequest, opts ...grpc.CallOption) (*ConsultancyResponse, error)
+	ListConsultancies(ctx context.Context, in *ListConsultanciesRequest, opts ...grpc.CallOption) (*ListConsultanciesResponse, error)
+}
+
+type theNutClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTheNutClient(cc grpc.ClientConnInterface) TheNutClient {
+	return &theNutClient{cc}
+}
+
+func (c *theNutClient) RegisterUser(ctx context.Context, in *RegisterUserRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, TheNut_RegisterUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) LoginUser(ctx context.Context, in *LoginUserRequest, opts ...grpc.CallOption) (*AuthResponse, error) {
+	out := new(AuthResponse)
+	err := c.cc.Invoke(ctx, TheNut_LoginUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) RenewAccessToken(ctx context.Context, in *RenewAccessTokenRequest, opts ...grpc.CallOption) (*RenewAccessTokenResponse, error) {
+	out := new(RenewAccessTokenResponse)
+	err := c.cc.Invoke(ctx, TheNut_RenewAccessToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, TheNut_GetUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*UserResponse, error) {
+	out := new(UserResponse)
+	err := c.cc.Invoke(ctx, TheNut_UpdateUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) CreateMerchant(ctx context.Context, in *CreateMerchantRequest, opts ...grpc.CallOption) (*MerchantResponse, error) {
+	out := new(MerchantResponse)
+	err := c.cc.Invoke(ctx, TheNut_CreateMerchant_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) GetMerchant(ctx context.Context, in *GetMerchantRequest, opts ...grpc.CallOption) (*MerchantResponse, error) {
+	out := new(MerchantResponse)
+	err := c.cc.Invoke(ctx, TheNut_GetMerchant_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) ListMerchants(ctx context.Context, in *ListMerchantsRequest, opts ...grpc.CallOption) (*ListMerchantsResponse, error) {
+	out := new(ListMerchantsResponse)
+	err := c.cc.Invoke(ctx, TheNut_ListMerchants_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) CreatePost(ctx context.Context, in *CreatePostRequest, opts ...grpc.CallOption) (*PostResponse, error) {
+	out := new(PostResponse)
+	err := c.cc.Invoke(ctx, TheNut_CreatePost_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) GetPost(ctx context.Context, in *GetPostRequest, opts ...grpc.CallOption) (*PostResponse, error) {
+	out := new(PostResponse)
+	err := c.cc.Invoke(ctx, TheNut_GetPost_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) ListPosts(ctx context.Context, in *ListPostsRequest, opts ...grpc.CallOption) (*ListPostsResponse, error) {
+	out := new(ListPostsResponse)
+	err := c.cc.Invoke(ctx, TheNut_ListPosts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) CreateConsultancy(ctx context.Context, in *CreateConsultancyRequest, opts ...grpc.CallOption) (*ConsultancyResponse, error) {
+	out := new(ConsultancyResponse)
+	err := c.cc.Invoke(ctx, TheNut_CreateConsultancy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) GetConsultancy(ctx context.Context, in *GetConsultancyRequest, opts ...grpc.CallOption) (*ConsultancyResponse, error) {
+	out := new(ConsultancyResponse)
+	err := c.cc.Invoke(ctx, TheNut_GetConsultancy_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *theNutClient) ListConsultancies(ctx context.Context, in *ListConsultanciesRequest, opts ...grpc.CallOption) (*ListConsultanciesResponse, error) {
+	out := new(ListConsultanciesResponse)
+	err := c.cc.Invoke(ctx, TheNut_ListConsultancies_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TheNutServer is the server API for TheNut service.
+// All implementations must embed UnimplementedTheNutServer
+// for forward compatibility
+type TheNutServer interface {
+	RegisterUser(context.Context, *RegisterUserRequest) (*AuthResponse, error)
+	LoginUser(context.Context, *LoginUserRequest) (*AuthResponse, error)
+	RenewAccessToken(context.Context, *RenewAccessTokenRequest) (*RenewAccessTokenResponse, error)
+	GetUser(context.Context, *GetUserRequest) (*UserResponse, error)
+	UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error)
+	CreateMerchant(context.Context, *CreateMerchantRequest) (*MerchantResponse, error)
+	GetMerchant(context.Context, *GetMerchantRequest) (*MerchantResponse, error)
+	ListMerchants(context.Context, *ListMerchantsRequest) (*ListMerchantsResponse, error)
+	CreatePost(context.Context, *CreatePostRequest) (*PostResponse, error)
+	GetPost(context.Context, *GetPostRequest) (*PostResponse, error)
+	ListPosts(context.Context, *ListPostsRequest) (*ListPostsResponse, error)
+	CreateConsultancy(context.Context, *CreateConsultancyRequest) (*ConsultancyResponse, error)
+	GetConsultancy(context.Context, *GetConsultancyRequest) (*ConsultancyResponse, error)
+	ListConsultancies(context.Context, *ListConsultanciesRequest) (*ListConsultanciesResponse, error)
+	mustEmbedUnimplementedTheNutServer()
+}
+
+// UnimplementedTheNutServer must be embedded to have forward compatible implementations.
+type UnimplementedTheNutServer struct {
+}
+
+func (UnimplementedTheNutServer) RegisterUser(context.Context, *RegisterUserRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterUser not implemented")
+}
+func (UnimplementedTheNutServer) LoginUser(context.Context, *LoginUserRequest) (*AuthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginUser not implemented")
+}
+func (UnimplementedTheNutServer) RenewAccessToken(context.Context, *RenewAccessTokenRequest) (*RenewAccessTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenewAccessToken not implemented")
+}
+func (UnimplementedTheNutServer) GetUser(context.Context, *GetUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedTheNutServer) UpdateUser(context.Context, *UpdateUserRequest) (*UserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
+}
+func (UnimplementedTheNutServer) CreateMerchant(context.Context, *CreateMerchantRequest) (*MerchantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMerchant not implemented")
+}
+func (UnimplementedTheNutServer) GetMerchant(context.Context, *GetMerchantRequest) (*MerchantResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMerchant not implemented")
+}
+func (UnimplementedTheNutServer) ListMerchants(context.Context, *ListMerchantsRequest) (*ListMerchantsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMerchants not implemented")
+}
+func (UnimplementedTheNutServer) CreatePost(context.Context, *CreatePostRequest) (*PostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePost not implemented")
+}
+func (UnimplementedTheNutServer) GetPost(context.Context, *GetPostRequest) (*PostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPost not implemented")
+}
+func (UnimplementedTheNutServer) ListPosts(context.Context, *ListPostsRequest) (*ListPostsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPosts not implemented")
+}
+func (UnimplementedTheNutServer) CreateConsultancy(context.Context, *CreateConsultancyRequest) (*ConsultancyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateConsultancy not implemented")
+}
+func (UnimplementedTheNutServer) GetConsultancy(context.Context, *GetConsultancyRequest) (*ConsultancyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConsultancy not implemented")
+}
+func (UnimplementedTheNutServer) ListConsultancies(context.Context, *ListConsultanciesRequest) (*ListConsultanciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConsultancies not implemented")
+}
+func (UnimplementedTheNutServer) mustEmbedUnimplementedTheNutServer() {}
+
+// UnsafeTheNutServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TheNutServer will
+// result in compilation errors.
+type UnsafeTheNutServer interface {
+	mustEmbedUnimplementedTheNutServer()
+}
+
+func RegisterTheNutServer(s grpc.ServiceRegistrar, srv TheNutServer) {
+	s.RegisterService(&TheNut_ServiceDesc, srv)
+}
+
+func _TheNut_RegisterUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).RegisterUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_RegisterUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).RegisterUser(ctx, req.(*RegisterUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_LoginUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).LoginUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_LoginUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).LoginUser(ctx, req.(*LoginUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_RenewAccessToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenewAccessTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).RenewAccessToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_RenewAccessToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).RenewAccessToken(ctx, req.(*RenewAccessTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).UpdateUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_UpdateUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).UpdateUser(ctx, req.(*UpdateUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_CreateMerchant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateMerchantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).CreateMerchant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_CreateMerchant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).CreateMerchant(ctx, req.(*CreateMerchantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_GetMerchant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMerchantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).GetMerchant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_GetMerchant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).GetMerchant(ctx, req.(*GetMerchantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_ListMerchants_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMerchantsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).ListMerchants(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_ListMerchants_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).ListMerchants(ctx, req.(*ListMerchantsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_CreatePost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreatePostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).CreatePost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_CreatePost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).CreatePost(ctx, req.(*CreatePostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_GetPost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).GetPost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_GetPost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).GetPost(ctx, req.(*GetPostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_ListPosts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPostsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).ListPosts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_ListPosts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).ListPosts(ctx, req.(*ListPostsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_CreateConsultancy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateConsultancyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).CreateConsultancy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_CreateConsultancy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).CreateConsultancy(ctx, req.(*CreateConsultancyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_GetConsultancy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConsultancyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).GetConsultancy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_GetConsultancy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).GetConsultancy(ctx, req.(*GetConsultancyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TheNut_ListConsultancies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConsultanciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TheNutServer).ListConsultancies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TheNut_ListConsultancies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TheNutServer).ListConsultancies(ctx, req.(*ListConsultanciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TheNut_ServiceDesc is the grpc.ServiceDesc for TheNut service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TheNut_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.TheNut",
+	HandlerType: (*TheNutServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterUser",
+			Handler:    _TheNut_RegisterUser_Handler,
+		},
+		{
+			MethodName: "LoginUser",
+			Handler:    _TheNut_LoginUser_Handler,
+		},
+		{
+			MethodName: "RenewAccessToken",
+			Handler:    _TheNut_RenewAccessToken_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _TheNut_GetUser_Handler,
+		},
+		{
+			MethodName: "UpdateUser",
+			Handler:    _TheNut_UpdateUser_Handler,
+		},
+		{
+			MethodName: "CreateMerchant",
+			Handler:    _TheNut_CreateMerchant_Handler,
+		},
+		{
+			MethodName: "GetMerchant",
+			Handler:    _TheNut_GetMerchant_Handler,
+		},
+		{
+			MethodName: "ListMerchants",
+			Handler:    _TheNut_ListMerchants_Handler,
+		},
+		{
+			MethodName: "CreatePost",
+			Handler:    _TheNut_CreatePost_Handler,
+		},
+		{
+			MethodName: "GetPost",
+			Handler:    _TheNut_GetPost_Handler,
+		},
+		{
+			MethodName: "ListPosts",
+			Handler:    _TheNut_ListPosts_Handler,
+		},
+		{
+			MethodName: "CreateConsultancy",
+			Handler:    _TheNut_CreateConsultancy_Handler,
+		},
+		{
+			MethodName: "GetConsultancy",
+			Handler:    _TheNut_GetConsultancy_Handler,
+		},
+		{
+			MethodName: "ListConsultancies",
+			Handler:    _TheNut_ListConsultancies_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "service_thenut.proto",
+}
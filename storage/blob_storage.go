@@ -0,0 +1,28 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInvalidKey is returned when a key would escape the storage root
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// ErrNotFound is returned when nothing is stored under a key
+var ErrNotFound = errors.New("storage: not found")
+
+// BlobStorage is an interface managing uploaded files
+type BlobStorage interface {
+	// Put stores the content under the given key and returns its public URL
+	Put(ctx context.Context, key string, contentType string, content io.Reader, size int64) (string, error)
+
+	// Get opens the content stored under the given key, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the content stored under the given key
+	Delete(ctx context.Context, key string) error
+
+	// DeleteFolder removes everything stored under the given folder
+	DeleteFolder(ctx context.Context, folder string) error
+}
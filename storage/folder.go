@@ -0,0 +1,26 @@
+package storage
+
+import "fmt"
+
+// The uploads are stored in the folder of the row showing them, so that the
+// uploads of a deleted account can be removed folder by folder.
+
+// UserFolder holds the avatars of the user
+func UserFolder(username string) string {
+	return "users/" + username
+}
+
+// MerchantFolder holds the images of the merchant
+func MerchantFolder(id int64) string {
+	return fmt.Sprintf("merchants/%d", id)
+}
+
+// CustomerFolder holds the images of the customer
+func CustomerFolder(id int64) string {
+	return fmt.Sprintf("customers/%d", id)
+}
+
+// PostFolder holds the images of every post of the merchant
+func PostFolder(merchantID int64) string {
+	return fmt.Sprintf("posts/%d", merchantID)
+}
@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStorage is a BlobStorage writing files to the local filesystem
+type LocalStorage struct {
+	root      string
+	publicURL string
+}
+
+// NewLocalStorage creates a new LocalStorage rooted at the given directory
+func NewLocalStorage(root string, publicURL string) (BlobStorage, error) {
+	err := os.MkdirAll(root, 0o755)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create storage directory: %w", err)
+	}
+
+	storage := &LocalStorage{
+		root:      root,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+	return storage, nil
+}
+
+// Put writes the content to a file under the storage root
+func (storage *LocalStorage) Put(ctx context.Context, key string, contentType string, content io.Reader, size int64) (string, error) {
+	name, err := storage.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(filepath.Dir(name), 0o755)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(file.Name(), name)
+	if err != nil {
+		return "", err
+	}
+
+	return storage.publicURL + "/" + path.Clean(key), nil
+}
+
+// Get opens the file stored under the given key
+func (storage *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := storage.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// Delete removes the file stored under the given key
+func (storage *LocalStorage) Delete(ctx context.Context, key string) error {
+	name, err := storage.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(name)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteFolder removes the directory of the folder with all of its files
+func (storage *LocalStorage) DeleteFolder(ctx context.Context, folder string) error {
+	name, err := storage.path(folder)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(name)
+}
+
+func (storage *LocalStorage) path(key string) (string, error) {
+	cleaned := path.Clean("/" + key)
+	if cleaned == "/" || cleaned != "/"+key {
+		return "", ErrInvalidKey
+	}
+	return filepath.Join(storage.root, filepath.FromSlash(cleaned)), nil
+}
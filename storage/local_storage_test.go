@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/asdsec/thenut/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStoragePut(t *testing.T) {
+	root := t.TempDir()
+	storage, err := NewLocalStorage(root, "/media/")
+	require.NoError(t, err)
+
+	content := []byte(utils.RandomString(32))
+	key := "users/" + utils.RandomOwner() + "/avatar.png"
+
+	url, err := storage.Put(context.Background(), key, "image/png", bytes.NewReader(content), int64(len(content)))
+	require.NoError(t, err)
+	require.Equal(t, "/media/"+key, url)
+
+	stored, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(key)))
+	require.NoError(t, err)
+	require.Equal(t, content, stored)
+
+	reader, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	got, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+	require.Equal(t, content, got)
+
+	err = storage.Delete(context.Background(), key)
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(root, filepath.FromSlash(key)))
+	require.True(t, os.IsNotExist(err))
+
+	err = storage.Delete(context.Background(), key)
+	require.NoError(t, err)
+
+	_, err = storage.Get(context.Background(), key)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalStorageDeleteFolder(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	folder := UserFolder(utils.RandomOwner())
+	other := UserFolder(utils.RandomOwner())
+	keys := []string{folder + "/avatar.jpg", folder + "/avatar_small.jpg", other + "/avatar.jpg"}
+	for _, key := range keys {
+		_, err = storage.Put(context.Background(), key, "image/jpeg", bytes.NewReader(nil), 0)
+		require.NoError(t, err)
+	}
+
+	err = storage.DeleteFolder(context.Background(), folder)
+	require.NoError(t, err)
+	for _, key := range keys[:2] {
+		_, err = storage.Get(context.Background(), key)
+		require.ErrorIs(t, err, ErrNotFound)
+	}
+	reader, err := storage.Get(context.Background(), keys[2])
+	require.NoError(t, err)
+	require.NoError(t, reader.Close())
+
+	// a missing folder is already deleted
+	err = storage.DeleteFolder(context.Background(), folder)
+	require.NoError(t, err)
+
+	err = storage.DeleteFolder(context.Background(), "../users")
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestLocalStorageInvalidKey(t *testing.T) {
+	storage, err := NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "users/../../secret", "/absolute", "users//avatar.png"} {
+		_, err = storage.Put(context.Background(), key, "image/png", bytes.NewReader(nil), 0)
+		require.ErrorIs(t, err, ErrInvalidKey, key)
+	}
+}
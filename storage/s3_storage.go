@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage is a BlobStorage writing objects to an S3-compatible API
+type S3Storage struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+// NewS3Storage creates a new S3Storage for the given endpoint and bucket
+func NewS3Storage(endpoint, accessKeyID, secretAccessKey, bucket string, useSSL bool, publicURL string) (BlobStorage, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create s3 client: %w", err)
+	}
+
+	if publicURL == "" {
+		scheme := "http"
+		if useSSL {
+			scheme = "https"
+		}
+		publicURL = (&url.URL{Scheme: scheme, Host: endpoint, Path: "/" + bucket}).String()
+	}
+
+	storage := &S3Storage{
+		client:    client,
+		bucket:    bucket,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+	return storage, nil
+}
+
+// Put uploads the content as an object to the bucket
+func (storage *S3Storage) Put(ctx context.Context, key string, contentType string, content io.Reader, size int64) (string, error) {
+	_, err := storage.client.PutObject(ctx, storage.bucket, key, content, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		return "", err
+	}
+	return storage.publicURL + "/" + key, nil
+}
+
+// Get downloads the object from the bucket
+func (storage *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := storage.client.GetObject(ctx, storage.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	// the request is only sent on the first read, stat it to report a missing key
+	_, err = object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return object, nil
+}
+
+// Delete removes the object from the bucket
+func (storage *S3Storage) Delete(ctx context.Context, key string) error {
+	return storage.client.RemoveObject(ctx, storage.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// DeleteFolder removes every object whose key starts with the folder
+func (storage *S3Storage) DeleteFolder(ctx context.Context, folder string) error {
+	objects := make(chan minio.ObjectInfo)
+	listed := make(chan struct{})
+	var listErr error
+	go func() {
+		defer close(listed)
+		defer close(objects)
+		for object := range storage.client.ListObjects(ctx, storage.bucket, minio.ListObjectsOptions{
+			Prefix:    folder + "/",
+			Recursive: true,
+		}) {
+			if object.Err != nil {
+				listErr = object.Err
+				return
+			}
+			select {
+			case objects <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// the removal ends once the listing closes the objects
+	var removeErr error
+	for result := range storage.client.RemoveObjects(ctx, storage.bucket, objects, minio.RemoveObjectsOptions{}) {
+		if removeErr == nil {
+			removeErr = result.Err
+		}
+	}
+	<-listed
+	if listErr != nil {
+		return listErr
+	}
+	return removeErr
+}
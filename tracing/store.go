@@ -0,0 +1,763 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedStore is a db.Store that starts a span for every call of the wrapped
+// store, named after the sqlc query. A method missing here is still served by
+// the wrapped store, only without the span.
+type TracedStore struct {
+	db.Store
+	tracer trace.Tracer
+}
+
+// NewTracedStore wraps the store with the tracing. The spans are children of
+// the span in the context of the call.
+func NewTracedStore(store db.Store) db.Store {
+	return &TracedStore{
+		Store:  store,
+		tracer: Tracer(),
+	}
+}
+
+// start starts the span of a store call
+func (store *TracedStore) start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return store.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperation(name)),
+	)
+}
+
+// end records the error of the store call and ends its span. sql.ErrNoRows is
+// not an error of the span since it is an expected result of the lookups.
+func end(span trace.Span, err *error) {
+	if *err != nil && !errors.Is(*err, sql.ErrNoRows) {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+func (store *TracedStore) AddMerchantBalance(ctx context.Context, arg db.AddMerchantBalanceParams) (result db.Merchant, err error) {
+	ctx, span := store.start(ctx, "AddMerchantBalance")
+	defer end(span, &err)
+	return store.Store.AddMerchantBalance(ctx, arg)
+}
+
+func (store *TracedStore) AnonymizeUserComments(ctx context.Context, owner string) (err error) {
+	ctx, span := store.start(ctx, "AnonymizeUserComments")
+	defer end(span, &err)
+	return store.Store.AnonymizeUserComments(ctx, owner)
+}
+
+func (store *TracedStore) AnonymizeUserCustomers(ctx context.Context, owner string) (result []int64, err error) {
+	ctx, span := store.start(ctx, "AnonymizeUserCustomers")
+	defer end(span, &err)
+	return store.Store.AnonymizeUserCustomers(ctx, owner)
+}
+
+func (store *TracedStore) AnonymizeUserMerchants(ctx context.Context, owner string) (result []int64, err error) {
+	ctx, span := store.start(ctx, "AnonymizeUserMerchants")
+	defer end(span, &err)
+	return store.Store.AnonymizeUserMerchants(ctx, owner)
+}
+
+func (store *TracedStore) AnonymizeUserNotifications(ctx context.Context, actor string) (err error) {
+	ctx, span := store.start(ctx, "AnonymizeUserNotifications")
+	defer end(span, &err)
+	return store.Store.AnonymizeUserNotifications(ctx, actor)
+}
+
+func (store *TracedStore) AnonymizeUserPostRevisions(ctx context.Context, editor string) (err error) {
+	ctx, span := store.start(ctx, "AnonymizeUserPostRevisions")
+	defer end(span, &err)
+	return store.Store.AnonymizeUserPostRevisions(ctx, editor)
+}
+
+func (store *TracedStore) AppendOutboxEvent(ctx context.Context, arg db.AppendOutboxEventParams) (result db.OutboxEvent, err error) {
+	ctx, span := store.start(ctx, "AppendOutboxEvent")
+	defer end(span, &err)
+	return store.Store.AppendOutboxEvent(ctx, arg)
+}
+
+func (store *TracedStore) AuditTx(ctx context.Context, arg db.AuditTxParams) (result db.AuditEvent, err error) {
+	ctx, span := store.start(ctx, "AuditTx")
+	defer end(span, &err)
+	return store.Store.AuditTx(ctx, arg)
+}
+
+func (store *TracedStore) BlockUserSessions(ctx context.Context, username string) (result int64, err error) {
+	ctx, span := store.start(ctx, "BlockUserSessions")
+	defer end(span, &err)
+	return store.Store.BlockUserSessions(ctx, username)
+}
+
+func (store *TracedStore) ClaimJobs(ctx context.Context, arg db.ClaimJobsParams) (result []db.Job, err error) {
+	ctx, span := store.start(ctx, "ClaimJobs")
+	defer end(span, &err)
+	return store.Store.ClaimJobs(ctx, arg)
+}
+
+func (store *TracedStore) CompleteIdempotencyKey(ctx context.Context, arg db.CompleteIdempotencyKeyParams) (result db.IdempotencyKey, err error) {
+	ctx, span := store.start(ctx, "CompleteIdempotencyKey")
+	defer end(span, &err)
+	return store.Store.CompleteIdempotencyKey(ctx, arg)
+}
+
+func (store *TracedStore) CompleteJob(ctx context.Context, arg db.CompleteJobParams) (result int64, err error) {
+	ctx, span := store.start(ctx, "CompleteJob")
+	defer end(span, &err)
+	return store.Store.CompleteJob(ctx, arg)
+}
+
+func (store *TracedStore) CountUnreadNotifications(ctx context.Context, recipient string) (result int64, err error) {
+	ctx, span := store.start(ctx, "CountUnreadNotifications")
+	defer end(span, &err)
+	return store.Store.CountUnreadNotifications(ctx, recipient)
+}
+
+func (store *TracedStore) CreateAppVersion(ctx context.Context, arg db.CreateAppVersionParams) (result db.AppVersion, err error) {
+	ctx, span := store.start(ctx, "CreateAppVersion")
+	defer end(span, &err)
+	return store.Store.CreateAppVersion(ctx, arg)
+}
+
+func (store *TracedStore) CreateAuditEvent(ctx context.Context, arg db.CreateAuditEventParams) (result db.AuditEvent, err error) {
+	ctx, span := store.start(ctx, "CreateAuditEvent")
+	defer end(span, &err)
+	return store.Store.CreateAuditEvent(ctx, arg)
+}
+
+func (store *TracedStore) CreateComment(ctx context.Context, arg db.CreateCommentParams) (result db.Comment, err error) {
+	ctx, span := store.start(ctx, "CreateComment")
+	defer end(span, &err)
+	return store.Store.CreateComment(ctx, arg)
+}
+
+func (store *TracedStore) CreateCommentTx(ctx context.Context, arg db.CreateCommentParams) (result db.Comment, err error) {
+	ctx, span := store.start(ctx, "CreateCommentTx")
+	defer end(span, &err)
+	return store.Store.CreateCommentTx(ctx, arg)
+}
+
+func (store *TracedStore) CreateConsultancy(ctx context.Context, arg db.CreateConsultancyParams) (result db.Consultancy, err error) {
+	ctx, span := store.start(ctx, "CreateConsultancy")
+	defer end(span, &err)
+	return store.Store.CreateConsultancy(ctx, arg)
+}
+
+func (store *TracedStore) CreateConsultancyTx(ctx context.Context, arg db.CreateConsultancyParams) (result db.Consultancy, err error) {
+	ctx, span := store.start(ctx, "CreateConsultancyTx")
+	defer end(span, &err)
+	return store.Store.CreateConsultancyTx(ctx, arg)
+}
+
+func (store *TracedStore) CreateCustomer(ctx context.Context, owner string) (result db.Customer, err error) {
+	ctx, span := store.start(ctx, "CreateCustomer")
+	defer end(span, &err)
+	return store.Store.CreateCustomer(ctx, owner)
+}
+
+func (store *TracedStore) CreateIdempotencyKey(ctx context.Context, arg db.CreateIdempotencyKeyParams) (result db.IdempotencyKey, err error) {
+	ctx, span := store.start(ctx, "CreateIdempotencyKey")
+	defer end(span, &err)
+	return store.Store.CreateIdempotencyKey(ctx, arg)
+}
+
+func (store *TracedStore) CreateMerchant(ctx context.Context, arg db.CreateMerchantParams) (result db.Merchant, err error) {
+	ctx, span := store.start(ctx, "CreateMerchant")
+	defer end(span, &err)
+	return store.Store.CreateMerchant(ctx, arg)
+}
+
+func (store *TracedStore) CreateNotification(ctx context.Context, arg db.CreateNotificationParams) (result db.Notification, err error) {
+	ctx, span := store.start(ctx, "CreateNotification")
+	defer end(span, &err)
+	return store.Store.CreateNotification(ctx, arg)
+}
+
+func (store *TracedStore) CreatePost(ctx context.Context, arg db.CreatePostParams) (result db.Post, err error) {
+	ctx, span := store.start(ctx, "CreatePost")
+	defer end(span, &err)
+	return store.Store.CreatePost(ctx, arg)
+}
+
+func (store *TracedStore) CreatePostRevision(ctx context.Context, arg db.CreatePostRevisionParams) (result db.PostRevision, err error) {
+	ctx, span := store.start(ctx, "CreatePostRevision")
+	defer end(span, &err)
+	return store.Store.CreatePostRevision(ctx, arg)
+}
+
+func (store *TracedStore) CreatePostTx(ctx context.Context, arg db.CreatePostParams) (result db.Post, err error) {
+	ctx, span := store.start(ctx, "CreatePostTx")
+	defer end(span, &err)
+	return store.Store.CreatePostTx(ctx, arg)
+}
+
+func (store *TracedStore) CreateSession(ctx context.Context, arg db.CreateSessionParams) (err error) {
+	ctx, span := store.start(ctx, "CreateSession")
+	defer end(span, &err)
+	return store.Store.CreateSession(ctx, arg)
+}
+
+func (store *TracedStore) CreateUser(ctx context.Context, arg db.CreateUserParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "CreateUser")
+	defer end(span, &err)
+	return store.Store.CreateUser(ctx, arg)
+}
+
+func (store *TracedStore) CreateUserTx(ctx context.Context, arg db.CreateUserParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "CreateUserTx")
+	defer end(span, &err)
+	return store.Store.CreateUserTx(ctx, arg)
+}
+
+func (store *TracedStore) DeleteComment(ctx context.Context, id int64) (err error) {
+	ctx, span := store.start(ctx, "DeleteComment")
+	defer end(span, &err)
+	return store.Store.DeleteComment(ctx, id)
+}
+
+func (store *TracedStore) DeleteCustomer(ctx context.Context, id int64) (err error) {
+	ctx, span := store.start(ctx, "DeleteCustomer")
+	defer end(span, &err)
+	return store.Store.DeleteCustomer(ctx, id)
+}
+
+func (store *TracedStore) DeleteDeviceToken(ctx context.Context, sessionID uuid.UUID) (err error) {
+	ctx, span := store.start(ctx, "DeleteDeviceToken")
+	defer end(span, &err)
+	return store.Store.DeleteDeviceToken(ctx, sessionID)
+}
+
+func (store *TracedStore) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (result int64, err error) {
+	ctx, span := store.start(ctx, "DeleteExpiredIdempotencyKeys")
+	defer end(span, &err)
+	return store.Store.DeleteExpiredIdempotencyKeys(ctx, now)
+}
+
+func (store *TracedStore) DeleteIdempotencyKey(ctx context.Context, arg db.DeleteIdempotencyKeyParams) (err error) {
+	ctx, span := store.start(ctx, "DeleteIdempotencyKey")
+	defer end(span, &err)
+	return store.Store.DeleteIdempotencyKey(ctx, arg)
+}
+
+func (store *TracedStore) DeleteMerchant(ctx context.Context, id int64) (err error) {
+	ctx, span := store.start(ctx, "DeleteMerchant")
+	defer end(span, &err)
+	return store.Store.DeleteMerchant(ctx, id)
+}
+
+func (store *TracedStore) DeleteOutboxEvent(ctx context.Context, id int64) (err error) {
+	ctx, span := store.start(ctx, "DeleteOutboxEvent")
+	defer end(span, &err)
+	return store.Store.DeleteOutboxEvent(ctx, id)
+}
+
+func (store *TracedStore) DeleteOwnerPosts(ctx context.Context, owner string) (err error) {
+	ctx, span := store.start(ctx, "DeleteOwnerPosts")
+	defer end(span, &err)
+	return store.Store.DeleteOwnerPosts(ctx, owner)
+}
+
+func (store *TracedStore) DeletePost(ctx context.Context, id int64) (err error) {
+	ctx, span := store.start(ctx, "DeletePost")
+	defer end(span, &err)
+	return store.Store.DeletePost(ctx, id)
+}
+
+func (store *TracedStore) DeleteUser(ctx context.Context, username string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUser")
+	defer end(span, &err)
+	return store.Store.DeleteUser(ctx, username)
+}
+
+func (store *TracedStore) DeleteUserDeviceTokens(ctx context.Context, username string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUserDeviceTokens")
+	defer end(span, &err)
+	return store.Store.DeleteUserDeviceTokens(ctx, username)
+}
+
+func (store *TracedStore) DeleteUserIdempotencyKeys(ctx context.Context, username string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUserIdempotencyKeys")
+	defer end(span, &err)
+	return store.Store.DeleteUserIdempotencyKeys(ctx, username)
+}
+
+func (store *TracedStore) DeleteUserNotificationPreferences(ctx context.Context, username string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUserNotificationPreferences")
+	defer end(span, &err)
+	return store.Store.DeleteUserNotificationPreferences(ctx, username)
+}
+
+func (store *TracedStore) DeleteUserNotificationSettings(ctx context.Context, username string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUserNotificationSettings")
+	defer end(span, &err)
+	return store.Store.DeleteUserNotificationSettings(ctx, username)
+}
+
+func (store *TracedStore) DeleteUserNotifications(ctx context.Context, recipient string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUserNotifications")
+	defer end(span, &err)
+	return store.Store.DeleteUserNotifications(ctx, recipient)
+}
+
+func (store *TracedStore) DeleteUserSessions(ctx context.Context, username string) (err error) {
+	ctx, span := store.start(ctx, "DeleteUserSessions")
+	defer end(span, &err)
+	return store.Store.DeleteUserSessions(ctx, username)
+}
+
+func (store *TracedStore) DeleteUserTx(ctx context.Context, username string) (result db.DeleteUserTxResult, err error) {
+	ctx, span := store.start(ctx, "DeleteUserTx")
+	defer end(span, &err)
+	return store.Store.DeleteUserTx(ctx, username)
+}
+
+func (store *TracedStore) EnqueueJob(ctx context.Context, arg db.EnqueueJobParams) (result db.Job, err error) {
+	ctx, span := store.start(ctx, "EnqueueJob")
+	defer end(span, &err)
+	return store.Store.EnqueueJob(ctx, arg)
+}
+
+func (store *TracedStore) ExportUserTx(ctx context.Context, username string) (result db.ExportUserTxResult, err error) {
+	ctx, span := store.start(ctx, "ExportUserTx")
+	defer end(span, &err)
+	return store.Store.ExportUserTx(ctx, username)
+}
+
+func (store *TracedStore) GetAppVersion(ctx context.Context, tag string) (result db.AppVersion, err error) {
+	ctx, span := store.start(ctx, "GetAppVersion")
+	defer end(span, &err)
+	return store.Store.GetAppVersion(ctx, tag)
+}
+
+func (store *TracedStore) GetComment(ctx context.Context, id int64) (result db.Comment, err error) {
+	ctx, span := store.start(ctx, "GetComment")
+	defer end(span, &err)
+	return store.Store.GetComment(ctx, id)
+}
+
+func (store *TracedStore) GetConsultancy(ctx context.Context, id int64) (result db.Consultancy, err error) {
+	ctx, span := store.start(ctx, "GetConsultancy")
+	defer end(span, &err)
+	return store.Store.GetConsultancy(ctx, id)
+}
+
+func (store *TracedStore) GetCustomer(ctx context.Context, id int64) (result db.Customer, err error) {
+	ctx, span := store.start(ctx, "GetCustomer")
+	defer end(span, &err)
+	return store.Store.GetCustomer(ctx, id)
+}
+
+func (store *TracedStore) GetIdempotencyKey(ctx context.Context, arg db.GetIdempotencyKeyParams) (result db.IdempotencyKey, err error) {
+	ctx, span := store.start(ctx, "GetIdempotencyKey")
+	defer end(span, &err)
+	return store.Store.GetIdempotencyKey(ctx, arg)
+}
+
+func (store *TracedStore) GetMerchant(ctx context.Context, id int64) (result db.Merchant, err error) {
+	ctx, span := store.start(ctx, "GetMerchant")
+	defer end(span, &err)
+	return store.Store.GetMerchant(ctx, id)
+}
+
+func (store *TracedStore) GetNotificationSettings(ctx context.Context, username string) (result db.NotificationSetting, err error) {
+	ctx, span := store.start(ctx, "GetNotificationSettings")
+	defer end(span, &err)
+	return store.Store.GetNotificationSettings(ctx, username)
+}
+
+func (store *TracedStore) GetPost(ctx context.Context, id int64) (result db.Post, err error) {
+	ctx, span := store.start(ctx, "GetPost")
+	defer end(span, &err)
+	return store.Store.GetPost(ctx, id)
+}
+
+func (store *TracedStore) GetPostForUpdate(ctx context.Context, id int64) (result db.Post, err error) {
+	ctx, span := store.start(ctx, "GetPostForUpdate")
+	defer end(span, &err)
+	return store.Store.GetPostForUpdate(ctx, id)
+}
+
+func (store *TracedStore) GetSchemaVersion(ctx context.Context) (result db.SchemaVersion, err error) {
+	ctx, span := store.start(ctx, "GetSchemaVersion")
+	defer end(span, &err)
+	return store.Store.GetSchemaVersion(ctx)
+}
+
+func (store *TracedStore) GetSession(ctx context.Context, id uuid.UUID) (result db.Session, err error) {
+	ctx, span := store.start(ctx, "GetSession")
+	defer end(span, &err)
+	return store.Store.GetSession(ctx, id)
+}
+
+func (store *TracedStore) GetUser(ctx context.Context, username string) (result db.User, err error) {
+	ctx, span := store.start(ctx, "GetUser")
+	defer end(span, &err)
+	return store.Store.GetUser(ctx, username)
+}
+
+func (store *TracedStore) KillJob(ctx context.Context, arg db.KillJobParams) (result db.Job, err error) {
+	ctx, span := store.start(ctx, "KillJob")
+	defer end(span, &err)
+	return store.Store.KillJob(ctx, arg)
+}
+
+func (store *TracedStore) KillStaleJobs(ctx context.Context, arg db.KillStaleJobsParams) (result []db.Job, err error) {
+	ctx, span := store.start(ctx, "KillStaleJobs")
+	defer end(span, &err)
+	return store.Store.KillStaleJobs(ctx, arg)
+}
+
+func (store *TracedStore) ListAllUserDeviceTokens(ctx context.Context, username string) (result []db.DeviceToken, err error) {
+	ctx, span := store.start(ctx, "ListAllUserDeviceTokens")
+	defer end(span, &err)
+	return store.Store.ListAllUserDeviceTokens(ctx, username)
+}
+
+func (store *TracedStore) ListAppVersions(ctx context.Context) (result []db.AppVersion, err error) {
+	ctx, span := store.start(ctx, "ListAppVersions")
+	defer end(span, &err)
+	return store.Store.ListAppVersions(ctx)
+}
+
+func (store *TracedStore) ListAuditEvents(ctx context.Context, arg db.ListAuditEventsParams) (result []db.AuditEvent, err error) {
+	ctx, span := store.start(ctx, "ListAuditEvents")
+	defer end(span, &err)
+	return store.Store.ListAuditEvents(ctx, arg)
+}
+
+func (store *TracedStore) ListAuditEventsByCursor(ctx context.Context, arg db.ListAuditEventsByCursorParams) (result []db.AuditEvent, err error) {
+	ctx, span := store.start(ctx, "ListAuditEventsByCursor")
+	defer end(span, &err)
+	return store.Store.ListAuditEventsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListCommentsByOwner(ctx context.Context, owner string) (result []db.Comment, err error) {
+	ctx, span := store.start(ctx, "ListCommentsByOwner")
+	defer end(span, &err)
+	return store.Store.ListCommentsByOwner(ctx, owner)
+}
+
+func (store *TracedStore) ListConsultancies(ctx context.Context, arg db.ListConsultanciesParams) (result []db.Consultancy, err error) {
+	ctx, span := store.start(ctx, "ListConsultancies")
+	defer end(span, &err)
+	return store.Store.ListConsultancies(ctx, arg)
+}
+
+func (store *TracedStore) ListConsultanciesByOwner(ctx context.Context, owner string) (result []db.Consultancy, err error) {
+	ctx, span := store.start(ctx, "ListConsultanciesByOwner")
+	defer end(span, &err)
+	return store.Store.ListConsultanciesByOwner(ctx, owner)
+}
+
+func (store *TracedStore) ListCustomersByOwner(ctx context.Context, owner string) (result []db.Customer, err error) {
+	ctx, span := store.start(ctx, "ListCustomersByOwner")
+	defer end(span, &err)
+	return store.Store.ListCustomersByOwner(ctx, owner)
+}
+
+func (store *TracedStore) ListDeadJobs(ctx context.Context, arg db.ListDeadJobsParams) (result []db.Job, err error) {
+	ctx, span := store.start(ctx, "ListDeadJobs")
+	defer end(span, &err)
+	return store.Store.ListDeadJobs(ctx, arg)
+}
+
+func (store *TracedStore) ListMerchantComments(ctx context.Context, arg db.ListMerchantCommentsParams) (result []db.Comment, err error) {
+	ctx, span := store.start(ctx, "ListMerchantComments")
+	defer end(span, &err)
+	return store.Store.ListMerchantComments(ctx, arg)
+}
+
+func (store *TracedStore) ListMerchantPosts(ctx context.Context, arg db.ListMerchantPostsParams) (result []db.Post, err error) {
+	ctx, span := store.start(ctx, "ListMerchantPosts")
+	defer end(span, &err)
+	return store.Store.ListMerchantPosts(ctx, arg)
+}
+
+func (store *TracedStore) ListMerchantPostsByCursor(ctx context.Context, arg db.ListMerchantPostsByCursorParams) (result []db.Post, err error) {
+	ctx, span := store.start(ctx, "ListMerchantPostsByCursor")
+	defer end(span, &err)
+	return store.Store.ListMerchantPostsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListMerchants(ctx context.Context, arg db.ListMerchantsParams) (result []db.Merchant, err error) {
+	ctx, span := store.start(ctx, "ListMerchants")
+	defer end(span, &err)
+	return store.Store.ListMerchants(ctx, arg)
+}
+
+func (store *TracedStore) ListMerchantsByCursor(ctx context.Context, arg db.ListMerchantsByCursorParams) (result []db.Merchant, err error) {
+	ctx, span := store.start(ctx, "ListMerchantsByCursor")
+	defer end(span, &err)
+	return store.Store.ListMerchantsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListMerchantsByOwner(ctx context.Context, owner string) (result []db.Merchant, err error) {
+	ctx, span := store.start(ctx, "ListMerchantsByOwner")
+	defer end(span, &err)
+	return store.Store.ListMerchantsByOwner(ctx, owner)
+}
+
+func (store *TracedStore) ListNotificationPreferences(ctx context.Context, username string) (result []db.NotificationPreference, err error) {
+	ctx, span := store.start(ctx, "ListNotificationPreferences")
+	defer end(span, &err)
+	return store.Store.ListNotificationPreferences(ctx, username)
+}
+
+func (store *TracedStore) ListNotifications(ctx context.Context, arg db.ListNotificationsParams) (result []db.Notification, err error) {
+	ctx, span := store.start(ctx, "ListNotifications")
+	defer end(span, &err)
+	return store.Store.ListNotifications(ctx, arg)
+}
+
+func (store *TracedStore) ListNotificationsByCursor(ctx context.Context, arg db.ListNotificationsByCursorParams) (result []db.Notification, err error) {
+	ctx, span := store.start(ctx, "ListNotificationsByCursor")
+	defer end(span, &err)
+	return store.Store.ListNotificationsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListOutboxEventsForUpdate(ctx context.Context, limit int32) (result []db.OutboxEvent, err error) {
+	ctx, span := store.start(ctx, "ListOutboxEventsForUpdate")
+	defer end(span, &err)
+	return store.Store.ListOutboxEventsForUpdate(ctx, limit)
+}
+
+func (store *TracedStore) ListPostComments(ctx context.Context, arg db.ListPostCommentsParams) (result []db.Comment, err error) {
+	ctx, span := store.start(ctx, "ListPostComments")
+	defer end(span, &err)
+	return store.Store.ListPostComments(ctx, arg)
+}
+
+func (store *TracedStore) ListPostCommentsByCursor(ctx context.Context, arg db.ListPostCommentsByCursorParams) (result []db.Comment, err error) {
+	ctx, span := store.start(ctx, "ListPostCommentsByCursor")
+	defer end(span, &err)
+	return store.Store.ListPostCommentsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListPostRevisions(ctx context.Context, arg db.ListPostRevisionsParams) (result []db.PostRevision, err error) {
+	ctx, span := store.start(ctx, "ListPostRevisions")
+	defer end(span, &err)
+	return store.Store.ListPostRevisions(ctx, arg)
+}
+
+func (store *TracedStore) ListPostRevisionsByCursor(ctx context.Context, arg db.ListPostRevisionsByCursorParams) (result []db.PostRevision, err error) {
+	ctx, span := store.start(ctx, "ListPostRevisionsByCursor")
+	defer end(span, &err)
+	return store.Store.ListPostRevisionsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListPosts(ctx context.Context, arg db.ListPostsParams) (result []db.Post, err error) {
+	ctx, span := store.start(ctx, "ListPosts")
+	defer end(span, &err)
+	return store.Store.ListPosts(ctx, arg)
+}
+
+func (store *TracedStore) ListPostsByCursor(ctx context.Context, arg db.ListPostsByCursorParams) (result []db.Post, err error) {
+	ctx, span := store.start(ctx, "ListPostsByCursor")
+	defer end(span, &err)
+	return store.Store.ListPostsByCursor(ctx, arg)
+}
+
+func (store *TracedStore) ListPostsByOwner(ctx context.Context, owner string) (result []db.Post, err error) {
+	ctx, span := store.start(ctx, "ListPostsByOwner")
+	defer end(span, &err)
+	return store.Store.ListPostsByOwner(ctx, owner)
+}
+
+func (store *TracedStore) ListUserAuditEvents(ctx context.Context, username string) (result []db.AuditEvent, err error) {
+	ctx, span := store.start(ctx, "ListUserAuditEvents")
+	defer end(span, &err)
+	return store.Store.ListUserAuditEvents(ctx, username)
+}
+
+func (store *TracedStore) ListUserDeviceTokens(ctx context.Context, username string) (result []db.DeviceToken, err error) {
+	ctx, span := store.start(ctx, "ListUserDeviceTokens")
+	defer end(span, &err)
+	return store.Store.ListUserDeviceTokens(ctx, username)
+}
+
+func (store *TracedStore) ListUserNotifications(ctx context.Context, recipient string) (result []db.Notification, err error) {
+	ctx, span := store.start(ctx, "ListUserNotifications")
+	defer end(span, &err)
+	return store.Store.ListUserNotifications(ctx, recipient)
+}
+
+func (store *TracedStore) ListUserSessions(ctx context.Context, username string) (result []db.Session, err error) {
+	ctx, span := store.start(ctx, "ListUserSessions")
+	defer end(span, &err)
+	return store.Store.ListUserSessions(ctx, username)
+}
+
+func (store *TracedStore) ListUsersDueForDeletion(ctx context.Context, arg db.ListUsersDueForDeletionParams) (result []string, err error) {
+	ctx, span := store.start(ctx, "ListUsersDueForDeletion")
+	defer end(span, &err)
+	return store.Store.ListUsersDueForDeletion(ctx, arg)
+}
+
+func (store *TracedStore) MarkAllNotificationsRead(ctx context.Context, recipient string) (result int64, err error) {
+	ctx, span := store.start(ctx, "MarkAllNotificationsRead")
+	defer end(span, &err)
+	return store.Store.MarkAllNotificationsRead(ctx, recipient)
+}
+
+func (store *TracedStore) MarkNotificationRead(ctx context.Context, arg db.MarkNotificationReadParams) (result db.Notification, err error) {
+	ctx, span := store.start(ctx, "MarkNotificationRead")
+	defer end(span, &err)
+	return store.Store.MarkNotificationRead(ctx, arg)
+}
+
+func (store *TracedStore) Ping(ctx context.Context) (err error) {
+	ctx, span := store.start(ctx, "Ping")
+	defer end(span, &err)
+	return store.Store.Ping(ctx)
+}
+
+func (store *TracedStore) RelayOutboxTx(ctx context.Context, arg db.RelayOutboxTxParams) (result int, err error) {
+	ctx, span := store.start(ctx, "RelayOutboxTx")
+	defer end(span, &err)
+	return store.Store.RelayOutboxTx(ctx, arg)
+}
+
+func (store *TracedStore) RequeueDeadJob(ctx context.Context, id int64) (result db.Job, err error) {
+	ctx, span := store.start(ctx, "RequeueDeadJob")
+	defer end(span, &err)
+	return store.Store.RequeueDeadJob(ctx, id)
+}
+
+func (store *TracedStore) RetryJob(ctx context.Context, arg db.RetryJobParams) (result db.Job, err error) {
+	ctx, span := store.start(ctx, "RetryJob")
+	defer end(span, &err)
+	return store.Store.RetryJob(ctx, arg)
+}
+
+func (store *TracedStore) ScheduleUserDeletion(ctx context.Context, arg db.ScheduleUserDeletionParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "ScheduleUserDeletion")
+	defer end(span, &err)
+	return store.Store.ScheduleUserDeletion(ctx, arg)
+}
+
+func (store *TracedStore) SetCustomerImageVariants(ctx context.Context, arg db.SetCustomerImageVariantsParams) (result int64, err error) {
+	ctx, span := store.start(ctx, "SetCustomerImageVariants")
+	defer end(span, &err)
+	return store.Store.SetCustomerImageVariants(ctx, arg)
+}
+
+func (store *TracedStore) SetMerchantImageVariants(ctx context.Context, arg db.SetMerchantImageVariantsParams) (result int64, err error) {
+	ctx, span := store.start(ctx, "SetMerchantImageVariants")
+	defer end(span, &err)
+	return store.Store.SetMerchantImageVariants(ctx, arg)
+}
+
+func (store *TracedStore) SetPostImageVariants(ctx context.Context, arg db.SetPostImageVariantsParams) (result int64, err error) {
+	ctx, span := store.start(ctx, "SetPostImageVariants")
+	defer end(span, &err)
+	return store.Store.SetPostImageVariants(ctx, arg)
+}
+
+func (store *TracedStore) SetUserDisabled(ctx context.Context, arg db.SetUserDisabledParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "SetUserDisabled")
+	defer end(span, &err)
+	return store.Store.SetUserDisabled(ctx, arg)
+}
+
+func (store *TracedStore) SetUserImageVariants(ctx context.Context, arg db.SetUserImageVariantsParams) (result int64, err error) {
+	ctx, span := store.start(ctx, "SetUserImageVariants")
+	defer end(span, &err)
+	return store.Store.SetUserImageVariants(ctx, arg)
+}
+
+func (store *TracedStore) UpdateAppVersion(ctx context.Context, arg db.UpdateAppVersionParams) (result db.AppVersion, err error) {
+	ctx, span := store.start(ctx, "UpdateAppVersion")
+	defer end(span, &err)
+	return store.Store.UpdateAppVersion(ctx, arg)
+}
+
+func (store *TracedStore) UpdateCustomer(ctx context.Context, arg db.UpdateCustomerParams) (result db.Customer, err error) {
+	ctx, span := store.start(ctx, "UpdateCustomer")
+	defer end(span, &err)
+	return store.Store.UpdateCustomer(ctx, arg)
+}
+
+func (store *TracedStore) UpdateEmail(ctx context.Context, arg db.UpdateEmailParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "UpdateEmail")
+	defer end(span, &err)
+	return store.Store.UpdateEmail(ctx, arg)
+}
+
+func (store *TracedStore) UpdateMerchant(ctx context.Context, arg db.UpdateMerchantParams) (result db.Merchant, err error) {
+	ctx, span := store.start(ctx, "UpdateMerchant")
+	defer end(span, &err)
+	return store.Store.UpdateMerchant(ctx, arg)
+}
+
+func (store *TracedStore) UpdateMerchantImage(ctx context.Context, arg db.UpdateMerchantImageParams) (result db.Merchant, err error) {
+	ctx, span := store.start(ctx, "UpdateMerchantImage")
+	defer end(span, &err)
+	return store.Store.UpdateMerchantImage(ctx, arg)
+}
+
+func (store *TracedStore) UpdateNotificationPreferencesTx(ctx context.Context, arg db.UpdateNotificationPreferencesTxParams) (result db.UpdateNotificationPreferencesTxResult, err error) {
+	ctx, span := store.start(ctx, "UpdateNotificationPreferencesTx")
+	defer end(span, &err)
+	return store.Store.UpdateNotificationPreferencesTx(ctx, arg)
+}
+
+func (store *TracedStore) UpdatePassword(ctx context.Context, arg db.UpdatePasswordParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "UpdatePassword")
+	defer end(span, &err)
+	return store.Store.UpdatePassword(ctx, arg)
+}
+
+func (store *TracedStore) UpdatePost(ctx context.Context, arg db.UpdatePostParams) (result db.Post, err error) {
+	ctx, span := store.start(ctx, "UpdatePost")
+	defer end(span, &err)
+	return store.Store.UpdatePost(ctx, arg)
+}
+
+func (store *TracedStore) UpdatePostTx(ctx context.Context, arg db.UpdatePostTxParams) (result db.UpdatePostTxResult, err error) {
+	ctx, span := store.start(ctx, "UpdatePostTx")
+	defer end(span, &err)
+	return store.Store.UpdatePostTx(ctx, arg)
+}
+
+func (store *TracedStore) UpdateUser(ctx context.Context, arg db.UpdateUserParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "UpdateUser")
+	defer end(span, &err)
+	return store.Store.UpdateUser(ctx, arg)
+}
+
+func (store *TracedStore) UpdateUserImage(ctx context.Context, arg db.UpdateUserImageParams) (result db.User, err error) {
+	ctx, span := store.start(ctx, "UpdateUserImage")
+	defer end(span, &err)
+	return store.Store.UpdateUserImage(ctx, arg)
+}
+
+func (store *TracedStore) UpsertDeviceToken(ctx context.Context, arg db.UpsertDeviceTokenParams) (result db.DeviceToken, err error) {
+	ctx, span := store.start(ctx, "UpsertDeviceToken")
+	defer end(span, &err)
+	return store.Store.UpsertDeviceToken(ctx, arg)
+}
+
+func (store *TracedStore) UpsertNotificationPreference(ctx context.Context, arg db.UpsertNotificationPreferenceParams) (result db.NotificationPreference, err error) {
+	ctx, span := store.start(ctx, "UpsertNotificationPreference")
+	defer end(span, &err)
+	return store.Store.UpsertNotificationPreference(ctx, arg)
+}
+
+func (store *TracedStore) UpsertNotificationSettings(ctx context.Context, arg db.UpsertNotificationSettingsParams) (result db.NotificationSetting, err error) {
+	ctx, span := store.start(ctx, "UpsertNotificationSettings")
+	defer end(span, &err)
+	return store.Store.UpsertNotificationSettings(ctx, arg)
+}
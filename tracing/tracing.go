@@ -0,0 +1,98 @@
+// Package tracing sets up the OpenTelemetry tracing of the server
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName is the name of the service in the traces
+const ServiceName = "thenut"
+
+// Exporters of the traces
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects where the traces are exported
+type Config struct {
+	Exporter string
+	// OTLPEndpoint is the host and port of the OTLP/HTTP collector. The
+	// OTEL_EXPORTER_OTLP_* environment variables are used when it is empty.
+	OTLPEndpoint string
+	OTLPInsecure bool
+	// Stdout is where the stdout exporter writes the spans
+	Stdout io.Writer
+}
+
+// Tracer returns the tracer of the service
+func Tracer() trace.Tracer {
+	return otel.Tracer(ServiceName)
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// function flushes the pending spans and must be called before exiting.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if exporter == nil {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, error) {
+	switch config.Exporter {
+	case "", ExporterNone:
+		return nil, nil
+	case ExporterStdout:
+		options := []stdouttrace.Option{}
+		if config.Stdout != nil {
+			options = append(options, stdouttrace.WithWriter(config.Stdout))
+		}
+		return stdouttrace.New(options...)
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{}
+		if config.OTLPEndpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(config.OTLPEndpoint))
+		}
+		if config.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+	}
+	return nil, fmt.Errorf("unsupported trace exporter %s", config.Exporter)
+}
@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/asdsec/thenut/db/memstore"
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/db/storetest"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+func TestSetup(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{
+		Exporter: ExporterStdout,
+		Stdout:   &buf,
+	})
+	require.NoError(t, err)
+
+	_, span := Tracer().Start(context.Background(), "test-span")
+	span.End()
+
+	require.NoError(t, shutdown(context.Background()))
+	require.Contains(t, buf.String(), `"Name":"test-span"`)
+	require.Contains(t, buf.String(), ServiceName)
+}
+
+func TestSetupExporters(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	for _, exporter := range []string{"", ExporterNone, ExporterOTLP} {
+		shutdown, err := Setup(context.Background(), Config{
+			Exporter:     exporter,
+			OTLPEndpoint: "localhost:4318",
+			OTLPInsecure: true,
+		})
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+	}
+
+	shutdown, err := Setup(context.Background(), Config{Exporter: "jaeger"})
+	require.EqualError(t, err, "unsupported trace exporter jaeger")
+	require.Nil(t, shutdown)
+}
+
+func TestTracedStore(t *testing.T) {
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mock_db.NewMockStore(ctrl)
+	gomock.InOrder(
+		mockStore.EXPECT().GetUser(gomock.Any(), gomock.Eq("user1")).Return(db.User{Username: "user1"}, nil),
+		mockStore.EXPECT().GetUser(gomock.Any(), gomock.Eq("user2")).Return(db.User{}, sql.ErrNoRows),
+		mockStore.EXPECT().DeleteUserTx(gomock.Any(), gomock.Eq("user1")).Return(db.DeleteUserTxResult{}, sql.ErrConnDone),
+	)
+
+	store := NewTracedStore(mockStore)
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	_, err := store.GetUser(ctx, "user1")
+	require.NoError(t, err)
+	_, err = store.GetUser(ctx, "user2")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = store.DeleteUserTx(ctx, "user1")
+	require.ErrorIs(t, err, sql.ErrConnDone)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	expected := []struct {
+		name   string
+		status codes.Code
+	}{
+		{"GetUser", codes.Unset},
+		{"GetUser", codes.Unset},
+		{"DeleteUserTx", codes.Error},
+	}
+	for i, want := range expected {
+		span := spans[i]
+		require.Equal(t, want.name, span.Name())
+		require.Equal(t, want.status, span.Status().Code)
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		require.Contains(t, span.Attributes(), semconv.DBOperation(want.name))
+	}
+}
+
+func TestTracedStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) db.Store {
+		return NewTracedStore(memstore.NewStore())
+	})
+}
@@ -0,0 +1,27 @@
+package utils
+
+import "runtime"
+
+// The build info is injected at link time, for example:
+//
+//	go build -ldflags "-X github.com/asdsec/thenut/utils.GitCommit=$(git rev-parse HEAD)"
+var (
+	GitCommit = "unknown"
+	BuildTime = "unknown"
+)
+
+// BuildInfo describes the running binary
+type BuildInfo struct {
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+// GetBuildInfo returns the build info of the running binary
+func GetBuildInfo() BuildInfo {
+	return BuildInfo{
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
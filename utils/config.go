@@ -7,12 +7,47 @@
 )
 
 type Config struct {
-	DBDriver             string        `mapstructure:"DB_DRIVE"`
-	DBSource             string        `mapstructure:"DB_SOURCE"`
-	ServerAddress        string        `mapstructure:"SERVER_ADDR"`
-	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
-	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	DBDriver                    string        `mapstructure:"DB_DRIVE"`
+	LogLevel                    string        `mapstructure:"LOG_LEVEL"`
+	TraceExporter               string        `mapstructure:"TRACE_EXPORTER"`
+	OTLPEndpoint                string        `mapstructure:"OTLP_ENDPOINT"`
+	OTLPInsecure                bool          `mapstructure:"OTLP_INSECURE"`
+	DBSource                    string        `mapstructure:"DB_SOURCE"`
+	AutoMigrate                 bool          `mapstructure:"AUTO_MIGRATE"`
+	ServerAddress               string        `mapstructure:"SERVER_ADDR"`
+	GRPCServerAddress           string        `mapstructure:"GRPC_SERVER_ADDR"`
+	HTTPReadTimeout             time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`
+	HTTPWriteTimeout            time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`
+	HTTPIdleTimeout             time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`
+	ShutdownTimeout             time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	TokenSymmetricKey           string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration         time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration        time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	StorageBackend              string        `mapstructure:"STORAGE_BACKEND"`
+	StorageLocalDir             string        `mapstructure:"STORAGE_LOCAL_DIR"`
+	StoragePublicURL            string        `mapstructure:"STORAGE_PUBLIC_URL"`
+	S3Endpoint                  string        `mapstructure:"S3_ENDPOINT"`
+	S3AccessKeyID               string        `mapstructure:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey           string        `mapstructure:"S3_SECRET_ACCESS_KEY"`
+	S3Bucket                    string        `mapstructure:"S3_BUCKET"`
+	S3UseSSL                    bool          `mapstructure:"S3_USE_SSL"`
+	MaxUploadSize               int64         `mapstructure:"MAX_UPLOAD_SIZE"`
+	AccountDeletionGracePeriod  time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+	AccountPurgeInterval        time.Duration `mapstructure:"ACCOUNT_PURGE_INTERVAL"`
+	IdempotencyKeyTTL           time.Duration `mapstructure:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencyKeyPurgeInterval time.Duration `mapstructure:"IDEMPOTENCY_KEY_PURGE_INTERVAL"`
+	FCMProjectID                string        `mapstructure:"FCM_PROJECT_ID"`
+	FCMCredentialsFile          string        `mapstructure:"FCM_CREDENTIALS_FILE"`
+	APNsKeyFile                 string        `mapstructure:"APNS_KEY_FILE"`
+	APNsKeyID                   string        `mapstructure:"APNS_KEY_ID"`
+	APNsTeamID                  string        `mapstructure:"APNS_TEAM_ID"`
+	APNsTopic                   string        `mapstructure:"APNS_TOPIC"`
+	APNsProduction              bool          `mapstructure:"APNS_PRODUCTION"`
+	JobWorkers                  int           `mapstructure:"JOB_WORKERS"`
+	JobPollInterval             time.Duration `mapstructure:"JOB_POLL_INTERVAL"`
+	JobLease                    time.Duration `mapstructure:"JOB_LEASE"`
+	OutboxBatchSize             int32         `mapstructure:"OUTBOX_BATCH_SIZE"`
+	OutboxPollInterval          time.Duration `mapstructure:"OUTBOX_POLL_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -0,0 +1,16 @@
+package utils
+
+const (
+	UserRole      = "user"
+	ModeratorRole = "moderator"
+	AdminRole     = "admin"
+)
+
+// IsSupportedRole returns true if the provided role is supported
+func IsSupportedRole(role string) bool {
+	switch role {
+	case UserRole, ModeratorRole, AdminRole:
+		return true
+	}
+	return false
+}
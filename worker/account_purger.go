@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+	"github.com/asdsec/thenut/storage"
+)
+
+const purgeBatchSize = 100
+
+// AccountPurger periodically deletes the accounts whose deletion grace period
+// has passed, along with their uploads
+type AccountPurger struct {
+	store    db.Store
+	storage  storage.BlobStorage
+	interval time.Duration
+}
+
+// NewAccountPurger creates a new AccountPurger
+func NewAccountPurger(store db.Store, storage storage.BlobStorage, interval time.Duration) *AccountPurger {
+	return &AccountPurger{
+		store:    store,
+		storage:  storage,
+		interval: interval,
+	}
+}
+
+// Run purges the due accounts on every interval until the context is canceled
+func (purger *AccountPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(purger.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := purger.PurgeDueAccounts(ctx); err != nil {
+			logger.FromContext(ctx).Error("cannot purge accounts", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeDueAccounts deletes the accounts whose deletion is due and returns how
+// many of them are deleted. An account that cannot be deleted is retried on the next run.
+func (purger *AccountPurger) PurgeDueAccounts(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		usernames, err := purger.store.ListUsersDueForDeletion(ctx, db.ListUsersDueForDeletionParams{
+			Now:        time.Now(),
+			LimitCount: purgeBatchSize,
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		failed := 0
+		for _, username := range usernames {
+			result, err := purger.store.DeleteUserTx(ctx, username)
+			if err != nil {
+				logger.FromContext(ctx).Error("cannot purge account", "username", username, "err", err)
+				failed++
+				continue
+			}
+			purger.deleteUploads(ctx, username, result)
+			purged++
+		}
+
+		// stop when the batch is drained or only failing accounts are left
+		if len(usernames) < purgeBatchSize || failed == len(usernames) {
+			return purged, nil
+		}
+	}
+}
+
+// deleteUploads removes the folders of the purged account and of its
+// anonymized merchants and customers. It runs after the transaction commits,
+// so that no row is left showing a removed image. A folder that cannot be
+// removed is only logged, since the account is gone either way.
+func (purger *AccountPurger) deleteUploads(ctx context.Context, username string, result db.DeleteUserTxResult) {
+	folders := []string{storage.UserFolder(username)}
+	for _, id := range result.MerchantIDs {
+		folders = append(folders, storage.MerchantFolder(id), storage.PostFolder(id))
+	}
+	for _, id := range result.CustomerIDs {
+		folders = append(folders, storage.CustomerFolder(id))
+	}
+
+	for _, folder := range folders {
+		if err := purger.storage.DeleteFolder(ctx, folder); err != nil {
+			logger.FromContext(ctx).Error("cannot delete uploads of purged account", "username", username, "folder", folder, "err", err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/storage"
+	"github.com/asdsec/thenut/utils"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeDueAccounts(t *testing.T) {
+	usernames := []string{utils.RandomOwner(), utils.RandomOwner(), utils.RandomOwner()}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mock_db.MockStore)
+		checkCount func(t *testing.T, purged int, err error)
+	}{
+		{
+			name: "Ok",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(usernames, nil)
+
+				for _, username := range usernames {
+					store.EXPECT().
+						DeleteUserTx(gomock.Any(), gomock.Eq(username)).
+						Times(1).
+						Return(db.DeleteUserTxResult{}, nil)
+				}
+			},
+			checkCount: func(t *testing.T, purged int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, len(usernames), purged)
+			},
+		},
+		{
+			name: "SkipFailedAccount",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(usernames, nil)
+
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Eq(usernames[0])).
+					Times(1).
+					Return(db.DeleteUserTxResult{}, sql.ErrConnDone)
+
+				for _, username := range usernames[1:] {
+					store.EXPECT().
+						DeleteUserTx(gomock.Any(), gomock.Eq(username)).
+						Times(1).
+						Return(db.DeleteUserTxResult{}, nil)
+				}
+			},
+			checkCount: func(t *testing.T, purged int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, len(usernames)-1, purged)
+			},
+		},
+		{
+			name: "NothingDue",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]string{}, nil)
+
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkCount: func(t *testing.T, purged int, err error) {
+				require.NoError(t, err)
+				require.Zero(t, purged)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+
+				store.EXPECT().
+					DeleteUserTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkCount: func(t *testing.T, purged int, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Zero(t, purged)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			blobs, err := storage.NewLocalStorage(t.TempDir(), "/media")
+			require.NoError(t, err)
+
+			purger := NewAccountPurger(store, blobs, time.Minute)
+			purged, err := purger.PurgeDueAccounts(context.Background())
+			tc.checkCount(t, purged, err)
+		})
+	}
+}
+
+func TestPurgeDueAccountsDeletesUploads(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	username := utils.RandomOwner()
+	result := db.DeleteUserTxResult{MerchantIDs: []int64{1}, CustomerIDs: []int64{2}}
+
+	store := mock_db.NewMockStore(ctrl)
+	store.EXPECT().
+		ListUsersDueForDeletion(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]string{username}, nil)
+	store.EXPECT().
+		DeleteUserTx(gomock.Any(), gomock.Eq(username)).
+		Times(1).
+		Return(result, nil)
+
+	blobs, err := storage.NewLocalStorage(t.TempDir(), "/media")
+	require.NoError(t, err)
+
+	deleted := []string{
+		storage.UserFolder(username) + "/avatar.jpg",
+		storage.MerchantFolder(1) + "/avatar.jpg",
+		storage.PostFolder(1) + "/image.jpg",
+		storage.PostFolder(1) + "/image_small.jpg",
+		storage.CustomerFolder(2) + "/avatar.jpg",
+	}
+	kept := []string{
+		storage.UserFolder(utils.RandomOwner()) + "/avatar.jpg",
+		storage.PostFolder(3) + "/image.jpg",
+	}
+	for _, key := range append(deleted, kept...) {
+		_, err := blobs.Put(context.Background(), key, "image/jpeg", bytes.NewReader(nil), 0)
+		require.NoError(t, err)
+	}
+
+	purger := NewAccountPurger(store, blobs, time.Minute)
+	purged, err := purger.PurgeDueAccounts(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	for _, key := range deleted {
+		_, err := blobs.Get(context.Background(), key)
+		require.ErrorIs(t, err, storage.ErrNotFound, key)
+	}
+	for _, key := range kept {
+		reader, err := blobs.Get(context.Background(), key)
+		require.NoError(t, err, key)
+		require.NoError(t, reader.Close())
+	}
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+)
+
+// IdempotencyKeyPurger periodically deletes the expired idempotency keys
+type IdempotencyKeyPurger struct {
+	store    db.Store
+	interval time.Duration
+}
+
+// NewIdempotencyKeyPurger creates a new IdempotencyKeyPurger
+func NewIdempotencyKeyPurger(store db.Store, interval time.Duration) *IdempotencyKeyPurger {
+	return &IdempotencyKeyPurger{
+		store:    store,
+		interval: interval,
+	}
+}
+
+// Run purges the expired keys on every interval until the context is canceled
+func (purger *IdempotencyKeyPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(purger.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := purger.PurgeExpiredKeys(ctx); err != nil {
+			logger.FromContext(ctx).Error("cannot purge idempotency keys", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpiredKeys deletes the expired keys and returns how many of them are
+// deleted. The expired keys are already ignored by the requests, so this only
+// keeps the table small.
+func (purger *IdempotencyKeyPurger) PurgeExpiredKeys(ctx context.Context) (int64, error) {
+	return purger.store.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+}
@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	mock_db "github.com/asdsec/thenut/db/mock"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurgeExpiredKeys(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mock_db.MockStore)
+		checkCount func(t *testing.T, purged int64, err error)
+	}{
+		{
+			name: "Ok",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					DeleteExpiredIdempotencyKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(3), nil)
+			},
+			checkCount: func(t *testing.T, purged int64, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(3), purged)
+			},
+		},
+		{
+			name: "InternalError",
+			buildStubs: func(store *mock_db.MockStore) {
+				store.EXPECT().
+					DeleteExpiredIdempotencyKeys(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkCount: func(t *testing.T, purged int64, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+				require.Zero(t, purged)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mock_db.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			purger := NewIdempotencyKeyPurger(store, time.Minute)
+			purged, err := purger.PurgeExpiredKeys(context.Background())
+			tc.checkCount(t, purged, err)
+		})
+	}
+}
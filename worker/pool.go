@@ -0,0 +1,202 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/asdsec/thenut/logger"
+)
+
+const (
+	retryBaseDelay = 10 * time.Second
+	retryMaxDelay  = time.Hour
+	// recordTimeout bounds the update recording the result of a job
+	recordTimeout = 10 * time.Second
+)
+
+var errLeaseExpired = errors.New("lease expired on the last attempt")
+
+type handlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+// Pool runs the jobs of the queue on a number of workers. Every worker claims
+// one job at a time, so the slow jobs do not hold the others back. The jobs
+// run at least once: a job whose worker is gone before it is finished runs
+// again after its lease, so the handlers have to be idempotent.
+type Pool struct {
+	store        db.Store
+	workers      int
+	pollInterval time.Duration
+	lease        time.Duration
+	handlers     map[string]handlerFunc
+	now          func() time.Time
+}
+
+// NewPool creates a new Pool. The idle workers look for the due jobs on every
+// poll interval, and a job that takes longer than the lease is canceled.
+func NewPool(store db.Store, workers int, pollInterval, lease time.Duration) *Pool {
+	return &Pool{
+		store:        store,
+		workers:      workers,
+		pollInterval: pollInterval,
+		lease:        lease,
+		handlers:     make(map[string]handlerFunc),
+		now:          time.Now,
+	}
+}
+
+// Handle sets the handler of the jobs of the kind. It is not safe to call
+// while the pool runs.
+func Handle[T any](pool *Pool, kind Kind[T], handler func(ctx context.Context, args T) error) {
+	pool.handlers[kind.Name] = func(ctx context.Context, payload json.RawMessage) error {
+		var args T
+		if err := json.Unmarshal(payload, &args); err != nil {
+			return Permanent(fmt.Errorf("cannot decode arguments: %w", err))
+		}
+		return handler(ctx, args)
+	}
+}
+
+// Run runs the jobs until the context is canceled, and then waits for the
+// running jobs to finish
+func (pool *Pool) Run(ctx context.Context) {
+	var workers sync.WaitGroup
+	for i := 0; i < pool.workers; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			pool.work(ctx)
+		}()
+	}
+	workers.Wait()
+}
+
+// work runs the jobs one by one, and waits for the poll interval when there
+// is nothing due
+func (pool *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		ran, err := pool.RunNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.FromContext(ctx).Error("cannot claim job", "err", err)
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(pool.pollInterval):
+		}
+	}
+}
+
+// RunNext claims the next due job and runs it. It reports whether there was a
+// job to run.
+func (pool *Pool) RunNext(ctx context.Context) (bool, error) {
+	now := pool.now()
+
+	// a job whose lease expired on its last attempt is not taken over, since
+	// it may be what crashed or hung its worker
+	dead, err := pool.store.KillStaleJobs(ctx, db.KillStaleJobsParams{
+		LastError:   errLeaseExpired.Error(),
+		StaleBefore: now.Add(-pool.lease),
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, job := range dead {
+		logger.FromContext(ctx).Error("job is dead", "job_id", job.ID, "kind", job.Kind, "err", errLeaseExpired)
+	}
+
+	jobs, err := pool.store.ClaimJobs(ctx, db.ClaimJobsParams{
+		Now:         now,
+		StaleBefore: now.Add(-pool.lease),
+		LimitCount:  1,
+	})
+	if err != nil || len(jobs) == 0 {
+		return false, err
+	}
+
+	pool.run(ctx, jobs[0])
+	return true, nil
+}
+
+// run runs the job and records its result. A finished job is deleted, a
+// failed one is retried with an exponential backoff until it runs out of
+// attempts, and then it is dead.
+func (pool *Pool) run(ctx context.Context, job db.Job) {
+	log := logger.FromContext(ctx).With("job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+
+	// the claimed job is finished even if the pool is stopping, but not after
+	// its lease, when another worker may take it over
+	jobCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), pool.lease)
+	err := pool.handle(jobCtx, job)
+	cancel()
+
+	// the result is recorded even if the job used up its lease. A job taken
+	// over by another worker is not claimed with the same lock any more, so
+	// its result is left to that worker.
+	ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), recordTimeout)
+	defer cancel()
+
+	lockedAt := job.LockedAt.Time
+	if err == nil {
+		completed, err := pool.store.CompleteJob(ctx, db.CompleteJobParams{ID: job.ID, LockedAt: lockedAt})
+		if err != nil {
+			log.Error("cannot complete job", "err", err)
+		} else if completed == 0 {
+			log.Warn("job is taken over before it is completed")
+		}
+		return
+	}
+
+	lastError := sql.NullString{String: err.Error(), Valid: true}
+	if IsPermanent(err) || job.Attempts >= job.MaxAttempts {
+		log.Error("job is dead", "err", err)
+		_, err = pool.store.KillJob(ctx, db.KillJobParams{ID: job.ID, LastError: lastError, LockedAt: lockedAt})
+		if err != nil {
+			log.Error("cannot kill job", "err", err)
+		}
+		return
+	}
+
+	runAt := pool.now().Add(retryDelay(job.Attempts))
+	log.Warn("job failed", "err", err, "retry_at", runAt)
+	_, err = pool.store.RetryJob(ctx, db.RetryJobParams{ID: job.ID, RunAt: runAt, LastError: lastError, LockedAt: lockedAt})
+	if err != nil {
+		log.Error("cannot retry job", "err", err)
+	}
+}
+
+// handle runs the handler of the job, a panic fails the job like an error
+func (pool *Pool) handle(ctx context.Context, job db.Job) (err error) {
+	handler, ok := pool.handlers[job.Kind]
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job kind %s", job.Kind))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+// retryDelay returns how long a job waits after its failed attempt. The delay
+// doubles on every attempt, up to retryMaxDelay.
+func retryDelay(attempts int32) time.Duration {
+	delay := retryBaseDelay
+	for i := int32(1); i < attempts && delay < retryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > retryMaxDelay {
+		return retryMaxDelay
+	}
+	return delay
+}
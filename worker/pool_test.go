@@ -0,0 +1,294 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/asdsec/thenut/db/memstore"
+	db "github.com/asdsec/thenut/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+type testArgs struct {
+	Name string `json:"name"`
+}
+
+var testKind = NewKind[testArgs]("test")
+
+// newTestPool returns a pool on an in-memory store whose clock is moved by the
+// tests. The clock starts ahead, so that the jobs enqueued now are due.
+func newTestPool(t *testing.T) (*Pool, db.Store, *time.Time) {
+	store := memstore.NewStore()
+	pool := NewPool(store, 2, time.Millisecond, time.Minute)
+	now := time.Now().Add(time.Second)
+	pool.now = func() time.Time {
+		return now
+	}
+	return pool, store, &now
+}
+
+// deadJobs returns the dead jobs in the order they are enqueued
+func deadJobs(t *testing.T, store db.Store) []db.Job {
+	jobs, err := store.ListDeadJobs(context.Background(), db.ListDeadJobsParams{Limit: 10})
+	require.NoError(t, err)
+	return jobs
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 10*time.Second, retryDelay(1))
+	require.Equal(t, 20*time.Second, retryDelay(2))
+	require.Equal(t, 80*time.Second, retryDelay(4))
+	require.Equal(t, retryMaxDelay, retryDelay(20))
+	require.Equal(t, retryMaxDelay, retryDelay(1000))
+}
+
+func TestPoolRetry(t *testing.T) {
+	pool, store, now := newTestPool(t)
+
+	var runs []string
+	Handle(pool, testKind, func(ctx context.Context, args testArgs) error {
+		runs = append(runs, args.Name)
+		if len(runs) == 1 {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	_, err := testKind.Enqueue(context.Background(), store, testArgs{Name: "first"})
+	require.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, ran)
+	require.Equal(t, []string{"first"}, runs)
+
+	// the failed job waits for its backoff
+	ran, err = pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.False(t, ran)
+
+	*now = now.Add(retryBaseDelay)
+	ran, err = pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, ran)
+	require.Equal(t, []string{"first", "first"}, runs)
+
+	// the finished job is gone
+	*now = now.Add(retryMaxDelay)
+	ran, err = pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.False(t, ran)
+	require.Empty(t, deadJobs(t, store))
+}
+
+func TestPoolDeadLetter(t *testing.T) {
+	pool, store, now := newTestPool(t)
+
+	Handle(pool, testKind, func(ctx context.Context, args testArgs) error {
+		if args.Name == "permanent" {
+			return Permanent(errors.New("invalid"))
+		}
+		if args.Name == "panic" {
+			panic("broken")
+		}
+		return errors.New("unavailable")
+	})
+
+	kind := testKind
+	kind.MaxAttempts = 2
+	_, err := kind.Enqueue(context.Background(), store, testArgs{Name: "failing"})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		ran, err := pool.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+		*now = now.Add(retryMaxDelay)
+	}
+
+	dead := deadJobs(t, store)
+	require.Len(t, dead, 1)
+	require.Equal(t, int32(2), dead[0].Attempts)
+	require.Equal(t, "unavailable", dead[0].LastError.String)
+
+	// the jobs that cannot succeed are dead at once
+	_, err = testKind.Enqueue(context.Background(), store, testArgs{Name: "permanent"})
+	require.NoError(t, err)
+	_, err = NewKind[testArgs]("unknown").Enqueue(context.Background(), store, testArgs{})
+	require.NoError(t, err)
+	_, err = store.EnqueueJob(context.Background(), db.EnqueueJobParams{
+		Kind:        testKind.Name,
+		Payload:     []byte(`{"name": 1}`),
+		MaxAttempts: DefaultMaxAttempts,
+		RunAt:       *now,
+	})
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		ran, err := pool.RunNext(context.Background())
+		require.NoError(t, err)
+		require.True(t, ran)
+	}
+
+	dead = deadJobs(t, store)
+	require.Len(t, dead, 4)
+	require.Equal(t, "invalid", dead[1].LastError.String)
+	require.Equal(t, "no handler for job kind unknown", dead[2].LastError.String)
+	require.Contains(t, dead[3].LastError.String, "cannot decode arguments")
+	for _, job := range dead[1:] {
+		require.Equal(t, int32(1), job.Attempts)
+	}
+
+	// a panic fails the job like an error
+	panicking, err := testKind.Enqueue(context.Background(), store, testArgs{Name: "panic"})
+	require.NoError(t, err)
+	ran, err := pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	jobs, err := store.ClaimJobs(context.Background(), db.ClaimJobsParams{
+		Now:         now.Add(retryBaseDelay),
+		StaleBefore: *now,
+		LimitCount:  10,
+	})
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	require.Equal(t, panicking.ID, jobs[0].ID)
+	require.Equal(t, "job panicked: broken", jobs[0].LastError.String)
+
+	// a dead job runs again once it is requeued
+	_, err = store.RequeueDeadJob(context.Background(), dead[0].ID)
+	require.NoError(t, err)
+	ran, err = pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, ran)
+}
+
+func TestPoolLease(t *testing.T) {
+	pool, store, now := newTestPool(t)
+	Handle(pool, testKind, func(ctx context.Context, args testArgs) error {
+		return nil
+	})
+
+	_, err := testKind.Enqueue(context.Background(), store, testArgs{})
+	require.NoError(t, err)
+
+	// the worker claiming the job is gone
+	_, err = store.ClaimJobs(context.Background(), db.ClaimJobsParams{
+		Now:         *now,
+		StaleBefore: now.Add(-pool.lease),
+		LimitCount:  1,
+	})
+	require.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.False(t, ran)
+
+	*now = now.Add(pool.lease)
+	ran, err = pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	// the job is finished, it is not taken over again
+	*now = now.Add(pool.lease)
+	ran, err = pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.False(t, ran)
+}
+
+func TestPoolResultAfterLease(t *testing.T) {
+	store := memstore.NewStore()
+	pool := NewPool(store, 1, time.Millisecond, 10*time.Millisecond)
+
+	// the handler fails because its lease is over, the failure is recorded
+	// anyway
+	Handle(pool, testKind, func(ctx context.Context, args testArgs) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	job, err := testKind.Enqueue(context.Background(), store, testArgs{})
+	require.NoError(t, err)
+
+	ran, err := pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, ran)
+
+	claimed, err := store.ClaimJobs(context.Background(), db.ClaimJobsParams{
+		Now:         time.Now().Add(time.Hour),
+		StaleBefore: time.Now().Add(-time.Hour),
+		LimitCount:  1,
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, job.ID, claimed[0].ID)
+	require.Equal(t, context.DeadlineExceeded.Error(), claimed[0].LastError.String)
+}
+
+func TestPoolLeaseLastAttempt(t *testing.T) {
+	pool, store, now := newTestPool(t)
+	kind := Kind[testArgs]{Name: testKind.Name, MaxAttempts: 1}
+	Handle(pool, kind, func(ctx context.Context, args testArgs) error {
+		t.Fatal("job is taken over after its last attempt")
+		return nil
+	})
+
+	_, err := kind.Enqueue(context.Background(), store, testArgs{})
+	require.NoError(t, err)
+
+	// the worker running the last attempt crashed
+	_, err = store.ClaimJobs(context.Background(), db.ClaimJobsParams{
+		Now:         *now,
+		StaleBefore: now.Add(-pool.lease),
+		LimitCount:  1,
+	})
+	require.NoError(t, err)
+
+	*now = now.Add(pool.lease)
+	ran, err := pool.RunNext(context.Background())
+	require.NoError(t, err)
+	require.False(t, ran)
+
+	dead := deadJobs(t, store)
+	require.Len(t, dead, 1)
+	require.Equal(t, errLeaseExpired.Error(), dead[0].LastError.String)
+}
+
+func TestPoolRun(t *testing.T) {
+	store := memstore.NewStore()
+	pool := NewPool(store, 3, time.Millisecond, time.Minute)
+
+	var handled atomic.Int32
+	Handle(pool, testKind, func(ctx context.Context, args testArgs) error {
+		handled.Add(1)
+		return nil
+	})
+
+	for i := 0; i < 10; i++ {
+		_, err := testKind.Enqueue(context.Background(), store, testArgs{})
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		return handled.Load() == 10
+	}, time.Second, time.Millisecond)
+
+	// the pool stops with the context
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool is not stopped")
+	}
+}
@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	db "github.com/asdsec/thenut/db/sqlc"
+)
+
+// DefaultMaxAttempts is how many times a job runs before it is dead, unless
+// its kind says otherwise
+const DefaultMaxAttempts = 5
+
+// Kind is a kind of jobs whose arguments are T. The arguments are stored as
+// JSON, so the name and a compatible T have to be kept across releases while
+// there are jobs of the kind in the queue.
+type Kind[T any] struct {
+	Name        string
+	MaxAttempts int32
+}
+
+// NewKind creates a new Kind with the default attempts
+func NewKind[T any](name string) Kind[T] {
+	return Kind[T]{
+		Name:        name,
+		MaxAttempts: DefaultMaxAttempts,
+	}
+}
+
+// Enqueue adds a job of the kind that runs as soon as a worker is free. The
+// store may be the querier of a transaction, so that the job is only added if
+// the transaction commits.
+func (kind Kind[T]) Enqueue(ctx context.Context, store db.Querier, args T) (db.Job, error) {
+	return kind.EnqueueAt(ctx, store, args, time.Now())
+}
+
+// EnqueueAt adds a job of the kind that runs at runAt or later
+func (kind Kind[T]) EnqueueAt(ctx context.Context, store db.Querier, args T, runAt time.Time) (db.Job, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return db.Job{}, err
+	}
+
+	return store.EnqueueJob(ctx, db.EnqueueJobParams{
+		Kind:        kind.Name,
+		Payload:     payload,
+		MaxAttempts: kind.MaxAttempts,
+		RunAt:       runAt,
+	})
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks the error of a job as one that retrying does not fix, so
+// that the job is dead at once
+func Permanent(err error) error {
+	return permanentError{err}
+}
+
+// IsPermanent reports whether the error is marked as Permanent
+func IsPermanent(err error) bool {
+	return errors.As(err, &permanentError{})
+}